@@ -46,6 +46,12 @@ type JobOperatorStatus struct {
 
 	// Total replicas count
 	Replicas int32 `json:"replicas,omitempty"`
+
+	// Conditions represent the latest observations of the JobOperator (Ready, Progressing, Degraded).
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
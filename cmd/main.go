@@ -21,6 +21,7 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -30,6 +31,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -39,6 +41,11 @@ import (
 
 	batchv1 "github.com/yeongki/my-operator/api/v1"
 	"github.com/yeongki/my-operator/internal/controller"
+	"github.com/yeongki/my-operator/internal/health"
+	"github.com/yeongki/my-operator/internal/managerconfig"
+	"github.com/yeongki/my-operator/internal/metrics"
+	"github.com/yeongki/my-operator/pkg/slo/logradapter"
+	"github.com/yeongki/my-operator/pkg/sloagent"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -61,12 +68,20 @@ func main() {
 	var webhookCertPath, webhookCertName, webhookCertKey string
 	var enableLeaderElection bool
 	var probeAddr string
+	var readyzChecks string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var sloAgentDir, sloAgentUploadURL string
+	var sloAgentWindow time.Duration
+	var configFile string
+	var watchNamespaces string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&readyzChecks, "readyz-checks", health.DefaultReadyChecks,
+		"Comma-separated readiness checks: ping, cache-sync (informers synced), "+
+			"webhook-cert (certificate in --webhook-cert-path loads), webhook (webhook server serving).")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -81,6 +96,16 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&sloAgentDir, "slo-agent-dir", "",
+		"If set, the in-process SLO agent writes one summary per window to this directory (e.g. a mounted volume).")
+	flag.StringVar(&sloAgentUploadURL, "slo-agent-upload-url", "",
+		"If set, the in-process SLO agent uploads each summary (s3://, gs:// or https://).")
+	flag.DurationVar(&sloAgentWindow, "slo-agent-window", 10*time.Minute, "The SLO agent measurement window.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma-separated namespaces the manager watches (default: $"+managerconfig.WatchNamespaceEnv+
+			", unset => all namespaces). Namespace-scoped RBAC: make deploy DEPLOY_CONFIG=config/namespaced.")
+	flag.StringVar(&configFile, "config", "",
+		"A YAML manager configuration file. Flags set on the command line override its values.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -89,6 +114,30 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	var fileConfig *managerconfig.Config
+	if configFile != "" {
+		var err error
+		if fileConfig, err = managerconfig.Load(configFile); err == nil {
+			err = fileConfig.ApplyTo(flag.CommandLine)
+		}
+		if err != nil {
+			setupLog.Error(err, "invalid manager configuration", "config", configFile)
+			os.Exit(1)
+		}
+		setupLog.Info("loaded manager configuration", "config", configFile)
+	}
+	if watchNamespaces == "" {
+		watchNamespaces = os.Getenv(managerconfig.WatchNamespaceEnv)
+	}
+	var cacheOptions cache.Options
+	if namespaces := managerconfig.ParseNamespaces(watchNamespaces); len(namespaces) > 0 {
+		setupLog.Info("watching namespaces", "namespaces", namespaces)
+		cacheOptions.DefaultNamespaces = map[string]cache.Config{}
+		for _, ns := range namespaces {
+			cacheOptions.DefaultNamespaces[ns] = cache.Config{}
+		}
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -183,6 +232,7 @@ func main() {
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
+		Cache:                  cacheOptions,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "b2909ea0.my.domain",
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
@@ -202,13 +252,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	jobOperatorSettings := fileConfig.Controller("joboperator")
 	if err := (&controller.JobOperatorReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		MaxConcurrentReconciles: jobOperatorSettings.MaxConcurrentReconciles,
+		RateLimiter:             jobOperatorSettings.RateLimiter(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "JobOperator")
 		os.Exit(1)
 	}
+	metrics.SetControllerSettings("joboperator", jobOperatorSettings)
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -227,14 +281,46 @@ func main() {
 		}
 	}
 
+	if sloAgentDir != "" || sloAgentUploadURL != "" {
+		agent, err := sloagent.New(sloagent.Options{
+			Dir:       sloAgentDir,
+			UploadURL: sloAgentUploadURL,
+			Window:    sloAgentWindow,
+			Tags:      map[string]string{"suite": "sloagent", "pod": os.Getenv("HOSTNAME")},
+			Logger:    logradapter.New(ctrl.Log.WithName("sloagent")),
+		})
+		if err == nil {
+			err = mgr.Add(agent)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to add SLO agent to manager")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
+	healthOpts := health.Options{
+		Cache:          mgr.GetCache(),
+		WebhookStarted: func() healthz.Checker { return mgr.GetWebhookServer().StartedChecker() },
+	}
+	if len(webhookCertPath) > 0 {
+		healthOpts.CertFile = filepath.Join(webhookCertPath, webhookCertName)
+		healthOpts.KeyFile = filepath.Join(webhookCertPath, webhookCertKey)
+	}
+	readyChecks, err := health.ReadyChecks(readyzChecks, healthOpts)
+	if err != nil {
+		setupLog.Error(err, "invalid --readyz-checks")
 		os.Exit(1)
 	}
+	for name, check := range readyChecks {
+		if err := mgr.AddReadyzCheck(name, check); err != nil {
+			setupLog.Error(err, "unable to set up ready check", "check", name)
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
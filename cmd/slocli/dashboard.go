@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/slo/export"
+	"github.com/yeongki/my-operator/pkg/slo/presets"
+	"github.com/yeongki/my-operator/pkg/slolab"
+)
+
+func runDashboard(args []string) int {
+	fs := flag.NewFlagSet("dashboard", flag.ContinueOnError)
+	preset := fs.String("preset", "controller-runtime",
+		"comma-separated SLI presets ("+strings.Join(presets.Names(), ", ")+")")
+	profilePath := fs.String("profile", "",
+		"slolab.yaml profile: its presets, metrics and objectives (-preset, when set, replaces the specs)")
+	title := fs.String("title", "", `dashboard title (default "SLO lab")`)
+	uid := fs.String("uid", "", "dashboard uid (default: derived from the title)")
+	selector := fs.String("selector", "", `extra label matchers for every query, e.g. suite="e2e"`)
+	out := fs.String("out", "", "output file (default: stdout)")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "usage: slocli dashboard [-preset P | -profile FILE] [-out FILE]")
+		_, _ = fmt.Fprintln(fs.Output(), "Writes a Grafana dashboard over the series of `slocli export` (one panel per SLI,")
+		_, _ = fmt.Fprintln(fs.Output(), "objective thresholds drawn as lines); import it or provision it from a file.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	prof := &slolab.Profile{}
+	if *profilePath != "" {
+		var err error
+		if prof, err = slolab.Load(*profilePath); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "dashboard: %v\n", err)
+			return 2
+		}
+		if flagSet(fs, "preset") {
+			prof.Presets, prof.Metrics = nil, nil
+		}
+	}
+	specs, err := presets.Resolve(*preset)
+	if err == nil {
+		specs, err = prof.Specs(specs)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "dashboard: %v\n", err)
+		return 2
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "dashboard: %v\n", err)
+			return 1
+		}
+		defer func() { _ = f.Close() }()
+		w = f
+	}
+	opts := export.GrafanaOptions{Title: *title, UID: *uid, Selector: *selector}
+	if err := export.WriteGrafanaDashboard(w, specs, opts); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "dashboard: %v\n", err)
+		return 1
+	}
+	return 0
+}
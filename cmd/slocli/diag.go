@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/diag"
+	"github.com/yeongki/my-operator/pkg/kubeutil"
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+func runDiag(args []string) int {
+	fs := flag.NewFlagSet("diag", flag.ContinueOnError)
+	namespaces := fs.String("n", "", "comma-separated namespaces to capture (required)")
+	out := fs.String("out", ".", "directory for the tarball")
+	name := fs.String("name", "", "tarball name without extension (default: diag-<unix time>)")
+	secrets := fs.Bool("include-secrets", false, "also dump Secret objects")
+	skipCRDs := fs.Bool("skip-crds", false, "skip CRD definitions")
+	tail := fs.Int("tail", 0, "lines per container log (0 = all)")
+	timeout := fs.Duration("timeout", 5*time.Minute, "overall timeout")
+	verbose := fs.Bool("v", false, "verbose logging")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var nsList []string
+	for _, ns := range strings.Split(*namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			nsList = append(nsList, ns)
+		}
+	}
+	if len(nsList) == 0 {
+		_, _ = fmt.Fprintln(os.Stderr, "diag: -n is required")
+		return 2
+	}
+
+	var logger slo.Logger
+	if *verbose {
+		logger = stderrLogger{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	path, err := diag.Collect(ctx, logger, kubeutil.DefaultRunner{}, diag.Options{
+		Namespaces:     nsList,
+		OutDir:         *out,
+		Name:           *name,
+		IncludeSecrets: *secrets,
+		SkipCRDs:       *skipCRDs,
+		LogTail:        *tail,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "diag: %v\n", err)
+		return 1
+	}
+	fmt.Println(path)
+	return 0
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yeongki/my-operator/pkg/slo/common/gzfile"
+	"github.com/yeongki/my-operator/pkg/slo/diff"
+)
+
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "usage: slocli diff [flags] BEFORE AFTER")
+		_, _ = fmt.Fprintln(fs.Output(), "Compares two raw /metrics scrapes series by series (added, removed, value")
+		_, _ = fmt.Fprintln(fs.Output(), "changes). Gzip'd scrapes (SLOLAB_CAPTURE_SCRAPES artifacts) are read as is.")
+		_, _ = fmt.Fprintln(fs.Output(), "Exits 1 when they differ.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	before, err := readScrape(fs.Arg(0))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		return 2
+	}
+	after, err := readScrape(fs.Arg(1))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		return 2
+	}
+	rep, err := diff.Text(before, after)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		return 2
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(rep)
+	} else {
+		_ = rep.WriteText(os.Stdout)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "diff: %d added, %d removed, %d changed, %d unchanged\n",
+		len(rep.Added), len(rep.Removed), len(rep.Changed), rep.Unchanged)
+	if !rep.Empty() {
+		return 1
+	}
+	return 0
+}
+
+// readScrape reads a raw scrape, gunzipping it when it is compressed.
+func readScrape(path string) (string, error) {
+	b, err := gzfile.ReadFile(path)
+	return string(b), err
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yeongki/my-operator/pkg/metricdrift"
+)
+
+func runDrift(args []string) int {
+	fs := flag.NewFlagSet("drift", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "usage: slocli drift [flags] BASELINE CURRENT")
+		_, _ = fmt.Fprintln(fs.Output(), "Compares metric families (names, types, label keys) of two runs. Each file is a")
+		_, _ = fmt.Fprintln(fs.Output(), "metric-families JSON artifact or a raw /metrics scrape. Exits 1 on removed,")
+		_, _ = fmt.Fprintln(fs.Output(), "renamed or changed families (additions are reported only).")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	baseline, err := metricdrift.Load(fs.Arg(0))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "drift: %v\n", err)
+		return 1
+	}
+	current, err := metricdrift.Load(fs.Arg(1))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "drift: %v\n", err)
+		return 1
+	}
+
+	rep := metricdrift.Diff(baseline, current)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(rep)
+	} else {
+		_ = rep.WriteText(os.Stdout)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "drift: %d added, %d removed, %d renamed, %d changed\n",
+		len(rep.Added), len(rep.Removed), len(rep.Renamed), len(rep.Changed))
+	if rep.Breaking() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/yeongki/my-operator/pkg/slo/export"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	dir := fs.String("dir", "", "directory with sli-summary JSON files (searched recursively, required)")
+	out := fs.String("out", "", "output file (default: stdout)")
+	runIDLabel := fs.Bool("run-id-label", false, "add a run_id label (one series per run)")
+	keepTags := fs.String("keep-tags", "", "comma-separated allow-list of tags exported as labels (default: all)")
+	maskTags := fs.String("mask-tags", "", "comma-separated tags whose values are replaced by a hash")
+	verbose := fs.Bool("v", false, "report skipped (non-summary) files")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "usage: slocli export -dir DIR [-out FILE]")
+		_, _ = fmt.Fprintln(fs.Output(), "Writes OpenMetrics for backfilling, e.g.:")
+		_, _ = fmt.Fprintln(fs.Output(), "  promtool tsdb create-blocks-from openmetrics FILE ./data")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *dir == "" {
+		fs.Usage()
+		return 2
+	}
+
+	sums, skipped, err := export.LoadSummaries(*dir)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	if *verbose {
+		for _, e := range skipped {
+			_, _ = fmt.Fprintf(os.Stderr, "export: skipped %v\n", e)
+		}
+	}
+
+	policy := summary.RedactPolicy{
+		KeepTags: summary.ParseList(*keepTags),
+		MaskTags: summary.ParseList(*maskTags),
+	}
+	for i := range sums {
+		sums[i] = sums[i].Redact(policy)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "export: %v\n", err)
+			return 1
+		}
+		defer func() { _ = f.Close() }()
+		w = f
+	}
+
+	if err := export.WriteOpenMetrics(w, sums, export.OpenMetricsOptions{IncludeRunID: *runIDLabel}); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "export: %d summaries exported, %d files skipped\n", len(sums), len(skipped))
+	return 0
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/common/gzfile"
+	"github.com/yeongki/my-operator/pkg/slo/export"
+	"github.com/yeongki/my-operator/pkg/slo/history"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// historyDirEnv is the default of -db.
+const historyDirEnv = "SLOLAB_HISTORY_DIR"
+
+func runHistory(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "import":
+			return runHistoryImport(args[1:])
+		case "query":
+			return runHistoryQuery(args[1:])
+		}
+	}
+	_, _ = fmt.Fprintln(os.Stderr, "usage: slocli history import|query [flags]")
+	_, _ = fmt.Fprintln(os.Stderr, "  import  add summaries (files, directories, CI logs) to the history")
+	_, _ = fmt.Fprintln(os.Stderr, "  query   print the results of one SLI across runs")
+	return 2
+}
+
+func runHistoryImport(args []string) int {
+	fs := flag.NewFlagSet("history import", flag.ContinueOnError)
+	db := fs.String("db", os.Getenv(historyDirEnv), "history directory (default: $"+historyDirEnv+")")
+	verbose := fs.Bool("v", false, "report skipped (non-summary) files")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "usage: slocli history import -db DIR PATH...")
+		_, _ = fmt.Fprintln(fs.Output(), "PATH is a summary JSON file, a directory of them (searched recursively,")
+		_, _ = fmt.Fprintln(fs.Output(), "e.g. an unpacked CI artifact) or a job log with SLOLAB_RESULT lines.")
+		_, _ = fmt.Fprintln(fs.Output(), "Sessions already in the history are skipped, so re-importing is harmless.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *db == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	store, err := history.Open(*db)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "history: %v\n", err)
+		return 1
+	}
+
+	added, known, skippedFiles := 0, 0, 0
+	for _, path := range fs.Args() {
+		sums, skipped, err := loadImport(path)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "history: %v\n", err)
+			return 1
+		}
+		skippedFiles += len(skipped)
+		if *verbose {
+			for _, e := range skipped {
+				_, _ = fmt.Fprintf(os.Stderr, "history: skipped %v\n", e)
+			}
+		}
+		for _, s := range sums {
+			ok, err := store.Append(history.FromSummary(s))
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "history: %v\n", err)
+				return 1
+			}
+			if ok {
+				added++
+			} else {
+				known++
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "history: %d sessions added, %d already known, %d files skipped\n",
+		added, known, skippedFiles)
+	return 0
+}
+
+// loadImport reads the summaries of one import path: a directory (export.LoadSummaries), a summary
+// file, or any other file scanned for result lines.
+func loadImport(path string) ([]summary.Summary, []error, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fi.IsDir() {
+		return export.LoadSummaries(path)
+	}
+	if gzfile.HasSuffix(filepath.Base(path), ".json") {
+		s, err := summary.Load(path)
+		if err != nil {
+			return nil, []error{fmt.Errorf("%s: %w", path, err)}, nil
+		}
+		return []summary.Summary{*s}, nil, nil
+	}
+	b, err := gzfile.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	sums, err := artifacts.ReadResultLines(bytes.NewReader(b))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return sums, nil, nil
+}
+
+func runHistoryQuery(args []string) int {
+	fs := flag.NewFlagSet("history query", flag.ContinueOnError)
+	db := fs.String("db", os.Getenv(historyDirEnv), "history directory (default: $"+historyDirEnv+")")
+	sli := fs.String("sli", "", "SLI id (required)")
+	since := fs.Duration("since", 0, "only sessions that finished within this duration, e.g. 720h (default: all)")
+	asJSON := fs.Bool("json", false, "print the points as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *db == "" || *sli == "" {
+		fs.Usage()
+		return 2
+	}
+	store, err := history.Open(*db)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "history: %v\n", err)
+		return 1
+	}
+	var w history.Window
+	if *since > 0 {
+		w = history.Last(*since)
+	}
+	points, err := store.Query(*sli, w)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "history: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(points); err != nil {
+			return 1
+		}
+		return 0
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "FINISHED\tRUN\tCOMMIT\tSTATUS\tVALUE")
+	for _, p := range points {
+		value := "-"
+		if p.Value != nil {
+			value = strings.TrimSpace(strconv.FormatFloat(*p.Value, 'g', -1, 64) + " " + p.Unit)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			p.At.Format(time.RFC3339), p.RunID, shortCommit(p.Build), p.Status, value)
+	}
+	_ = tw.Flush()
+	return 0
+}
+
+// shortCommit is the abbreviated commit of b ("-" => unknown), marked when the tree was dirty.
+func shortCommit(b *summary.Build) string {
+	if b == nil || b.Commit == "" {
+		return "-"
+	}
+	c := b.Commit
+	if len(c) > 12 {
+		c = c[:12]
+	}
+	if b.Dirty {
+		c += "-dirty"
+	}
+	return c
+}
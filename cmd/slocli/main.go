@@ -0,0 +1,116 @@
+// Command slocli is the command line companion of the SLO lab.
+// It runs the pkg/slo pipeline outside of Ginkgo (no cluster required for most commands).
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands = []command{
+	{
+		name:    "selftest",
+		summary: "validate fetch -> parse -> evaluate -> write against an in-process metrics server",
+		run:     runSelftest,
+	},
+	{
+		name:    "measure",
+		summary: "scrape a metrics endpoint over a window and evaluate a preset, with live progress",
+		run:     runMeasure,
+	},
+	{
+		name:    "soak",
+		summary: "measure with soak defaults (6h window, 1m refresh)",
+		run:     runSoak,
+	},
+	{
+		name:    "export",
+		summary: "convert a directory of summaries to OpenMetrics for Prometheus TSDB backfill",
+		run:     runExport,
+	},
+	{
+		name:    "diag",
+		summary: "capture a namespace snapshot (resources, events, logs, CRDs) into a tarball",
+		run:     runDiag,
+	},
+	{
+		name:    "reap",
+		summary: "delete orphaned curl-metrics scrape pods left behind by interrupted runs",
+		run:     runReap,
+	},
+	{
+		name:    "replay",
+		summary: "re-evaluate a recorded session bundle and report verdicts that changed",
+		run:     runReplay,
+	},
+	{
+		name:    "drift",
+		summary: "compare exposed metric families between a baseline and the current run",
+		run:     runDrift,
+	},
+	{
+		name:    "diff",
+		summary: "compare two raw /metrics scrapes series by series (added, removed, changed values)",
+		run:     runDiff,
+	},
+	{
+		name:    "dashboard",
+		summary: "generate a Grafana dashboard (panel per SLI, objective thresholds) for exported results",
+		run:     runDashboard,
+	},
+	{
+		name:    "rules",
+		summary: "generate a PrometheusRule (SLI recording rules, burn-rate and objective alerts)",
+		run:     runRules,
+	},
+	{
+		name:    "history",
+		summary: "import summaries and CI logs into a local result history and query an SLI across runs",
+		run:     runHistory,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage()
+		return
+	}
+
+	for _, c := range commands {
+		if c.name == name {
+			os.Exit(c.run(os.Args[2:]))
+		}
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "slocli: unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	_, _ = fmt.Fprintln(os.Stderr, "usage: slocli <command> [flags]")
+	_, _ = fmt.Fprintln(os.Stderr)
+	_, _ = fmt.Fprintln(os.Stderr, "commands:")
+	for _, c := range commands {
+		_, _ = fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.summary)
+	}
+}
+
+// stderrLogger adapts slo.Logger to stderr for -v runs.
+type stderrLogger struct{}
+
+func (stderrLogger) Logf(format string, args ...any) {
+	_, _ = fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/presets"
+	"github.com/yeongki/my-operator/pkg/slo/replay"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+	"github.com/yeongki/my-operator/pkg/slolab"
+)
+
+// measureDefaults differ between "measure" (short interactive window) and "soak" (hours).
+type measureDefaults struct {
+	name     string
+	duration time.Duration
+	interval time.Duration
+}
+
+func runMeasure(args []string) int {
+	return measureCommand(measureDefaults{name: "measure", duration: time.Minute, interval: 10 * time.Second}, args)
+}
+
+func runSoak(args []string) int {
+	return measureCommand(measureDefaults{name: "soak", duration: 6 * time.Hour, interval: time.Minute}, args)
+}
+
+func measureCommand(def measureDefaults, args []string) int {
+	fs := flag.NewFlagSet(def.name, flag.ContinueOnError)
+	url := fs.String("url", "", "metrics endpoint to scrape, e.g. http://localhost:8080/metrics (exactly one of -url, -prometheus or -snapshots)")
+	promURL := fs.String("prometheus", "", "query this Prometheus server instead of scraping -url")
+	selector := fs.String("selector", "", `label matchers for -prometheus queries, e.g. namespace="my-operator-system"`)
+	token := fs.String("token", "", "bearer token (default: $SLOLAB_TOKEN)")
+	insecure := fs.Bool("insecure", false, "skip TLS certificate verification")
+	caFile := fs.String("cacert", "", "PEM CA bundle to verify the server certificate against")
+	certFile := fs.String("cert", "", "PEM client certificate for mTLS (with -key)")
+	keyFile := fs.String("key", "", "PEM client key for mTLS (with -cert)")
+	protobuf := fs.Bool("protobuf", false, "negotiate the protobuf exposition format (faster for large scrapes)")
+	snapshots := fs.String("snapshots", "",
+		"offline: replay recorded snapshot files or directories (comma-separated) instead of -url/-prometheus")
+	record := fs.String("record", "", "save every scrape as a snapshot file in this directory (see -snapshots)")
+	duration := fs.Duration("duration", def.duration, "measurement window")
+	interval := fs.Duration("interval", def.interval, "progress refresh interval (one scrape per refresh)")
+	preset := fs.String("preset", "controller-runtime",
+		"comma-separated SLI presets ("+strings.Join(presets.Names(), ", ")+")")
+	out := fs.String("out", "", "write the final summary JSON to this path")
+	runID := fs.String("run-id", "", "run id recorded in the summary (default: <command>-<unix time>)")
+	progress := fs.String("progress", progressAuto, "progress display: auto, tty, plain or off")
+	failOnPolicy := fs.Bool("fail-on-policy", false, "exit 1 when an SLI rule at level fail is violated")
+	resultLine := fs.Bool("result-line", false,
+		"print the final summary as one `SLOLAB_RESULT {json}` line on stdout, for CI log parsing")
+	verbose := fs.Bool("v", false, "verbose logging")
+	profilePath := fs.String("profile", "",
+		"slolab.yaml profile (presets, metrics, objectives, fetcher, writers, policies); flags win over it")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	prof := &slolab.Profile{}
+	if *profilePath != "" {
+		var err error
+		if prof, err = slolab.Load(*profilePath); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", def.name, err)
+			return 2
+		}
+		if flagSet(fs, "preset") {
+			// -preset replaces the profile's measured specs; its objectives still apply
+			prof.Presets, prof.Metrics = nil, nil
+		}
+		if f := prof.Fetcher; f != nil && *url == "" && *promURL == "" && *snapshots == "" {
+			switch f.Type {
+			case slolab.FetcherHTTP:
+				*url = f.URL
+			case slolab.FetcherPrometheus:
+				*promURL = f.URL
+				if *selector == "" {
+					*selector = f.Selector
+				}
+			}
+		}
+		*failOnPolicy = *failOnPolicy || prof.Policies.FailOnPolicy
+	}
+	if n := countSet(*url, *promURL, *snapshots); n != 1 {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: exactly one of -url, -prometheus or -snapshots is required\n", def.name)
+		return 2
+	}
+	specs, err := presets.Resolve(*preset)
+	if err == nil {
+		specs, err = prof.Specs(specs)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", def.name, err)
+		return 2
+	}
+
+	var logger slo.Logger
+	if *verbose {
+		logger = stderrLogger{}
+	}
+	writer, err := profileWriter(prof, artifacts.NewSummaryWriter(artifacts.DefaultOptions()), logger)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", def.name, err)
+		return 2
+	}
+	if *resultLine || prof.HasWriter(slolab.WriterStdout) {
+		rw := artifacts.NewResultLineWriter(writer)
+		rw.Policy = prof.Policies.Upload.RedactPolicy()
+		writer = rw
+	}
+
+	if *token == "" {
+		*token = os.Getenv("SLOLAB_TOKEN")
+	}
+	var header http.Header
+	if *token != "" {
+		header = http.Header{"Authorization": {"Bearer " + *token}}
+	}
+	client, err := fetch.NewHTTPClient(fetch.TLSOptions{
+		CAFile:             *caFile,
+		CertFile:           *certFile,
+		KeyFile:            *keyFile,
+		InsecureSkipVerify: *insecure,
+	}, 30*time.Second)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", def.name, err)
+		return 2
+	}
+	var f fetch.MetricsFetcher = &fetch.HTTPFetcher{URL: *url, Header: header, Client: client, Protobuf: *protobuf}
+	if *promURL != "" {
+		f = &fetch.PrometheusFetcher{
+			URL:      *promURL,
+			Names:    spec.MetricNames(specs),
+			Selector: *selector,
+			Header:   header,
+			Client:   client,
+		}
+	}
+	var offline *replay.FileFetcher
+	if *snapshots != "" {
+		if offline, err = replay.NewFileFetcher(strings.Split(*snapshots, ",")...); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", def.name, err)
+			return 2
+		}
+		f = offline
+	}
+	if *record != "" {
+		f = replay.NewRecordingFetcher(f, *record, artifacts.Options{}, logger)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	started := time.Now()
+	start, err := f.Fetch(ctx, started)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: start scrape failed: %v\n", def.name, err)
+		return 1
+	}
+	if offline != nil {
+		// the window is the recorded one, not the replay's wall clock
+		started = start.At
+	}
+
+	view, err := newProgressView(os.Stdout, *progress, started, *duration)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", def.name, err)
+		return 2
+	}
+
+	if *runID == "" {
+		*runID = fmt.Sprintf("%s-%d", def.name, started.Unix())
+	}
+	if dir := prof.Writer(slolab.WriterLocal); *out == "" && dir != "" {
+		*out = filepath.Join(dir, *runID+".json")
+	}
+	cfg := engine.RunConfig{
+		RunID:     *runID,
+		StartedAt: started,
+		Mode:      engine.RunMode{Location: "outside", Trigger: "none"},
+		Tags:      map[string]string{"suite": "slocli", "test_case": def.name},
+	}
+	evaluate := func(end fetch.Sample, w summary.Writer, outPath string) (*summary.Summary, error) {
+		c := cfg
+		c.FinishedAt = end.At
+		return engine.New(pairFetcher{start: start, end: end}, w, logger).Execute(ctx, engine.ExecuteRequest{
+			Config:  c,
+			Specs:   specs,
+			OutPath: outPath,
+		})
+	}
+
+	last := start
+	var lastErr error
+	live, _ := evaluate(start, discardWriter{}, "")
+	view.render(time.Now(), live, nil)
+
+	if offline != nil {
+		// offline: run through the recorded snapshots as fast as they are read; the last is the window end
+		for i := 1; i < offline.Len(); i++ {
+			s, err := f.Fetch(ctx, time.Now())
+			lastErr = err
+			if err == nil {
+				last = s
+				live, _ = evaluate(last, discardWriter{}, "")
+			}
+			view.render(last.At, live, lastErr)
+		}
+		*duration = last.At.Sub(started)
+	} else {
+		deadline := time.NewTimer(*duration)
+		defer deadline.Stop()
+		ticker := time.NewTicker(*interval)
+		defer ticker.Stop()
+
+	loop:
+		for {
+			select {
+			case <-ctx.Done():
+				_, _ = fmt.Fprintf(os.Stderr, "%s: interrupted, evaluating the partial window\n", def.name)
+				break loop
+			case <-deadline.C:
+				break loop
+			case now := <-ticker.C:
+				s, err := f.Fetch(ctx, now)
+				lastErr = err
+				if err == nil {
+					last = s
+					live, _ = evaluate(last, discardWriter{}, "")
+				}
+				view.render(now, live, lastErr)
+			}
+		}
+
+		// final scrape: the window end. A failed scrape falls back to the last good one.
+		ctx = context.WithoutCancel(ctx)
+		if s, err := f.Fetch(ctx, time.Now()); err == nil {
+			last = s
+		} else {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: final scrape failed, using last sample from %s: %v\n",
+				def.name, last.At.Format(time.RFC3339), err)
+		}
+	}
+
+	sum, err := evaluate(last, writer, *out)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", def.name, err)
+		return 1
+	}
+	final, _ := newProgressView(os.Stdout, progressPlain, started, *duration)
+	final.render(last.At, sum, nil)
+	if *out != "" {
+		fmt.Printf("summary written to %s\n", *out)
+	}
+
+	if *failOnPolicy && sum.HasPolicyFailures() {
+		for _, r := range sum.Breaches() {
+			_, _ = fmt.Fprintln(os.Stderr, summary.BreachMessage(r))
+		}
+		return 1
+	}
+	return 0
+}
+
+// profileWriter wraps local with the profile's upload and OTLP writers (redacted by its upload
+// policy). Uploads need a summary path (-out or a local writer).
+func profileWriter(prof *slolab.Profile, local summary.Writer, l slo.Logger) (summary.Writer, error) {
+	w := local
+	policy := prof.Policies.Upload.RedactPolicy()
+	if target := prof.Writer(slolab.WriterUpload); target != "" {
+		up, err := artifacts.NewUploadSummaryWriter(w, target, l)
+		if err != nil {
+			return nil, err
+		}
+		up.Policy = policy
+		w = up
+	}
+	if target := prof.Writer(slolab.WriterOTLP); target != "" {
+		o := artifacts.NewOTLPWriter(w, target, l)
+		o.Policy = policy
+		w = o
+	}
+	if prof.Writer(slolab.WriterBundle) != "" {
+		_, _ = fmt.Fprintln(os.Stderr, "profile: bundle writers are only supported by the e2e suite, ignored")
+	}
+	return w, nil
+}
+
+// countSet counts the non-empty values.
+func countSet(values ...string) int {
+	n := 0
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+	return set
+}
+
+// pairFetcher replays two already taken samples: start for the window start, end otherwise.
+type pairFetcher struct {
+	start, end fetch.Sample
+}
+
+func (p pairFetcher) Fetch(_ context.Context, at time.Time) (fetch.Sample, error) {
+	if at.Equal(p.start.At) {
+		return p.start, nil
+	}
+	return p.end, nil
+}
+
+type discardWriter struct{}
+
+func (discardWriter) Write(string, summary.Summary) error { return nil }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// progress modes for long measurement sessions.
+const (
+	progressAuto  = "auto"  // tty if stdout is a terminal, plain otherwise
+	progressTTY   = "tty"   // redraw in place
+	progressPlain = "plain" // append one block per refresh (CI logs)
+	progressOff   = "off"
+)
+
+// progressView renders the live state of a measurement window.
+type progressView struct {
+	out     io.Writer
+	mode    string
+	window  time.Duration
+	started time.Time
+}
+
+func newProgressView(out io.Writer, mode string, started time.Time, window time.Duration) (*progressView, error) {
+	switch mode {
+	case progressAuto:
+		mode = progressPlain
+		if isTerminal(out) {
+			mode = progressTTY
+		}
+	case progressTTY, progressPlain, progressOff:
+	default:
+		return nil, fmt.Errorf("unknown progress mode %q (want auto, tty, plain or off)", mode)
+	}
+	return &progressView{out: out, mode: mode, window: window, started: started}, nil
+}
+
+// render draws the current evaluation. sum may be nil before the first successful scrape.
+func (v *progressView) render(now time.Time, sum *summary.Summary, lastErr error) {
+	if v.mode == progressOff {
+		return
+	}
+
+	var b strings.Builder
+	if v.mode == progressTTY {
+		b.WriteString("\033[H\033[2J") // cursor home + clear screen
+	}
+
+	elapsed := now.Sub(v.started).Truncate(time.Second)
+	remaining := (v.window - elapsed).Truncate(time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	pct := 100.0
+	if v.window > 0 {
+		pct = float64(elapsed) / float64(v.window) * 100
+		if pct > 100 {
+			pct = 100
+		}
+	}
+	fmt.Fprintf(&b, "[%s] elapsed %s / %s (%.0f%%), remaining %s\n",
+		now.Format(time.RFC3339), elapsed, v.window, pct, remaining)
+	if lastErr != nil {
+		fmt.Fprintf(&b, "last scrape failed: %v\n", lastErr)
+	}
+
+	if sum != nil {
+		tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "SLI\tVALUE\tSTATUS\tREASON")
+		for _, r := range sum.Results {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.ID, formatValue(r.Value, r.Unit), r.Status, r.Reason)
+		}
+		_ = tw.Flush()
+	}
+	if v.mode == progressPlain {
+		b.WriteString("\n")
+	}
+
+	_, _ = io.WriteString(v.out, b.String())
+}
+
+func formatValue(v *float64, unit string) string {
+	if v == nil {
+		return "-"
+	}
+	s := strconv.FormatFloat(*v, 'g', 6, 64)
+	if unit != "" {
+		s += " " + unit
+	}
+	return s
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/kubeutil"
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+func runReap(args []string) int {
+	fs := flag.NewFlagSet("reap", flag.ContinueOnError)
+	namespaces := fs.String("n", "", "comma-separated namespaces to scan (default: all namespaces)")
+	selector := fs.String("l", "app=curl-metrics", "label selector of the pods to reap")
+	olderThan := fs.Duration("older-than", 10*time.Minute, "only reap pods older than this")
+	dryRun := fs.Bool("dry-run", false, "list pods that would be reaped without deleting them")
+	timeout := fs.Duration("timeout", 2*time.Minute, "overall timeout")
+	verbose := fs.Bool("v", false, "verbose logging")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var logger slo.Logger
+	if *verbose {
+		logger = stderrLogger{}
+	}
+
+	var nsList []string
+	for _, ns := range strings.Split(*namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			nsList = append(nsList, ns)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	res, err := kubeutil.ReapPods(ctx, logger, kubeutil.DefaultRunner{}, kubeutil.ReapOptions{
+		Namespaces:    nsList,
+		LabelSelector: *selector,
+		MinAge:        *olderThan,
+		DryRun:        *dryRun,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "reap: %v\n", err)
+		return 1
+	}
+
+	verb := "reaped"
+	if *dryRun {
+		verb = "would reap"
+	}
+	for _, p := range res.Reaped {
+		fmt.Printf("%s %s\n", verb, p)
+	}
+	fmt.Printf("%s %d pod(s), kept %d younger than %s\n", verb, len(res.Reaped), res.Kept, *olderThan)
+	return 0
+}
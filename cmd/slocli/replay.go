@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/replay"
+)
+
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	values := fs.Bool("values", false, "also fail on value changes that keep the verdict")
+	tolerance := fs.Float64("tolerance", 1e-9, "absolute tolerance when comparing values")
+	verbose := fs.Bool("v", false, "verbose logging")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "usage: slocli replay [flags] BUNDLE_DIR")
+		_, _ = fmt.Fprintln(fs.Output(), "Re-evaluates recorded sessions (SLOLAB_BUNDLE_DIR) with this engine and")
+		_, _ = fmt.Fprintln(fs.Output(), "reports every result whose verdict differs from the recorded one.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	paths, sessions, err := replay.LoadBundle(fs.Arg(0))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		return 1
+	}
+
+	var logger slo.Logger
+	if *verbose {
+		logger = stderrLogger{}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SESSION\tSLI\tFIELD\tRECORDED\tREPLAYED")
+	verdicts, changes := 0, 0
+	for i, s := range sessions {
+		name := filepath.Base(paths[i])
+		got, err := replay.Evaluate(context.Background(), s, logger)
+		if err != nil {
+			_ = tw.Flush()
+			_, _ = fmt.Fprintf(os.Stderr, "replay: %s: %v\n", name, err)
+			return 1
+		}
+		for _, d := range replay.Compare(s.Summary, got, *tolerance) {
+			changes++
+			if d.Verdict() {
+				verdicts++
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", name, d.ID, d.Field, d.Recorded, d.Replayed)
+		}
+	}
+	_ = tw.Flush()
+
+	_, _ = fmt.Fprintf(os.Stderr, "replay: %d sessions, %d verdict changes, %d value-only changes\n",
+		len(sessions), verdicts, changes-verdicts)
+	if verdicts > 0 || (*values && changes > 0) {
+		return 1
+	}
+	return 0
+}
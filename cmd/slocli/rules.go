@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/yeongki/my-operator/pkg/slo/export"
+	"github.com/yeongki/my-operator/pkg/slo/presets"
+	"github.com/yeongki/my-operator/pkg/slolab"
+)
+
+func runRules(args []string) int {
+	fs := flag.NewFlagSet("rules", flag.ContinueOnError)
+	preset := fs.String("preset", "controller-runtime",
+		"comma-separated SLI presets ("+strings.Join(presets.Names(), ", ")+")")
+	profilePath := fs.String("profile", "",
+		"slolab.yaml profile: its presets, metrics and objectives (-preset, when set, replaces the specs)")
+	name := fs.String("name", "", `PrometheusRule name (default "slolab-slos")`)
+	namespace := fs.String("namespace", "", "PrometheusRule namespace")
+	selector := fs.String("selector", "",
+		`extra label matchers for every input metric, e.g. namespace="my-operator-system"`)
+	window := fs.Duration("window", 0, "window of the recorded SLI values and threshold alerts (default 5m)")
+	forDur := fs.Duration("for", 0, "for: of threshold alerts (default 10m)")
+	asJSON := fs.Bool("json", false, "write JSON instead of YAML")
+	out := fs.String("out", "", "output file (default: stdout)")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "usage: slocli rules [-preset P | -profile FILE] [-out FILE]")
+		_, _ = fmt.Fprintln(fs.Output(), "Writes a PrometheusRule from the objectives e2e judges: a recording rule per SLI,")
+		_, _ = fmt.Fprintln(fs.Output(), "burn-rate alerts for ratio objectives and threshold alerts for the others.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	prof := &slolab.Profile{}
+	if *profilePath != "" {
+		var err error
+		if prof, err = slolab.Load(*profilePath); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "rules: %v\n", err)
+			return 2
+		}
+		if flagSet(fs, "preset") {
+			prof.Presets, prof.Metrics = nil, nil
+		}
+	}
+	specs, err := presets.Resolve(*preset)
+	if err == nil {
+		specs, err = prof.Specs(specs)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "rules: %v\n", err)
+		return 2
+	}
+
+	var buf bytes.Buffer
+	opts := export.PrometheusRuleOptions{
+		Name: *name, Namespace: *namespace, Selector: *selector, Window: *window, For: *forDur,
+	}
+	skipped, err := export.WritePrometheusRules(&buf, specs, opts)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "rules: %v\n", err)
+		return 1
+	}
+	for _, id := range skipped {
+		_, _ = fmt.Fprintf(os.Stderr, "rules: skipped %s (not expressible in PromQL)\n", id)
+	}
+	data := buf.Bytes()
+	if !*asJSON {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "rules: %v\n", err)
+			return 1
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "rules: %v\n", err)
+			return 1
+		}
+		defer func() { _ = f.Close() }()
+		w = f
+	}
+	if _, err := w.Write(data); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "rules: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// canned scrapes served by the selftest server (first request = start, then end).
+const (
+	selftestStartMetrics = `# HELP controller_runtime_reconcile_total Total number of reconciliations per controller
+# TYPE controller_runtime_reconcile_total counter
+controller_runtime_reconcile_total{controller="selftest",result="success"} 10
+controller_runtime_reconcile_total{controller="selftest",result="error"} 1
+# HELP workqueue_depth Current depth of workqueue
+# TYPE workqueue_depth gauge
+workqueue_depth{name="selftest"} 0
+`
+	selftestEndMetrics = `# HELP controller_runtime_reconcile_total Total number of reconciliations per controller
+# TYPE controller_runtime_reconcile_total counter
+controller_runtime_reconcile_total{controller="selftest",result="success"} 25
+controller_runtime_reconcile_total{controller="selftest",result="error"} 1
+# HELP workqueue_depth Current depth of workqueue
+# TYPE workqueue_depth gauge
+workqueue_depth{name="selftest"} 3
+`
+)
+
+type selftestExpect struct {
+	value  float64
+	status summary.Status
+}
+
+func selftestSpecs() ([]spec.SLISpec, map[string]selftestExpect) {
+	specs := []spec.SLISpec{
+		{
+			ID:      "reconcile_total_delta",
+			Kind:    "delta_counter",
+			Inputs:  []spec.MetricRef{spec.PromMetric("controller_runtime_reconcile_total", nil)},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:   "reconcile_error_delta",
+			Kind: "delta_counter",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("controller_runtime_reconcile_total", spec.Labels{
+					"controller": "selftest",
+					"result":     "error",
+				}),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+			Judge: &spec.JudgeSpec{Rules: []spec.Rule{
+				{Metric: "value", Op: spec.OpGT, Target: 0, Level: spec.LevelFail},
+			}},
+		},
+		{
+			ID:      "workqueue_depth_start",
+			Kind:    "gauge",
+			Inputs:  []spec.MetricRef{spec.PromMetric("workqueue_depth", nil)},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeSingle},
+		},
+	}
+	expect := map[string]selftestExpect{
+		"reconcile_total_delta": {value: 15, status: summary.StatusPass},
+		"reconcile_error_delta": {value: 0, status: summary.StatusPass},
+		"workqueue_depth_start": {value: 0, status: summary.StatusPass},
+	}
+	return specs, expect
+}
+
+// selftestWriter is one row of the pass/fail matrix.
+type selftestWriter struct {
+	name   string
+	writer summary.Writer
+	path   string
+	// check inspects what the writer produced (a file, a request, a log line), so the write
+	// step is verified end to end; sum is what the engine handed to the writer.
+	check func(path string, sum *summary.Summary) error
+}
+
+// selftestWriters returns one row per writer kind. Writers that leave the machine send to sink,
+// an in-process stand-in for the bucket or collector.
+func selftestWriters(dir string, sink *selftestSink) []selftestWriter {
+	var lines bytes.Buffer
+	return []selftestWriter{
+		{
+			name:   "json-file",
+			writer: artifacts.NewSummaryWriter(artifacts.DefaultOptions()),
+			path:   filepath.Join(dir, "sli-summary.selftest.json"),
+			check:  checkFile,
+		},
+		{
+			name:   "json-file-compact",
+			writer: artifacts.NewSummaryWriter(artifacts.Options{Compact: true}),
+			path:   filepath.Join(dir, "sli-summary.selftest.compact.json"),
+			check:  checkFile,
+		},
+		{
+			name:   "upload-http",
+			writer: &artifacts.UploadSummaryWriter{Uploader: artifacts.NewHTTPUploader(sink.URL + "/upload")},
+			path:   filepath.Join(dir, "sli-summary.selftest.upload.json"),
+			check: func(p string, sum *summary.Summary) error {
+				body, ok := sink.body(http.MethodPut, "/upload/"+filepath.Base(p))
+				if !ok {
+					return fmt.Errorf("nothing uploaded to %s", sink.URL)
+				}
+				var got summary.Summary
+				if err := json.Unmarshal(body, &got); err != nil {
+					return fmt.Errorf("uploaded summary: %w", err)
+				}
+				return sameResults(&got, sum)
+			},
+		},
+		{
+			name:   "otlp-http",
+			writer: &artifacts.OTLPWriter{Endpoint: sink.URL},
+			path:   filepath.Join(dir, "sli-summary.selftest.otlp.json"),
+			check: func(string, *summary.Summary) error {
+				for path, want := range map[string]string{
+					"/v1/traces":  artifacts.OTLPSpanName,
+					"/v1/metrics": artifacts.OTLPMetricSLIValue,
+				} {
+					body, ok := sink.body(http.MethodPost, path)
+					if !ok {
+						return fmt.Errorf("nothing exported to %s%s", sink.URL, path)
+					}
+					if !bytes.Contains(body, []byte(strconv.Quote(want))) {
+						return fmt.Errorf("%s: no %s in the export", path, want)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:   "result-line",
+			writer: &artifacts.ResultLineWriter{Out: &lines},
+			check: func(_ string, sum *summary.Summary) error {
+				got, err := artifacts.ReadResultLines(&lines)
+				if err != nil {
+					return err
+				}
+				if len(got) != 1 {
+					return fmt.Errorf("got %d result lines, want 1", len(got))
+				}
+				return sameResults(&got[0], sum)
+			},
+		},
+	}
+}
+
+func checkFile(p string, sum *summary.Summary) error {
+	got, err := summary.Load(p)
+	if err != nil {
+		return err
+	}
+	return sameResults(got, sum)
+}
+
+func sameResults(got, want *summary.Summary) error {
+	if len(got.Results) != len(want.Results) {
+		return fmt.Errorf("read back %d results, want %d", len(got.Results), len(want.Results))
+	}
+	return nil
+}
+
+// selftestSink records the last request body per "METHOD path".
+type selftestSink struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	bodies map[string][]byte
+}
+
+func newSelftestSink() *selftestSink {
+	s := &selftestSink{bodies: map[string][]byte{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.bodies[r.Method+" "+r.URL.Path] = b
+		s.mu.Unlock()
+	}))
+	return s
+}
+
+func (s *selftestSink) body(method, path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.bodies[method+" "+path]
+	return b, ok
+}
+
+func runSelftest(args []string) int {
+	fs := flag.NewFlagSet("selftest", flag.ContinueOnError)
+	dir := fs.String("dir", "", "directory for writer outputs (default: temp dir, removed afterwards)")
+	verbose := fs.Bool("v", false, "verbose logging")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	outDir := *dir
+	if outDir == "" {
+		tmp, err := os.MkdirTemp("", "slocli-selftest-")
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "selftest: %v\n", err)
+			return 1
+		}
+		defer func() { _ = os.RemoveAll(tmp) }()
+		outDir = tmp
+	}
+
+	var logger slo.Logger
+	if *verbose {
+		logger = stderrLogger{}
+	}
+
+	steps := []string{"fetch", "parse", "evaluate", "write"}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "WRITER\tFETCH\tPARSE\tEVALUATE\tWRITE")
+
+	sink := newSelftestSink()
+	defer sink.Close()
+
+	failed := false
+	var details []string
+	for _, w := range selftestWriters(outDir, sink) {
+		res := runSelftestSession(w, logger)
+		row := w.name
+		for _, step := range steps {
+			cell := "PASS"
+			if err := res[step]; err != nil {
+				cell = "FAIL"
+				failed = true
+				details = append(details, fmt.Sprintf("%s/%s: %v", w.name, step, err))
+			}
+			row += "\t" + cell
+		}
+		_, _ = fmt.Fprintln(tw, row)
+	}
+	_ = tw.Flush()
+
+	for _, d := range details {
+		_, _ = fmt.Fprintln(os.Stdout, d)
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// runSelftestSession runs one full session against a fresh in-process server and
+// returns the error (nil = pass) per pipeline step.
+func runSelftestSession(w selftestWriter, logger slo.Logger) map[string]error {
+	res := map[string]error{}
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		body := selftestEndMetrics
+		if hits.Add(1) == 1 {
+			body = selftestStartMetrics
+		}
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = fmt.Fprint(rw, body)
+	}))
+	defer srv.Close()
+
+	rec := &recordingFetcher{inner: fetch.NewHTTPFetcher(srv.URL + "/metrics")}
+	specs, expect := selftestSpecs()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	started := time.Now()
+	sum, err := engine.New(rec, w.writer, logger).Execute(ctx, engine.ExecuteRequest{
+		Config: engine.RunConfig{
+			RunID:      "selftest",
+			StartedAt:  started,
+			FinishedAt: started.Add(time.Second),
+			Mode:       engine.RunMode{Location: "outside", Trigger: "none"},
+			Tags:       map[string]string{"suite": "selftest"},
+		},
+		Specs:   specs,
+		OutPath: w.path,
+	})
+
+	res["fetch"] = rec.check()
+	res["parse"] = checkParsed(rec.samples, specs)
+	if err != nil {
+		res["evaluate"] = err
+		res["write"] = err
+		return res
+	}
+	res["evaluate"] = checkResults(sum, expect)
+
+	res["write"] = w.check(w.path, sum)
+	return res
+}
+
+// recordingFetcher keeps every sample and error so the fetch/parse steps can be reported separately.
+type recordingFetcher struct {
+	inner fetch.MetricsFetcher
+
+	mu      sync.Mutex
+	samples []fetch.Sample
+	errs    []error
+}
+
+func (r *recordingFetcher) Fetch(ctx context.Context, at time.Time) (fetch.Sample, error) {
+	s, err := r.inner.Fetch(ctx, at)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.errs = append(r.errs, err)
+	} else {
+		r.samples = append(r.samples, s)
+	}
+	return s, err
+}
+
+func (r *recordingFetcher) check() error {
+	if len(r.errs) > 0 {
+		return r.errs[0]
+	}
+	if len(r.samples) != 2 {
+		return fmt.Errorf("got %d snapshots, want 2", len(r.samples))
+	}
+	return nil
+}
+
+func checkParsed(samples []fetch.Sample, specs []spec.SLISpec) error {
+	if len(samples) == 0 {
+		return fmt.Errorf("no snapshots parsed")
+	}
+	for i, s := range samples {
+		for _, sp := range specs {
+			for _, in := range sp.Inputs {
+				if _, ok := s.Values[in.Key]; !ok {
+					return fmt.Errorf("snapshot %d: missing %s", i, in.Key)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func checkResults(sum *summary.Summary, expect map[string]selftestExpect) error {
+	if sum == nil {
+		return fmt.Errorf("no summary produced")
+	}
+	if len(sum.Warnings) > 0 {
+		return fmt.Errorf("unexpected warnings: %v", sum.Warnings)
+	}
+	for _, r := range sum.Results {
+		want, ok := expect[r.ID]
+		if !ok {
+			return fmt.Errorf("unexpected result %s", r.ID)
+		}
+		if r.Status != want.status {
+			return fmt.Errorf("%s: status %s, want %s (%s)", r.ID, r.Status, want.status, r.Reason)
+		}
+		if r.Value == nil || *r.Value != want.value {
+			return fmt.Errorf("%s: value %v, want %v", r.ID, r.Value, want.value)
+		}
+	}
+	if len(sum.Results) != len(expect) {
+		return fmt.Errorf("got %d results, want %d", len(sum.Results), len(expect))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestSelftestWriters(t *testing.T) {
+	sink := newSelftestSink()
+	defer sink.Close()
+
+	for _, w := range selftestWriters(t.TempDir(), sink) {
+		t.Run(w.name, func(t *testing.T) {
+			for step, err := range runSelftestSession(w, nil) {
+				if err != nil {
+					t.Errorf("%s: %v", step, err)
+				}
+			}
+		})
+	}
+}
+
+// Upload and OTLP failures are only logged by the writers, so the write step must catch
+// them by looking at the sink.
+func TestSelftestWriteFailure(t *testing.T) {
+	sink := newSelftestSink()
+	sink.Close()
+
+	for _, w := range selftestWriters(t.TempDir(), sink) {
+		if w.name != "upload-http" && w.name != "otlp-http" {
+			continue
+		}
+		t.Run(w.name, func(t *testing.T) {
+			res := runSelftestSession(w, nil)
+			if res["evaluate"] != nil {
+				t.Fatalf("evaluate: %v", res["evaluate"])
+			}
+			if res["write"] == nil {
+				t.Fatal("expected the write step to fail against a closed sink")
+			}
+		})
+	}
+}
+
+func TestRunSelftest(t *testing.T) {
+	if code := runSelftest([]string{"-dir", t.TempDir()}); code != 0 {
+		t.Fatalf("selftest exited %d", code)
+	}
+}
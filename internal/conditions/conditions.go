@@ -0,0 +1,67 @@
+// Package conditions defines the status conditions of the operator's custom resources and the
+// helpers controllers use to set them, so every controller reports the same types and reasons.
+//
+// Conditions follow the metav1.Condition conventions (meta.SetStatusCondition): lastTransitionTime
+// only moves when the status changes, so it is what convergence measurements key off:
+//
+//	before := slices.Clone(obj.Status.Conditions)
+//	conditions.Set(&obj.Status.Conditions, obj.Generation, conditions.Ready, metav1.ConditionTrue,
+//		conditions.ReasonReplicasReady, "1/1 replicas ready")
+//	for _, c := range conditions.Transitions(before, obj.Status.Conditions) { ... }
+package conditions
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types.
+const (
+	// Ready: 원하는 replica 가 모두 Ready
+	Ready = "Ready"
+	// Progressing: 원하는 상태로 변경 중 (생성, scale, 삭제)
+	Progressing = "Progressing"
+	// Degraded: reconcile 이 실패해서 원하는 상태로 진행할 수 없음
+	Degraded = "Degraded"
+)
+
+// Condition reasons (CamelCase, as required by metav1.Condition).
+const (
+	ReasonReplicasReady    = "ReplicasReady"
+	ReasonReplicasNotReady = "ReplicasNotReady"
+	ReasonScaling          = "Scaling"
+	ReasonStable           = "Stable"
+	ReasonDeleting         = "Deleting"
+	ReasonReconcileError   = "ReconcileError"
+	ReasonAsExpected       = "AsExpected"
+)
+
+// Set sets the condition typ on conds and reports whether anything changed (status, reason,
+// message or observed generation).
+func Set(conds *[]metav1.Condition, generation int64, typ string, status metav1.ConditionStatus,
+	reason, message string) bool {
+	return meta.SetStatusCondition(conds, metav1.Condition{
+		Type:               typ,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// IsTrue reports whether conds has typ with status True.
+func IsTrue(conds []metav1.Condition, typ string) bool {
+	return meta.IsStatusConditionTrue(conds, typ)
+}
+
+// Transitions returns the conditions of after whose status differs from before (including
+// conditions that did not exist before), in the order of after.
+func Transitions(before, after []metav1.Condition) []metav1.Condition {
+	var out []metav1.Condition
+	for _, c := range after {
+		if old := meta.FindStatusCondition(before, c.Type); old == nil || old.Status != c.Status {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package conditions
+
+import (
+	"slices"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetAndTransitions(t *testing.T) {
+	var conds []metav1.Condition
+	if !Set(&conds, 1, Ready, metav1.ConditionFalse, ReasonReplicasNotReady, "0/1 replicas ready") {
+		t.Fatal("expected the new condition to be a change")
+	}
+	Set(&conds, 1, Degraded, metav1.ConditionFalse, ReasonAsExpected, "")
+	before := slices.Clone(conds)
+	firstTransition := conds[0].LastTransitionTime
+
+	if !Set(&conds, 2, Degraded, metav1.ConditionFalse, ReasonAsExpected, "") {
+		t.Fatal("expected a new observed generation to be a change")
+	}
+	if Set(&conds, 2, Degraded, metav1.ConditionFalse, ReasonAsExpected, "") {
+		t.Fatal("expected an identical condition not to be a change")
+	}
+	Set(&conds, 2, Ready, metav1.ConditionTrue, ReasonReplicasReady, "1/1 replicas ready")
+	Set(&conds, 2, Progressing, metav1.ConditionFalse, ReasonStable, "")
+
+	if !IsTrue(conds, Ready) || IsTrue(conds, Degraded) {
+		t.Fatalf("unexpected conditions %+v", conds)
+	}
+	got := Transitions(before, conds)
+	if len(got) != 2 || got[0].Type != Ready || got[1].Type != Progressing {
+		t.Fatalf("expected Ready and Progressing transitions, got %+v", got)
+	}
+	if len(Transitions(conds, conds)) != 0 {
+		t.Fatal("expected no transitions without changes")
+	}
+	if c := before[0]; c.LastTransitionTime != firstTransition || c.Status != metav1.ConditionFalse {
+		t.Fatalf("expected the previous conditions untouched, got %+v", c)
+	}
+}
@@ -18,25 +18,48 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	controllerruntime "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	batchv1 "github.com/yeongki/my-operator/api/v1"
+	"github.com/yeongki/my-operator/internal/conditions"
+	"github.com/yeongki/my-operator/internal/metrics"
+	"github.com/yeongki/my-operator/pkg/devutil"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const controllerName = "joboperator"
+
+// jobOperatorFinalizer holds a deleted JobOperator until its StatefulSet is gone.
+const jobOperatorFinalizer = "batch.my.domain/finalizer"
+
+// convergedAnnoKey records the test/start-time annotation whose convergence was observed, so
+// later not-Ready -> Ready transitions (scale-ups, recoveries) are not observed again.
+const convergedAnnoKey = "batch.my.domain/converged-start-time"
+
 // JobOperatorReconciler reconciles a JobOperator object
 type JobOperatorReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// MaxConcurrentReconciles (0 => controller-runtime default of 1).
+	MaxConcurrentReconciles int
+	// RateLimiter of the workqueue (nil => workqueue.DefaultTypedControllerRateLimiter).
+	RateLimiter workqueue.TypedRateLimiter[reconcile.Request]
 }
 
 // +kubebuilder:rbac:groups=batch.my.domain,resources=joboperators,verbs=get;list;watch;create;update;patch;delete
@@ -54,14 +77,29 @@ func (r *JobOperatorReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	jobOp := &batchv1.JobOperator{}
 	if err := r.Get(ctx, req.NamespacedName, jobOp); err != nil {
 		if apierrors.IsNotFound(err) {
+			r.recordManaged(ctx)
+			metrics.RecordReconcile(controllerName, metrics.Success)
 			return ctrl.Result{}, nil
 		}
 		// [Metrics] 조회 실패 기록 추가
 		ReconcileErrors.WithLabelValues(req.Name, req.Namespace, "fetch_failed").Inc()
 		ReconcileTotal.WithLabelValues(req.Name, req.Namespace, "error").Inc()
+		metrics.RecordReconcile(controllerName, metrics.Error("fetch_failed"))
 		return ctrl.Result{}, err
 	}
 
+	if !jobOp.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, jobOp, startTime)
+	}
+	if controllerutil.AddFinalizer(jobOp, jobOperatorFinalizer) {
+		if err := r.Update(ctx, jobOp); err != nil {
+			ReconcileErrors.WithLabelValues(req.Name, req.Namespace, "add_finalizer_failed").Inc()
+			ReconcileTotal.WithLabelValues(req.Name, req.Namespace, "error").Inc()
+			metrics.RecordReconcile(controllerName, metrics.Error("add_finalizer_failed"))
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Create or update StatefulSet
 	sts := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -102,32 +140,199 @@ func (r *JobOperatorReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if err := ctrl.SetControllerReference(jobOp, sts, r.Scheme); err != nil {
 		// [Metrics] OwnerRef 설정 실패 기록 추가
 		ReconcileErrors.WithLabelValues(req.Name, req.Namespace, "owner_ref_failed").Inc()
+		r.markDegraded(ctx, jobOp, err)
+		metrics.RecordReconcile(controllerName, metrics.Error("owner_ref_failed"))
 		return ctrl.Result{}, err
 	}
 
 	if err := r.Create(ctx, sts); err != nil && !apierrors.IsAlreadyExists(err) {
 		// [Metrics] 생성 실패 기록 추가
 		ReconcileErrors.WithLabelValues(req.Name, req.Namespace, "create_sts_failed").Inc()
+		r.markDegraded(ctx, jobOp, err)
 		ReconcileTotal.WithLabelValues(req.Name, req.Namespace, "error").Inc()
+		metrics.RecordReconcile(controllerName, metrics.Error("create_sts_failed"))
 		// [Metrics] 실패 시에도 소요 시간 기록
 		ReconcileDurationSeconds.WithLabelValues(req.Name, req.Namespace, "error").Observe(time.Since(startTime).Seconds())
 
 		return ctrl.Result{}, err
 	}
 
+	if err := r.updateStatus(ctx, jobOp); err != nil {
+		ReconcileErrors.WithLabelValues(req.Name, req.Namespace, "status_update_failed").Inc()
+		ReconcileTotal.WithLabelValues(req.Name, req.Namespace, "error").Inc()
+		metrics.RecordReconcile(controllerName, metrics.Error("status_update_failed"))
+		return ctrl.Result{}, err
+	}
+
 	// [Metrics] 성공 기록
 	ReconcileTotal.WithLabelValues(req.Name, req.Namespace, "success").Inc()
 	ReconcileDurationSeconds.WithLabelValues(req.Name, req.Namespace, "success").Observe(time.Since(startTime).Seconds())
+	metrics.RecordReconcile(controllerName, metrics.Success)
+	r.recordManaged(ctx)
 
 	log.Info("Reconciliation successful", "duration", time.Since(startTime).String())
 
 	return ctrl.Result{}, nil
 }
 
+// finalize deletes the StatefulSet of a deleted JobOperator and releases the finalizer once it
+// is gone, observing the time since the deletion request in myoperator_deletion_duration_seconds.
+func (r *JobOperatorReconciler) finalize(
+	ctx context.Context, jobOp *batchv1.JobOperator, startTime time.Time,
+) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(jobOp, jobOperatorFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	fail := func(errorType string, err error) (ctrl.Result, error) {
+		ReconcileErrors.WithLabelValues(jobOp.Name, jobOp.Namespace, errorType).Inc()
+		ReconcileTotal.WithLabelValues(jobOp.Name, jobOp.Namespace, "error").Inc()
+		metrics.RecordReconcile(controllerName, metrics.Error(errorType))
+		return ctrl.Result{}, err
+	}
+
+	before := *jobOp.Status.DeepCopy()
+	const deleting = "waiting for the StatefulSet to be deleted"
+	conds, gen := &jobOp.Status.Conditions, jobOp.Generation
+	conditions.Set(conds, gen, conditions.Ready, metav1.ConditionFalse, conditions.ReasonDeleting, deleting)
+	conditions.Set(conds, gen, conditions.Progressing, metav1.ConditionTrue, conditions.ReasonDeleting, deleting)
+	if err := r.writeStatus(ctx, jobOp, before); err != nil {
+		return fail("status_update_failed", err)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: jobOp.Namespace, Name: jobOp.Name + "-sts"}, sts)
+	switch {
+	case err == nil:
+		if sts.DeletionTimestamp.IsZero() {
+			if err := r.Delete(ctx, sts); client.IgnoreNotFound(err) != nil {
+				return fail("delete_sts_failed", err)
+			}
+		}
+		// the StatefulSet deletion event requeues us as well (Owns); the delay is a fallback
+		ReconcileTotal.WithLabelValues(jobOp.Name, jobOp.Namespace, "success").Inc()
+		metrics.RecordReconcile(controllerName, metrics.Requeue("waiting_sts_deletion"))
+		return ctrl.Result{RequeueAfter: 2 * time.Second}, nil
+	case !apierrors.IsNotFound(err):
+		return fail("fetch_sts_failed", err)
+	}
+
+	controllerutil.RemoveFinalizer(jobOp, jobOperatorFinalizer)
+	if err := r.Update(ctx, jobOp); err != nil {
+		return fail("remove_finalizer_failed", err)
+	}
+	DeletionDurationSeconds.WithLabelValues(jobOp.Name, jobOp.Namespace).
+		Observe(time.Since(jobOp.DeletionTimestamp.Time).Seconds())
+	ReconcileTotal.WithLabelValues(jobOp.Name, jobOp.Namespace, "success").Inc()
+	ReconcileDurationSeconds.WithLabelValues(jobOp.Name, jobOp.Namespace, "success").
+		Observe(time.Since(startTime).Seconds())
+	metrics.RecordReconcile(controllerName, metrics.Success)
+	r.recordManaged(ctx)
+	return ctrl.Result{}, nil
+}
+
+// recordManaged publishes the number of JobOperators (read from the cache, so no API call).
+func (r *JobOperatorReconciler) recordManaged(ctx context.Context) {
+	list := &batchv1.JobOperatorList{}
+	if err := r.List(ctx, list); err != nil {
+		return
+	}
+	metrics.SetManagedObjects(controllerName, "JobOperator", len(list.Items))
+}
+
+// updateStatus copies the StatefulSet replica counts into the status and derives the Ready,
+// Progressing and Degraded conditions from them. The first time Ready is True for the
+// test/start-time annotation, the time since that annotation is observed in
+// myoperator_convergence_seconds (see observeConvergence).
+func (r *JobOperatorReconciler) updateStatus(ctx context.Context, jobOp *batchv1.JobOperator) error {
+	sts := &appsv1.StatefulSet{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: jobOp.Namespace, Name: jobOp.Name + "-sts"}, sts); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	desired := int32(1)
+	if jobOp.Spec.Replicas != nil {
+		desired = *jobOp.Spec.Replicas
+	}
+	before := *jobOp.Status.DeepCopy()
+
+	jobOp.Status.ReadyReplicas = sts.Status.ReadyReplicas
+	jobOp.Status.Replicas = sts.Status.Replicas
+	conds, gen := &jobOp.Status.Conditions, jobOp.Generation
+	msg := fmt.Sprintf("%d/%d replicas ready", sts.Status.ReadyReplicas, desired)
+	ready := sts.Status.ReadyReplicas >= desired
+	if ready {
+		conditions.Set(conds, gen, conditions.Ready, metav1.ConditionTrue, conditions.ReasonReplicasReady, msg)
+	} else {
+		conditions.Set(conds, gen, conditions.Ready, metav1.ConditionFalse, conditions.ReasonReplicasNotReady, msg)
+	}
+	if ready && sts.Status.Replicas == desired {
+		conditions.Set(conds, gen, conditions.Progressing, metav1.ConditionFalse, conditions.ReasonStable, msg)
+	} else {
+		conditions.Set(conds, gen, conditions.Progressing, metav1.ConditionTrue, conditions.ReasonScaling, msg)
+	}
+	conditions.Set(conds, gen, conditions.Degraded, metav1.ConditionFalse, conditions.ReasonAsExpected, "")
+	if err := r.writeStatus(ctx, jobOp, before); err != nil {
+		return err
+	}
+
+	if conditions.IsTrue(jobOp.Status.Conditions, conditions.Ready) {
+		return r.observeConvergence(ctx, jobOp)
+	}
+	return nil
+}
+
+// observeConvergence observes the time since the test/start-time annotation the first time the
+// JobOperator is seen Ready with it, and marks that annotation as observed (convergedAnnoKey): a
+// failed mark is retried by the next reconcile, and a new start time is observed again.
+func (r *JobOperatorReconciler) observeConvergence(ctx context.Context, jobOp *batchv1.JobOperator) error {
+	start, ok := devutil.TestStartTimeAnno(jobOp.Annotations)
+	if !ok || jobOp.Annotations[convergedAnnoKey] == jobOp.Annotations[devutil.TestStartTimeAnnoKey] {
+		return nil
+	}
+	jobOp.Annotations[convergedAnnoKey] = jobOp.Annotations[devutil.TestStartTimeAnnoKey]
+	if err := r.Update(ctx, jobOp); err != nil {
+		return err
+	}
+	ConvergenceSeconds.WithLabelValues(jobOp.Name, jobOp.Namespace).Observe(time.Since(start).Seconds())
+	return nil
+}
+
+// markDegraded records err in the Degraded condition. Best effort: the reconcile error is what
+// gets returned, a failed status update is only logged.
+func (r *JobOperatorReconciler) markDegraded(ctx context.Context, jobOp *batchv1.JobOperator, err error) {
+	before := *jobOp.Status.DeepCopy()
+	conditions.Set(&jobOp.Status.Conditions, jobOp.Generation, conditions.Degraded, metav1.ConditionTrue,
+		conditions.ReasonReconcileError, err.Error())
+	if err := r.writeStatus(ctx, jobOp, before); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to mark JobOperator degraded")
+	}
+}
+
+// writeStatus persists jobOp.Status when it differs from before and counts the condition
+// transitions in myoperator_condition_transitions_total.
+func (r *JobOperatorReconciler) writeStatus(
+	ctx context.Context, jobOp *batchv1.JobOperator, before batchv1.JobOperatorStatus,
+) error {
+	if equality.Semantic.DeepEqual(before, jobOp.Status) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, jobOp); err != nil {
+		return err
+	}
+	for _, c := range conditions.Transitions(before.Conditions, jobOp.Status.Conditions) {
+		metrics.RecordConditionTransition(controllerName, c.Type, string(c.Status), c.Reason)
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *JobOperatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&batchv1.JobOperator{}).
-		Named("joboperator").
+		Owns(&appsv1.StatefulSet{}).
+		Named(controllerName).
+		WithOptions(controllerruntime.Options{
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+			RateLimiter:             r.RateLimiter,
+		}).
 		Complete(r)
 }
@@ -21,13 +21,22 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	batchv1 "github.com/yeongki/my-operator/api/v1"
+	"github.com/yeongki/my-operator/internal/conditions"
+	"github.com/yeongki/my-operator/pkg/devutil"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slogather"
 )
 
 var _ = Describe("JobOperator Controller", func() {
@@ -58,13 +67,18 @@ var _ = Describe("JobOperator Controller", func() {
 		})
 
 		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
 			resource := &batchv1.JobOperator{}
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			if errors.IsNotFound(err) {
+				return
+			}
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance JobOperator")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			// a reconciled resource carries the finalizer: reconcile until it is released, so the next
+			// spec starts from a fresh resource instead of one stuck terminating
+			reconcileUntilGone(ctx, typeNamespacedName)
 		})
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
@@ -80,5 +94,147 @@ var _ = Describe("JobOperator Controller", func() {
 			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
+
+		It("should delete the StatefulSet before releasing the finalizer", func() {
+			controllerReconciler := &JobOperatorReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			req := reconcile.Request{NamespacedName: typeNamespacedName}
+			_, err := controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &batchv1.JobOperator{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(resource, jobOperatorFinalizer)).To(BeTrue())
+			stsKey := types.NamespacedName{Namespace: typeNamespacedName.Namespace, Name: resourceName + "-sts"}
+			Expect(k8sClient.Get(ctx, stsKey, &appsv1.StatefulSet{})).To(Succeed())
+			deletions := histogramCount(DeletionDurationSeconds, resourceName, typeNamespacedName.Namespace)
+
+			By("deleting the JobOperator: the finalizer holds it until the StatefulSet is gone")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, stsKey, &appsv1.StatefulSet{}))).To(BeTrue())
+
+			reconcileUntilGone(ctx, typeNamespacedName)
+			Expect(histogramCount(DeletionDurationSeconds, resourceName, typeNamespacedName.Namespace)).
+				To(Equal(deletions + 1))
+		})
+
+		It("should count the reconcile in the metrics registry", func() {
+			window, err := slogather.Start(ctx, slogather.New(nil))
+			Expect(err).NotTo(HaveOccurred())
+
+			controllerReconciler := &JobOperatorReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			sum, err := window.End(ctx, []spec.SLISpec{{
+				ID:      "joboperator_reconcile_total_delta",
+				Inputs:  []spec.MetricRef{spec.UnsafePromKey("joboperator_reconcile_total")},
+				Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+			}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sum.Results).To(HaveLen(1))
+			Expect(sum.Results[0].Value).NotTo(BeNil())
+			Expect(*sum.Results[0].Value).To(BeNumerically("==", 1))
+		})
 	})
 })
+
+var _ = Describe("JobOperator status", func() {
+	const resourceName = "converging-resource"
+	ctx := context.Background()
+	key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+	stsKey := types.NamespacedName{Name: resourceName + "-sts", Namespace: "default"}
+	var r *JobOperatorReconciler
+
+	BeforeEach(func() {
+		r = &JobOperatorReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		Expect(k8sClient.Create(ctx, &batchv1.JobOperator{ObjectMeta: metav1.ObjectMeta{
+			Name:        resourceName,
+			Namespace:   "default",
+			Annotations: devutil.SetTestStartTimeAnno(nil),
+		}})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		resource := &batchv1.JobOperator{}
+		Expect(k8sClient.Get(ctx, key, resource)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		reconcileUntilGone(ctx, key)
+	})
+
+	// setReady sets the StatefulSet's ready replicas (no StatefulSet controller runs in envtest)
+	// and reconciles.
+	setReady := func(ready int32) {
+		sts := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, stsKey, sts)).To(Succeed())
+		sts.Status.Replicas, sts.Status.ReadyReplicas = 1, ready
+		Expect(k8sClient.Status().Update(ctx, sts)).To(Succeed())
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("should derive the Ready, Progressing and Degraded conditions from the StatefulSet", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		conditionOf := func(typ string) *metav1.Condition {
+			resource := &batchv1.JobOperator{}
+			Expect(k8sClient.Get(ctx, key, resource)).To(Succeed())
+			return meta.FindStatusCondition(resource.Status.Conditions, typ)
+		}
+		Expect(conditionOf(conditions.Ready)).To(HaveField("Status", metav1.ConditionFalse))
+		Expect(conditionOf(conditions.Ready)).To(HaveField("Reason", conditions.ReasonReplicasNotReady))
+		Expect(conditionOf(conditions.Progressing)).To(HaveField("Status", metav1.ConditionTrue))
+		Expect(conditionOf(conditions.Degraded)).To(HaveField("Status", metav1.ConditionFalse))
+
+		setReady(1)
+		Expect(conditionOf(conditions.Ready)).To(HaveField("Status", metav1.ConditionTrue))
+		Expect(conditionOf(conditions.Progressing)).To(HaveField("Reason", conditions.ReasonStable))
+		Expect(conditionOf(conditions.Degraded)).To(HaveField("Status", metav1.ConditionFalse))
+	})
+
+	It("should observe the convergence only the first time the resource is Ready", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		observed := histogramCount(ConvergenceSeconds, resourceName, "default")
+
+		setReady(1)
+		Expect(histogramCount(ConvergenceSeconds, resourceName, "default")).To(Equal(observed + 1))
+		resource := &batchv1.JobOperator{}
+		Expect(k8sClient.Get(ctx, key, resource)).To(Succeed())
+		Expect(resource.Annotations).To(HaveKeyWithValue(convergedAnnoKey,
+			resource.Annotations[devutil.TestStartTimeAnnoKey]))
+
+		By("recovering from a not-Ready phase: no second observation")
+		setReady(0)
+		setReady(1)
+		Expect(histogramCount(ConvergenceSeconds, resourceName, "default")).To(Equal(observed + 1))
+	})
+})
+
+// reconcileUntilGone reconciles a deleted JobOperator until its finalizer is released and the
+// object is gone.
+func reconcileUntilGone(ctx context.Context, key types.NamespacedName) {
+	r := &JobOperatorReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+	Eventually(func() error {
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+			return err
+		}
+		return k8sClient.Get(ctx, key, &batchv1.JobOperator{})
+	}).Should(Satisfy(errors.IsNotFound))
+}
+
+// histogramCount is the number of observations of one histogram series.
+func histogramCount(h *prometheus.HistogramVec, labels ...string) uint64 {
+	m := &dto.Metric{}
+	Expect(h.WithLabelValues(labels...).(prometheus.Histogram).Write(m)).To(Succeed())
+	return m.GetHistogram().GetSampleCount()
+}
@@ -33,6 +33,27 @@ var (
 		},
 		[]string{"name", "namespace", "error_type"},
 	)
+
+	// ConvergenceSeconds: test/start-time annotation 부터 처음 Ready 가 될 때까지 걸린 시간
+	// (테스트 쪽이 아닌 operator 시계 기준)
+	ConvergenceSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "myoperator_convergence_seconds",
+			Help:    "Seconds from the test/start-time annotation until the JobOperator was first marked Ready",
+			Buckets: []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120, 300},
+		},
+		[]string{"name", "namespace"},
+	)
+
+	// DeletionDurationSeconds: 삭제 요청(deletionTimestamp)부터 finalizer 해제까지 걸린 시간
+	DeletionDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "myoperator_deletion_duration_seconds",
+			Help:    "Seconds from the JobOperator deletion request until its finalizer was released",
+			Buckets: []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120, 300},
+		},
+		[]string{"name", "namespace"},
+	)
 )
 
 func init() {
@@ -41,5 +62,7 @@ func init() {
 		ReconcileDurationSeconds,
 		ReconcileTotal,
 		ReconcileErrors,
+		ConvergenceSeconds,
+		DeletionDurationSeconds,
 	)
 }
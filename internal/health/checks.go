@@ -0,0 +1,105 @@
+// Package health builds the manager's readiness checks from the --readyz-checks flag, so the
+// manager only reports Ready once it can actually reconcile (caches synced) and serve admission
+// requests (webhook certificate present).
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// Check names accepted by --readyz-checks.
+const (
+	CheckPing        = "ping"         // always ready once the probe server runs
+	CheckCacheSync   = "cache-sync"   // every informer of the manager cache has synced
+	CheckWebhookCert = "webhook-cert" // the webhook certificate/key pair loads from disk
+	CheckWebhook     = "webhook"      // the webhook server is started and completes a TLS handshake
+)
+
+// DefaultReadyChecks is the --readyz-checks default.
+const DefaultReadyChecks = CheckPing + "," + CheckCacheSync
+
+// CacheSyncTimeout bounds one cache-sync probe (the kubelet probe timeout defaults to 1s).
+const CacheSyncTimeout = 500 * time.Millisecond
+
+// Syncer is implemented by the manager cache (cache.Cache).
+type Syncer interface {
+	WaitForCacheSync(ctx context.Context) bool
+}
+
+// CacheSynced reports ready once s synced; the result is kept, so later probes are free.
+func CacheSynced(s Syncer) healthz.Checker {
+	var synced atomic.Bool
+	return func(req *http.Request) error {
+		if synced.Load() {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(req.Context(), CacheSyncTimeout)
+		defer cancel()
+		if !s.WaitForCacheSync(ctx) {
+			return fmt.Errorf("informer caches not synced yet")
+		}
+		synced.Store(true)
+		return nil
+	}
+}
+
+// CertFiles reports ready when the certificate/key pair loads (e.g. once cert-manager wrote it).
+func CertFiles(certFile, keyFile string) healthz.Checker {
+	return func(*http.Request) error {
+		if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+			return fmt.Errorf("webhook certificate: %w", err)
+		}
+		return nil
+	}
+}
+
+// Options are the inputs of the checks that need them.
+type Options struct {
+	Cache Syncer
+	// CertFile/KeyFile are required by webhook-cert.
+	CertFile, KeyFile string
+	// WebhookStarted is required by webhook (webhook.Server.StartedChecker). It is only called when
+	// the check is requested: Manager.GetWebhookServer starts the webhook server.
+	WebhookStarted func() healthz.Checker
+}
+
+// ReadyChecks resolves comma-separated check names; an unknown name or a check without its
+// inputs is an error, so a typo in the flag fails at startup instead of weakening readiness.
+func ReadyChecks(names string, o Options) (map[string]healthz.Checker, error) {
+	out := map[string]healthz.Checker{}
+	for _, n := range strings.Split(names, ",") {
+		n = strings.TrimSpace(n)
+		switch n {
+		case "":
+			continue
+		case CheckPing:
+			out[n] = healthz.Ping
+		case CheckCacheSync:
+			if o.Cache == nil {
+				return nil, fmt.Errorf("readyz check %q: no cache", n)
+			}
+			out[n] = CacheSynced(o.Cache)
+		case CheckWebhookCert:
+			if o.CertFile == "" || o.KeyFile == "" {
+				return nil, fmt.Errorf("readyz check %q needs --webhook-cert-path", n)
+			}
+			out[n] = CertFiles(o.CertFile, o.KeyFile)
+		case CheckWebhook:
+			if o.WebhookStarted == nil {
+				return nil, fmt.Errorf("readyz check %q: no webhook server", n)
+			}
+			out[n] = o.WebhookStarted()
+		default:
+			return nil, fmt.Errorf("unknown readyz check %q", n)
+		}
+	}
+	return out, nil
+}
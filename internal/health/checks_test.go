@@ -0,0 +1,40 @@
+package health
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeSyncer struct{ synced bool }
+
+func (f *fakeSyncer) WaitForCacheSync(context.Context) bool { return f.synced }
+
+func TestCacheSynced(t *testing.T) {
+	s := &fakeSyncer{}
+	check := CacheSynced(s)
+	req := httptest.NewRequest("GET", "/readyz", nil)
+	if check(req) == nil {
+		t.Fatal("expected not ready before the sync")
+	}
+	s.synced = true
+	if err := check(req); err != nil {
+		t.Fatal(err)
+	}
+	s.synced = false
+	if err := check(req); err != nil {
+		t.Fatalf("expected the synced state to be kept, got %v", err)
+	}
+}
+
+func TestReadyChecks(t *testing.T) {
+	checks, err := ReadyChecks(" ping, cache-sync ,", Options{Cache: &fakeSyncer{}})
+	if err != nil || len(checks) != 2 {
+		t.Fatalf("unexpected checks %v %v", checks, err)
+	}
+	for _, names := range []string{"cache-synced", "webhook-cert", "webhook"} {
+		if _, err := ReadyChecks(names, Options{Cache: &fakeSyncer{}}); err == nil {
+			t.Fatalf("%s: expected an error", names)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Package managerconfig loads the manager configuration file passed with --config.
+//
+//	metricsBindAddress: ":8443"
+//	healthProbeBindAddress: ":8081"
+//	leaderElection: true
+//	watchNamespaces: [team-a, team-b]
+//	readyzChecks: "ping,cache-sync"
+//	controllers:
+//	  joboperator:
+//	    maxConcurrentReconciles: 2
+//	    rateLimiter: {baseDelay: 10ms, maxDelay: 5m, qps: 20, burst: 200}
+//	slo:
+//	  agentDir: /var/run/slo
+//	  agentWindow: 10m
+//
+// Flags given explicitly on the command line win over the file, so a deployment can keep its
+// args and a test profile only needs to ship a file.
+package managerconfig
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the manager configuration file. Unset fields keep the flag defaults.
+type Config struct {
+	MetricsBindAddress     *string `json:"metricsBindAddress,omitempty"`
+	HealthProbeBindAddress *string `json:"healthProbeBindAddress,omitempty"`
+	LeaderElection         *bool   `json:"leaderElection,omitempty"`
+	SecureMetrics          *bool   `json:"secureMetrics,omitempty"`
+	ReadyzChecks           *string `json:"readyzChecks,omitempty"`
+	// WatchNamespaces restricts the manager cache to these namespaces (empty => cluster scope).
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
+	// Controllers is keyed by controller name (e.g. "joboperator").
+	Controllers map[string]ControllerConfig `json:"controllers,omitempty"`
+
+	SLO SLOConfig `json:"slo,omitempty"`
+}
+
+// ControllerConfig tunes one controller.
+type ControllerConfig struct {
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+
+	RateLimiter RateLimiterConfig `json:"rateLimiter,omitempty"`
+}
+
+// RateLimiterConfig tunes the workqueue rate limiter: the per-item exponential back-off
+// (BaseDelay doubling up to MaxDelay) combined with an overall token bucket (QPS, Burst = bucket
+// size). Unset fields keep the controller-runtime defaults.
+type RateLimiterConfig struct {
+	BaseDelay *metav1.Duration `json:"baseDelay,omitempty"`
+	MaxDelay  *metav1.Duration `json:"maxDelay,omitempty"`
+	QPS       *float64         `json:"qps,omitempty"`
+	Burst     *int             `json:"burst,omitempty"`
+}
+
+// Defaults of workqueue.DefaultTypedControllerRateLimiter and controller-runtime.
+const (
+	DefaultMaxConcurrentReconciles = 1
+	DefaultBaseDelay               = 5 * time.Millisecond
+	DefaultMaxDelay                = 1000 * time.Second
+	DefaultQPS                     = 10.0
+	DefaultBurst                   = 100
+)
+
+// ControllerSettings are the effective values of one controller (defaults applied).
+type ControllerSettings struct {
+	MaxConcurrentReconciles int
+	BaseDelay               time.Duration
+	MaxDelay                time.Duration
+	QPS                     float64
+	Burst                   int
+}
+
+// RateLimiter builds the workqueue rate limiter of s.
+func (s ControllerSettings) RateLimiter() workqueue.TypedRateLimiter[reconcile.Request] {
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](s.BaseDelay, s.MaxDelay),
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(s.QPS), s.Burst)},
+	)
+}
+
+// SLOConfig are the SLO lab toggles (same as the --slo-agent-* flags).
+type SLOConfig struct {
+	AgentDir       *string          `json:"agentDir,omitempty"`
+	AgentUploadURL *string          `json:"agentUploadURL,omitempty"`
+	AgentWindow    *metav1.Duration `json:"agentWindow,omitempty"`
+}
+
+// Load reads and validates path. Unknown fields are errors, so a typo does not silently fall
+// back to a default.
+func Load(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := yaml.UnmarshalStrict(b, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Validate checks the values that would otherwise only fail once the manager runs.
+func (c *Config) Validate() error {
+	var errs []error
+	for field, addr := range map[string]*string{
+		"metricsBindAddress":     c.MetricsBindAddress,
+		"healthProbeBindAddress": c.HealthProbeBindAddress,
+	} {
+		if addr == nil || *addr == "0" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(*addr); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+		}
+	}
+	for name, cc := range c.Controllers {
+		if cc.MaxConcurrentReconciles < 0 {
+			errs = append(errs, fmt.Errorf("controllers.%s.maxConcurrentReconciles: must be >= 0", name))
+		}
+		s := c.Controller(name)
+		if s.BaseDelay <= 0 || s.MaxDelay < s.BaseDelay {
+			errs = append(errs, fmt.Errorf("controllers.%s.rateLimiter: need 0 < baseDelay (%s) <= maxDelay (%s)",
+				name, s.BaseDelay, s.MaxDelay))
+		}
+		if s.QPS <= 0 || s.Burst < 1 {
+			errs = append(errs, fmt.Errorf("controllers.%s.rateLimiter: need qps > 0 and burst >= 1", name))
+		}
+	}
+	for _, ns := range c.WatchNamespaces {
+		if msgs := validation.IsDNS1123Label(ns); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("watchNamespaces: %q: %s", ns, strings.Join(msgs, "; ")))
+		}
+	}
+	if w := c.SLO.AgentWindow; w != nil && w.Duration < time.Second {
+		errs = append(errs, fmt.Errorf("slo.agentWindow: %s is shorter than 1s", w.Duration))
+	}
+	return errors.Join(errs...)
+}
+
+// ApplyTo sets the flags of fs that the file configures and the command line did not set
+// (call it after fs.Parse).
+func (c *Config) ApplyTo(fs *flag.FlagSet) error {
+	values := map[string]string{}
+	str := func(name string, v *string) {
+		if v != nil {
+			values[name] = *v
+		}
+	}
+	boolean := func(name string, v *bool) {
+		if v != nil {
+			values[name] = strconv.FormatBool(*v)
+		}
+	}
+	str("metrics-bind-address", c.MetricsBindAddress)
+	str("health-probe-bind-address", c.HealthProbeBindAddress)
+	boolean("leader-elect", c.LeaderElection)
+	boolean("metrics-secure", c.SecureMetrics)
+	str("readyz-checks", c.ReadyzChecks)
+	if len(c.WatchNamespaces) > 0 {
+		values["watch-namespaces"] = strings.Join(c.WatchNamespaces, ",")
+	}
+	str("slo-agent-dir", c.SLO.AgentDir)
+	str("slo-agent-upload-url", c.SLO.AgentUploadURL)
+	if c.SLO.AgentWindow != nil {
+		values["slo-agent-window"] = c.SLO.AgentWindow.Duration.String()
+	}
+
+	fs.Visit(func(f *flag.Flag) { delete(values, f.Name) })
+	for name, v := range values {
+		if err := fs.Set(name, v); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// WatchNamespaceEnv is read when neither --watch-namespaces nor the file set the namespaces
+// (config/namespaced sets it to the manager's own namespace).
+const WatchNamespaceEnv = "WATCH_NAMESPACE"
+
+// ParseNamespaces splits a comma-separated namespace list ("" => nil, i.e. cluster scope).
+func ParseNamespaces(s string) []string {
+	var out []string
+	for _, ns := range strings.Split(s, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			out = append(out, ns)
+		}
+	}
+	return out
+}
+
+// Controller returns the effective settings of controller name (a nil Config => defaults).
+func (c *Config) Controller(name string) ControllerSettings {
+	s := ControllerSettings{
+		MaxConcurrentReconciles: DefaultMaxConcurrentReconciles,
+		BaseDelay:               DefaultBaseDelay,
+		MaxDelay:                DefaultMaxDelay,
+		QPS:                     DefaultQPS,
+		Burst:                   DefaultBurst,
+	}
+	if c == nil {
+		return s
+	}
+	cc := c.Controllers[name]
+	if cc.MaxConcurrentReconciles > 0 {
+		s.MaxConcurrentReconciles = cc.MaxConcurrentReconciles
+	}
+	rl := cc.RateLimiter
+	if rl.BaseDelay != nil {
+		s.BaseDelay = rl.BaseDelay.Duration
+	}
+	if rl.MaxDelay != nil {
+		s.MaxDelay = rl.MaxDelay.Duration
+	}
+	if rl.QPS != nil {
+		s.QPS = *rl.QPS
+	}
+	if rl.Burst != nil {
+		s.Burst = *rl.Burst
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package managerconfig
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func write(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	c, err := Load(write(t, `
+metricsBindAddress: ":8443"
+leaderElection: false
+watchNamespaces: [team-a, team-b]
+controllers:
+  joboperator:
+    maxConcurrentReconciles: 3
+    rateLimiter: {maxDelay: 1m, burst: 20}
+slo:
+  agentWindow: 2m
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *c.MetricsBindAddress != ":8443" || *c.LeaderElection || c.HealthProbeBindAddress != nil {
+		t.Fatalf("unexpected config %+v", c)
+	}
+	if c.Controller("joboperator").MaxConcurrentReconciles != 3 || c.Controller("other").MaxConcurrentReconciles != 1 {
+		t.Fatalf("unexpected controllers %+v", c.Controllers)
+	}
+	want := ControllerSettings{
+		MaxConcurrentReconciles: 3, BaseDelay: DefaultBaseDelay, MaxDelay: time.Minute, QPS: DefaultQPS, Burst: 20,
+	}
+	if got := c.Controller("joboperator"); got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if got := (*Config)(nil).Controller("joboperator"); got.MaxDelay != DefaultMaxDelay {
+		t.Fatalf("expected defaults, got %+v", got)
+	}
+	if got := ParseNamespaces(strings.Join(c.WatchNamespaces, ", ")); len(got) != 2 || got[1] != "team-b" {
+		t.Fatalf("unexpected namespaces %v", got)
+	}
+	if c.SLO.AgentWindow.Duration != 2*time.Minute {
+		t.Fatalf("unexpected window %v", c.SLO.AgentWindow)
+	}
+}
+
+func TestLoadRejectsInvalid(t *testing.T) {
+	for _, content := range []string{
+		"leaderElect: true\n",
+		"metricsBindAddress: 8443\n",
+		"controllers: {joboperator: {maxConcurrentReconciles: -1}}\n",
+		"slo: {agentWindow: 10ms}\n",
+		"controllers: {joboperator: {rateLimiter: {baseDelay: 1m, maxDelay: 1s}}}\n",
+		"controllers: {joboperator: {rateLimiter: {burst: 0}}}\n",
+		"watchNamespaces: [Team_A]\n",
+	} {
+		if _, err := Load(write(t, content)); err == nil {
+			t.Fatalf("%q: expected an error", strings.TrimSpace(content))
+		}
+	}
+}
+
+func TestApplyTo(t *testing.T) {
+	fs := flag.NewFlagSet("manager", flag.ContinueOnError)
+	metrics := fs.String("metrics-bind-address", "0", "")
+	leader := fs.Bool("leader-elect", false, "")
+	window := fs.Duration("slo-agent-window", 10*time.Minute, "")
+	if err := fs.Parse([]string{"--metrics-bind-address=:9443"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(write(t, "metricsBindAddress: \":8443\"\nleaderElection: true\nslo: {agentWindow: 1m}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ApplyTo(fs); err != nil {
+		t.Fatal(err)
+	}
+	if *metrics != ":9443" || !*leader || *window != time.Minute {
+		t.Fatalf("unexpected flags metrics=%s leader=%v window=%s", *metrics, *leader, *window)
+	}
+}
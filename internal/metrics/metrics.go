@@ -0,0 +1,144 @@
+// Package metrics holds the operator's custom collectors and the typed helpers controllers use
+// to record them, so label names and values stay consistent across controllers.
+//
+// The collectors are registered in init() against the controller-runtime metrics Registry
+// (sigs.k8s.io/controller-runtime/pkg/metrics.Registry), which the manager serves on its
+// metrics endpoint next to the controller_runtime_* and workqueue_* families. Importing this
+// package is enough; controllers never touch the collectors directly:
+//
+//	metrics.RecordReconcile("joboperator", metrics.Error("create_sts_failed"))
+//	done := metrics.TimeExternalCall("registry")
+//	err := pull(...)
+//	done(err)
+//	metrics.SetManagedObjects("joboperator", "StatefulSet", n)
+//	metrics.SetControllerSettings("joboperator", cfg.Controller("joboperator"))
+//	metrics.RecordConditionTransition("joboperator", "Ready", "True", "ReplicasReady")
+//
+// Names are part of the SLO contract (see pkg/metricdrift): rename a family only together with
+// the presets that read it.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/yeongki/my-operator/internal/managerconfig"
+)
+
+// Outcome is the result of one reconcile: Result is "success", "error" or "requeue", Reason is a
+// short snake_case cause ("" for success).
+type Outcome struct {
+	Result string
+	Reason string
+}
+
+// Success is the outcome of a reconcile that converged.
+var Success = Outcome{Result: "success"}
+
+// Error is the outcome of a reconcile that returned an error.
+func Error(reason string) Outcome { return Outcome{Result: "error", Reason: reason} }
+
+// Requeue is the outcome of a reconcile that asked to be retried without an error.
+func Requeue(reason string) Outcome { return Outcome{Result: "requeue", Reason: reason} }
+
+var (
+	// ReconcileOutcomes: controller 별 reconcile 결과 (result/reason)
+	ReconcileOutcomes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "myoperator_reconcile_outcomes_total",
+			Help: "Reconcile outcomes per controller, result and reason",
+		},
+		[]string{"controller", "result", "reason"},
+	)
+
+	// ExternalCallDuration: operator 가 클러스터 밖 서비스(registry, API 등)를 호출한 시간
+	ExternalCallDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "myoperator_external_call_duration_seconds",
+			Help:    "Latency of calls to services outside the cluster in seconds",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"target", "result"},
+	)
+
+	// ManagedObjects: controller 가 현재 관리 중인 object 수
+	ManagedObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "myoperator_managed_objects",
+			Help: "Objects currently managed per controller and kind",
+		},
+		[]string{"controller", "kind"},
+	)
+
+	// ControllerSettingsInfo: controller 의 실제 적용된 concurrency/rate limiter 설정 (값은 항상 1)
+	ControllerSettingsInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "myoperator_controller_settings_info",
+			Help: "Effective concurrency and rate limiter settings per controller (always 1)",
+		},
+		[]string{"controller", "max_concurrent_reconciles", "base_delay", "max_delay", "qps", "burst"},
+	)
+
+	// ConditionTransitions: status condition 의 status 전이 횟수 (Ready False→True 등)
+	ConditionTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "myoperator_condition_transitions_total",
+			Help: "Status condition transitions per controller, condition type, new status and reason",
+		},
+		[]string{"controller", "type", "status", "reason"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		ReconcileOutcomes,
+		ExternalCallDuration,
+		ManagedObjects,
+		ControllerSettingsInfo,
+		ConditionTransitions,
+	)
+}
+
+// SetControllerSettings publishes the effective settings of controller, so summaries of SLO
+// experiments can be told apart by the settings they ran with.
+func SetControllerSettings(controller string, s managerconfig.ControllerSettings) {
+	ControllerSettingsInfo.DeletePartialMatch(prometheus.Labels{"controller": controller})
+	ControllerSettingsInfo.WithLabelValues(controller,
+		strconv.Itoa(s.MaxConcurrentReconciles),
+		s.BaseDelay.String(),
+		s.MaxDelay.String(),
+		strconv.FormatFloat(s.QPS, 'g', -1, 64),
+		strconv.Itoa(s.Burst),
+	).Set(1)
+}
+
+// RecordReconcile counts one reconcile of controller.
+func RecordReconcile(controller string, outcome Outcome) {
+	ReconcileOutcomes.WithLabelValues(controller, outcome.Result, outcome.Reason).Inc()
+}
+
+// TimeExternalCall starts timing a call to target; call the returned func with the call's error.
+func TimeExternalCall(target string) func(err error) {
+	start := time.Now()
+	return func(err error) {
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+		ExternalCallDuration.WithLabelValues(target, result).Observe(time.Since(start).Seconds())
+	}
+}
+
+// SetManagedObjects sets the number of objects of kind managed by controller.
+func SetManagedObjects(controller, kind string, n int) {
+	ManagedObjects.WithLabelValues(controller, kind).Set(float64(n))
+}
+
+// RecordConditionTransition counts one transition of the condition typ to status (with reason)
+// on an object of controller. Call it once the status update was persisted.
+func RecordConditionTransition(controller, typ, status, reason string) {
+	ConditionTransitions.WithLabelValues(controller, typ, status, reason).Inc()
+}
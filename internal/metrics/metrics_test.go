@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/yeongki/my-operator/internal/managerconfig"
+)
+
+func TestHelpers(t *testing.T) {
+	RecordReconcile("test", Success)
+	RecordReconcile("test", Error("create_failed"))
+	RecordReconcile("test", Error("create_failed"))
+	if got := testutil.ToFloat64(ReconcileOutcomes.WithLabelValues("test", "error", "create_failed")); got != 2 {
+		t.Fatalf("expected 2 errors, got %v", got)
+	}
+	if got := testutil.ToFloat64(ReconcileOutcomes.WithLabelValues("test", "success", "")); got != 1 {
+		t.Fatalf("expected 1 success, got %v", got)
+	}
+
+	TimeExternalCall("registry")(errors.New("timeout"))
+	if got := testutil.CollectAndCount(ExternalCallDuration, "myoperator_external_call_duration_seconds"); got != 1 {
+		t.Fatalf("expected one series, got %d", got)
+	}
+
+	SetControllerSettings("test", managerconfig.ControllerSettings{MaxConcurrentReconciles: 1, QPS: 10})
+	SetControllerSettings("test", managerconfig.ControllerSettings{MaxConcurrentReconciles: 4, QPS: 2.5, Burst: 5})
+	if got := testutil.ToFloat64(ControllerSettingsInfo.WithLabelValues("test", "4", "0s", "0s", "2.5", "5")); got != 1 {
+		t.Fatalf("expected the settings info, got %v", got)
+	}
+	if got := testutil.CollectAndCount(ControllerSettingsInfo); got != 1 {
+		t.Fatalf("expected the previous settings to be replaced, got %d series", got)
+	}
+
+	SetManagedObjects("test", "StatefulSet", 3)
+	if got := testutil.ToFloat64(ManagedObjects.WithLabelValues("test", "StatefulSet")); got != 3 {
+		t.Fatalf("expected 3, got %v", got)
+	}
+
+	RecordConditionTransition("test", "Ready", "True", "ReplicasReady")
+	transitions := ConditionTransitions.WithLabelValues("test", "Ready", "True", "ReplicasReady")
+	if got := testutil.ToFloat64(transitions); got != 1 {
+		t.Fatalf("expected 1 transition, got %v", got)
+	}
+}
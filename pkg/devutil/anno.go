@@ -19,3 +19,17 @@ func SetTestStartTimeAnnoAt(ann map[string]string, now time.Time) map[string]str
 	ann[TestStartTimeAnnoKey] = now.UTC().Format(time.RFC3339Nano)
 	return ann
 }
+
+// TestStartTimeAnno returns the time recorded by SetTestStartTimeAnno (false when absent or malformed).
+// The operator reads it to observe convergence from its own clock (see myoperator_convergence_seconds).
+func TestStartTimeAnno(ann map[string]string) (time.Time, bool) {
+	v, ok := ann[TestStartTimeAnnoKey]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
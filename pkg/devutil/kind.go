@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
+	"strings"
 
 	"github.com/yeongki/my-operator/pkg/kubeutil"
 	"github.com/yeongki/my-operator/pkg/slo"
@@ -27,11 +29,7 @@ func LoadImageToKindClusterWithName(ctx context.Context, logger slo.Logger, r ku
 		return err
 	}
 
-	cluster := "kind"
-	if v, ok := os.LookupEnv("KIND_CLUSTER"); ok && v != "" {
-		cluster = v
-	}
-
+	cluster := KindClusterName()
 	logger.Logf("loading image into kind cluster=%q image=%q", cluster, image)
 
 	cmd := exec.Command("kind", "load", "docker-image", image, "--name", cluster)
@@ -40,3 +38,86 @@ func LoadImageToKindClusterWithName(ctx context.Context, logger slo.Logger, r ku
 	}
 	return nil
 }
+
+// KindClusterName returns env KIND_CLUSTER, or "kind".
+func KindClusterName() string {
+	if v, ok := os.LookupEnv("KIND_CLUSTER"); ok && v != "" {
+		return v
+	}
+	return "kind"
+}
+
+// KindClusterExists reports whether `kind get clusters` lists name.
+func KindClusterExists(ctx context.Context, logger slo.Logger, r kubeutil.CmdRunner, name string) (bool, error) {
+	if r == nil {
+		r = kubeutil.DefaultRunner{}
+	}
+	out, err := r.Run(ctx, logger, exec.Command("kind", "get", "clusters"))
+	if err != nil {
+		return false, fmt.Errorf("kind get clusters failed: %w", err)
+	}
+	return slices.Contains(strings.Fields(out), name), nil
+}
+
+// CreateKindCluster creates the kind cluster name and waits until its control plane is ready.
+// image may be empty (kind's default node image).
+func CreateKindCluster(ctx context.Context, logger slo.Logger, r kubeutil.CmdRunner, name, image string) error {
+	if r == nil {
+		r = kubeutil.DefaultRunner{}
+	}
+	args := []string{"create", "cluster", "--name", name, "--wait", "5m"}
+	if image != "" {
+		args = append(args, "--image", image)
+	}
+	slo.NewLogger(logger).Logf("creating kind cluster=%q", name)
+	if _, err := r.Run(ctx, logger, exec.Command("kind", args...)); err != nil {
+		return fmt.Errorf("kind create cluster failed: %w", err)
+	}
+	return nil
+}
+
+// DeleteKindCluster deletes the kind cluster name (no error when it does not exist).
+func DeleteKindCluster(ctx context.Context, logger slo.Logger, r kubeutil.CmdRunner, name string) error {
+	if r == nil {
+		r = kubeutil.DefaultRunner{}
+	}
+	slo.NewLogger(logger).Logf("deleting kind cluster=%q", name)
+	if _, err := r.Run(ctx, logger, exec.Command("kind", "delete", "cluster", "--name", name)); err != nil {
+		return fmt.Errorf("kind delete cluster failed: %w", err)
+	}
+	return nil
+}
+
+// EnsureKindCluster reuses the kind cluster name when it exists (exporting its kubeconfig as the
+// current context) and creates it otherwise. created tells the caller whether to delete it later.
+func EnsureKindCluster(
+	ctx context.Context, logger slo.Logger, r kubeutil.CmdRunner, name, image string,
+) (created bool, err error) {
+	if r == nil {
+		r = kubeutil.DefaultRunner{}
+	}
+	exists, err := KindClusterExists(ctx, logger, r, name)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		slo.NewLogger(logger).Logf("reusing kind cluster=%q", name)
+		if _, err := r.Run(ctx, logger, exec.Command("kind", "export", "kubeconfig", "--name", name)); err != nil {
+			return false, fmt.Errorf("kind export kubeconfig failed: %w", err)
+		}
+		return false, nil
+	}
+	if err := CreateKindCluster(ctx, logger, r, name, image); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// HasKubeContext reports whether kubectl has a current context (i.e. a cluster is configured).
+func HasKubeContext(ctx context.Context, logger slo.Logger, r kubeutil.CmdRunner) bool {
+	if r == nil {
+		r = kubeutil.DefaultRunner{}
+	}
+	out, err := r.Run(ctx, logger, exec.Command("kubectl", "config", "current-context"))
+	return err == nil && strings.TrimSpace(out) != ""
+}
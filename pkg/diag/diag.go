@@ -0,0 +1,209 @@
+// Package diag captures a must-gather style snapshot of namespaces into a tarball:
+// all namespaced resources as YAML, events, logs of every container and the CRD definitions.
+// It shells out to kubectl through kubeutil.CmdRunner, like the rest of the test tooling.
+package diag
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/kubeutil"
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// Options controls Collect.
+type Options struct {
+	// Namespaces to capture (required).
+	Namespaces []string
+	// OutDir receives the tarball (required, usually ArtifactsDir).
+	OutDir string
+	// Name of the tarball without extension (default "diag-<unix time>").
+	Name string
+
+	// IncludeSecrets also dumps Secret objects. Off by default: the tarball is often uploaded.
+	IncludeSecrets bool
+	// SkipCRDs skips the cluster-wide CRD definitions.
+	SkipCRDs bool
+	// LogTail limits lines per container log (0 => all).
+	LogTail int
+}
+
+// file is one tarball entry.
+type file struct {
+	name string
+	data []byte
+}
+
+// Collect captures the snapshot and returns the tarball path.
+// Individual kubectl failures do not abort the capture; they are listed in errors.txt inside the tarball.
+func Collect(ctx context.Context, logger slo.Logger, r kubeutil.CmdRunner, opts Options) (string, error) {
+	logger = slo.NewLogger(logger)
+	if r == nil {
+		r = kubeutil.DefaultRunner{}
+	}
+	if len(opts.Namespaces) == 0 {
+		return "", fmt.Errorf("diag: at least one namespace is required")
+	}
+	if strings.TrimSpace(opts.OutDir) == "" {
+		return "", fmt.Errorf("diag: OutDir is required")
+	}
+	if opts.Name == "" {
+		opts.Name = fmt.Sprintf("diag-%d", time.Now().Unix())
+	}
+
+	c := &collector{ctx: ctx, logger: logger, r: r}
+
+	kinds := c.namespacedKinds(opts.IncludeSecrets)
+	for _, ns := range opts.Namespaces {
+		c.namespace(ns, kinds, opts.LogTail)
+	}
+	if !opts.SkipCRDs {
+		c.add("cluster/crds.yaml", "get", "crd", "-o", "yaml")
+	}
+	if len(c.errs) > 0 {
+		c.files = append(c.files, file{name: "errors.txt", data: []byte(strings.Join(c.errs, "\n") + "\n")})
+	}
+
+	path := filepath.Join(opts.OutDir, opts.Name+".tar.gz")
+	if err := writeTarGz(path, opts.Name, c.files); err != nil {
+		return "", err
+	}
+	logger.Logf("diag: wrote %s (%d files, %d errors)", path, len(c.files), len(c.errs))
+	return path, nil
+}
+
+type collector struct {
+	ctx    context.Context
+	logger slo.Logger
+	r      kubeutil.CmdRunner
+
+	files []file
+	errs  []string
+}
+
+// kubectl runs one command. On error the (partial) stdout is still returned.
+func (c *collector) kubectl(args ...string) (string, bool) {
+	out, err := c.r.Run(c.ctx, c.logger, exec.Command("kubectl", args...))
+	if err != nil {
+		c.errs = append(c.errs, fmt.Sprintf("kubectl %s: %v", strings.Join(args, " "), err))
+		return out, false
+	}
+	return out, true
+}
+
+// add stores the output of a kubectl command (also when it partially failed).
+func (c *collector) add(name string, args ...string) {
+	out, _ := c.kubectl(args...)
+	if out != "" {
+		c.files = append(c.files, file{name: name, data: []byte(out)})
+	}
+}
+
+// namespacedKinds lists every listable namespaced resource (events are captured separately).
+func (c *collector) namespacedKinds(includeSecrets bool) []string {
+	out, ok := c.kubectl("api-resources", "--verbs=list", "--namespaced", "-o", "name")
+	if !ok {
+		return []string{"all", "configmaps", "serviceaccounts", "roles", "rolebindings"}
+	}
+	var kinds []string
+	for _, k := range strings.Fields(out) {
+		switch {
+		case k == "events" || strings.HasPrefix(k, "events."):
+			continue
+		case !includeSecrets && k == "secrets":
+			continue
+		}
+		kinds = append(kinds, k)
+	}
+	return kinds
+}
+
+func (c *collector) namespace(ns string, kinds []string, tail int) {
+	if len(kinds) > 0 {
+		c.add(ns+"/resources.yaml", "get", strings.Join(kinds, ","), "-n", ns, "-o", "yaml", "--ignore-not-found")
+	}
+	c.add(ns+"/events.txt", "get", "events", "-n", ns, "--sort-by=.lastTimestamp", "-o", "wide")
+
+	// "<pod> <container> <container> ..." per line
+	jsonpath := `jsonpath={range .items[*]}{.metadata.name}{" "}` +
+		`{range .spec.initContainers[*]}{.name}{" "}{end}{range .spec.containers[*]}{.name}{" "}{end}{"\n"}{end}`
+	out, ok := c.kubectl("get", "pods", "-n", ns, "-o", jsonpath)
+	if !ok {
+		return
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		pod := fields[0]
+		for _, container := range fields[1:] {
+			args := []string{"logs", pod, "-n", ns, "-c", container}
+			if tail > 0 {
+				args = append(args, fmt.Sprintf("--tail=%d", tail))
+			}
+			c.add(fmt.Sprintf("%s/logs/%s/%s.log", ns, pod, container), args...)
+		}
+	}
+}
+
+// writeTarGz writes files under root/ into a gzip tarball via temp file + rename.
+func writeTarGz(path, root string, files []file) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	success := false
+	defer func() {
+		if !success {
+			_ = f.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, fl := range files {
+		hdr := &tar.Header{
+			Name:    root + "/" + fl.name,
+			Mode:    0o644,
+			Size:    int64(len(fl.data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(fl.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	success = true
+	return nil
+}
@@ -0,0 +1,114 @@
+package diag
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// fakeRunner answers kubectl by argument prefix and fails everything else.
+type fakeRunner struct {
+	out  map[string]string // "<args>" prefix -> stdout
+	seen []string
+}
+
+func (f *fakeRunner) Run(_ context.Context, _ slo.Logger, cmd *exec.Cmd) (string, error) {
+	line := strings.Join(cmd.Args[1:], " ")
+	f.seen = append(f.seen, line)
+	for prefix, out := range f.out {
+		if strings.HasPrefix(line, prefix) {
+			return out, nil
+		}
+	}
+	return "", errors.New("forbidden")
+}
+
+// readTarGz returns the tarball entries by name.
+func readTarGz(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	out := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[hdr.Name] = string(b)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	r := &fakeRunner{out: map[string]string{
+		"api-resources":  "pods\nsecrets\nevents\nevents.events.k8s.io\nconfigmaps\n",
+		"get pods,":      "kind: List\n",
+		"get events":     "LAST SEEN   TYPE   REASON\n",
+		"get pods -n ns": "manager-0 init manager\n",
+		"logs manager-0": "started\n",
+	}}
+	path, err := Collect(context.Background(), nil, r, Options{
+		Namespaces: []string{"ns"},
+		OutDir:     t.TempDir(),
+		Name:       "diag-test",
+		LogTail:    50,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := readTarGz(t, path)
+	for _, name := range []string{
+		"diag-test/ns/resources.yaml",
+		"diag-test/ns/events.txt",
+		"diag-test/ns/logs/manager-0/init.log",
+		"diag-test/ns/logs/manager-0/manager.log",
+		"diag-test/errors.txt",
+	} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s in %v", name, r.seen)
+		}
+	}
+	// the CRD dump was refused: listed in errors.txt, not an error of Collect
+	if errs := files["diag-test/errors.txt"]; !strings.Contains(errs, "kubectl get crd -o yaml: forbidden") {
+		t.Errorf("errors.txt = %q", errs)
+	}
+	for _, line := range r.seen {
+		if strings.HasPrefix(line, "get pods,") && (strings.Contains(line, "secrets") || strings.Contains(line, "events")) {
+			t.Errorf("secrets and events must not be dumped as resources: %q", line)
+		}
+		if strings.HasPrefix(line, "logs ") && !strings.HasSuffix(line, "--tail=50") {
+			t.Errorf("log tail not applied: %q", line)
+		}
+	}
+}
+
+func TestCollectOptions(t *testing.T) {
+	for _, opts := range []Options{{OutDir: t.TempDir()}, {Namespaces: []string{"ns"}}} {
+		if _, err := Collect(context.Background(), nil, &fakeRunner{}, opts); err == nil {
+			t.Errorf("expected %+v to be rejected", opts)
+		}
+	}
+}
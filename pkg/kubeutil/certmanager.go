@@ -5,23 +5,38 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/yeongki/my-operator/pkg/slo"
 )
 
-const (
-	certmanagerVersion = "v1.16.3"
-	certmanagerURLTmpl = "https://github.com/cert-manager/cert-manager/releases/download/%s/cert-manager.yaml"
-)
+// CertManagerVersion is the cert-manager release installed when no version is given.
+const CertManagerVersion = "v1.16.3"
+
+const certmanagerURLTmpl = "https://github.com/cert-manager/cert-manager/releases/download/%s/cert-manager.yaml"
+
+// certmanagerWebhookProbe is created with --dry-run=server: it only succeeds once the webhook
+// serves admission requests (endpoints ready and its CA injected), which "Available" alone doesn't imply.
+const certmanagerWebhookProbe = `apiVersion: cert-manager.io/v1
+kind: ClusterIssuer
+metadata:
+  name: e2e-webhook-probe
+spec:
+  selfSigned: {}
+`
 
-func certmanagerURL() string {
-	return fmt.Sprintf(certmanagerURLTmpl, certmanagerVersion)
+func certmanagerURL(version string) string {
+	if version == "" {
+		version = CertManagerVersion
+	}
+	return fmt.Sprintf(certmanagerURLTmpl, version)
 }
 
-// InstallCertManager installs cert-manager and waits for webhook deployment to be Available.
+// InstallCertManager installs cert-manager version ("" => CertManagerVersion) and waits until its
+// webhook accepts requests.
 // - logger may be nil (no-op).
 // - r may be nil (uses DefaultRunner).
-func InstallCertManager(ctx context.Context, logger slo.Logger, r CmdRunner) error {
+func InstallCertManager(ctx context.Context, logger slo.Logger, r CmdRunner, version string) error {
 	logger = slo.NewLogger(logger)
 	if r == nil {
 		r = DefaultRunner{}
@@ -30,30 +45,86 @@ func InstallCertManager(ctx context.Context, logger slo.Logger, r CmdRunner) err
 		return err
 	}
 
-	url := certmanagerURL()
-	logger.Logf("installing cert-manager version=%s", certmanagerVersion)
+	logger.Logf("installing cert-manager url=%s", certmanagerURL(version))
 
 	// Apply bundle
-	cmd := exec.Command("kubectl", "apply", "-f", url)
+	cmd := exec.Command("kubectl", "apply", "-f", certmanagerURL(version))
 	if _, err := r.Run(ctx, logger, cmd); err != nil {
 		return err
 	}
+	return WaitCertManagerWebhookReady(ctx, logger, r, WaitOptions{})
+}
+
+// WaitCertManagerWebhookReady waits for the cert-manager deployments to be Available and then until
+// a server-side dry-run of a ClusterIssuer passes the webhook (can take time after reinstall).
+// - logger may be nil (no-op).
+// - r may be nil (uses DefaultRunner).
+func WaitCertManagerWebhookReady(
+	ctx context.Context, logger slo.Logger, r CmdRunner, opts WaitOptions,
+) error {
+	logger = slo.NewLogger(logger)
+	if r == nil {
+		r = DefaultRunner{}
+	}
+	opts = opts.withDefaults()
+
+	waitCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
 
-	// Wait for webhook to be ready (can take time after reinstall).
 	// Note: kubectl --timeout is independent of ctx; ctx still can cancel the process.
-	cmd = exec.Command("kubectl", "wait", "deployment.apps/cert-manager-webhook",
+	cmd := exec.Command("kubectl", "wait", "deployment.apps",
+		"cert-manager", "cert-manager-cainjector", "cert-manager-webhook",
 		"--for", "condition=Available",
 		"--namespace", "cert-manager",
-		"--timeout", "5m",
+		"--timeout", opts.Timeout.String(),
 	)
-	_, err := r.Run(ctx, logger, cmd)
-	return err
+	if _, err := r.Run(waitCtx, logger, cmd); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		cmd := exec.Command("kubectl", "create", "--dry-run=server", "-f", "-")
+		cmd.Stdin = strings.NewReader(certmanagerWebhookProbe)
+		_, err := r.Run(waitCtx, logger, cmd)
+		if err == nil {
+			return nil
+		}
+		slo.Debugf(logger, "wait cert-manager webhook: not ready yet: %v", err)
+
+		select {
+		case <-waitCtx.Done():
+			return fmt.Errorf("timeout waiting cert-manager webhook: %w", waitCtx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnsureCertManager reuses an existing cert-manager install (waiting for its webhook) or installs
+// version. installed tells the caller whether UninstallCertManager should run on cleanup.
+// - logger may be nil (no-op).
+// - r may be nil (uses DefaultRunner).
+func EnsureCertManager(ctx context.Context, logger slo.Logger, r CmdRunner, version string) (installed bool, err error) {
+	logger = slo.NewLogger(logger)
+	if r == nil {
+		r = DefaultRunner{}
+	}
+	if IsCertManagerCRDsInstalled(ctx, logger, r) {
+		logger.Logf("cert-manager is already installed; reusing it")
+		return false, WaitCertManagerWebhookReady(ctx, logger, r, WaitOptions{})
+	}
+	if err := InstallCertManager(ctx, logger, r, version); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// UninstallCertManager uninstalls cert-manager bundle.
+// UninstallCertManager uninstalls the cert-manager bundle of version ("" => CertManagerVersion).
 // - logger may be nil (no-op).
 // - r may be nil (uses DefaultRunner).
-func UninstallCertManager(ctx context.Context, logger slo.Logger, r CmdRunner) error {
+func UninstallCertManager(ctx context.Context, logger slo.Logger, r CmdRunner, version string) error {
 	logger = slo.NewLogger(logger)
 	if r == nil {
 		r = DefaultRunner{}
@@ -62,10 +133,9 @@ func UninstallCertManager(ctx context.Context, logger slo.Logger, r CmdRunner) e
 		return err
 	}
 
-	url := certmanagerURL()
-	logger.Logf("uninstalling cert-manager version=%s", certmanagerVersion)
+	logger.Logf("uninstalling cert-manager url=%s", certmanagerURL(version))
 
-	cmd := exec.Command("kubectl", "delete", "-f", url)
+	cmd := exec.Command("kubectl", "delete", "--ignore-not-found", "-f", certmanagerURL(version))
 	_, err := r.Run(ctx, logger, cmd)
 	return err
 }
@@ -80,7 +150,7 @@ func IsCertManagerCRDsInstalled(ctx context.Context, logger slo.Logger, r CmdRun
 		r = DefaultRunner{}
 	}
 	if err := ctx.Err(); err != nil {
-		logger.Logf("IsCertManagerCRDsInstalled: ctx error: %v", err)
+		slo.Warnf(logger, "IsCertManagerCRDsInstalled: ctx error: %v", err)
 		return false
 	}
 
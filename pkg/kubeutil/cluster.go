@@ -0,0 +1,245 @@
+package kubeutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// ClusterInfo describes the cluster a measurement ran on. Deltas of two runs are only comparable
+// when the cluster shape is, so it is recorded next to the results.
+type ClusterInfo struct {
+	ServerVersion string // e.g. "v1.30.0"
+	Nodes         int
+	Provider      string // see DetectProvider
+	OperatorImage string // image of the operator's manager container
+	// OperatorImageDigest is the digest ("sha256:...") of the image the container runs, from its
+	// status imageID; it pins the exact build even when the tag is reused (e.g. :latest on kind).
+	OperatorImageDigest string
+}
+
+// Cluster tag keys set by ClusterInfo.Tags.
+const (
+	TagK8sVersion      = "k8s_version"
+	TagClusterNodes    = "cluster_nodes"
+	TagClusterProvider = "cluster_provider"
+	TagOperatorImage   = "operator_image"
+	TagOperatorVersion = "operator_version"
+)
+
+// Tags returns the known fields as summary tags; unknown fields are left out.
+func (c ClusterInfo) Tags() map[string]string {
+	t := map[string]string{}
+	if c.ServerVersion != "" {
+		t[TagK8sVersion] = c.ServerVersion
+	}
+	if c.Nodes > 0 {
+		t[TagClusterNodes] = strconv.Itoa(c.Nodes)
+	}
+	if c.Provider != "" {
+		t[TagClusterProvider] = c.Provider
+	}
+	if c.OperatorImage != "" {
+		t[TagOperatorImage] = c.OperatorImage
+		if v := ImageTag(c.OperatorImage); v != "" {
+			t[TagOperatorVersion] = v
+		}
+	}
+	return t
+}
+
+// ClusterInfoOptions controls DescribeCluster.
+type ClusterInfoOptions struct {
+	// Namespace of the operator; empty => OperatorImage is not looked up.
+	Namespace string
+	// Selector of the operator pods (default "control-plane=controller-manager").
+	Selector string
+	// Container whose image is reported (default "manager", else the first container).
+	Container string
+}
+
+// DescribeCluster reads the server version, the nodes and the operator image with kubectl. It
+// returns whatever it could read together with the errors of the lookups that failed.
+func DescribeCluster(
+	ctx context.Context, logger slo.Logger, r CmdRunner, opts ClusterInfoOptions,
+) (ClusterInfo, error) {
+	logger = slo.NewLogger(logger)
+	if r == nil {
+		r = DefaultRunner{}
+	}
+	if opts.Selector == "" {
+		opts.Selector = "control-plane=controller-manager"
+	}
+	if opts.Container == "" {
+		opts.Container = "manager"
+	}
+
+	var info ClusterInfo
+	var errs []error
+	if out, err := r.Run(ctx, logger, exec.Command("kubectl", "version", "-o", "json")); err != nil {
+		errs = append(errs, err)
+	} else if info.ServerVersion, err = parseServerVersion(out); err != nil {
+		errs = append(errs, err)
+	}
+
+	if out, err := r.Run(ctx, logger, exec.Command("kubectl", "get", "nodes", "-o", "json")); err != nil {
+		errs = append(errs, err)
+	} else if nodes, err := parseNodes(out); err != nil {
+		errs = append(errs, err)
+	} else {
+		info.Nodes = len(nodes)
+		info.Provider = DetectProvider(nodes)
+	}
+
+	if opts.Namespace != "" {
+		cmd := exec.Command("kubectl", "get", "pods", "-n", opts.Namespace, "-l", opts.Selector, "-o", "json")
+		if out, err := r.Run(ctx, logger, cmd); err != nil {
+			errs = append(errs, err)
+		} else if info.OperatorImage, info.OperatorImageDigest, err = parseContainerImage(out, opts.Container); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return info, errors.Join(errs...)
+}
+
+// NodeInfo is the part of a Node DetectProvider looks at.
+type NodeInfo struct {
+	Name       string
+	ProviderID string
+	Labels     map[string]string
+}
+
+// DetectProvider names the kind of cluster from its nodes: "kind", "minikube", "k3d", "k3s",
+// "docker-desktop", "eks", "gke", "aks", another providerID scheme, or "real" for clusters whose
+// nodes carry no provider hint (e.g. kubeadm on bare metal). "" => no nodes.
+func DetectProvider(nodes []NodeInfo) string {
+	if len(nodes) == 0 {
+		return ""
+	}
+	n := nodes[0]
+	scheme, _, _ := strings.Cut(n.ProviderID, "://")
+	switch {
+	case scheme == "kind":
+		return "kind"
+	case n.Labels["minikube.k8s.io/name"] != "" || n.Name == "minikube":
+		return "minikube"
+	case scheme == "k3s" && strings.HasPrefix(n.Name, "k3d-"):
+		return "k3d"
+	case scheme == "k3s":
+		return "k3s"
+	case n.Name == "docker-desktop" || n.Name == "desktop-control-plane":
+		return "docker-desktop"
+	case scheme == "aws":
+		return "eks"
+	case scheme == "gce":
+		return "gke"
+	case scheme == "azure":
+		return "aks"
+	case scheme != "":
+		return scheme
+	}
+	return "real"
+}
+
+// ImageTag returns the tag of an image reference ("" when it has none, e.g. only a digest).
+func ImageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, _ := strings.Cut(name, ":")
+	return tag
+}
+
+func parseServerVersion(out string) (string, error) {
+	var v struct {
+		ServerVersion *struct {
+			GitVersion string `json:"gitVersion"`
+		} `json:"serverVersion"`
+	}
+	if err := json.Unmarshal([]byte(out), &v); err != nil {
+		return "", fmt.Errorf("kubectl version: %w", err)
+	}
+	if v.ServerVersion == nil {
+		return "", errors.New("kubectl version: no server version")
+	}
+	return v.ServerVersion.GitVersion, nil
+}
+
+func parseNodes(out string) ([]NodeInfo, error) {
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name   string            `json:"name"`
+				Labels map[string]string `json:"labels"`
+			} `json:"metadata"`
+			Spec struct {
+				ProviderID string `json:"providerID"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return nil, fmt.Errorf("kubectl get nodes: %w", err)
+	}
+	nodes := make([]NodeInfo, 0, len(list.Items))
+	for _, it := range list.Items {
+		nodes = append(nodes, NodeInfo{Name: it.Metadata.Name, ProviderID: it.Spec.ProviderID, Labels: it.Metadata.Labels})
+	}
+	return nodes, nil
+}
+
+// parseContainerImage returns the image and image digest of container (else of the first
+// container) in a pod list.
+func parseContainerImage(out, container string) (image, digest string, err error) {
+	type named struct {
+		Name    string `json:"name"`
+		Image   string `json:"image"`
+		ImageID string `json:"imageID"`
+	}
+	var list struct {
+		Items []struct {
+			Spec struct {
+				Containers []named `json:"containers"`
+			} `json:"spec"`
+			Status struct {
+				ContainerStatuses []named `json:"containerStatuses"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return "", "", fmt.Errorf("kubectl get pods: %w", err)
+	}
+	for _, it := range list.Items {
+		for i, c := range it.Spec.Containers {
+			if c.Name != container && (image != "" || i > 0) {
+				continue
+			}
+			image, digest = c.Image, ""
+			for _, st := range it.Status.ContainerStatuses {
+				if st.Name == c.Name {
+					digest = ImageDigest(st.ImageID)
+				}
+			}
+			if c.Name == container {
+				return image, digest, nil
+			}
+		}
+	}
+	if image == "" {
+		return "", "", errors.New("operator image: no operator pod found")
+	}
+	return image, digest, nil
+}
+
+// ImageDigest returns the "sha256:..." part of an image reference or container imageID (e.g.
+// "docker.io/library/op@sha256:ab..."), "" when there is none.
+func ImageDigest(ref string) string {
+	if i := strings.Index(ref, "sha256:"); i >= 0 {
+		return ref[i:]
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package kubeutil
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+type fakeRunner map[string]string // "kubectl <args>" prefix -> stdout
+
+func (f fakeRunner) Run(_ context.Context, _ slo.Logger, cmd *exec.Cmd) (string, error) {
+	line := strings.Join(cmd.Args, " ")
+	for prefix, out := range f {
+		if strings.HasPrefix(line, prefix) {
+			return out, nil
+		}
+	}
+	return "", errors.New("forbidden")
+}
+
+func TestDescribeCluster(t *testing.T) {
+	r := fakeRunner{
+		"kubectl version": `{"clientVersion":{"gitVersion":"v1.31.1"},"serverVersion":{"gitVersion":"v1.30.0"}}`,
+		"kubectl get nodes": `{"items":[
+			{"metadata":{"name":"kind-control-plane"},"spec":{"providerID":"kind://docker/kind/kind-control-plane"}},
+			{"metadata":{"name":"kind-worker"},"spec":{"providerID":"kind://docker/kind/kind-worker"}}]}`,
+	}
+	info, err := DescribeCluster(context.Background(), nil, r, ClusterInfoOptions{Namespace: "my-operator-system"})
+	if err == nil || !strings.Contains(err.Error(), "forbidden") {
+		t.Errorf("expected the pod lookup error, got %v", err)
+	}
+	want := ClusterInfo{ServerVersion: "v1.30.0", Nodes: 2, Provider: "kind"}
+	if info != want {
+		t.Errorf("info = %+v, want %+v", info, want)
+	}
+
+	r["kubectl get pods"] = `{"items":[{"spec":{"containers":[
+		{"name":"kube-rbac-proxy","image":"quay.io/brancz/kube-rbac-proxy:v0.16.0"},
+		{"name":"manager","image":"localhost:5000/my-operator:v0.2.1"}]},
+		"status":{"containerStatuses":[{"name":"manager","imageID":"localhost:5000/my-operator@sha256:feed"}]}}]}`
+	info, err = DescribeCluster(context.Background(), nil, r, ClusterInfoOptions{Namespace: "my-operator-system"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.OperatorImageDigest != "sha256:feed" {
+		t.Errorf("digest = %q", info.OperatorImageDigest)
+	}
+	tags := info.Tags()
+	if tags[TagOperatorImage] != "localhost:5000/my-operator:v0.2.1" || tags[TagOperatorVersion] != "v0.2.1" ||
+		tags[TagClusterNodes] != "2" || tags[TagK8sVersion] != "v1.30.0" {
+		t.Errorf("tags = %v", tags)
+	}
+}
+
+func TestDetectProvider(t *testing.T) {
+	for want, n := range map[string]NodeInfo{
+		"minikube": {Name: "minikube", Labels: map[string]string{"minikube.k8s.io/name": "minikube"}},
+		"k3d":      {Name: "k3d-dev-server-0", ProviderID: "k3s://k3d-dev-server-0"},
+		"eks":      {Name: "ip-10-0-1-2", ProviderID: "aws:///eu-west-1a/i-0abc"},
+		"real":     {Name: "node-1"},
+	} {
+		if got := DetectProvider([]NodeInfo{n}); got != want {
+			t.Errorf("DetectProvider(%+v) = %q, want %q", n, got, want)
+		}
+	}
+	if got := ImageTag("localhost:5000/op@sha256:abc"); got != "" {
+		t.Errorf("ImageTag of a digest = %q", got)
+	}
+}
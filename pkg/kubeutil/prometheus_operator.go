@@ -97,7 +97,7 @@ func IsPrometheusOperatorCRDsInstalled(
 		r = DefaultRunner{}
 	}
 	if err := ctx.Err(); err != nil {
-		logger.Logf("IsPrometheusOperatorCRDsInstalled: ctx error: %v", err)
+		slo.Warnf(logger, "IsPrometheusOperatorCRDsInstalled: ctx error: %v", err)
 		return false
 	}
 
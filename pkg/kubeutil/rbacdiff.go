@@ -0,0 +1,171 @@
+package kubeutil
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Permission is one (apiGroup, resource, verb) triple. Resource includes the subresource
+// ("joboperators/status"); in rules "*" matches anything.
+type Permission struct {
+	Group    string `json:"group"`
+	Resource string `json:"resource"`
+	Verb     string `json:"verb"`
+}
+
+// String renders "verb resource.group" (core group: "verb resource").
+func (p Permission) String() string {
+	if p.Group == "" {
+		return p.Verb + " " + p.Resource
+	}
+	return p.Verb + " " + p.Resource + "." + p.Group
+}
+
+// RBACDiff compares the permissions granted by RBAC rules with the ones actually exercised.
+type RBACDiff struct {
+	// Missing were used (or denied) but no rule grants them.
+	Missing []Permission `json:"missing,omitempty"`
+	// Excessive are granted but were not used in the observed window (advisory: the window may
+	// not have exercised every code path).
+	Excessive []Permission `json:"excessive,omitempty"`
+	// Denied are requests the API server answered with 403.
+	Denied []Permission `json:"denied,omitempty"`
+}
+
+var yamlDocSep = regexp.MustCompile(`(?m)^---\s*$`)
+
+// LoadRBACRules reads the rules of every Role/ClusterRole document in files
+// (e.g. config/rbac/role.yaml, config/rbac/leader_election_role.yaml). Namespaces are not tracked.
+func LoadRBACRules(files ...string) ([]rbacv1.PolicyRule, error) {
+	var rules []rbacv1.PolicyRule
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range yamlDocSep.Split(string(b), -1) {
+			var obj struct {
+				Kind  string              `json:"kind"`
+				Rules []rbacv1.PolicyRule `json:"rules"`
+			}
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				return nil, fmt.Errorf("%s: %w", f, err)
+			}
+			if obj.Kind == "Role" || obj.Kind == "ClusterRole" {
+				rules = append(rules, obj.Rules...)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// auditEvent is the subset of an audit.k8s.io/v1 Event the diff needs.
+type auditEvent struct {
+	Verb string `json:"verb"`
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ObjectRef *struct {
+		APIGroup    string `json:"apiGroup"`
+		Resource    string `json:"resource"`
+		Subresource string `json:"subresource"`
+	} `json:"objectRef"`
+	ResponseStatus *struct {
+		Code int `json:"code"`
+	} `json:"responseStatus"`
+}
+
+// PermissionsFromAudit returns the resource permissions user (e.g.
+// "system:serviceaccount:<ns>:<sa>") exercised and the ones denied with 403, from a JSON-lines
+// audit log. Non-resource requests and lines that are not audit events are ignored.
+func PermissionsFromAudit(r io.Reader, user string) (used, denied []Permission, err error) {
+	usedSet, deniedSet := map[Permission]bool{}, map[Permission]bool{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var ev auditEvent
+		if json.Unmarshal(sc.Bytes(), &ev) != nil || ev.User.Username != user || ev.ObjectRef == nil {
+			continue
+		}
+		p := Permission{Group: ev.ObjectRef.APIGroup, Resource: ev.ObjectRef.Resource, Verb: ev.Verb}
+		if ev.ObjectRef.Subresource != "" {
+			p.Resource += "/" + ev.ObjectRef.Subresource
+		}
+		if ev.ResponseStatus != nil && ev.ResponseStatus.Code == 403 {
+			deniedSet[p] = true
+			continue
+		}
+		usedSet[p] = true
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+	return sortedPermissions(usedSet), sortedPermissions(deniedSet), nil
+}
+
+// DiffRBAC flags permissions used or denied but not granted by rules (Missing) and granted but not
+// used (Excessive). Wildcard rules count as used when anything matched them.
+func DiffRBAC(rules []rbacv1.PolicyRule, used, denied []Permission) RBACDiff {
+	granted := map[Permission]bool{}
+	for _, rule := range rules {
+		for _, g := range rule.APIGroups {
+			for _, res := range rule.Resources {
+				for _, v := range rule.Verbs {
+					granted[Permission{Group: g, Resource: res, Verb: v}] = true
+				}
+			}
+		}
+	}
+
+	missing, exercised := map[Permission]bool{}, map[Permission]bool{}
+	for _, p := range slices.Concat(used, denied) {
+		matched := false
+		for g := range granted {
+			if g.allows(p) {
+				exercised[g] = true
+				matched = true
+			}
+		}
+		if !matched {
+			missing[p] = true
+		}
+	}
+
+	excessive := map[Permission]bool{}
+	for g := range granted {
+		if !exercised[g] {
+			excessive[g] = true
+		}
+	}
+	return RBACDiff{
+		Missing:   sortedPermissions(missing),
+		Excessive: sortedPermissions(excessive),
+		Denied:    denied,
+	}
+}
+
+// allows reports whether the granted (rule) permission g covers p.
+func (g Permission) allows(p Permission) bool {
+	match := func(rule, v string) bool { return rule == "*" || rule == v }
+	return match(g.Group, p.Group) && match(g.Resource, p.Resource) && match(g.Verb, p.Verb)
+}
+
+func sortedPermissions(set map[Permission]bool) []Permission {
+	out := make([]Permission, 0, len(set))
+	for p := range set {
+		out = append(out, p)
+	}
+	slices.SortFunc(out, func(a, b Permission) int {
+		return cmp.Or(cmp.Compare(a.Group, b.Group), cmp.Compare(a.Resource, b.Resource), cmp.Compare(a.Verb, b.Verb))
+	})
+	return out
+}
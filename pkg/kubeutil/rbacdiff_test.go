@@ -0,0 +1,64 @@
+package kubeutil
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testRole = `---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: manager-role
+rules:
+- apiGroups: [apps]
+  resources: [statefulsets]
+  verbs: [get, list, watch, delete]
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: ignored
+`
+
+func TestDiffRBACFromAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "role.yaml")
+	if err := os.WriteFile(path, []byte(testRole), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRBACRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sa := "system:serviceaccount:ns:sa"
+	audit := strings.Join([]string{
+		`{"verb":"list","user":{"username":"` + sa + `"},"objectRef":{"apiGroup":"apps","resource":"statefulsets"}}`,
+		`{"verb":"watch","user":{"username":"` + sa + `"},"objectRef":{"apiGroup":"apps","resource":"statefulsets"}}`,
+		`{"verb":"create","user":{"username":"` + sa + `"},"objectRef":{"apiGroup":"apps","resource":"statefulsets"},` +
+			`"responseStatus":{"code":403}}`,
+		`{"verb":"get","user":{"username":"someone-else"},"objectRef":{"resource":"secrets"}}`,
+		`{"verb":"get","user":{"username":"` + sa + `"}}`,
+		`not json`,
+	}, "\n")
+	used, denied, err := PermissionsFromAudit(strings.NewReader(audit), sa)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff := DiffRBAC(rules, used, denied)
+	create := Permission{Group: "apps", Resource: "statefulsets", Verb: "create"}
+	if !reflect.DeepEqual(diff.Missing, []Permission{create}) || !reflect.DeepEqual(diff.Denied, []Permission{create}) {
+		t.Fatalf("unexpected missing/denied %+v", diff)
+	}
+	want := []Permission{
+		{Group: "apps", Resource: "statefulsets", Verb: "delete"},
+		{Group: "apps", Resource: "statefulsets", Verb: "get"},
+	}
+	if !reflect.DeepEqual(diff.Excessive, want) {
+		t.Fatalf("unexpected excessive %v", diff.Excessive)
+	}
+}
@@ -0,0 +1,143 @@
+package kubeutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// ReapOptions controls ReapPods.
+type ReapOptions struct {
+	// Namespaces to scan. Empty => all namespaces.
+	Namespaces []string
+	// LabelSelector of the pods to reap (required, e.g. "app=curl-metrics").
+	LabelSelector string
+	// MinAge: only pods created at least this long ago are deleted (0 => 10m).
+	MinAge time.Duration
+	// DryRun lists candidates without deleting them.
+	DryRun bool
+
+	// Now is used for age calculation (nil => time.Now).
+	Now func() time.Time
+}
+
+// ReapResult reports what ReapPods did. Pod names are "<namespace>/<name>".
+type ReapResult struct {
+	Reaped []string
+	Kept   int
+}
+
+// ReapPods deletes pods matching opts.LabelSelector that are older than opts.MinAge.
+// It is meant for leftovers of interrupted runs (e.g. curl-metrics scrape pods), so
+// young pods are kept: they may belong to a run that is still in progress.
+func ReapPods(ctx context.Context, logger slo.Logger, r CmdRunner, opts ReapOptions) (ReapResult, error) {
+	logger = slo.NewLogger(logger)
+	if r == nil {
+		r = DefaultRunner{}
+	}
+	if strings.TrimSpace(opts.LabelSelector) == "" {
+		return ReapResult{}, errors.New("reap pods: label selector is required")
+	}
+	if opts.MinAge <= 0 {
+		opts.MinAge = 10 * time.Minute
+	}
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+
+	scopes := opts.Namespaces
+	if len(scopes) == 0 {
+		scopes = []string{""}
+	}
+
+	var res ReapResult
+	byNS := map[string][]string{}
+	for _, ns := range scopes {
+		out, err := r.Run(ctx, logger, listPodsCmd(ns, opts.LabelSelector))
+		if err != nil {
+			return res, err
+		}
+		pods, err := parsePodList(out)
+		if err != nil {
+			return res, err
+		}
+		for _, p := range pods {
+			if now().Sub(p.created) < opts.MinAge {
+				res.Kept++
+				continue
+			}
+			byNS[p.namespace] = append(byNS[p.namespace], p.name)
+		}
+	}
+
+	namespaces := make([]string, 0, len(byNS))
+	for ns := range byNS {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	for _, ns := range namespaces {
+		names := byNS[ns]
+		sort.Strings(names)
+		if !opts.DryRun {
+			args := append([]string{"delete", "pod", "-n", ns}, names...)
+			args = append(args, "--ignore-not-found=true", "--wait=false")
+			if _, err := r.Run(ctx, logger, exec.Command("kubectl", args...)); err != nil {
+				return res, err
+			}
+		}
+		for _, n := range names {
+			res.Reaped = append(res.Reaped, ns+"/"+n)
+		}
+	}
+
+	logger.Logf("reap pods (selector=%q minAge=%s dryRun=%v): reaped=%d kept=%d",
+		opts.LabelSelector, opts.MinAge, opts.DryRun, len(res.Reaped), res.Kept)
+	return res, nil
+}
+
+type podAge struct {
+	namespace string
+	name      string
+	created   time.Time
+}
+
+func listPodsCmd(ns, selector string) *exec.Cmd {
+	jsonpath := `jsonpath={range .items[*]}{.metadata.namespace}{"\t"}{.metadata.name}` +
+		`{"\t"}{.metadata.creationTimestamp}{"\n"}{end}`
+	args := []string{"get", "pods", "-l", selector, "-o", jsonpath}
+	if ns == "" {
+		args = append(args, "--all-namespaces")
+	} else {
+		args = append(args, "-n", ns)
+	}
+	return exec.Command("kubectl", args...)
+}
+
+// parsePodList parses "<ns>\t<name>\t<RFC3339>" lines.
+func parsePodList(out string) ([]podAge, error) {
+	var pods []podAge
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("reap pods: unexpected kubectl output line %q", line)
+		}
+		created, err := time.Parse(time.RFC3339, parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("reap pods: pod %s/%s: %w", parts[0], parts[1], err)
+		}
+		pods = append(pods, podAge{namespace: parts[0], name: parts[1], created: created})
+	}
+	return pods, nil
+}
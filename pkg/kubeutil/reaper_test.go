@@ -0,0 +1,119 @@
+package kubeutil
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+func TestParsePodList(t *testing.T) {
+	created := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name    string
+		out     string
+		want    []podAge
+		wantErr string
+	}{
+		{name: "empty", out: "", want: nil},
+		{
+			name: "lines",
+			out:  "ns-a\tcurl-1\t2026-01-01T10:00:00Z\n\n  ns-b\tcurl-2\t2026-01-01T10:00:00Z  \n",
+			want: []podAge{{"ns-a", "curl-1", created}, {"ns-b", "curl-2", created}},
+		},
+		{name: "missing field", out: "ns-a\tcurl-1\n", wantErr: "unexpected kubectl output line"},
+		{name: "bad timestamp", out: "ns-a\tcurl-1\tyesterday\n", wantErr: "pod ns-a/curl-1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parsePodList(tc.out)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+// reapRunner lists pods from list and records the delete commands.
+type reapRunner struct {
+	list    string
+	deletes []string
+}
+
+func (r *reapRunner) Run(_ context.Context, _ slo.Logger, cmd *exec.Cmd) (string, error) {
+	line := strings.Join(cmd.Args[1:], " ")
+	if strings.HasPrefix(line, "delete") {
+		r.deletes = append(r.deletes, line)
+		return "", nil
+	}
+	return r.list, nil
+}
+
+func TestReapPodsAge(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	list := "ns-b\told-2\t2026-01-01T10:00:00Z\n" +
+		"ns-a\told-1\t2026-01-01T11:00:00Z\n" +
+		"ns-a\tyoung\t2026-01-01T11:55:00Z\n"
+	for _, tc := range []struct {
+		name        string
+		minAge      time.Duration
+		dryRun      bool
+		wantReaped  []string
+		wantKept    int
+		wantDeletes []string
+	}{
+		{
+			name:       "default min age",
+			wantReaped: []string{"ns-a/old-1", "ns-b/old-2"},
+			wantKept:   1,
+			wantDeletes: []string{
+				"delete pod -n ns-a old-1 --ignore-not-found=true --wait=false",
+				"delete pod -n ns-b old-2 --ignore-not-found=true --wait=false",
+			},
+		},
+		{
+			name:        "age equal to min age is reaped",
+			minAge:      2 * time.Hour,
+			wantReaped:  []string{"ns-b/old-2"},
+			wantKept:    2,
+			wantDeletes: []string{"delete pod -n ns-b old-2 --ignore-not-found=true --wait=false"},
+		},
+		{name: "nothing old enough", minAge: 3 * time.Hour, wantKept: 3},
+		{name: "dry run", dryRun: true, wantReaped: []string{"ns-a/old-1", "ns-b/old-2"}, wantKept: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &reapRunner{list: list}
+			res, err := ReapPods(context.Background(), nil, r, ReapOptions{
+				LabelSelector: "app=curl-metrics",
+				MinAge:        tc.minAge,
+				DryRun:        tc.dryRun,
+				Now:           func() time.Time { return now },
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res.Reaped, tc.wantReaped) || res.Kept != tc.wantKept {
+				t.Fatalf("result %+v, want reaped %v kept %d", res, tc.wantReaped, tc.wantKept)
+			}
+			if !reflect.DeepEqual(r.deletes, tc.wantDeletes) {
+				t.Fatalf("deletes %q, want %q", r.deletes, tc.wantDeletes)
+			}
+		})
+	}
+
+	if _, err := ReapPods(context.Background(), nil, &reapRunner{}, ReapOptions{}); err == nil {
+		t.Fatal("expected a missing label selector to be rejected")
+	}
+}
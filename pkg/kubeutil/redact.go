@@ -0,0 +1,46 @@
+package kubeutil
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// redacted replaces a credential in logs and errors.
+const redacted = "[REDACTED]"
+
+// secretPatterns match credentials that can show up in a command line or its output: bearer
+// tokens, JWTs (ServiceAccount tokens), --token flags and "token" JSON fields (TokenRequest
+// responses, also with %q-escaped quotes). The first group of each is kept.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+	regexp.MustCompile(`()eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	regexp.MustCompile(`(--token[= ])\S+`),
+	regexp.MustCompile(`(\\?"token\\?"\s*:\s*\\?")[^"\\]+`),
+}
+
+// Redact masks the credentials in s (see secretPatterns), so a command line, kubectl output or
+// error can be logged or written to an artifact.
+func Redact(s string) string {
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, "${1}"+redacted)
+	}
+	return s
+}
+
+// RedactLogger returns a logger masking credentials (Redact) in every line before passing it to l.
+// The level of a line (slo.Warnf, slo.Debugf) is passed on.
+func RedactLogger(l slo.Logger) slo.Logger {
+	return redactLogger{l: slo.NewLogger(l)}
+}
+
+type redactLogger struct{ l slo.Logger }
+
+func (r redactLogger) Logf(format string, args ...any) {
+	r.l.Logf("%s", Redact(fmt.Sprintf(format, args...)))
+}
+
+func (r redactLogger) LogLevel(level slo.Level, msg string) {
+	slo.Log(r.l, level, "%s", Redact(msg))
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/yeongki/my-operator/pkg/slo"
 )
@@ -16,7 +17,8 @@ type CmdRunner interface {
 }
 
 // DefaultRunner executes commands and returns stdout.
-// On error, includes stderr+stdout in the returned error.
+// On error, includes stderr+stdout in the returned error. The logged command and the error are
+// redacted (Redact): a token in argv or in the output never reaches the logs.
 type DefaultRunner struct{}
 
 func (DefaultRunner) Run(ctx context.Context, logger slo.Logger, cmd *exec.Cmd) (string, error) {
@@ -45,7 +47,7 @@ func (DefaultRunner) Run(ctx context.Context, logger slo.Logger, cmd *exec.Cmd)
 		c2.Env = append(c2.Env, "GO111MODULE=on")
 	}
 
-	command := strings.Join(c2.Args, " ")
+	command := Redact(strings.Join(c2.Args, " "))
 	logger.Logf("running: %q", command)
 
 	// TODO 왜 이렇게 했는지, 혹시 문제가 발생한다면 어떠한 문제가 발생할 수 있는지 스터디
@@ -54,13 +56,16 @@ func (DefaultRunner) Run(ctx context.Context, logger slo.Logger, cmd *exec.Cmd)
 	var stdout, stderr strings.Builder
 	c2.Stdout = &stdout
 	c2.Stderr = &stderr
+	// after ctx is done, don't wait forever for children (e.g. exec credential plugins) that keep
+	// the output pipes open
+	c2.WaitDelay = 5 * time.Second
 
 	err := c2.Run()
 	outStr := stdout.String()
 	errStr := stderr.String()
 
 	if err != nil {
-		combined := strings.TrimSpace(errStr + "\n" + outStr)
+		combined := Redact(strings.TrimSpace(errStr + "\n" + outStr))
 		return outStr, fmt.Errorf("%q failed: %s: %w", command, combined, err)
 	}
 	return outStr, nil
@@ -68,7 +68,7 @@ func ServiceAccountToken(ctx context.Context, logger slo.Logger, r CmdRunner, ns
 
 		var tr tokenRequest
 		if err := json.Unmarshal([]byte(stdout), &tr); err != nil {
-			return "", fmt.Errorf("token response json parse failed: %w (body=%q)", err, stdout)
+			return "", fmt.Errorf("token response json parse failed: %w (body=%q)", err, Redact(stdout))
 		}
 		if tr.Status.Token == "" {
 			return "", fmt.Errorf("token is empty")
@@ -80,7 +80,7 @@ func ServiceAccountToken(ctx context.Context, logger slo.Logger, r CmdRunner, ns
 		return tok, nil
 	} else {
 		lastErr = err
-		logger.Logf("token not ready yet: %v", err)
+		slo.Debugf(logger, "token not ready yet: %v", err)
 	}
 
 	for {
@@ -96,7 +96,7 @@ func ServiceAccountToken(ctx context.Context, logger slo.Logger, r CmdRunner, ns
 				return tok, nil
 			}
 			lastErr = err
-			logger.Logf("token not ready yet: %v", err)
+			slo.Debugf(logger, "token not ready yet: %v", err)
 		}
 	}
 }
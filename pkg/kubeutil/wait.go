@@ -85,7 +85,7 @@ func WaitPodContainerReadyByLabel(ctx context.Context, logger slo.Logger, r CmdR
 	if ok, err := tryOnce(); err == nil && ok {
 		return nil
 	} else if err != nil {
-		logger.Logf("wait pod ready: not ready yet: %v", err)
+		slo.Debugf(logger, "wait pod ready: not ready yet: %v", err)
 	}
 
 	for {
@@ -101,7 +101,7 @@ func WaitPodContainerReadyByLabel(ctx context.Context, logger slo.Logger, r CmdR
 		case <-ticker.C:
 			ok, err := tryOnce()
 			if err != nil {
-				logger.Logf("wait pod ready: not ready yet: %v", err)
+				slo.Debugf(logger, "wait pod ready: not ready yet: %v", err)
 				continue
 			}
 			if ok {
@@ -141,7 +141,7 @@ func WaitServiceHasEndpoints(ctx context.Context, logger slo.Logger, r CmdRunner
 	if ok, err := tryOnce(); err == nil && ok {
 		return nil
 	} else if err != nil {
-		logger.Logf("wait endpoints: not ready yet: %v", err)
+		slo.Debugf(logger, "wait endpoints: not ready yet: %v", err)
 	}
 
 	for {
@@ -157,7 +157,7 @@ func WaitServiceHasEndpoints(ctx context.Context, logger slo.Logger, r CmdRunner
 		case <-ticker.C:
 			ok, err := tryOnce()
 			if err != nil {
-				logger.Logf("wait endpoints: not ready yet: %v", err)
+				slo.Debugf(logger, "wait endpoints: not ready yet: %v", err)
 				continue
 			}
 			if ok {
@@ -0,0 +1,139 @@
+package metricdrift
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Rename is a removed family paired with an added one of the same type and label keys.
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Change is a family present in both runs whose type or label keys changed.
+type Change struct {
+	Name   string `json:"name"`
+	Detail string `json:"detail"` // e.g. "type COUNTER -> GAUGE", "labels [a b] -> [a]"
+}
+
+// Report is the drift between a baseline and the current families.
+type Report struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Renamed []Rename `json:"renamed,omitempty"`
+	Changed []Change `json:"changed,omitempty"`
+}
+
+// Breaking reports whether the drift can break consumers (alerts, dashboards): anything but additions.
+func (r Report) Breaking() bool {
+	return len(r.Removed) > 0 || len(r.Renamed) > 0 || len(r.Changed) > 0
+}
+
+// Empty reports whether nothing changed.
+func (r Report) Empty() bool {
+	return len(r.Added) == 0 && !r.Breaking()
+}
+
+// Diff compares current against baseline. A removed family is reported as renamed when exactly
+// one added family has its type and label keys and the two names share most of their prefix/suffix
+// (e.g. joboperator_reconcile_total -> joboperator_reconciles_total).
+func Diff(baseline, current []Family) Report {
+	base := index(baseline)
+	cur := index(current)
+
+	var rep Report
+	var removed, added []Family
+	for _, f := range baseline {
+		c, ok := cur[f.Name]
+		if !ok {
+			removed = append(removed, f)
+			continue
+		}
+		if c.Type != f.Type {
+			rep.Changed = append(rep.Changed, Change{Name: f.Name, Detail: fmt.Sprintf("type %s -> %s", f.Type, c.Type)})
+		}
+		if !slices.Equal(c.Labels, f.Labels) {
+			rep.Changed = append(rep.Changed, Change{Name: f.Name, Detail: fmt.Sprintf("labels %v -> %v", f.Labels, c.Labels)})
+		}
+	}
+	for _, f := range current {
+		if _, ok := base[f.Name]; !ok {
+			added = append(added, f)
+		}
+	}
+
+	used := map[string]bool{}
+	for _, r := range removed {
+		to := renameTarget(r, added, used)
+		if to == "" {
+			rep.Removed = append(rep.Removed, r.Name)
+			continue
+		}
+		used[to] = true
+		rep.Renamed = append(rep.Renamed, Rename{From: r.Name, To: to})
+	}
+	for _, a := range added {
+		if !used[a.Name] {
+			rep.Added = append(rep.Added, a.Name)
+		}
+	}
+	return rep
+}
+
+// renameTarget returns the only unused added family that looks like a rename of r ("" => none).
+func renameTarget(r Family, added []Family, used map[string]bool) string {
+	var match string
+	for _, a := range added {
+		if used[a.Name] || a.Type != r.Type || !slices.Equal(a.Labels, r.Labels) || !similar(r.Name, a.Name) {
+			continue
+		}
+		if match != "" {
+			return "" // ambiguous
+		}
+		match = a.Name
+	}
+	return match
+}
+
+// similar: the common prefix plus common suffix cover at least half of the longer name.
+func similar(a, b string) bool {
+	p := 0
+	for p < len(a) && p < len(b) && a[p] == b[p] {
+		p++
+	}
+	s := 0
+	for s < len(a)-p && s < len(b)-p && a[len(a)-1-s] == b[len(b)-1-s] {
+		s++
+	}
+	return 2*(p+s) >= max(len(a), len(b))
+}
+
+func index(fs []Family) map[string]Family {
+	out := make(map[string]Family, len(fs))
+	for _, f := range fs {
+		out[f.Name] = f
+	}
+	return out
+}
+
+// WriteText prints the report, one line per drift, e.g. "renamed  a_total -> b_total".
+func (r Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, n := range r.Removed {
+		fmt.Fprintf(&b, "removed  %s\n", n)
+	}
+	for _, n := range r.Renamed {
+		fmt.Fprintf(&b, "renamed  %s -> %s\n", n.From, n.To)
+	}
+	for _, c := range r.Changed {
+		fmt.Fprintf(&b, "changed  %s: %s\n", c.Name, c.Detail)
+	}
+	for _, n := range r.Added {
+		fmt.Fprintf(&b, "added    %s\n", n)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
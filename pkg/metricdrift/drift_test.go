@@ -0,0 +1,44 @@
+package metricdrift
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	counter := func(name string, labels ...string) Family {
+		return Family{Name: name, Type: "COUNTER", Labels: labels}
+	}
+	baseline := []Family{
+		counter("joboperator_reconcile_total", "result"),
+		counter("joboperator_errors_total", "reason"),
+		counter("controller_runtime_reconcile_total", "controller", "result"),
+		{Name: "workqueue_depth", Type: "GAUGE", Labels: []string{"name"}},
+	}
+	current := []Family{
+		counter("joboperator_reconciles_total", "result"),
+		counter("controller_runtime_reconcile_total", "controller"),
+		{Name: "workqueue_depth", Type: "COUNTER", Labels: []string{"name"}},
+		counter("joboperator_sts_created_total"),
+	}
+
+	got := Diff(baseline, current)
+	want := Report{
+		Added:   []string{"joboperator_sts_created_total"},
+		Removed: []string{"joboperator_errors_total"},
+		Renamed: []Rename{{From: "joboperator_reconcile_total", To: "joboperator_reconciles_total"}},
+		Changed: []Change{
+			{Name: "controller_runtime_reconcile_total", Detail: "labels [controller result] -> [controller]"},
+			{Name: "workqueue_depth", Detail: "type GAUGE -> COUNTER"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v\nwant %+v", got, want)
+	}
+	if !got.Breaking() {
+		t.Fatal("expected a breaking drift")
+	}
+	if r := Diff(current, current); !r.Empty() {
+		t.Fatalf("expected no drift, got %+v", r)
+	}
+}
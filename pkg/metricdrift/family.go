@@ -0,0 +1,98 @@
+// Package metricdrift records the metric families an operator exposes and compares them between
+// runs, so renamed or removed metrics (which silently break alerts and dashboards) fail the e2e run
+// instead of production alerting.
+package metricdrift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+
+	"github.com/yeongki/my-operator/pkg/slo/common/gzfile"
+)
+
+// Family describes one metric family of a scrape, as recorded in the metric-families artifact.
+type Family struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // COUNTER, GAUGE, HISTOGRAM, SUMMARY, UNTYPED, ...
+	Help string `json:"help,omitempty"`
+	// Labels are the label keys used by any series, sorted (histogram le / summary quantile excluded).
+	Labels []string `json:"labels,omitempty"`
+	Series int      `json:"series"`
+}
+
+// Parse validates text as Prometheus text exposition format (duplicate families, malformed
+// samples, TYPE/HELP after samples, ... are errors) and returns its families sorted by name.
+func Parse(text string) ([]Family, error) {
+	p := expfmt.NewTextParser(model.UTF8Validation)
+	mfs, err := p.TextToMetricFamilies(strings.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid exposition format: %w", err)
+	}
+
+	out := make([]Family, 0, len(mfs))
+	for name, mf := range mfs {
+		keys := map[string]bool{}
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				keys[lp.GetName()] = true
+			}
+		}
+		labels := make([]string, 0, len(keys))
+		for k := range keys {
+			labels = append(labels, k)
+		}
+		sort.Strings(labels)
+
+		out = append(out, Family{
+			Name:   name,
+			Type:   mf.GetType().String(),
+			Help:   mf.GetHelp(),
+			Labels: labels,
+			Series: len(mf.GetMetric()),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Load reads families from path: a metric-families JSON artifact or a raw /metrics scrape, either
+// possibly gzip-compressed.
+func Load(path string) ([]Family, error) {
+	b, err := gzfile.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		var out []Family
+		if err := json.Unmarshal(trimmed, &out); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return out, nil
+	}
+	out, err := Parse(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
+
+// Missing returns the required family names that families does not expose.
+func Missing(families []Family, required []string) []string {
+	exposed := make(map[string]bool, len(families))
+	for _, f := range families {
+		exposed[f.Name] = true
+	}
+	var missing []string
+	for _, name := range required {
+		if !exposed[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
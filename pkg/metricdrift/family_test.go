@@ -0,0 +1,62 @@
+package metricdrift
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const scrape = `# HELP controller_runtime_reconcile_total Total number of reconciliations per controller
+# TYPE controller_runtime_reconcile_total counter
+controller_runtime_reconcile_total{controller="joboperator",result="success"} 3
+controller_runtime_reconcile_total{controller="joboperator",result="error"} 0
+# TYPE workqueue_depth gauge
+workqueue_depth{name="joboperator"} 0
+`
+
+func TestParse(t *testing.T) {
+	families, err := Parse(scrape)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Family{
+		{
+			Name: "controller_runtime_reconcile_total", Type: "COUNTER",
+			Help:   "Total number of reconciliations per controller",
+			Labels: []string{"controller", "result"}, Series: 2,
+		},
+		{Name: "workqueue_depth", Type: "GAUGE", Labels: []string{"name"}, Series: 1},
+	}
+	if !reflect.DeepEqual(families, want) {
+		t.Fatalf("got %+v", families)
+	}
+
+	missing := Missing(families, []string{"workqueue_depth", "rest_client_requests_total"})
+	if !reflect.DeepEqual(missing, []string{"rest_client_requests_total"}) {
+		t.Fatalf("unexpected missing %v", missing)
+	}
+
+	if _, err := Parse("# TYPE x counter\nx 1\n# TYPE x counter\nx 2\n"); err == nil {
+		t.Fatal("expected an error for a duplicate TYPE line")
+	}
+}
+
+func TestLoadTextAndJSON(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "metrics.txt")
+	if err := os.WriteFile(text, []byte(scrape), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	js := filepath.Join(dir, "metric-families.json")
+	if err := os.WriteFile(js, []byte(`[{"name":"workqueue_depth","type":"GAUGE","series":1}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if fs, err := Load(text); err != nil || len(fs) != 2 {
+		t.Fatalf("text: %v %v", fs, err)
+	}
+	if fs, err := Load(js); err != nil || len(fs) != 1 || fs[0].Name != "workqueue_depth" {
+		t.Fatalf("json: %v %v", fs, err)
+	}
+}
@@ -0,0 +1,225 @@
+package artifacts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// IndexFileName is the manifest maintained next to the artifacts it lists.
+	IndexFileName = "artifacts-index.json"
+	// IndexSchemaVersion identifies the manifest format.
+	IndexSchemaVersion = "artifacts-index.v1"
+
+	// TypeSLISummary is the IndexEntry.Type used for summary.Summary files.
+	TypeSLISummary = "sli-summary"
+	// TypeFailureDump is the IndexEntry.Type used for cluster state dumped on spec failure.
+	TypeFailureDump = "failure-dump"
+	// TypeLog is the IndexEntry.Type used for captured container logs.
+	TypeLog = "log"
+	// TypeMetricFamilies is the IndexEntry.Type used for the list of exposed metric families.
+	TypeMetricFamilies = "metric-families"
+)
+
+const (
+	lockRetryInterval = 20 * time.Millisecond
+	lockTimeout       = 10 * time.Second
+	// staleLockAge: a lock older than this is assumed to be left behind by a crashed process.
+	staleLockAge = 2 * time.Minute
+)
+
+// Index is the content of artifacts-index.json.
+type Index struct {
+	SchemaVersion string       `json:"schemaVersion"`
+	Entries       []IndexEntry `json:"entries"`
+}
+
+// IndexEntry describes one artifact file.
+type IndexEntry struct {
+	// Path is relative to the index directory (slash-separated).
+	Path      string    `json:"path"`
+	Type      string    `json:"type"`
+	Spec      string    `json:"spec,omitempty"`
+	RunID     string    `json:"runId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ArtifactIndexWriter maintains artifacts-index.json in Dir.
+// Updates are read-modify-write under a lock file, so parallel Ginkgo processes
+// sharing one artifacts directory do not lose entries. Entries are keyed by Path
+// and kept sorted, so the file content is deterministic for a given set of artifacts.
+type ArtifactIndexWriter struct {
+	Dir string
+
+	opts Options
+	mu   sync.Mutex
+}
+
+// NewArtifactIndexWriter also removes temp files orphaned in dir by crashed runs (CleanupTemp).
+func NewArtifactIndexWriter(dir string, opts Options) *ArtifactIndexWriter {
+	if dir != "" {
+		_, _ = CleanupTemp(dir, staleTempAge)
+	}
+	opts.Gzip = false // the index is small and read-modify-written on every Add
+	return &ArtifactIndexWriter{Dir: dir, opts: opts.withDefaults()}
+}
+
+// Path returns the location of the index file.
+func (w *ArtifactIndexWriter) Path() string {
+	return filepath.Join(w.Dir, IndexFileName)
+}
+
+// Add records e in the index, replacing an existing entry with the same path.
+// Absolute paths under Dir are stored relative to it.
+func (w *ArtifactIndexWriter) Add(e IndexEntry) error {
+	if w == nil || w.Dir == "" {
+		return nil
+	}
+	if e.Path == "" {
+		return errors.New("artifacts index: entry path is empty")
+	}
+	if filepath.IsAbs(e.Path) {
+		if rel, err := filepath.Rel(w.Dir, e.Path); err == nil {
+			e.Path = rel
+		}
+	}
+	e.Path = filepath.ToSlash(e.Path)
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := os.MkdirAll(w.Dir, w.opts.DirMode); err != nil {
+		return err
+	}
+	unlock, err := acquireLock(w.Path() + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	idx, err := w.read()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range idx.Entries {
+		if idx.Entries[i].Path == e.Path {
+			idx.Entries[i] = e
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		idx.Entries = append(idx.Entries, e)
+	}
+	sort.Slice(idx.Entries, func(i, j int) bool { return idx.Entries[i].Path < idx.Entries[j].Path })
+
+	return writeJSONAtomic(w.Path(), idx, w.opts)
+}
+
+// Remove drops the entries of the given paths (relative to Dir), e.g. after their files were
+// pruned. A missing index is left missing.
+func (w *ArtifactIndexWriter) Remove(paths ...string) error {
+	if w == nil || w.Dir == "" || len(paths) == 0 {
+		return nil
+	}
+	drop := map[string]bool{}
+	for _, p := range paths {
+		drop[filepath.ToSlash(p)] = true
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := os.Stat(w.Path()); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	unlock, err := acquireLock(w.Path() + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	idx, err := w.read()
+	if err != nil {
+		return err
+	}
+	kept := idx.Entries[:0]
+	for _, e := range idx.Entries {
+		if !drop[e.Path] {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(idx.Entries) {
+		return nil
+	}
+	idx.Entries = kept
+	return writeJSONAtomic(w.Path(), idx, w.opts)
+}
+
+// Read returns the current index (empty when the file does not exist yet).
+func (w *ArtifactIndexWriter) Read() (Index, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.read()
+}
+
+func (w *ArtifactIndexWriter) read() (Index, error) {
+	idx := Index{SchemaVersion: IndexSchemaVersion}
+	b, err := os.ReadFile(w.Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return idx, err
+	}
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return idx, fmt.Errorf("artifacts index %s: %w", w.Path(), err)
+	}
+	if idx.SchemaVersion != IndexSchemaVersion {
+		return idx, fmt.Errorf("artifacts index %s: unsupported schemaVersion %q", w.Path(), idx.SchemaVersion)
+	}
+	return idx, nil
+}
+
+// LockFile takes the cross-process lock at path the index uses (a file created exclusively), for
+// other stores that read-modify-write files shared by parallel processes. Call the returned func
+// to release it.
+func LockFile(path string) (func(), error) {
+	return acquireLock(path)
+}
+
+// acquireLock creates path exclusively, retrying until lockTimeout.
+// Locks older than staleLockAge are removed and retried once they are seen.
+func acquireLock(path string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if fi, statErr := os.Stat(path); statErr == nil && time.Since(fi.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("artifacts: timed out waiting for lock %s", path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
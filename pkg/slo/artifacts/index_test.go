@@ -0,0 +1,58 @@
+package artifacts
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestArtifactIndexWriterConcurrentAdds(t *testing.T) {
+	dir := t.TempDir()
+	const n = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// separate writers simulate separate processes (only the lock file is shared)
+			w := NewArtifactIndexWriter(dir, DefaultOptions())
+			errs <- w.Add(IndexEntry{
+				Path:  filepath.Join(dir, fmt.Sprintf("sli-summary.%02d.json", i)),
+				Type:  TypeSLISummary,
+				RunID: "r1",
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	w := NewArtifactIndexWriter(dir, DefaultOptions())
+	// re-adding an existing path replaces the entry
+	if err := w.Add(IndexEntry{Path: "sli-summary.00.json", Type: TypeSLISummary, Spec: "again"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	idx, err := w.Read()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(idx.Entries) != n {
+		t.Fatalf("expected %d entries, got %d", n, len(idx.Entries))
+	}
+	if idx.Entries[0].Path != "sli-summary.00.json" || idx.Entries[0].Spec != "again" {
+		t.Fatalf("unexpected first entry: %+v", idx.Entries[0])
+	}
+	for i := 1; i < len(idx.Entries); i++ {
+		if idx.Entries[i-1].Path >= idx.Entries[i].Path {
+			t.Fatalf("entries not sorted: %q >= %q", idx.Entries[i-1].Path, idx.Entries[i].Path)
+		}
+	}
+}
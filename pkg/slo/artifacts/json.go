@@ -0,0 +1,167 @@
+package artifacts
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/common/atomicfile"
+	"github.com/yeongki/my-operator/pkg/slo/common/gzfile"
+)
+
+// Options configures how JSON artifacts are written.
+// Zero values fall back to the defaults noted per field.
+type Options struct {
+	// Compact writes single-line JSON instead of indented output.
+	Compact bool
+	// Sync fsyncs the temp file before the atomic rename.
+	Sync bool
+	// Durable also fsyncs the parent directory after the rename (implies Sync), so the new file
+	// survives a node crash right after the write, not only its content.
+	Durable bool
+	// FileMode of the final file (0 => 0o644).
+	FileMode os.FileMode
+	// DirMode used when creating parent directories (0 => 0o755).
+	DirMode os.FileMode
+	// Gzip compresses the written content. The path is used as given: callers name the file with
+	// Name. Loaders decompress by content (gzfile), so both forms load the same.
+	Gzip bool
+}
+
+// Name returns the file name to write path under: path + ".gz" when Gzip is set.
+func (o Options) Name(path string) string {
+	if o.Gzip {
+		return path + gzfile.Ext
+	}
+	return path
+}
+
+// DefaultOptions returns the settings used by the harness: pretty output, durable writes.
+func DefaultOptions() Options {
+	return Options{Sync: true, Durable: true, FileMode: 0o644, DirMode: 0o755}
+}
+
+func (o Options) withDefaults() Options {
+	if o.FileMode == 0 {
+		o.FileMode = 0o644
+	}
+	if o.DirMode == 0 {
+		o.DirMode = 0o755
+	}
+	return o
+}
+
+// JSONWriter writes any JSON-serializable value atomically (temp file + rename).
+// The first write to a directory removes temp files orphaned there by crashed runs (CleanupTemp).
+type JSONWriter struct {
+	opts Options
+
+	mu      sync.Mutex
+	cleaned map[string]bool
+}
+
+func NewJSONWriter(opts Options) *JSONWriter {
+	return &JSONWriter{opts: opts.withDefaults(), cleaned: map[string]bool{}}
+}
+
+// WriteJSON writes v to path. An empty path is a no-op (no output configured).
+func (w *JSONWriter) WriteJSON(path string, v any) error {
+	if path == "" {
+		return nil
+	}
+	w.mu.Lock()
+	if dir := filepath.Dir(path); !w.cleaned[dir] {
+		w.cleaned[dir] = true
+		_, _ = CleanupTemp(dir, staleTempAge)
+	}
+	w.mu.Unlock()
+	return slo.Wrap(slo.KindWrite, writeJSONAtomic(path, v, w.opts))
+}
+
+// WriteFile writes data to path atomically with opts (temp file + rename, Gzip and Durable
+// honoured), for non-JSON artifacts such as failure dumps. Name the path with opts.Name.
+func WriteFile(path string, data []byte, opts Options) error {
+	return slo.Wrap(slo.KindWrite, writeAtomic(path, opts.withDefaults(), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}))
+}
+
+// staleTempAge: a temp file older than this was left behind by a crashed writer (a write takes
+// milliseconds; younger ones may belong to a parallel process).
+const staleTempAge = 10 * time.Minute
+
+// CleanupTemp removes the temp files of atomic writes ("<name>.<random>.tmp") in dir that are at
+// least minAge old and returns how many were removed. A missing dir is not an error.
+func CleanupTemp(dir string, minAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || !isTempName(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < minAge {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// isTempName matches the os.CreateTemp(dir, base+".*.tmp") names of writeJSONAtomic.
+func isTempName(name string) bool {
+	rest, ok := strings.CutSuffix(name, ".tmp")
+	if !ok {
+		return false
+	}
+	i := strings.LastIndexByte(rest, '.')
+	if i <= 0 || i == len(rest)-1 {
+		return false
+	}
+	for _, r := range rest[i+1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// writeJSONAtomic writes v as JSON with writeAtomic.
+func writeJSONAtomic(path string, v any, opts Options) error {
+	return writeAtomic(path, opts, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		if !opts.Compact {
+			enc.SetIndent("", "  ")
+		}
+		return enc.Encode(v)
+	})
+}
+
+// writeAtomic writes the content produced by write with atomicfile.Write.
+func writeAtomic(path string, opts Options, write func(io.Writer) error) error {
+	return atomicfile.Write(path, atomicfile.Options{
+		FileMode: opts.FileMode,
+		DirMode:  opts.DirMode,
+		Gzip:     opts.Gzip,
+		Sync:     opts.Sync,
+		Durable:  opts.Durable,
+	}, write)
+}
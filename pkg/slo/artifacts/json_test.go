@@ -0,0 +1,68 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/common/gzfile"
+)
+
+func TestJSONWriterCleansOrphanedTemps(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	touch := func(name string, mod time.Time) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte("{"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	orphan := touch("sli-summary.v3.run.case.json.123456789.tmp", old)
+	fresh := touch("sli-summary.v3.run.case.json.987654321.tmp", time.Now()) // a parallel writer's
+	other := touch("notes.tmp", old)
+	named := touch("backup.json.v2.tmp", old)
+
+	w := NewJSONWriter(Options{Durable: true})
+	out := filepath.Join(dir, "out.json")
+	if err := w.WriteJSON(out, map[string]int{"a": 1}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if b, err := os.ReadFile(out); err != nil || string(b) != "{\n  \"a\": 1\n}\n" {
+		t.Fatalf("content %q, %v", b, err)
+	}
+
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Errorf("orphaned temp file kept: %v", err)
+	}
+	for _, p := range []string{fresh, other, named} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s should be kept: %v", filepath.Base(p), err)
+		}
+	}
+	if n, err := CleanupTemp(filepath.Join(dir, "missing"), 0); n != 0 || err != nil {
+		t.Errorf("missing dir: %d, %v", n, err)
+	}
+}
+
+func TestWriteFileGzip(t *testing.T) {
+	opts := Options{Gzip: true}
+	path := opts.Name(filepath.Join(t.TempDir(), "controller-logs.txt"))
+	if filepath.Ext(path) != ".gz" {
+		t.Fatalf("Name = %s", path)
+	}
+	if err := WriteFile(path, []byte("line 1\nline 2\n"), opts); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	raw, _ := os.ReadFile(path)
+	if !gzfile.IsGzip(raw) {
+		t.Fatalf("not compressed: %q", raw)
+	}
+	if b, err := gzfile.ReadFile(path); err != nil || string(b) != "line 1\nline 2\n" {
+		t.Errorf("ReadFile = %q, %v", b, err)
+	}
+}
@@ -0,0 +1,314 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// OTLP metric names and the instrumentation scope used by OTLPWriter.
+const (
+	OTLPMetricSLIValue  = "slo.sli.value"
+	OTLPMetricSLIStatus = "slo.sli.status"
+	OTLPSpanName        = "slo.session"
+
+	otlpScope = "github.com/yeongki/my-operator/pkg/slo"
+)
+
+// OTLPWriter writes the summary locally through Local (optional) and then exports it to an
+// OpenTelemetry collector over OTLP/HTTP (JSON encoding):
+//   - one span per session (window start/end) with run config, tags and results as attributes,
+//     status ERROR when an SLI failed its policy;
+//   - gauges slo.sli.value (per result with a value) and slo.sli.status (1, labeled by status).
+//
+// Export failures are logged, not returned, like uploads.
+type OTLPWriter struct {
+	Local summary.Writer
+	// Endpoint is the collector base URL, e.g. http://otel-collector:4318 (/v1/traces and
+	// /v1/metrics are appended).
+	Endpoint string
+	Header   http.Header
+	// ServiceName is the service.name resource attribute (default "slolab").
+	ServiceName string
+	Logger      slo.Logger
+
+	// Policy filters what is exported, as for uploads.
+	Policy summary.RedactPolicy
+
+	// Client may be nil (uses a client with a 60s timeout).
+	Client *http.Client
+	// Timeout for the whole export (0 => 60s).
+	Timeout time.Duration
+}
+
+// NewOTLPWriter wraps local with an exporter to endpoint.
+func NewOTLPWriter(local summary.Writer, endpoint string, l slo.Logger) *OTLPWriter {
+	return &OTLPWriter{Local: local, Endpoint: endpoint, Logger: l}
+}
+
+// NewOTLPWriterFromEnv configures an OTLPWriter from the standard OTEL_EXPORTER_OTLP_ENDPOINT,
+// OTEL_EXPORTER_OTLP_HEADERS ("k=v,k2=v2") and OTEL_SERVICE_NAME variables.
+// ok is false when no endpoint is configured.
+func NewOTLPWriterFromEnv(local summary.Writer, l slo.Logger) (w *OTLPWriter, ok bool) {
+	endpoint := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
+	if endpoint == "" {
+		return nil, false
+	}
+	w = NewOTLPWriter(local, endpoint, l)
+	w.ServiceName = os.Getenv("OTEL_SERVICE_NAME")
+	w.Header = ParseOTLPHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	return w, true
+}
+
+// ParseOTLPHeaders parses the OTEL_EXPORTER_OTLP_HEADERS format ("k=v,k2=v2", values may be
+// percent-encoded). Malformed entries are ignored.
+func ParseOTLPHeaders(v string) http.Header {
+	h := http.Header{}
+	for _, kv := range strings.Split(v, ",") {
+		k, val, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			continue
+		}
+		if u, err := url.PathUnescape(strings.TrimSpace(val)); err == nil {
+			val = u
+		}
+		h.Add(strings.TrimSpace(k), val)
+	}
+	return h
+}
+
+func (w *OTLPWriter) Write(p string, s summary.Summary) error {
+	if w.Local != nil {
+		if err := w.Local.Write(p, s); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(w.Endpoint) == "" {
+		return nil
+	}
+
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = defaultUploadTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	s = s.Redact(w.Policy)
+	logger := slo.NewLogger(w.Logger)
+	if err := w.post(ctx, "/v1/traces", otlpTraces(s, w.resource())); err != nil {
+		slo.Warnf(logger, "artifacts: otlp trace export failed: %v", err)
+	}
+	if err := w.post(ctx, "/v1/metrics", otlpMetrics(s, w.resource())); err != nil {
+		slo.Warnf(logger, "artifacts: otlp metric export failed: %v", err)
+	}
+	return nil
+}
+
+// Compile-time check
+var _ summary.Writer = (*OTLPWriter)(nil)
+
+func (w *OTLPWriter) resource() otlpResource {
+	name := w.ServiceName
+	if name == "" {
+		name = "slolab"
+	}
+	return otlpResource{Attributes: []otlpKV{strAttr("service.name", name)}}
+}
+
+func (w *OTLPWriter) post(ctx context.Context, path string, body any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimRight(w.Endpoint, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, vs := range w.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	return doUpload(w.Client, req)
+}
+
+// --- OTLP/JSON model (only the fields written here) ---
+
+type otlpKV struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKV `json:"attributes"`
+}
+
+type otlpScopeInfo struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"` // 1 = INTERNAL
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []otlpKV   `json:"attributes"`
+	Status            otlpStatus `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"` // 1 = OK, 2 = ERROR
+	Message string `json:"message,omitempty"`
+}
+
+type otlpDataPoint struct {
+	Attributes   []otlpKV `json:"attributes"`
+	TimeUnixNano string   `json:"timeUnixNano"`
+	AsDouble     *float64 `json:"asDouble,omitempty"`
+	AsInt        *string  `json:"asInt,omitempty"`
+}
+
+type otlpMetric struct {
+	Name  string `json:"name"`
+	Unit  string `json:"unit,omitempty"`
+	Gauge struct {
+		DataPoints []otlpDataPoint `json:"dataPoints"`
+	} `json:"gauge"`
+}
+
+func strAttr(k, v string) otlpKV {
+	return otlpKV{Key: k, Value: otlpAnyValue{StringValue: &v}}
+}
+
+func dblAttr(k string, v float64) otlpKV {
+	return otlpKV{Key: k, Value: otlpAnyValue{DoubleValue: &v}}
+}
+
+func unixNano(t time.Time) string { return strconv.FormatInt(t.UnixNano(), 10) }
+
+// tagAttrs returns the run tags as slo.tag.<k> attributes, sorted by key.
+func tagAttrs(tags map[string]string) []otlpKV {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]otlpKV, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, strAttr("slo.tag."+k, tags[k]))
+	}
+	return out
+}
+
+func otlpTraces(s summary.Summary, res otlpResource) any {
+	attrs := []otlpKV{
+		strAttr("slo.run_id", s.Config.RunID),
+		strAttr("slo.schema_version", s.SchemaVersion),
+		strAttr("slo.mode.location", s.Config.Mode.Location),
+		strAttr("slo.mode.trigger", s.Config.Mode.Trigger),
+	}
+	attrs = append(attrs, tagAttrs(s.Config.Tags)...)
+	for _, r := range s.Results {
+		attrs = append(attrs, strAttr("slo.sli."+r.ID+".status", string(r.Status)))
+		if r.Value != nil {
+			attrs = append(attrs, dblAttr("slo.sli."+r.ID+".value", *r.Value))
+		}
+	}
+
+	status := otlpStatus{Code: 1}
+	if s.HasPolicyFailures() {
+		var failed []string
+		for _, r := range s.Breaches() {
+			if r.Status == summary.StatusFail {
+				failed = append(failed, r.ID)
+			}
+		}
+		status = otlpStatus{Code: 2, Message: "slo policy failed: " + strings.Join(failed, ", ")}
+	}
+
+	span := otlpSpan{
+		TraceID:           randomHex(16),
+		SpanID:            randomHex(8),
+		Name:              OTLPSpanName,
+		Kind:              1,
+		StartTimeUnixNano: unixNano(s.Config.StartedAt),
+		EndTimeUnixNano:   unixNano(s.Config.FinishedAt),
+		Attributes:        attrs,
+		Status:            status,
+	}
+	return map[string]any{
+		"resourceSpans": []any{map[string]any{
+			"resource": res,
+			"scopeSpans": []any{map[string]any{
+				"scope": otlpScopeInfo{Name: otlpScope},
+				"spans": []otlpSpan{span},
+			}},
+		}},
+	}
+}
+
+func otlpMetrics(s summary.Summary, res otlpResource) any {
+	ts := unixNano(s.Config.FinishedAt)
+	one := "1"
+
+	value := otlpMetric{Name: OTLPMetricSLIValue}
+	status := otlpMetric{Name: OTLPMetricSLIStatus, Unit: "1"}
+	for _, r := range s.Results {
+		attrs := append([]otlpKV{strAttr("sli", r.ID)}, tagAttrs(s.Config.Tags)...)
+		if r.Value != nil {
+			v := *r.Value
+			pointAttrs := append(append([]otlpKV{}, attrs...), strAttr("unit", r.Unit))
+			value.Gauge.DataPoints = append(value.Gauge.DataPoints,
+				otlpDataPoint{Attributes: pointAttrs, TimeUnixNano: ts, AsDouble: &v})
+		}
+		statusAttrs := append(append([]otlpKV{}, attrs...), strAttr("status", string(r.Status)))
+		status.Gauge.DataPoints = append(status.Gauge.DataPoints,
+			otlpDataPoint{Attributes: statusAttrs, TimeUnixNano: ts, AsInt: &one})
+	}
+
+	metrics := []otlpMetric{status}
+	if len(value.Gauge.DataPoints) > 0 {
+		metrics = []otlpMetric{value, status}
+	}
+	return map[string]any{
+		"resourceMetrics": []any{map[string]any{
+			"resource": res,
+			"scopeMetrics": []any{map[string]any{
+				"scope":   otlpScopeInfo{Name: otlpScope},
+				"metrics": metrics,
+			}},
+		}},
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to the clock anyway
+		return fmt.Sprintf("%0*x", 2*n, time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
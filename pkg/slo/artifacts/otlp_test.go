@@ -0,0 +1,86 @@
+package artifacts
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+func TestOTLPWriterExportsSpanAndMetrics(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies[r.URL.Path] = string(b)
+		mu.Unlock()
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-Tenant") != "ci" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	v := 3.0
+	start := time.Unix(100, 0)
+	s := summary.Summary{
+		SchemaVersion: summary.CurrentSchemaVersion,
+		Config: summary.RunConfig{
+			RunID: "r1", StartedAt: start, FinishedAt: start.Add(time.Minute),
+			Tags: map[string]string{"suite": "e2e"},
+		},
+		Results: []summary.SLIResult{
+			{ID: "reconcile_total_delta", Value: &v, Unit: "count", Status: summary.StatusFail},
+			{ID: "workqueue_depth_end", Status: summary.StatusSkip},
+		},
+	}
+
+	w := NewOTLPWriter(nil, srv.URL+"/", nil)
+	w.Header = ParseOTLPHeaders("X-Tenant=ci")
+	if err := w.Write("unused.json", s); err != nil {
+		t.Fatal(err)
+	}
+
+	traces := bodies["/v1/traces"]
+	for _, want := range []string{
+		`"name":"slo.session"`,
+		`"startTimeUnixNano":"100000000000"`,
+		`"endTimeUnixNano":"160000000000"`,
+		`"key":"slo.tag.suite"`,
+		`"key":"slo.sli.reconcile_total_delta.value","value":{"doubleValue":3}`,
+		`"code":2,"message":"slo policy failed: reconcile_total_delta"`,
+		`"key":"service.name","value":{"stringValue":"slolab"}`,
+	} {
+		if !strings.Contains(traces, want) {
+			t.Fatalf("traces payload missing %s:\n%s", want, traces)
+		}
+	}
+
+	metrics := bodies["/v1/metrics"]
+	for _, want := range []string{
+		`"name":"slo.sli.value"`,
+		`"name":"slo.sli.status"`,
+		`"timeUnixNano":"160000000000","asDouble":3`,
+		`{"key":"status","value":{"stringValue":"skip"}}`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Fatalf("metrics payload missing %s:\n%s", want, metrics)
+		}
+	}
+}
+
+func TestOTLPWriterLogsExportErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	if err := NewOTLPWriter(nil, srv.URL, nil).Write("unused.json", summary.Summary{}); err != nil {
+		t.Fatalf("export errors must not fail the write: %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package artifacts
+
+import "github.com/yeongki/my-operator/pkg/slo/summary"
+
+// RedactingWriter applies a summary.RedactPolicy before delegating to Inner.
+// Use it for destinations that must not receive e.g. namespace names or test descriptions,
+// while other writers keep the full summary.
+type RedactingWriter struct {
+	Inner  summary.Writer
+	Policy summary.RedactPolicy
+}
+
+func (w RedactingWriter) Write(path string, s summary.Summary) error {
+	return w.Inner.Write(path, s.Redact(w.Policy))
+}
+
+// Compile-time check
+var _ summary.Writer = RedactingWriter{}
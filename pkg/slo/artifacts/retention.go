@@ -0,0 +1,117 @@
+package artifacts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RetentionKinds are the file name prefixes pruned by default: summaries (harness, agent) and
+// raw scrapes (CaptureScrapes). Each kind is counted separately.
+var RetentionKinds = []string{"sli-summary.", "metrics-scrape."}
+
+// PruneOptions selects the artifacts Prune removes. With neither MaxAge nor MaxFiles set,
+// nothing is removed.
+type PruneOptions struct {
+	// MaxAge removes files last modified longer ago than this (0 => no age limit).
+	MaxAge time.Duration
+	// MaxFiles keeps only the newest MaxFiles files of each kind (0 => no count limit).
+	MaxFiles int
+	// Kinds are the file name prefixes considered (nil => RetentionKinds). Other files, the index
+	// and temp files are never touched.
+	Kinds []string
+	// MinAge: younger files are always kept, they may belong to a run in progress (0 => 10m).
+	MinAge time.Duration
+	// DryRun lists candidates without removing them.
+	DryRun bool
+
+	// Now is used for age calculation (nil => time.Now).
+	Now func() time.Time
+}
+
+// PruneResult reports what Prune did. Removed holds file names relative to the directory.
+type PruneResult struct {
+	Removed []string
+	Kept    int
+}
+
+// Prune removes old artifacts from dir (not recursively) by age and count, and drops their
+// entries from artifacts-index.json when there is one. It is meant to run before a run on
+// long-lived machines where the artifacts directory (/tmp by default) only grows.
+// A missing dir is not an error.
+func Prune(dir string, opts PruneOptions) (PruneResult, error) {
+	var res PruneResult
+	if dir == "" || (opts.MaxAge <= 0 && opts.MaxFiles <= 0) {
+		return res, nil
+	}
+	if opts.MinAge == 0 {
+		opts.MinAge = staleTempAge
+	}
+	kinds := opts.Kinds
+	if kinds == nil {
+		kinds = RetentionKinds
+	}
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return res, nil
+	}
+	if err != nil {
+		return res, err
+	}
+
+	type file struct {
+		name string
+		mod  time.Time
+	}
+	byKind := make([][]file, len(kinds))
+	for _, e := range entries {
+		if !e.Type().IsRegular() || isTempName(e.Name()) || e.Name() == IndexFileName {
+			continue
+		}
+		k := slices.IndexFunc(kinds, func(p string) bool { return strings.HasPrefix(e.Name(), p) })
+		if k < 0 {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		byKind[k] = append(byKind[k], file{name: e.Name(), mod: info.ModTime()})
+	}
+
+	var errs []error
+	for _, files := range byKind {
+		// newest first, so the count limit keeps the latest runs
+		slices.SortFunc(files, func(a, b file) int { return b.mod.Compare(a.mod) })
+		for i, f := range files {
+			age := now.Sub(f.mod)
+			expired := (opts.MaxAge > 0 && age > opts.MaxAge) || (opts.MaxFiles > 0 && i >= opts.MaxFiles)
+			if !expired || age < opts.MinAge {
+				res.Kept++
+				continue
+			}
+			if !opts.DryRun {
+				err := os.Remove(filepath.Join(dir, f.name))
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					errs = append(errs, err)
+					continue
+				}
+			}
+			res.Removed = append(res.Removed, f.name)
+		}
+	}
+	slices.Sort(res.Removed)
+
+	if !opts.DryRun && len(res.Removed) > 0 {
+		errs = append(errs, NewArtifactIndexWriter(dir, DefaultOptions()).Remove(res.Removed...))
+	}
+	return res, errors.Join(errs...)
+}
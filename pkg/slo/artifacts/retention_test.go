@@ -0,0 +1,73 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	touch := func(name string, age time.Duration) {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mod := now.Add(-age)
+		if err := os.Chtimes(p, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch("sli-summary.v3.r1.a.json", 30*24*time.Hour)
+	touch("sli-summary.v3.r2.a.json", 3*time.Hour)
+	touch("sli-summary.v3.r3.a.json", 2*time.Hour)
+	touch("sli-summary.v3.r4.a.json", time.Minute) // a run in progress
+	touch("metrics-scrape.r1.a.01-start.prom.gz", 30*24*time.Hour)
+	touch("metrics-scrape.r3.a.01-start.prom.gz", 2*time.Hour)
+	touch("notes.json", 30*24*time.Hour)
+
+	idx := NewArtifactIndexWriter(dir, Options{})
+	for _, p := range []string{"sli-summary.v3.r1.a.json", "sli-summary.v3.r4.a.json"} {
+		if err := idx.Add(IndexEntry{Path: p, Type: TypeSLISummary}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := PruneOptions{MaxAge: 7 * 24 * time.Hour, MaxFiles: 1, DryRun: true}
+	res, err := Prune(dir, opts)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	want := []string{
+		"metrics-scrape.r1.a.01-start.prom.gz",
+		"sli-summary.v3.r1.a.json",
+		"sli-summary.v3.r2.a.json",
+		"sli-summary.v3.r3.a.json",
+	}
+	if !slices.Equal(res.Removed, want) || res.Kept != 2 {
+		t.Fatalf("removed %v, kept %d; want %v, kept 2", res.Removed, res.Kept, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, want[0])); err != nil {
+		t.Errorf("dry run removed a file: %v", err)
+	}
+
+	opts.DryRun = false
+	if _, err := Prune(dir, opts); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	left, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(left) != 4 { // r4 summary, r3 scrape, notes.json, the index
+		t.Errorf("left %v", left)
+	}
+	got, err := idx.Read()
+	if err != nil || len(got.Entries) != 1 || got.Entries[0].Path != "sli-summary.v3.r4.a.json" {
+		t.Errorf("index = %+v, %v", got.Entries, err)
+	}
+
+	if res, err := Prune(filepath.Join(dir, "missing"), opts); err != nil || len(res.Removed) != 0 {
+		t.Errorf("missing dir: %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,89 @@
+package artifacts
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// ResultMarker starts every line written by ResultLineWriter, so results can be picked out of a
+// CI log with e.g. `grep '^SLOLAB_RESULT '`. It is part of the contract: do not change it.
+const ResultMarker = "SLOLAB_RESULT"
+
+// ResultLineWriter writes the summary locally through Local (optional) and then prints it to Out
+// as one line, "SLOLAB_RESULT " followed by compact JSON, for CI systems (or remote runners) that
+// cannot collect artifact files but keep the job log. The line is printed also without a path.
+// Summaries leave the machine this way, so Policy applies as for uploads.
+type ResultLineWriter struct {
+	Local summary.Writer
+	// Out receives the lines (nil => os.Stdout).
+	Out    io.Writer
+	Policy summary.RedactPolicy
+
+	mu sync.Mutex
+}
+
+// NewResultLineWriter wraps local with a result line on stdout.
+func NewResultLineWriter(local summary.Writer) *ResultLineWriter {
+	return &ResultLineWriter{Local: local}
+}
+
+func (w *ResultLineWriter) Write(p string, s summary.Summary) error {
+	if w.Local != nil {
+		if err := w.Local.Write(p, s); err != nil {
+			return err
+		}
+	}
+	b, err := json.Marshal(s.Redact(w.Policy))
+	if err != nil {
+		return fmt.Errorf("result line: %w", err)
+	}
+	out := w.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	// one Write per line, so lines of parallel sessions never interleave
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = fmt.Fprintf(out, "%s %s\n", ResultMarker, b)
+	return err
+}
+
+// ParseResultLine decodes a line written by ResultLineWriter; ok is false for any other line.
+// Text before the marker (log prefixes, timestamps) is ignored.
+func ParseResultLine(line string) (s summary.Summary, ok bool, err error) {
+	i := strings.Index(line, ResultMarker+" {")
+	if i < 0 {
+		return s, false, nil
+	}
+	if err := json.Unmarshal([]byte(line[i+len(ResultMarker)+1:]), &s); err != nil {
+		return s, true, fmt.Errorf("result line: %w", err)
+	}
+	return s, true, nil
+}
+
+// ReadResultLines returns the summaries of every result line in a log.
+func ReadResultLines(r io.Reader) ([]summary.Summary, error) {
+	var out []summary.Summary
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		s, ok, err := ParseResultLine(sc.Text())
+		if err != nil {
+			return out, err
+		}
+		if ok {
+			out = append(out, s)
+		}
+	}
+	return out, sc.Err()
+}
+
+// Compile-time check
+var _ summary.Writer = (*ResultLineWriter)(nil)
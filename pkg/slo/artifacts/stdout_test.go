@@ -0,0 +1,65 @@
+package artifacts
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+type recordingWriter struct{ paths []string }
+
+func (w *recordingWriter) Write(p string, _ summary.Summary) error {
+	w.paths = append(w.paths, p)
+	return nil
+}
+
+func TestResultLineWriterRoundTrip(t *testing.T) {
+	v := 2.0
+	s := summary.Summary{
+		SchemaVersion: summary.CurrentSchemaVersion,
+		Config: summary.RunConfig{
+			RunID: "r1",
+			Tags:  map[string]string{"suite": "e2e", "cluster": "prod-eu"},
+		},
+		Results:  []summary.SLIResult{{ID: "reconcile_total_delta", Value: &v, Status: summary.StatusPass}},
+		Warnings: []string{"scrape retried"},
+	}
+
+	local := &recordingWriter{}
+	var out bytes.Buffer
+	w := NewResultLineWriter(local)
+	w.Out = &out
+	w.Policy = summary.RedactPolicy{KeepTags: []string{"suite"}, DropWarnings: true}
+	if err := w.Write("", s); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(local.paths) != 1 {
+		t.Errorf("local writer called %d times", len(local.paths))
+	}
+	line := out.String()
+	if !strings.HasPrefix(line, ResultMarker+" {") || strings.Count(line, "\n") != 1 {
+		t.Fatalf("not a single result line: %q", line)
+	}
+
+	log := "INFO step 1\n2026-01-02T03:04:05Z " + line + "done\n"
+	got, err := ReadResultLines(strings.NewReader(log))
+	if err != nil || len(got) != 1 {
+		t.Fatalf("ReadResultLines = %d, %v", len(got), err)
+	}
+	g := got[0]
+	if g.Config.RunID != "r1" || len(g.Results) != 1 || *g.Results[0].Value != 2 {
+		t.Errorf("round trip = %+v", g)
+	}
+	if _, ok := g.Config.Tags["cluster"]; ok || len(g.Warnings) != 0 {
+		t.Errorf("policy not applied: tags %v, warnings %v", g.Config.Tags, g.Warnings)
+	}
+
+	if _, ok, _ := ParseResultLine("SLOLAB_RESULT is the marker"); ok {
+		t.Errorf("prose mentioning the marker parsed as a result line")
+	}
+	if _, ok, err := ParseResultLine(ResultMarker + " {broken"); !ok || err == nil {
+		t.Errorf("broken line: ok=%v err=%v", ok, err)
+	}
+}
@@ -0,0 +1,71 @@
+package artifacts
+
+import (
+	"fmt"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// Artifact is a typed payload that checks itself before it is written (summary.Summary,
+// history.SessionResult), unlike WriteJSON's any.
+type Artifact interface {
+	Validate() error
+}
+
+// WriteArtifact validates a and writes it to path like WriteJSON. An invalid payload is not
+// written; its error is classified slo.KindWrite.
+func (w *JSONWriter) WriteArtifact(path string, a Artifact) error {
+	if path == "" {
+		return nil
+	}
+	if err := a.Validate(); err != nil {
+		return slo.Wrap(slo.KindWrite, fmt.Errorf("artifacts: not writing %s: %w", path, err))
+	}
+	return w.WriteJSON(path, a)
+}
+
+// WriteSummary writes s as an SLI summary artifact: an unset schemaVersion is set to
+// summary.CurrentSchemaVersion and the summary must pass summary.Validate.
+func (w *JSONWriter) WriteSummary(path string, s summary.Summary) error {
+	if s.SchemaVersion == "" {
+		s.SchemaVersion = summary.CurrentSchemaVersion
+	}
+	return w.WriteArtifact(path, s)
+}
+
+// SummaryWriter adapts JSONWriter to summary.Writer.
+// When Index is set, every written summary is also recorded in the artifact index.
+type SummaryWriter struct {
+	JSON  *JSONWriter
+	Index *ArtifactIndexWriter
+}
+
+// NewSummaryWriter returns a summary.Writer backed by a JSONWriter with opts.
+func NewSummaryWriter(opts Options) *SummaryWriter {
+	return &SummaryWriter{JSON: NewJSONWriter(opts)}
+}
+
+// NewIndexedSummaryWriter is NewSummaryWriter plus an artifacts-index.json in dir.
+func NewIndexedSummaryWriter(dir string, opts Options) *SummaryWriter {
+	return &SummaryWriter{JSON: NewJSONWriter(opts), Index: NewArtifactIndexWriter(dir, opts)}
+}
+
+func (w *SummaryWriter) Write(path string, s summary.Summary) error {
+	if err := w.JSON.WriteSummary(path, s); err != nil {
+		return err
+	}
+	if w.Index == nil || path == "" {
+		return nil
+	}
+	return w.Index.Add(IndexEntry{
+		Path:      path,
+		Type:      TypeSLISummary,
+		Spec:      s.Config.Tags["test_case"],
+		RunID:     s.Config.RunID,
+		CreatedAt: s.GeneratedAt,
+	})
+}
+
+// Compile-time check
+var _ summary.Writer = (*SummaryWriter)(nil)
@@ -0,0 +1,42 @@
+package artifacts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+func TestWriteSummary(t *testing.T) {
+	dir := t.TempDir()
+	w := NewJSONWriter(Options{})
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	s := summary.Summary{
+		GeneratedAt: at,
+		Config:      summary.RunConfig{RunID: "r1", StartedAt: at.Add(-time.Minute), FinishedAt: at},
+		Results:     []summary.SLIResult{{ID: "reconcile_total", Status: summary.StatusPass}},
+	}
+
+	ok := filepath.Join(dir, "ok.json")
+	if err := w.WriteSummary(ok, s); err != nil {
+		t.Fatalf("WriteSummary: %v", err)
+	}
+	got, err := summary.Load(ok)
+	if err != nil || got.SchemaVersion != summary.CurrentSchemaVersion {
+		t.Fatalf("written summary does not load: %+v, %v", got, err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	s.Results = append(s.Results, summary.SLIResult{ID: "reconcile_total", Status: "green"})
+	err = w.WriteSummary(bad, s)
+	if err == nil || !errors.Is(err, slo.ErrWrite) {
+		t.Fatalf("invalid summary: err = %v, want a write error", err)
+	}
+	if _, err := os.Stat(bad); !os.IsNotExist(err) {
+		t.Errorf("invalid summary was written: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// defaultUploadTimeout bounds a single upload when the caller has no context (summary.Writer).
+const defaultUploadTimeout = 60 * time.Second
+
+// Uploader pushes one object to remote storage.
+// key is a slash-separated object name relative to the uploader's base location.
+type Uploader interface {
+	Upload(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+// NewUploader returns an Uploader for rawURL:
+//   - s3://bucket/prefix   (AWS SigV4, credentials from the AWS env chain)
+//   - gs://bucket/prefix   (GCS XML API, credentials from the Google env chain)
+//   - https://host/prefix  (plain PUT, optional basic auth from userinfo or SLOLAB_UPLOAD_TOKEN bearer)
+func NewUploader(rawURL string) (Uploader, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("upload url: %w", err)
+	}
+	prefix := strings.Trim(u.Path, "/")
+
+	switch u.Scheme {
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("upload url %q: missing bucket", rawURL)
+		}
+		return NewS3UploaderFromEnv(u.Host, prefix)
+	case "gs":
+		if u.Host == "" {
+			return nil, fmt.Errorf("upload url %q: missing bucket", rawURL)
+		}
+		return NewGCSUploaderFromEnv(u.Host, prefix), nil
+	case "http", "https":
+		return NewHTTPUploader(u.String()), nil
+	default:
+		return nil, fmt.Errorf("upload url %q: unsupported scheme %q (want s3, gs, http or https)", rawURL, u.Scheme)
+	}
+}
+
+// HTTPUploader PUTs objects to BaseURL + "/" + key.
+type HTTPUploader struct {
+	BaseURL string
+	Header  http.Header
+
+	// Client may be nil (uses a client with a 60s timeout).
+	Client *http.Client
+}
+
+// NewHTTPUploader returns an HTTPUploader for baseURL.
+// Userinfo in baseURL becomes basic auth; otherwise SLOLAB_UPLOAD_TOKEN (if set) is sent as a bearer token.
+func NewHTTPUploader(baseURL string) *HTTPUploader {
+	h := http.Header{}
+	if u, err := url.Parse(baseURL); err == nil && u.User != nil {
+		req := &http.Request{Header: http.Header{}}
+		pw, _ := u.User.Password()
+		req.SetBasicAuth(u.User.Username(), pw)
+		h.Set("Authorization", req.Header.Get("Authorization"))
+		u.User = nil
+		baseURL = u.String()
+	} else if tok := strings.TrimSpace(os.Getenv("SLOLAB_UPLOAD_TOKEN")); tok != "" {
+		h.Set("Authorization", "Bearer "+tok)
+	}
+	return &HTTPUploader{BaseURL: baseURL, Header: h}
+}
+
+func (u *HTTPUploader) Upload(ctx context.Context, key string, body []byte, contentType string) error {
+	target := strings.TrimRight(u.BaseURL, "/") + "/" + escapeKey(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, vs := range u.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return doUpload(u.Client, req)
+}
+
+// UploadFile uploads the local file at localPath under key (base name of localPath when key is empty).
+func UploadFile(ctx context.Context, up Uploader, localPath, key string) error {
+	b, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		key = filepath.Base(localPath)
+	}
+	return up.Upload(ctx, key, b, contentTypeFor(localPath))
+}
+
+// UploadSummaryWriter writes the summary locally through Local (optional) and then uploads
+// the same JSON under the base name of path.
+// Upload failures are logged, not returned: the local artifact is already in place and
+// measurement/reporting problems must not turn into test failures.
+type UploadSummaryWriter struct {
+	Local    summary.Writer
+	Uploader Uploader
+	Logger   slo.Logger
+
+	// Policy filters what is uploaded; the local copy always keeps everything.
+	Policy summary.RedactPolicy
+
+	// Timeout per upload (0 => 60s).
+	Timeout time.Duration
+}
+
+// NewUploadSummaryWriter wraps local with an uploader for rawURL.
+func NewUploadSummaryWriter(local summary.Writer, rawURL string, l slo.Logger) (*UploadSummaryWriter, error) {
+	up, err := NewUploader(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &UploadSummaryWriter{Local: local, Uploader: up, Logger: l}, nil
+}
+
+func (w *UploadSummaryWriter) Write(p string, s summary.Summary) error {
+	if w.Local != nil {
+		if err := w.Local.Write(p, s); err != nil {
+			return err
+		}
+	}
+	if p == "" || w.Uploader == nil {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(s.Redact(w.Policy), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = defaultUploadTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	key := filepath.Base(p)
+	if err := w.Uploader.Upload(ctx, key, append(b, '\n'), "application/json"); err != nil {
+		slo.Warnf(w.Logger, "artifacts: upload %s failed (local copy kept at %s): %v", key, p, err)
+	}
+	return nil
+}
+
+// Compile-time check
+var _ summary.Writer = (*UploadSummaryWriter)(nil)
+
+func doUpload(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = &http.Client{Timeout: defaultUploadTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("upload %s: unexpected status %d: %s", req.URL.Redacted(), resp.StatusCode, body)
+	}
+	return nil
+}
+
+// joinKey joins an optional prefix and key with "/".
+func joinKey(prefix, key string) string {
+	key = strings.TrimLeft(key, "/")
+	if prefix == "" {
+		return key
+	}
+	return path.Join(prefix, key)
+}
+
+// escapeKey percent-encodes every path segment of key (RFC 3986 unreserved characters are kept).
+func escapeKey(key string) string {
+	segs := strings.Split(key, "/")
+	for i, s := range segs {
+		segs[i] = uriEncode(s)
+	}
+	return strings.Join(segs, "/")
+}
+
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func contentTypeFor(p string) string {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".json":
+		return "application/json"
+	case ".txt", ".log":
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
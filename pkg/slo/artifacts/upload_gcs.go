@@ -0,0 +1,227 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcsDefaultEndpoint = "https://storage.googleapis.com"
+	gcsScope           = "https://www.googleapis.com/auth/devstorage.read_write"
+	gceMetadataToken   = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// GCSUploader PUTs objects through the GCS XML API with an OAuth2 bearer token.
+type GCSUploader struct {
+	Bucket string
+	Prefix string
+
+	// Endpoint overrides https://storage.googleapis.com (e.g. a fake-gcs-server).
+	Endpoint string
+	// Token returns an access token; nil means uploads are unauthenticated (emulators).
+	Token func(ctx context.Context) (string, error)
+
+	// Client may be nil (uses a client with a 60s timeout).
+	Client *http.Client
+}
+
+// NewGCSUploaderFromEnv uses the Google credential chain:
+// GOOGLE_OAUTH_ACCESS_TOKEN, then the service account key in GOOGLE_APPLICATION_CREDENTIALS,
+// then the GCE/GKE metadata server. STORAGE_EMULATOR_HOST switches to an unauthenticated emulator.
+func NewGCSUploaderFromEnv(bucket, prefix string) *GCSUploader {
+	u := &GCSUploader{Bucket: bucket, Prefix: prefix, Endpoint: gcsDefaultEndpoint}
+	if emu := strings.TrimSpace(os.Getenv("STORAGE_EMULATOR_HOST")); emu != "" {
+		if !strings.Contains(emu, "://") {
+			emu = "http://" + emu
+		}
+		u.Endpoint = emu
+		return u
+	}
+	u.Token = (&googleTokenSource{}).token
+	return u
+}
+
+func (u *GCSUploader) Upload(ctx context.Context, key string, body []byte, contentType string) error {
+	endpoint := u.Endpoint
+	if endpoint == "" {
+		endpoint = gcsDefaultEndpoint
+	}
+	target := strings.TrimRight(endpoint, "/") + "/" + u.Bucket + "/" + escapeKey(joinKey(u.Prefix, key))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if u.Token != nil {
+		tok, err := u.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("gcs: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+	return doUpload(u.Client, req)
+}
+
+// googleTokenSource resolves and caches an access token from the Google env chain.
+type googleTokenSource struct {
+	mu      sync.Mutex
+	tok     string
+	expires time.Time
+}
+
+func (s *googleTokenSource) token(ctx context.Context) (string, error) {
+	if tok := strings.TrimSpace(os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")); tok != "" {
+		return tok, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok != "" && time.Until(s.expires) > time.Minute {
+		return s.tok, nil
+	}
+
+	var (
+		tok string
+		ttl time.Duration
+		err error
+	)
+	if path := strings.TrimSpace(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")); path != "" {
+		tok, ttl, err = serviceAccountToken(ctx, path)
+	} else {
+		tok, ttl, err = metadataToken(ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+	s.tok, s.expires = tok, time.Now().Add(ttl)
+	return tok, nil
+}
+
+type oauthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+func metadataToken(ctx context.Context) (string, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gceMetadataToken, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	return requestToken(req, "metadata server")
+}
+
+// serviceAccountToken exchanges a signed JWT for an access token (OAuth2 JWT bearer grant).
+func serviceAccountToken(ctx context.Context, keyFile string) (string, time.Duration, error) {
+	b, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", 0, err
+	}
+	var key struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(b, &key); err != nil {
+		return "", 0, fmt.Errorf("%s: %w", keyFile, err)
+	}
+	if key.Type != "service_account" {
+		return "", 0, fmt.Errorf("%s: unsupported credentials type %q (want service_account)", keyFile, key.Type)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	pk, err := parseRSAKey(key.PrivateKey)
+	if err != nil {
+		return "", 0, fmt.Errorf("%s: %w", keyFile, err)
+	}
+
+	now := time.Now()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iss":   key.ClientEmail,
+		"scope": gcsScope,
+		"aud":   key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(nil, pk, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", 0, err
+	}
+	assertion := unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, key.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return requestToken(req, key.TokenURI)
+}
+
+func requestToken(req *http.Request, source string) (string, time.Duration, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("token from %s: %w", source, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("token from %s: unexpected status %d: %.512s", source, resp.StatusCode, body)
+	}
+	var tr oauthTokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return "", 0, fmt.Errorf("token from %s: %w", source, err)
+	}
+	if tr.AccessToken == "" {
+		return "", 0, fmt.Errorf("token from %s: empty access_token", source)
+	}
+	return tr.AccessToken, time.Duration(tr.ExpiresIn) * time.Second, nil
+}
+
+func parseRSAKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("private_key is not PEM encoded")
+	}
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rk, ok := k.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("private_key is not an RSA key")
+		}
+		return rk, nil
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
@@ -0,0 +1,259 @@
+package artifacts
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSCredentials are static (or session) credentials used for SigV4 signing.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3Uploader PUTs objects to an S3 (or S3-compatible) bucket using SigV4.
+type S3Uploader struct {
+	Bucket string
+	Prefix string
+	Region string
+	Creds  AWSCredentials
+
+	// Endpoint overrides the AWS endpoint (e.g. MinIO). When set, or when Bucket contains a dot,
+	// path-style URLs are used.
+	Endpoint string
+
+	// Client may be nil (uses a client with a 60s timeout).
+	Client *http.Client
+	// Now may be nil (time.Now).
+	Now func() time.Time
+}
+
+// NewS3UploaderFromEnv resolves region, endpoint and credentials the way the AWS CLI does for the
+// common cases: AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY/AWS_SESSION_TOKEN first, then the
+// shared credentials file (AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials, profile AWS_PROFILE).
+// Region comes from AWS_REGION or AWS_DEFAULT_REGION (default us-east-1), the endpoint from
+// AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL.
+func NewS3UploaderFromEnv(bucket, prefix string) (*S3Uploader, error) {
+	creds, err := awsCredentialsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	region := firstEnv("AWS_REGION", "AWS_DEFAULT_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3Uploader{
+		Bucket:   bucket,
+		Prefix:   prefix,
+		Region:   region,
+		Creds:    creds,
+		Endpoint: firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"),
+	}, nil
+}
+
+func (u *S3Uploader) Upload(ctx context.Context, key string, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	now := time.Now
+	if u.Now != nil {
+		now = u.Now
+	}
+	sum := sha256.Sum256(body)
+	signV4(req, hex.EncodeToString(sum[:]), u.Creds, u.Region, "s3", now())
+
+	return doUpload(u.Client, req)
+}
+
+// objectURL is the PUT target for key. Virtual-hosted URLs are used against AWS except for bucket
+// names containing dots, which do not match the *.s3.<region>.amazonaws.com certificate; those,
+// like buckets behind a custom Endpoint, are addressed path-style.
+func (u *S3Uploader) objectURL(key string) string {
+	objectKey := escapeKey(joinKey(u.Prefix, key))
+	switch {
+	case u.Endpoint != "":
+		return strings.TrimRight(u.Endpoint, "/") + "/" + u.Bucket + "/" + objectKey
+	case strings.Contains(u.Bucket, "."):
+		return fmt.Sprintf("https://s3.%s.amazonaws.com/%s/%s", u.Region, u.Bucket, objectKey)
+	default:
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", u.Bucket, u.Region, objectKey)
+	}
+}
+
+// signV4 adds AWS Signature Version 4 headers to req. All headers already set on req are signed.
+func signV4(req *http.Request, payloadHash string, creds AWSCredentials, region, service string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	day := t.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, vs := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(vs, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonURI := req.URL.EscapedPath()
+	if canonURI == "" {
+		canonURI = "/"
+	}
+	canonRequest := strings.Join([]string{
+		req.Method,
+		canonURI,
+		canonicalQuery(req),
+		canonHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	crHash := sha256.Sum256([]byte(canonRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, sig,
+	))
+}
+
+func canonicalQuery(req *http.Request) string {
+	q := req.URL.Query()
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), q[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
+
+func awsCredentialsFromEnv() (AWSCredentials, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return AWSCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	file := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return AWSCredentials{}, errors.New("s3: no AWS credentials in environment")
+		}
+		file = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	creds, err := readSharedCredentials(file, profile)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("s3: no AWS credentials in environment: %w", err)
+	}
+	return creds, nil
+}
+
+// readSharedCredentials reads one profile from an AWS INI credentials file.
+func readSharedCredentials(file, profile string) (AWSCredentials, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var creds AWSCredentials
+	in := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			in = strings.TrimSpace(line[1:len(line)-1]) == profile
+			continue
+		}
+		if !in {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(k) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(v)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(v)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(v)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return AWSCredentials{}, err
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return AWSCredentials{}, fmt.Errorf("profile %q not found in %s", profile, file)
+	}
+	return creds, nil
+}
+
+func firstEnv(keys ...string) string {
+	for _, k := range keys {
+		if v := strings.TrimSpace(os.Getenv(k)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
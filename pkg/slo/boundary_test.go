@@ -0,0 +1,46 @@
+package slo_test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// modulePath is the import path of this module; everything else must be stdlib.
+const modulePath = "github.com/yeongki/my-operator/pkg/slo"
+
+// TestDependencyBoundary keeps the core measurement library free of third-party
+// dependencies (ginkgo, gomega, k8s.io, controller-runtime, ...).
+// Integrations that need them belong in their own module or in the glue layer.
+func TestDependencyBoundary(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not found in PATH")
+	}
+
+	cmd := exec.Command(goBin, "list", "-deps", "-test",
+		"-f", "{{if not .Standard}}{{.ImportPath}}{{end}}", "./...")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go list failed: %v\n%s", err, out)
+	}
+
+	var violations []string
+	for _, line := range strings.Split(string(out), "\n") {
+		pkg := strings.TrimSpace(line)
+		if pkg == "" {
+			continue
+		}
+		// test variants look like "pkg [pkg.test]", "pkg_test" or "pkg.test"
+		pkg = strings.TrimSuffix(strings.Fields(pkg)[0], ".test")
+		pkg = strings.TrimSuffix(pkg, "_test")
+		if pkg == modulePath || strings.HasPrefix(pkg, modulePath+"/") {
+			continue
+		}
+		violations = append(violations, pkg)
+	}
+	if len(violations) > 0 {
+		t.Fatalf("pkg/slo must only depend on the standard library, found:\n  %s",
+			strings.Join(violations, "\n  "))
+	}
+}
@@ -0,0 +1,97 @@
+// Package buildinfo resolves the provenance stamped into summaries (summary.Build): the git commit
+// and dirty flag of the measured code, who built it, and the operator image.
+//
+// Each field comes from the first source that knows it:
+//  1. SLOLAB_GIT_COMMIT, SLOLAB_GIT_DIRTY, SLOLAB_BUILDER, SLOLAB_OPERATOR_IMAGE and
+//     SLOLAB_OPERATOR_IMAGE_DIGEST (e.g. exported by the Makefile that built the image);
+//  2. the CI system's commit (GITHUB_SHA, CI_COMMIT_SHA, GIT_COMMIT) and name;
+//  3. the variables below, set with -ldflags "-X github.com/yeongki/my-operator/pkg/slo/buildinfo.Commit=...";
+//  4. the VCS stamp of the Go build (vcs.revision / vcs.modified; not set for go test binaries).
+package buildinfo
+
+import (
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// Set by -ldflags -X at build time (optional). Dirty is "true" or "false".
+var (
+	Commit  string
+	Dirty   string
+	Builder string
+)
+
+// Environment variables read by Resolve.
+const (
+	CommitEnv      = "SLOLAB_GIT_COMMIT"
+	DirtyEnv       = "SLOLAB_GIT_DIRTY"
+	BuilderEnv     = "SLOLAB_BUILDER"
+	ImageEnv       = "SLOLAB_OPERATOR_IMAGE"
+	ImageDigestEnv = "SLOLAB_OPERATOR_IMAGE_DIGEST"
+)
+
+// Resolve returns the build provenance from getenv (nil => os.Getenv), the ldflags variables and
+// the Go build info. Builder falls back to "local"; the image is left to the caller when unset
+// (the harness reads it from the operator pod).
+func Resolve(getenv func(string) string) summary.Build {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	vcsRevision, vcsModified := vcsStamp()
+
+	b := summary.Build{
+		Commit: first(getenv(CommitEnv), getenv("GITHUB_SHA"), getenv("CI_COMMIT_SHA"), getenv("GIT_COMMIT"),
+			Commit, vcsRevision),
+		Builder:     first(getenv(BuilderEnv), ciName(getenv), Builder, "local"),
+		Image:       strings.TrimSpace(getenv(ImageEnv)),
+		ImageDigest: strings.TrimSpace(getenv(ImageDigestEnv)),
+	}
+	dirty := first(getenv(DirtyEnv), Dirty, vcsModified)
+	b.Dirty, _ = strconv.ParseBool(dirty)
+	return b
+}
+
+// ciName names the CI system running this process ("" => none).
+func ciName(getenv func(string) string) string {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		return "github-actions"
+	case getenv("GITLAB_CI") == "true":
+		return "gitlab-ci"
+	case getenv("JENKINS_URL") != "":
+		return "jenkins"
+	case getenv("CI") == "true":
+		return "ci"
+	}
+	return ""
+}
+
+// vcsStamp returns the revision and modified flag the go command stamped into the binary.
+func vcsStamp() (revision, modified string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", ""
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	return revision, modified
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
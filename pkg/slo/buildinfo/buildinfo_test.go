@@ -0,0 +1,26 @@
+package buildinfo
+
+import (
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+func TestResolve(t *testing.T) {
+	env := func(m map[string]string) func(string) string {
+		return func(k string) string { return m[k] }
+	}
+	got := Resolve(env(map[string]string{
+		"GITHUB_ACTIONS": "true", "GITHUB_SHA": "0123abc",
+		DirtyEnv: "true", ImageDigestEnv: "sha256:feed",
+	}))
+	want := summary.Build{Commit: "0123abc", Dirty: true, Builder: "github-actions", ImageDigest: "sha256:feed"}
+	if got != want {
+		t.Errorf("Resolve = %+v, want %+v", got, want)
+	}
+
+	got = Resolve(env(map[string]string{CommitEnv: "explicit", "GITHUB_SHA": "0123abc", DirtyEnv: "false"}))
+	if got.Commit != "explicit" || got.Dirty || got.Builder != "local" {
+		t.Errorf("explicit commit outside CI: %+v", got)
+	}
+}
@@ -0,0 +1,49 @@
+// Package clock abstracts the wall clock for measurement windows (session start/end, checkpoints,
+// waits) and polling tickers, so tests can run them on a Fake clock and assert exact durations.
+package clock
+
+import "time"
+
+// Clock tells the time and makes tickers.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the part of *time.Ticker the callers use.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the wall clock.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+// Func is a Clock telling the time with now; its tickers are real.
+type Func func() time.Time
+
+func (f Func) Now() time.Time { return f() }
+
+func (Func) NewTicker(d time.Duration) Ticker { return Real.NewTicker(d) }
+
+// Or returns c, else Func(now), else Real: for configs that take a Clock next to a Now func.
+func Or(c Clock, now func() time.Time) Clock {
+	switch {
+	case c != nil:
+		return c
+	case now != nil:
+		return Func(now)
+	}
+	return Real
+}
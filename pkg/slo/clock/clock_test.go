@@ -0,0 +1,38 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	c := NewFake(start)
+	tick := c.NewTicker(10 * time.Second)
+	defer tick.Stop()
+
+	c.Advance(5 * time.Second)
+	select {
+	case <-tick.C():
+		t.Fatal("ticked before the interval")
+	default:
+	}
+	c.Advance(25 * time.Second) // two intervals passed: one tick, the missed one is dropped
+	if got := <-tick.C(); !got.Equal(start.Add(30 * time.Second)) {
+		t.Errorf("tick at %v", got)
+	}
+	select {
+	case <-tick.C():
+		t.Fatal("missed tick was queued")
+	default:
+	}
+
+	c.SetStep(time.Second)
+	a, b := c.Now(), c.Now()
+	if b.Sub(a) != time.Second {
+		t.Errorf("step: %v then %v", a, b)
+	}
+	if Or(nil, nil) != Real || Or(c, time.Now) != c {
+		t.Error("Or picked the wrong clock")
+	}
+}
@@ -0,0 +1,96 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told: by Advance, or by Step on every Now call (so code
+// reading the clock twice measures exactly Step). Its tickers fire on Advance, dropping ticks a
+// slow reader missed like time.Ticker. Safe for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	step    time.Duration
+	tickers []*fakeTicker
+}
+
+// NewFake returns a Fake clock at t.
+func NewFake(t time.Time) *Fake {
+	return &Fake{now: t}
+}
+
+// SetStep makes every Now call advance the clock by d after reading it (0 => never).
+func (f *Fake) SetStep(d time.Duration) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.step = d
+	return f
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	now := f.now
+	step := f.step
+	f.mu.Unlock()
+	if step > 0 {
+		f.Advance(step)
+	}
+	return now
+}
+
+// Advance moves the clock forward by d and fires the tickers that became due.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	for _, t := range f.tickers {
+		t.fire(f.now)
+	}
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTicker{clock: f, d: d, next: f.now.Add(d), c: make(chan time.Time, 1)}
+	f.tickers = append(f.tickers, t)
+	return t
+}
+
+type fakeTicker struct {
+	clock *Fake
+	d     time.Duration
+	next  time.Time
+	c     chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, o := range f.tickers {
+		if o == t {
+			f.tickers = append(f.tickers[:i], f.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+// fire sends one tick if the ticker is due (the clock's lock is held).
+func (t *fakeTicker) fire(now time.Time) {
+	if now.Before(t.next) {
+		return
+	}
+	for !now.Before(t.next) {
+		t.next = t.next.Add(t.d)
+	}
+	select {
+	case t.c <- now:
+	default:
+	}
+}
@@ -0,0 +1,114 @@
+// Package atomicfile writes files through a temp file in the same directory and a rename, so a
+// reader never sees a partially written file. It is the write path of pkg/slo/artifacts, kept
+// apart so packages artifacts depends on (summary) can write files the same way.
+package atomicfile
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Options configures Write. Zero values fall back to the defaults noted per field.
+type Options struct {
+	// FileMode of the final file (0 => 0o644).
+	FileMode os.FileMode
+	// DirMode used when creating parent directories (0 => 0o755).
+	DirMode os.FileMode
+	// Gzip compresses the written content.
+	Gzip bool
+	// Sync fsyncs the temp file before the rename.
+	Sync bool
+	// Durable also fsyncs the parent directory after the rename (implies Sync).
+	Durable bool
+}
+
+// Write writes the content produced by write to a temp file ("<base>.<random>.tmp") in the
+// directory of path and then renames it to path.
+//   - Atomic replace is provided by os.Rename (same filesystem).
+//   - If opts.Gzip is true, the content is gzip-compressed.
+//   - If opts.Sync (or Durable) is true, it fsyncs the temp file before close for stronger durability.
+//   - If opts.Durable is true, it fsyncs the directory after the rename so the rename itself is durable.
+func Write(path string, opts Options, write func(io.Writer) error) error {
+	if opts.FileMode == 0 {
+		opts.FileMode = 0o644
+	}
+	if opts.DirMode == 0 {
+		opts.DirMode = 0o755
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, opts.DirMode); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	success := false
+	defer func() {
+		if !success {
+			_ = f.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	if opts.Gzip {
+		zw := gzip.NewWriter(f)
+		if err := write(zw); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	} else if err := write(f); err != nil {
+		return err
+	}
+
+	if opts.Sync || opts.Durable {
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp, opts.FileMode); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	success = true
+
+	if opts.Durable {
+		return syncDir(dir)
+	}
+	return nil
+}
+
+// syncDir fsyncs a directory so a rename in it is on disk. Windows cannot fsync directories
+// (NTFS journals renames), so it is a no-op there.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = d.Close() }()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("fsync %s: %w", dir, err)
+	}
+	return nil
+}
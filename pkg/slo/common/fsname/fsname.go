@@ -0,0 +1,97 @@
+// Package fsname turns arbitrary text (spec names, run IDs, test cases) into filename components
+// that are valid on Linux, macOS and Windows and do not collide.
+package fsname
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLen is the default byte length cap of a component. Artifact names join a few components
+// (run ID, test case, phase) under the usual 255-byte filename limit.
+const MaxLen = 80
+
+// hashLen is the number of hex digits of the suffix added to altered names.
+const hashLen = 8
+
+// Empty is the component used for blank input.
+const Empty = "na"
+
+// Sanitize is SanitizeN with MaxLen.
+func Sanitize(s string) string {
+	return SanitizeN(s, MaxLen)
+}
+
+// SanitizeN returns a filename component of at most maxLen bytes (at least hashLen+2) made of
+// letters (with combining marks), digits, '.', '-' and '_'. Any other rune becomes '_', a leading
+// '.' (hidden file, "..") becomes '_', trailing dots are dropped and Windows device names (CON,
+// NUL, COM1, ...) get a '_' prefix.
+//
+// An input that is already such a component is returned unchanged. Anything else (a replaced
+// rune, truncation) gets a '-' and a short hash of the whole input appended, so different
+// inputs ("a/b" and "a:b", two long names with the same prefix) keep different names and the
+// same input always gets the same one. Names differing only in case still share a file on
+// case-insensitive filesystems.
+func SanitizeN(s string, maxLen int) string {
+	maxLen = max(maxLen, hashLen+2)
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Empty
+	}
+
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == utf8.RuneError:
+			b.WriteByte('_')
+		case i == 0 && r == '.':
+			b.WriteByte('_')
+		case unicode.IsLetter(r), unicode.IsMark(r), unicode.IsDigit(r), r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	out := strings.TrimRight(b.String(), ".")
+	if out == "" {
+		out = "_"
+	}
+	if reserved(out) {
+		out = "_" + out
+	}
+	if out == s && len(out) <= maxLen {
+		return out
+	}
+
+	sum := sha256.Sum256([]byte(s))
+	suffix := "-" + hex.EncodeToString(sum[:])[:hashLen]
+	return truncate(out, maxLen-len(suffix)) + suffix
+}
+
+// truncate cuts s to at most n bytes on a rune boundary.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// reserved reports whether s is a Windows device name, alone or before an extension ("nul.txt").
+func reserved(s string) bool {
+	base, _, _ := strings.Cut(s, ".")
+	switch strings.ToUpper(base) {
+	case "CON", "PRN", "AUX", "NUL":
+		return true
+	}
+	if len(base) == 4 {
+		prefix := strings.ToUpper(base[:3])
+		return (prefix == "COM" || prefix == "LPT") && base[3] >= '1' && base[3] <= '9'
+	}
+	return false
+}
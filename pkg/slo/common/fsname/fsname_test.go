@@ -0,0 +1,86 @@
+package fsname
+
+import (
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode"
+	"unicode/utf8"
+)
+
+func TestSanitize(t *testing.T) {
+	long := strings.Repeat("상태", 40) // 240 bytes
+	for in, want := range map[string]string{
+		"run-42_a.b":       "run-42_a.b",
+		"  trimmed ":       "trimmed",
+		"":                 Empty,
+		"Manager should 1": "Manager_should_1-",
+		"a/b":              "a_b-",
+		"..":               "_-",
+		".hidden":          "_hidden-",
+		"nul":              "_nul-",
+		"COM3.json":        "_COM3.json-",
+		"COMX":             "COMX",
+		"trailing.":        "trailing-",
+		"héllo_世界":         "héllo_世界",
+		long:               strings.Repeat("상태", 11) + "상-", // 71 bytes + "-" + hash: rune boundary
+	} {
+		got := Sanitize(in)
+		if strings.HasSuffix(want, "-") {
+			if !strings.HasPrefix(got, want) || len(got) != len(want)+hashLen {
+				t.Errorf("Sanitize(%q) = %q, want %q + hash", in, got, want)
+			}
+		} else if got != want {
+			t.Errorf("Sanitize(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if a, b := Sanitize("a/b"), Sanitize("a:b"); a == b {
+		t.Errorf("a/b and a:b collide: %q", a)
+	}
+}
+
+// valid reports whether s is a component SanitizeN may return.
+func valid(s string, maxLen int) bool {
+	if s == "" || len(s) > maxLen || !utf8.ValidString(s) || s[0] == '.' || strings.HasSuffix(s, ".") || reserved(s) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsMark(r) && !unicode.IsDigit(r) && !strings.ContainsRune(".-_", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSanitizeProperties(t *testing.T) {
+	cfg := &quick.Config{MaxCount: 5000}
+	props := map[string]any{
+		"valid": func(s string, n uint8) bool {
+			maxLen := int(n)%100 + 1
+			return valid(SanitizeN(s, maxLen), max(maxLen, hashLen+2))
+		},
+		"idempotent": func(s string) bool {
+			once := Sanitize(s)
+			return Sanitize(once) == once
+		},
+		"stable": func(s string) bool {
+			return Sanitize(s) == Sanitize(s)
+		},
+		"injective": func(a, b string) bool {
+			a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+			return a == b || Sanitize(a) != Sanitize(b)
+		},
+		"long prefixes stay distinct": func(prefix string, a, b uint16) bool {
+			p := strings.Repeat("x", MaxLen) + prefix
+			x, y := p+string(rune('a'+a%26))+"1", p+string(rune('a'+b%26))+"2"
+			return Sanitize(x) != Sanitize(y)
+		},
+	}
+	for name, f := range props {
+		t.Run(name, func(t *testing.T) {
+			if err := quick.Check(f, cfg); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Package gzfile reads artifacts that may be gzip-compressed, so every loader accepts both the
+// plain file and its compressed form (artifacts.Options.Gzip, raw scrapes) without a flag.
+package gzfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Ext is appended to the name of a compressed artifact ("x.json" => "x.json.gz").
+const Ext = ".gz"
+
+// magic starts every gzip stream.
+var magic = []byte{0x1f, 0x8b}
+
+// IsGzip reports whether b starts with the gzip magic.
+func IsGzip(b []byte) bool {
+	return bytes.HasPrefix(b, magic)
+}
+
+// Decode returns b decompressed when it is gzip data, else b unchanged. The content decides, not
+// the name, so a renamed file still loads.
+func Decode(b []byte) ([]byte, error) {
+	if !IsGzip(b) {
+		return b, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = zr.Close() }()
+	return io.ReadAll(zr)
+}
+
+// ReadFile is os.ReadFile with transparent decompression (see Decode).
+func ReadFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	out, err := Decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
+
+// HasSuffix reports whether name ends with suffix, compressed or not ("x.json", "x.json.gz").
+func HasSuffix(name, suffix string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(name, Ext), suffix)
+}
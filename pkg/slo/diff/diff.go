@@ -0,0 +1,99 @@
+// Package diff compares two raw Prometheus text scrapes series by series (new series, removed
+// series, value changes), instead of diffing /metrics dumps by hand.
+package diff
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
+)
+
+// Series is one series present in only one of the scrapes.
+type Series struct {
+	Key   string  `json:"key"` // canonical key, e.g. foo_total{a="b"}
+	Value float64 `json:"value"`
+}
+
+// Change is a series present in both scrapes whose value changed.
+type Change struct {
+	Key    string  `json:"key"`
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+}
+
+// Delta is After - Before.
+func (c Change) Delta() float64 {
+	return c.After - c.Before
+}
+
+// Report is the per-series difference between two scrapes, each list sorted by key.
+type Report struct {
+	Added     []Series `json:"added,omitempty"`
+	Removed   []Series `json:"removed,omitempty"`
+	Changed   []Change `json:"changed,omitempty"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// Empty reports whether both scrapes hold the same series with the same values.
+func (r Report) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// Text parses two exposition payloads with the harness parser (promtext) and compares them.
+// Series keys are canonical, so label order and quoting differences are not reported.
+func Text(before, after string) (Report, error) {
+	b, err := promtext.ParseTextToMap(strings.NewReader(before))
+	if err != nil {
+		return Report{}, fmt.Errorf("before: %w", err)
+	}
+	a, err := promtext.ParseTextToMap(strings.NewReader(after))
+	if err != nil {
+		return Report{}, fmt.Errorf("after: %w", err)
+	}
+	return Maps(b, a), nil
+}
+
+// Maps compares two parsed scrapes (promtext.ParseTextToMap). NaN equals NaN.
+func Maps(before, after map[string]float64) Report {
+	var rep Report
+	for key, bv := range before {
+		av, ok := after[key]
+		switch {
+		case !ok:
+			rep.Removed = append(rep.Removed, Series{Key: key, Value: bv})
+		case av == bv || (math.IsNaN(av) && math.IsNaN(bv)):
+			rep.Unchanged++
+		default:
+			rep.Changed = append(rep.Changed, Change{Key: key, Before: bv, After: av})
+		}
+	}
+	for key, av := range after {
+		if _, ok := before[key]; !ok {
+			rep.Added = append(rep.Added, Series{Key: key, Value: av})
+		}
+	}
+	sort.Slice(rep.Added, func(i, j int) bool { return rep.Added[i].Key < rep.Added[j].Key })
+	sort.Slice(rep.Removed, func(i, j int) bool { return rep.Removed[i].Key < rep.Removed[j].Key })
+	sort.Slice(rep.Changed, func(i, j int) bool { return rep.Changed[i].Key < rep.Changed[j].Key })
+	return rep
+}
+
+// WriteText prints the report, one line per series, e.g. "changed  foo_total 3 -> 5 (+2)".
+func (r Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, s := range r.Removed {
+		fmt.Fprintf(&b, "removed  %s %g\n", s.Key, s.Value)
+	}
+	for _, c := range r.Changed {
+		fmt.Fprintf(&b, "changed  %s %g -> %g (%+g)\n", c.Key, c.Before, c.After, c.Delta())
+	}
+	for _, s := range r.Added {
+		fmt.Fprintf(&b, "added    %s %g\n", s.Key, s.Value)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
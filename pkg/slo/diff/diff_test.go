@@ -0,0 +1,51 @@
+package diff
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	before := `# TYPE reconcile_total counter
+reconcile_total{controller="a",result="success"} 3
+reconcile_total{controller="a",result="error"} 1
+workqueue_depth{name="a"} 2
+`
+	after := `reconcile_total{result="success",controller="a"} 5
+workqueue_depth{name="a"} 2
+sts_created_total 1
+`
+	got, err := Text(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Report{
+		Added:     []Series{{Key: "sts_created_total", Value: 1}},
+		Removed:   []Series{{Key: `reconcile_total{controller="a",result="error"}`, Value: 1}},
+		Changed:   []Change{{Key: `reconcile_total{controller="a",result="success"}`, Before: 3, After: 5}},
+		Unchanged: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v\nwant %+v", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := got.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := `removed  reconcile_total{controller="a",result="error"} 1
+changed  reconcile_total{controller="a",result="success"} 3 -> 5 (+2)
+added    sts_created_total 1
+`; buf.String() != want {
+		t.Fatalf("unexpected text:\n%s", buf.String())
+	}
+
+	if r := Maps(map[string]float64{"x": math.NaN()}, map[string]float64{"x": math.NaN()}); !r.Empty() {
+		t.Fatalf("expected NaN to equal NaN, got %+v", r)
+	}
+	if _, err := Text("x abc\n", ""); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"github.com/yeongki/my-operator/pkg/slo/common/promkey"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+// breakdown computes the SLI per value of the spec's BreakdownBy labels.
+// Keys are the input key narrowed to the breakdown labels, e.g.
+// controller_runtime_reconcile_total{controller="foo"}; the series summed into each key are
+// those matching the input (name and labels). A series that appears during a delta window
+// started from zero; a negative delta (counter reset) counts from the reset, as in evalSLI.
+func breakdown(s spec.SLISpec, start, end map[string]float64) map[string]float64 {
+	if len(s.BreakdownBy) == 0 {
+		return nil
+	}
+
+	out := map[string]float64{}
+	for _, in := range s.Inputs {
+		name, want, err := promkey.Parse(in.Key)
+		if err != nil {
+			continue
+		}
+		group := func(key string) (string, bool) {
+			labels, ok := matchSeries(key, in)
+			if !ok {
+				return "", false
+			}
+			g := make(map[string]string, len(want)+len(s.BreakdownBy))
+			for k, v := range want {
+				g[k] = v
+			}
+			for _, b := range s.BreakdownBy {
+				g[b] = labels[b]
+			}
+			return promkey.Format(name, g), true
+		}
+
+		switch s.Compute.Mode {
+		case spec.ComputeSingle, spec.ComputeEnd:
+			values := start
+			if s.Compute.Mode == spec.ComputeEnd {
+				values = end
+			}
+			for key, v := range values {
+				if g, ok := group(key); ok {
+					out[g] += v
+				}
+			}
+		case spec.ComputeDelta:
+			for key, v := range end {
+				if g, ok := group(key); ok {
+					out[g] += counterDelta(start[key], v)
+				}
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
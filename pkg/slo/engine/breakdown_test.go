@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+func TestBreakdownByController(t *testing.T) {
+	s := spec.SLISpec{
+		ID:          "reconcile_error_delta",
+		Inputs:      []spec.MetricRef{spec.PromMetric("controller_runtime_reconcile_total", spec.Labels{"result": "error"})},
+		Compute:     spec.ComputeSpec{Mode: spec.ComputeDelta},
+		BreakdownBy: []string{"controller"},
+	}
+	start := map[string]float64{
+		`controller_runtime_reconcile_total{controller="a",result="error"}`:   2,
+		`controller_runtime_reconcile_total{controller="a",result="success"}`: 10,
+		`controller_runtime_reconcile_total{controller="b",result="error"}`:   5,
+		`controller_runtime_reconcile_total`:                                  17,
+	}
+	end := map[string]float64{
+		`controller_runtime_reconcile_total{controller="a",result="error"}`:   4,
+		`controller_runtime_reconcile_total{controller="a",result="success"}`: 30,
+		`controller_runtime_reconcile_total{controller="b",result="error"}`:   1, // reset
+		`controller_runtime_reconcile_total{controller="c",result="error"}`:   3, // new series
+		`controller_runtime_reconcile_total`:                                  38,
+	}
+
+	got := breakdown(s, start, end)
+	want := map[string]float64{
+		`controller_runtime_reconcile_total{controller="a",result="error"}`: 2,
+		`controller_runtime_reconcile_total{controller="b",result="error"}`: 1,
+		`controller_runtime_reconcile_total{controller="c",result="error"}`: 3,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("%s = %v, want %v (all: %v)", k, got[k], v, got)
+		}
+	}
+}
+
+func TestLookupLabelSubset(t *testing.T) {
+	values := map[string]float64{
+		`rest_client_requests_total{code="200",host="api",method="PATCH"}`: 3,
+		`rest_client_requests_total{code="409",host="api",method="PATCH"}`: 1,
+		`rest_client_requests_total{code="200",host="api",method="GET"}`:   7,
+		`rest_client_requests_total`:                                       11,
+	}
+	patch := spec.PromMetric("rest_client_requests_total", spec.Labels{"method": "PATCH"})
+	if v, ok := lookup(values, patch); !ok || v != 4 {
+		t.Fatalf("PATCH = %v, %v; want 4", v, ok)
+	}
+	// an exposed metric without a matching series is missing unless the input opts into zero
+	del := spec.PromMetric("rest_client_requests_total", spec.Labels{"method": "DELETE"})
+	if _, ok := lookup(values, del); ok {
+		t.Fatal("DELETE without a series must be missing")
+	}
+	if v, ok := lookup(values, del.OrZero()); !ok || v != 0 {
+		t.Fatalf("DELETE.OrZero() = %v, %v; want 0", v, ok)
+	}
+	if _, ok := lookup(values, spec.PromMetric("workqueue_depth", spec.Labels{"name": "a"}).OrZero()); ok {
+		t.Fatal("unexposed metric must be missing")
+	}
+}
@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// evalDerived evaluates a derived spec from the results computed so far (matched by ID).
+// An operand without a value (skipped, missing, or a derived spec not evaluated yet) skips it.
+func evalDerived(s spec.SLISpec, results []summary.SLIResult, windowSeconds float64) summary.SLIResult {
+	res := summary.SLIResult{
+		ID:          s.ID,
+		Title:       s.Title,
+		Unit:        s.Unit,
+		Kind:        s.Kind,
+		Description: s.Description,
+		Owner:       s.Owner,
+		Category:    s.Category,
+		Status:      summary.StatusPass,
+	}
+
+	operands := make([]float64, 0, len(s.Derived.Operands))
+	for _, id := range s.Derived.Operands {
+		v, ok := resultValue(results, id)
+		if !ok {
+			res.Status = summary.StatusSkip
+			res.Reason = fmt.Sprintf("derived operand %q has no value", id)
+			res.ErrorKind = resultErrorKind(results, id)
+			return res
+		}
+		operands = append(operands, v)
+	}
+
+	var value float64
+	switch {
+	case s.Derived.Op == spec.DerivedRatio && len(operands) == 2:
+		if operands[1] == 0 {
+			res.Status = summary.StatusSkip
+			res.Reason = fmt.Sprintf("derived ratio: %s is zero", s.Derived.Operands[1])
+			return res
+		}
+		value = operands[0] / operands[1]
+	case s.Derived.Op == spec.DerivedRate && len(operands) == 1:
+		if windowSeconds <= 0 {
+			res.Status = summary.StatusSkip
+			res.Reason = "derived rate: empty window"
+			return res
+		}
+		value = operands[0] / windowSeconds
+	default:
+		res.Status = summary.StatusSkip
+		res.Reason = fmt.Sprintf("derived: unsupported op %q with %d operands", s.Derived.Op, len(operands))
+		return res
+	}
+	res.Value = &value
+
+	if s.Judge != nil {
+		res.Status, res.Reason = judge(value, s.Judge.Rules)
+	}
+	return res
+}
+
+// derivedOrder returns the derived spec indexes so that a derived operand is evaluated before the
+// specs reading it, whatever their order in the request. Specs in a dependency cycle come last:
+// their operands have no value, so they are skipped.
+func derivedOrder(specs []spec.SLISpec, derived []int) []int {
+	pending := map[string]bool{}
+	for _, i := range derived {
+		pending[specs[i].ID] = true
+	}
+	order := make([]int, 0, len(derived))
+	for rest := derived; len(rest) > 0; {
+		var blocked []int
+		for _, i := range rest {
+			ready := true
+			for _, id := range specs[i].Derived.Operands {
+				ready = ready && !pending[id]
+			}
+			if !ready {
+				blocked = append(blocked, i)
+				continue
+			}
+			order = append(order, i)
+			delete(pending, specs[i].ID)
+		}
+		if len(blocked) == len(rest) {
+			return append(order, blocked...)
+		}
+		rest = blocked
+	}
+	return order
+}
+
+// resultErrorKind is the ErrorKind of the result id, so a derived spec inherits why its operand
+// was skipped.
+func resultErrorKind(results []summary.SLIResult, id string) string {
+	for _, r := range results {
+		if r.ID == id {
+			return r.ErrorKind
+		}
+	}
+	return ""
+}
+
+func resultValue(results []summary.SLIResult, id string) (float64, bool) {
+	for _, r := range results {
+		if r.ID == id && r.Value != nil {
+			return *r.Value, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+type seqFetcher []map[string]float64
+
+func (f *seqFetcher) Fetch(_ context.Context, at time.Time) (fetch.Sample, error) {
+	v := (*f)[0]
+	*f = (*f)[1:]
+	return fetch.Sample{At: at, Values: v}, nil
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(string, summary.Summary) error { return nil }
+
+func TestDerivedSpecs(t *testing.T) {
+	total := spec.PromMetric("reconcile_total", nil)
+	errs := spec.PromMetric("reconcile_errors_total", nil)
+	specs := []spec.SLISpec{
+		// derived specs may come before their operands
+		{ID: "error_ratio", Kind: "derived", Derived: &spec.DerivedSpec{
+			Op: spec.DerivedRatio, Operands: []string{"errors", "total"},
+		}, Judge: &spec.JudgeSpec{Rules: []spec.Rule{{Op: spec.OpGT, Target: 0.1, Level: spec.LevelFail}}}},
+		{ID: "total", Inputs: []spec.MetricRef{total}, Compute: spec.ComputeSpec{Mode: spec.ComputeDelta}},
+		{ID: "errors", Inputs: []spec.MetricRef{errs}, Compute: spec.ComputeSpec{Mode: spec.ComputeDelta}},
+		{ID: "rate", Kind: "derived", Derived: &spec.DerivedSpec{Op: spec.DerivedRate, Operands: []string{"total"}}},
+		{ID: "broken", Kind: "derived", Derived: &spec.DerivedSpec{Op: spec.DerivedRate, Operands: []string{"nope"}}},
+	}
+	f := &seqFetcher{{total.Key: 10, errs.Key: 1}, {total.Key: 50, errs.Key: 9}}
+	start := time.Unix(1700000000, 0)
+	sum, err := New(f, nopWriter{}, nil).Execute(context.Background(), ExecuteRequest{
+		Config: RunConfig{StartedAt: start, FinishedAt: start.Add(20 * time.Second)},
+		Specs:  specs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]float64{"error_ratio": 0.2, "total": 40, "errors": 8, "rate": 2}
+	for i, r := range sum.Results {
+		if r.ID != specs[i].ID {
+			t.Fatalf("result %d is %s, want spec order", i, r.ID)
+		}
+		if r.ID == "broken" {
+			if r.Status != summary.StatusSkip {
+				t.Fatalf("broken: status %s, want skip", r.Status)
+			}
+			continue
+		}
+		if r.Value == nil || *r.Value != want[r.ID] {
+			t.Fatalf("%s: value %v, want %v (%s)", r.ID, r.Value, want[r.ID], r.Reason)
+		}
+	}
+	if sum.Results[0].Status != summary.StatusFail {
+		t.Fatalf("error_ratio: status %s, want fail", sum.Results[0].Status)
+	}
+}
+
+func TestDerivedOnDerived(t *testing.T) {
+	total := spec.PromMetric("reconcile_total", nil)
+	derived := func(id string, op spec.DerivedOp, operands ...string) spec.SLISpec {
+		return spec.SLISpec{ID: id, Kind: spec.KindDerived, Derived: &spec.DerivedSpec{Op: op, Operands: operands}}
+	}
+	specs := []spec.SLISpec{
+		// reads "ratio" and "rate", which are derived and come later
+		derived("rate_of_ratio", spec.DerivedRate, "ratio"),
+		derived("ratio", spec.DerivedRatio, "rate", "total"),
+		derived("rate", spec.DerivedRate, "total"),
+		{ID: "total", Inputs: []spec.MetricRef{total}, Compute: spec.ComputeSpec{Mode: spec.ComputeDelta}},
+		derived("cycle_a", spec.DerivedRate, "cycle_b"),
+		derived("cycle_b", spec.DerivedRate, "cycle_a"),
+	}
+	f := &seqFetcher{{total.Key: 0}, {total.Key: 40}}
+	start := time.Unix(1700000000, 0)
+	sum, err := New(f, nopWriter{}, nil).Execute(context.Background(), ExecuteRequest{
+		Config: RunConfig{StartedAt: start, FinishedAt: start.Add(20 * time.Second)},
+		Specs:  specs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]float64{"rate_of_ratio": 0.0025, "ratio": 0.05, "rate": 2, "total": 40}
+	for i, r := range sum.Results {
+		if r.ID != specs[i].ID {
+			t.Fatalf("result %d is %s, want spec order", i, r.ID)
+		}
+		if r.ID == "cycle_a" || r.ID == "cycle_b" {
+			if r.Status != summary.StatusSkip {
+				t.Fatalf("%s: status %s, want skip", r.ID, r.Status)
+			}
+			continue
+		}
+		if r.Value == nil || *r.Value != want[r.ID] {
+			t.Fatalf("%s: value %v, want %v (%s)", r.ID, r.Value, want[r.ID], r.Reason)
+		}
+	}
+}
+
+type failingFetcher struct{ err error }
+
+func (f failingFetcher) Fetch(context.Context, time.Time) (fetch.Sample, error) {
+	return fetch.Sample{}, f.err
+}
+
+func TestSkipErrorKinds(t *testing.T) {
+	specs := []spec.SLISpec{
+		{ID: "total", Inputs: []spec.MetricRef{spec.PromMetric("reconcile_total", nil)},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta}},
+		{ID: "rate", Kind: "derived", Derived: &spec.DerivedSpec{Op: spec.DerivedRate, Operands: []string{"total"}}},
+	}
+	start := time.Unix(1700000000, 0)
+	req := ExecuteRequest{Config: RunConfig{StartedAt: start, FinishedAt: start.Add(time.Minute)}, Specs: specs}
+
+	f := &seqFetcher{{}, {}}
+	sum, err := New(f, nopWriter{}, nil).Execute(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range sum.Results {
+		if r.ErrorKind != string(slo.KindMetricMissing) {
+			t.Errorf("%s: error kind %q, want metric_missing (also inherited by derived specs)", r.ID, r.ErrorKind)
+		}
+	}
+
+	parse := slo.Errorf(slo.KindParse, "line 1: bad value")
+	for _, tc := range []struct {
+		err  error
+		want slo.Kind
+	}{{parse, slo.KindParse}, {errors.New("connection refused"), slo.KindFetch}} {
+		sum, err = New(failingFetcher{tc.err}, nopWriter{}, nil).Execute(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sum.Results) != 2 || sum.Results[0].ErrorKind != string(tc.want) {
+			t.Errorf("fetch error %v: results %+v, want skips of kind %s", tc.err, sum.Results, tc.want)
+		}
+	}
+}
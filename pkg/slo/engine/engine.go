@@ -1,8 +1,10 @@
 package engine
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/yeongki/my-operator/pkg/slo"
@@ -22,6 +24,9 @@ type Engine struct {
 	//reg     *spec.Registry
 	writer summary.Writer
 	logf   func(string, ...any)
+
+	// hooks enrich snapshots and summaries (see Use).
+	hooks Chain
 }
 
 func New(fetcher fetch.MetricsFetcher, writer summary.Writer, l slo.Logger) *Engine {
@@ -42,19 +47,23 @@ func (e *Engine) Execute(ctx context.Context, req ExecuteRequest) (*summary.Summ
 	start, err := e.fetcher.Fetch(ctx, cfg.StartedAt)
 	if err != nil {
 		// philosophy: "measurement failure is not test failure" → return a Summary with warnings
-		s := e.emptySummary(cfg, []string{fmt.Sprintf("fetch(start) failed: %v", err)})
+		s := e.failedSummary(cfg, req.Specs, nil, "fetch(start) failed", err)
+		e.hooks.Result(ctx, s)
 		_ = e.writer.Write(req.OutPath, *s)
 		return s, nil
 	}
+	hookWarnings := e.hooks.StartSnapshot(ctx, &start)
 	end, err := e.fetcher.Fetch(ctx, cfg.FinishedAt)
 	if err != nil {
-		s := e.emptySummary(cfg, []string{fmt.Sprintf("fetch(end) failed: %v", err)})
+		s := e.failedSummary(cfg, req.Specs, hookWarnings, "fetch(end) failed", err)
+		e.hooks.Result(ctx, s)
 		_ = e.writer.Write(req.OutPath, *s)
 		return s, nil
 	}
+	hookWarnings = append(hookWarnings, e.hooks.EndSnapshot(ctx, &end)...)
 
 	sum := summary.Summary{
-		SchemaVersion: "slo.v3",
+		SchemaVersion: summary.CurrentSchemaVersion,
 		GeneratedAt:   time.Now(),
 		Config: summary.RunConfig{
 			RunID:      cfg.RunID,
@@ -68,9 +77,30 @@ func (e *Engine) Execute(ctx context.Context, req ExecuteRequest) (*summary.Summ
 			Format:        cfg.Format,
 			EvidencePaths: cfg.EvidencePaths,
 		},
+		Disruptions:  cfg.Disruptions,
+		Load:         cfg.Load,
+		Phases:       cfg.Phases,
+		Convergences: cfg.Convergences,
+		Waits:        cfg.Waits,
+		Extras:       cfg.Extras,
+		Warnings:     hookWarnings,
+	}
+
+	// fetchers that sampled the window in between (e.g. fetch.PeakTracker) know gauge peaks,
+	// also when wrapped (filter, stats, merge, capture)
+	var peaks map[string]float64
+	if ps, ok := fetch.FindPeaks(e.fetcher); ok {
+		peaks = ps.Peaks()
 	}
 
-	for _, s := range req.Specs {
+	var derived []int
+	for i, s := range req.Specs {
+		if s.Derived != nil {
+			// evaluated once every operand has a result
+			derived = append(derived, i)
+			sum.Results = append(sum.Results, summary.SLIResult{})
+			continue
+		}
 		// specItem, ok := e.reg.Get(id)
 		// if !ok {
 		// 	sum.Warnings = append(sum.Warnings, fmt.Sprintf("unknown sli id: %s", id))
@@ -82,19 +112,60 @@ func (e *Engine) Execute(ctx context.Context, req ExecuteRequest) (*summary.Summ
 		// 	continue
 		// }
 		// r := evalSLI(specItem, start.Values, end.Values)
-		r := evalSLI(s, start.Values, end.Values)
+		r := evalSLI(s, start.Values, end.Values, peaks)
+		r.Provenance = provenanceFor(s, start, end)
+		if r.Status != summary.StatusSkip {
+			for k, v := range breakdown(s, start.Values, end.Values) {
+				if r.Fields == nil {
+					r.Fields = map[string]float64{}
+				}
+				r.Fields[k] = v
+			}
+		}
+		normalizePerObject(&r, cfg.Load)
 		sum.Results = append(sum.Results, r)
 	}
 
+	window := cfg.FinishedAt.Sub(cfg.StartedAt).Seconds()
+	for _, i := range derivedOrder(req.Specs, derived) {
+		sum.Results[i] = evalDerived(req.Specs[i], sum.Results, window)
+	}
+
+	e.hooks.Result(ctx, &sum)
 	if err := e.writer.Write(req.OutPath, sum); err != nil {
-		return nil, err
+		return nil, slo.Wrap(slo.KindWrite, err)
 	}
 	return &sum, nil
 }
 
+// failedSummary is the summary of a window whose snapshot could not be fetched: every spec is
+// skipped with the fetch error, classified (slo.KindOf; unclassified => fetch).
+func (e *Engine) failedSummary(
+	cfg RunConfig, specs []spec.SLISpec, warnings []string, what string, err error,
+) *summary.Summary {
+	reason := fmt.Sprintf("%s: %v", what, err)
+	s := e.emptySummary(cfg, append(warnings, reason))
+	kind := cmp.Or(slo.KindOf(err), slo.KindFetch)
+	for _, sp := range specs {
+		s.Results = append(s.Results, summary.SLIResult{
+			ID:          sp.ID,
+			Title:       sp.Title,
+			Unit:        sp.Unit,
+			Kind:        sp.Kind,
+			Description: sp.Description,
+			Owner:       sp.Owner,
+			Category:    sp.Category,
+			Status:      summary.StatusSkip,
+			Reason:      reason,
+			ErrorKind:   string(kind),
+		})
+	}
+	return s
+}
+
 func (e *Engine) emptySummary(cfg RunConfig, warnings []string) *summary.Summary {
 	return &summary.Summary{
-		SchemaVersion: "slo.v3",
+		SchemaVersion: summary.CurrentSchemaVersion,
 		GeneratedAt:   time.Now(),
 		Config: summary.RunConfig{
 			RunID:         cfg.RunID,
@@ -105,18 +176,26 @@ func (e *Engine) emptySummary(cfg RunConfig, warnings []string) *summary.Summary
 			Format:        cfg.Format,
 			EvidencePaths: cfg.EvidencePaths,
 		},
-		Results:  []summary.SLIResult{},
-		Warnings: warnings,
+		Disruptions:  cfg.Disruptions,
+		Load:         cfg.Load,
+		Phases:       cfg.Phases,
+		Convergences: cfg.Convergences,
+		Waits:        cfg.Waits,
+		Extras:       cfg.Extras,
+		Results:      []summary.SLIResult{},
+		Warnings:     warnings,
 	}
 }
 
-func evalSLI(s spec.SLISpec, start, end map[string]float64) summary.SLIResult {
+func evalSLI(s spec.SLISpec, start, end, peaks map[string]float64) summary.SLIResult {
 	res := summary.SLIResult{
 		ID:          s.ID,
 		Title:       s.Title,
 		Unit:        s.Unit,
 		Kind:        s.Kind,
 		Description: s.Description,
+		Owner:       s.Owner,
+		Category:    s.Category,
 		Status:      summary.StatusPass,
 	}
 
@@ -127,8 +206,8 @@ func evalSLI(s spec.SLISpec, start, end map[string]float64) summary.SLIResult {
 	var valStart, valEnd float64
 	for _, in := range s.Inputs {
 		used = append(used, in.Key)
-		a, okA := start[in.Key]
-		b, okB := end[in.Key]
+		a, okA := lookup(start, in)
+		b, okB := lookup(end, in)
 		if !okA || !okB {
 			missing = append(missing, in.Key)
 			continue
@@ -142,22 +221,53 @@ func evalSLI(s spec.SLISpec, start, end map[string]float64) summary.SLIResult {
 	if len(missing) > 0 {
 		res.Status = summary.StatusSkip
 		res.Reason = "missing input metrics"
+		res.ErrorKind = string(slo.KindMetricMissing)
 		return res
 	}
 
+	valMax := math.Max(valStart, valEnd)
+	if len(s.Inputs) == 1 {
+		// a peak of a sum is not the sum of the peaks: only single-input gauges use tracked peaks
+		if p, ok := peaks[s.Inputs[0].Key]; ok && p > valMax {
+			valMax = p
+		}
+	}
+	if s.Kind == spec.KindGauge {
+		if s.Compute.Mode == spec.ComputeDelta {
+			res.Status = summary.StatusSkip
+			res.Reason = "delta of a gauge is meaningless: use compute mode single, end or max"
+			return res
+		}
+		res.Fields = map[string]float64{"start": valStart, "end": valEnd, "max": valMax}
+	}
+
 	var value float64
 	switch s.Compute.Mode {
 	case spec.ComputeSingle:
 		value = valStart
+	case spec.ComputeEnd:
+		value = valEnd
+	case spec.ComputeMax:
+		value = valMax
+	case spec.ComputeQuantile:
+		q, err := quantile(s, start, end)
+		if err != nil {
+			res.Status = summary.StatusSkip
+			res.Reason = err.Error()
+			res.ErrorKind = string(slo.KindOf(err))
+			return res
+		}
+		value = q
 	case spec.ComputeDelta:
 		value = valEnd - valStart
 		if value < 0 {
-			// v3: counter reset suspected (process restart)
+			// Counter reset (process restart, e.g. a killed controller pod): like Prometheus increase(),
+			// count from zero after the reset. The pre-reset increase is unknown without intermediate
+			// samples, so the value is a lower bound and judging is skipped.
+			value = valEnd
 			res.Value = &value
 			res.Status = summary.StatusWarn
-			res.Reason = "delta < 0 (counter reset suspected)"
-			// judge가 있으면 judge 결과로 덮어써버리니까,
-			// 이 경우 judge를 건너뛰는 정책을 택할지 결정해야 함.
+			res.Reason = "counter reset detected (delta < 0): value is the increase since the reset (lower bound)"
 			return res // judge skip
 		}
 	default:
@@ -174,11 +284,57 @@ func evalSLI(s spec.SLISpec, start, end map[string]float64) summary.SLIResult {
 	return res
 }
 
+// normalizePerObject adds Fields["per_object"] (value / churned objects) to delta counters
+// measured under a load generator, so runs with different object counts are comparable.
+func normalizePerObject(r *summary.SLIResult, load *summary.LoadReport) {
+	if load == nil || load.Objects <= 0 || r.Kind != spec.KindDeltaCounter || r.Value == nil {
+		return
+	}
+	if r.Fields == nil {
+		r.Fields = map[string]float64{}
+	}
+	r.Fields["per_object"] = *r.Value / float64(load.Objects)
+}
+
+// provenanceFor copies fetch provenance into the result, restricted to the inputs of s.
+func provenanceFor(s spec.SLISpec, start, end fetch.Sample) *summary.Provenance {
+	if start.Provenance == nil && end.Provenance == nil {
+		return nil
+	}
+	p := &summary.Provenance{Start: scrapeProvenance(s, start.Provenance)}
+	if s.Compute.Mode != spec.ComputeSingle {
+		p.End = scrapeProvenance(s, end.Provenance)
+	}
+	return p
+}
+
+func scrapeProvenance(s spec.SLISpec, fp *fetch.Provenance) *summary.ScrapeProvenance {
+	if fp == nil {
+		return nil
+	}
+	sp := &summary.ScrapeProvenance{
+		Fetcher:   fp.Fetcher,
+		Target:    fp.Target,
+		Via:       fp.Via,
+		Parser:    fp.Parser,
+		ScrapedAt: fp.ScrapedAt,
+	}
+	for _, in := range s.Inputs {
+		if n, ok := fp.Series[in.Key]; ok {
+			if sp.Series == nil {
+				sp.Series = map[string]int{}
+			}
+			sp.Series[in.Key] = n
+		}
+	}
+	return sp
+}
+
 func judge(v float64, rules []spec.Rule) (status summary.Status, reason string) {
 	// v3: fail dominates warn
 	var warn string
 	for _, r := range rules {
-		if !compare(v, r.Op, r.Target) {
+		if !r.Op.Holds(v, r.Target) {
 			continue
 		}
 		switch r.Level {
@@ -195,20 +351,3 @@ func judge(v float64, rules []spec.Rule) (status summary.Status, reason string)
 	}
 	return summary.StatusPass, ""
 }
-
-func compare(v float64, op spec.Op, target float64) bool {
-	switch op {
-	case spec.OpLE:
-		return v <= target
-	case spec.OpGE:
-		return v >= target
-	case spec.OpLT:
-		return v < target
-	case spec.OpGT:
-		return v > target
-	case spec.OpEQ:
-		return v == target
-	default:
-		return false
-	}
-}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+type peakFetcher struct {
+	seqFetcher
+	peaks map[string]float64
+}
+
+func (p *peakFetcher) Peaks() map[string]float64 { return p.peaks }
+
+func TestGaugeSampling(t *testing.T) {
+	depth := spec.PromMetric("workqueue_depth", nil)
+	gauge := func(id string, mode spec.ComputeMode) spec.SLISpec {
+		return spec.SLISpec{
+			ID:      id,
+			Kind:    spec.KindGauge,
+			Inputs:  []spec.MetricRef{depth},
+			Compute: spec.ComputeSpec{Mode: mode},
+		}
+	}
+	specs := []spec.SLISpec{
+		gauge("start", spec.ComputeSingle),
+		gauge("end", spec.ComputeEnd),
+		gauge("max", spec.ComputeMax),
+		gauge("delta", spec.ComputeDelta),
+	}
+	f := &peakFetcher{
+		seqFetcher: seqFetcher{{depth.Key: 4}, {depth.Key: 1}},
+		peaks:      map[string]float64{depth.Key: 9},
+	}
+	start := time.Unix(1700000000, 0)
+	sum, err := New(f, nopWriter{}, nil).Execute(context.Background(), ExecuteRequest{
+		Config: RunConfig{StartedAt: start, FinishedAt: start.Add(time.Minute)},
+		Specs:  specs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]float64{"start": 4, "end": 1, "max": 9}
+	for _, r := range sum.Results {
+		if r.ID == "delta" {
+			if r.Status != summary.StatusSkip {
+				t.Fatalf("gauge delta: status %s, want skip", r.Status)
+			}
+			continue
+		}
+		if r.Value == nil || *r.Value != want[r.ID] {
+			t.Fatalf("%s: value %v, want %v", r.ID, r.Value, want[r.ID])
+		}
+		if r.Fields["start"] != 4 || r.Fields["end"] != 1 || r.Fields["max"] != 9 {
+			t.Fatalf("%s: fields %v", r.ID, r.Fields)
+		}
+	}
+}
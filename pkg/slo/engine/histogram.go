@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+type bucket struct {
+	le    float64
+	count float64
+}
+
+// quantile estimates s.Compute.Quantile from the bucket deltas of the spec's inputs.
+// Buckets with the same `le` are summed across the remaining labels (all queues, controllers, ...).
+func quantile(s spec.SLISpec, start, end map[string]float64) (float64, error) {
+	q := s.Compute.Quantile
+	if q <= 0 || q > 1 {
+		return 0, fmt.Errorf("quantile %v out of range (0, 1]", q)
+	}
+
+	byLE := map[float64]float64{}
+	for _, in := range s.Inputs {
+		for key, v := range end {
+			labels, ok := matchSeries(key, in)
+			if !ok {
+				continue
+			}
+			le, err := strconv.ParseFloat(labels["le"], 64)
+			if err != nil {
+				continue // not a bucket series
+			}
+			byLE[le] += counterDelta(start[key], v)
+		}
+	}
+	if len(byLE) == 0 {
+		return 0, slo.Errorf(slo.KindMetricMissing, "no histogram buckets")
+	}
+
+	buckets := make([]bucket, 0, len(byLE))
+	for le, c := range byLE {
+		buckets = append(buckets, bucket{le: le, count: c})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].le < buckets[j].le })
+	return bucketQuantile(q, buckets)
+}
+
+// bucketQuantile follows PromQL histogram_quantile: linear interpolation inside the bucket that
+// holds the rank, the lower bound of the first bucket is 0, and a rank in the +Inf bucket
+// returns the highest finite bound.
+func bucketQuantile(q float64, buckets []bucket) (float64, error) {
+	last := buckets[len(buckets)-1]
+	if !math.IsInf(last.le, 1) {
+		return 0, slo.Errorf(slo.KindParse, "histogram has no +Inf bucket")
+	}
+	total := last.count
+	if total == 0 {
+		return 0, errors.New("no observations in window")
+	}
+
+	rank := q * total
+	prevLE, prevCount := 0.0, 0.0
+	for i, b := range buckets {
+		if b.count < rank {
+			prevLE, prevCount = b.le, b.count
+			continue
+		}
+		if math.IsInf(b.le, 1) {
+			if i == 0 {
+				return 0, slo.Errorf(slo.KindParse, "only a +Inf bucket")
+			}
+			return prevLE, nil
+		}
+		if b.count == prevCount {
+			return b.le, nil
+		}
+		if i == 0 && b.le <= 0 {
+			return b.le, nil
+		}
+		return prevLE + (b.le-prevLE)*(rank-prevCount)/(b.count-prevCount), nil
+	}
+	return prevLE, nil
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+func TestQuantileFromBucketDeltas(t *testing.T) {
+	s := spec.SLISpec{
+		ID:      "queue_p90",
+		Inputs:  []spec.MetricRef{spec.PromMetric("workqueue_queue_duration_seconds_bucket", nil)},
+		Compute: spec.ComputeSpec{Mode: spec.ComputeQuantile, Quantile: 0.9},
+	}
+	start := map[string]float64{
+		`workqueue_queue_duration_seconds_bucket{le="0.1",name="a"}`:  5,
+		`workqueue_queue_duration_seconds_bucket{le="1",name="a"}`:    5,
+		`workqueue_queue_duration_seconds_bucket{le="+Inf",name="a"}`: 5,
+	}
+	// window: 10 observations <= 0.1s (split over two queues), 10 in (0.1, 1]
+	end := map[string]float64{
+		`workqueue_queue_duration_seconds_bucket{le="0.1",name="a"}`:  10,
+		`workqueue_queue_duration_seconds_bucket{le="1",name="a"}`:    20,
+		`workqueue_queue_duration_seconds_bucket{le="+Inf",name="a"}`: 20,
+		`workqueue_queue_duration_seconds_bucket{le="0.1",name="b"}`:  5,
+		`workqueue_queue_duration_seconds_bucket{le="1",name="b"}`:    5,
+		`workqueue_queue_duration_seconds_bucket{le="+Inf",name="b"}`: 5,
+		`workqueue_queue_duration_seconds_bucket`:                     60,
+	}
+
+	got, err := quantile(s, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rank 18 of 20 => 8/10 of the way through (0.1, 1]
+	if want := 0.1 + 0.9*0.8; math.Abs(got-want) > 1e-9 {
+		t.Fatalf("p90 = %v, want %v", got, want)
+	}
+
+	if _, err := quantile(s, end, end); err == nil {
+		t.Fatal("expected an error for a window without observations")
+	}
+}
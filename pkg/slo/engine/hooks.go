@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// Hooks enrich a run without replacing the fetcher or the writer, e.g. tag the summary with the
+// git SHA, the cluster version or the node count. Every field is optional.
+//
+// A hook error (or panic) is a measurement problem, not a test failure: it becomes a summary
+// warning and the run goes on.
+type Hooks struct {
+	// OnStartSnapshot / OnEndSnapshot see each snapshot before it is evaluated and may change it,
+	// e.g. add a value read from elsewhere.
+	OnStartSnapshot func(ctx context.Context, s *fetch.Sample) error
+	OnEndSnapshot   func(ctx context.Context, s *fetch.Sample) error
+	// OnResult sees the summary before it is written, also when a fetch failed. Config.Tags may be
+	// set (it is never nil here and not shared with the caller), as may Warnings and Results.
+	OnResult func(ctx context.Context, s *summary.Summary) error
+}
+
+// Chain runs hooks in order.
+type Chain []Hooks
+
+// Use appends hooks to the engine's chain and returns the engine.
+func (e *Engine) Use(hooks ...Hooks) *Engine {
+	e.hooks = append(e.hooks, hooks...)
+	return e
+}
+
+// StartSnapshot runs the OnStartSnapshot hooks and returns their errors as warnings.
+func (c Chain) StartSnapshot(ctx context.Context, s *fetch.Sample) []string {
+	var warnings []string
+	for i, h := range c {
+		if h.OnStartSnapshot != nil {
+			warnings = appendHookErr(warnings, "OnStartSnapshot", i, func() error { return h.OnStartSnapshot(ctx, s) })
+		}
+	}
+	return warnings
+}
+
+// EndSnapshot runs the OnEndSnapshot hooks and returns their errors as warnings.
+func (c Chain) EndSnapshot(ctx context.Context, s *fetch.Sample) []string {
+	var warnings []string
+	for i, h := range c {
+		if h.OnEndSnapshot != nil {
+			warnings = appendHookErr(warnings, "OnEndSnapshot", i, func() error { return h.OnEndSnapshot(ctx, s) })
+		}
+	}
+	return warnings
+}
+
+// Result runs the OnResult hooks on sum and appends their errors to its warnings. Execute calls it;
+// callers that build a summary themselves (e.g. for an aborted window) call it before writing.
+func (c Chain) Result(ctx context.Context, sum *summary.Summary) {
+	if len(c) == 0 {
+		return
+	}
+	tags := maps.Clone(sum.Config.Tags)
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	sum.Config.Tags = tags
+	for i, h := range c {
+		if h.OnResult != nil {
+			sum.Warnings = appendHookErr(sum.Warnings, "OnResult", i, func() error { return h.OnResult(ctx, sum) })
+		}
+	}
+}
+
+func appendHookErr(warnings []string, name string, i int, fn func() error) []string {
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return fn()
+	}()
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("hook %s[%d] failed: %v", name, i, err))
+	}
+	return warnings
+}
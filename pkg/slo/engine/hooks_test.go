@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+func TestHooks(t *testing.T) {
+	nodes := spec.PromMetric("cluster_nodes", nil)
+	specs := []spec.SLISpec{{
+		ID: "nodes", Kind: spec.KindGauge, Inputs: []spec.MetricRef{nodes}, Compute: spec.ComputeSpec{Mode: spec.ComputeEnd},
+	}}
+	tags := map[string]string{"suite": "e2e"}
+	var order []string
+
+	f := &seqFetcher{{}, {}}
+	eng := New(f, nopWriter{}, nil).Use(
+		Hooks{
+			OnStartSnapshot: func(_ context.Context, s *fetch.Sample) error {
+				order = append(order, "start")
+				s.Values[nodes.Key] = 2
+				return errors.New("no cluster")
+			},
+			OnEndSnapshot: func(_ context.Context, s *fetch.Sample) error {
+				order = append(order, "end")
+				s.Values[nodes.Key] = 3
+				return nil
+			},
+		},
+		Hooks{OnResult: func(_ context.Context, s *summary.Summary) error {
+			order = append(order, "result")
+			s.Config.Tags["git_sha"] = "abc123"
+			panic("boom")
+		}},
+	)
+	start := time.Unix(1700000000, 0)
+	sum, err := eng.Execute(context.Background(), ExecuteRequest{
+		Config: RunConfig{StartedAt: start, FinishedAt: start.Add(time.Minute), Tags: tags},
+		Specs:  specs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(order, ",") != "start,end,result" {
+		t.Errorf("hook order = %v", order)
+	}
+	if r := sum.Results[0]; r.Value == nil || *r.Value != 3 {
+		t.Errorf("end snapshot hook value: %+v", r)
+	}
+	if sum.Config.Tags["git_sha"] != "abc123" || tags["git_sha"] != "" {
+		t.Errorf("tags = %v, caller's = %v", sum.Config.Tags, tags)
+	}
+	want := []string{"hook OnStartSnapshot[0] failed: no cluster", "hook OnResult[1] failed: panic: boom"}
+	if strings.Join(sum.Warnings, "|") != strings.Join(want, "|") {
+		t.Errorf("warnings = %q", sum.Warnings)
+	}
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/slo/common/promkey"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+// matchSeries reports the labels of key when it is a labeled series of the input `in`:
+// same metric name and every label of the input present with the same value.
+// Bare (aggregated) names never match.
+func matchSeries(key string, in spec.MetricRef) (map[string]string, bool) {
+	if !strings.Contains(key, "{") {
+		return nil, false
+	}
+	name, want, err := promkey.Parse(in.Key)
+	if err != nil {
+		return nil, false
+	}
+	n, labels, err := promkey.Parse(key)
+	if err != nil || n != name {
+		return nil, false
+	}
+	for k, v := range want {
+		if labels[k] != v {
+			return nil, false
+		}
+	}
+	return labels, true
+}
+
+// lookup returns the value of an input. An exact series key is used as is; a label selector
+// that is a subset of the real labels (e.g. rest_client_requests_total{method="PATCH"} while the
+// series also carry code/host) sums the matching series. No matching series is missing, unless
+// the input is ZeroIfNoSeries and the metric is exposed: then the value is 0.
+func lookup(values map[string]float64, in spec.MetricRef) (float64, bool) {
+	if v, ok := values[in.Key]; ok {
+		return v, true
+	}
+	name, _, err := promkey.Parse(in.Key)
+	if err != nil || name == in.Key {
+		return 0, false
+	}
+	var sum float64
+	matched := false
+	for key, v := range values {
+		if _, ok := matchSeries(key, in); ok {
+			sum += v
+			matched = true
+		}
+	}
+	if matched {
+		return sum, true
+	}
+	if _, exposed := values[name]; exposed && in.ZeroIfNoSeries {
+		return 0, true
+	}
+	return 0, false
+}
+
+// counterDelta is end - start for one series; a series that appeared in the window starts
+// from zero and a counter reset counts from the reset.
+func counterDelta(start, end float64) float64 {
+	if d := end - start; d >= 0 {
+		return d
+	}
+	return end
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
 )
 
 type RunMode struct {
@@ -20,6 +21,20 @@ type RunConfig struct {
 	Tags          map[string]string
 	Format        string
 	EvidencePaths map[string]string
+
+	// Disruptions injected during the window (copied into Summary.Disruptions).
+	Disruptions []summary.Disruption
+	// Load applied during the window (optional, copied into Summary.Load).
+	// delta_counter results get a "per_object" field when Load.Objects > 0.
+	Load *summary.LoadReport
+	// Phases evaluated by the caller (optional, copied into Summary.Phases).
+	Phases []summary.Phase
+	// Convergences observed by the caller (optional, copied into Summary.Convergences).
+	Convergences []summary.Convergence
+	// Waits timed by the caller (optional, copied into Summary.Waits).
+	Waits []summary.Wait
+	// Extras recorded by the caller (optional, copied into Summary.Extras).
+	Extras map[string]float64
 }
 
 type ExecuteRequest struct {
@@ -0,0 +1,92 @@
+package slo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies a measurement failure, e.g. to break down skipped SLIs on a dashboard.
+// It is recorded as summary.SLIResult.ErrorKind.
+type Kind string
+
+const (
+	KindFetch         Kind = "fetch"          // the metrics could not be obtained (network, status, timeout)
+	KindParse         Kind = "parse"          // the metrics were obtained but could not be read
+	KindMetricMissing Kind = "metric_missing" // an input metric is not in the snapshots
+	KindPolicy        Kind = "policy"         // an SLI rule at level fail was violated
+	KindWrite         Kind = "write"          // the summary or an artifact could not be written
+)
+
+// Sentinel errors of the kinds, for errors.Is. Errors made by Wrap and Errorf match the sentinel of
+// their kind while keeping their own message.
+var (
+	ErrFetch         = errors.New("fetch failed")
+	ErrParse         = errors.New("parse failed")
+	ErrMetricMissing = errors.New("metric missing")
+	ErrPolicy        = errors.New("policy failed")
+	ErrWrite         = errors.New("write failed")
+)
+
+// kinds lists the kinds in the order KindOf tries their sentinels.
+var kinds = []struct {
+	kind     Kind
+	sentinel error
+}{
+	{KindFetch, ErrFetch},
+	{KindParse, ErrParse},
+	{KindMetricMissing, ErrMetricMissing},
+	{KindPolicy, ErrPolicy},
+	{KindWrite, ErrWrite},
+}
+
+// Error is an error classified with a Kind.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is reports whether target is the sentinel of e's kind.
+func (e *Error) Is(target error) bool {
+	for _, k := range kinds {
+		if k.kind == e.Kind {
+			return target == k.sentinel
+		}
+	}
+	return false
+}
+
+// Wrap classifies err as kind (nil => nil). An error already classified keeps its kind, so the
+// innermost classification (e.g. parse under a fetch) wins.
+func Wrap(kind Kind, err error) error {
+	if err == nil || KindOf(err) != "" {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// Errorf is fmt.Errorf classified as kind.
+func Errorf(kind Kind, format string, args ...any) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// KindOf returns the kind of err: of the outermost *Error in its chain, else of the first sentinel it
+// wraps; "" when err is nil or unclassified.
+func KindOf(err error) Kind {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	for _, k := range kinds {
+		if errors.Is(err, k.sentinel) {
+			return k.kind
+		}
+	}
+	return ""
+}
@@ -0,0 +1,24 @@
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorKinds(t *testing.T) {
+	parse := Errorf(KindParse, "line 3: bad value")
+	err := fmt.Errorf("scrape http://x/metrics: %w", Wrap(KindFetch, parse))
+	if !errors.Is(err, ErrParse) || errors.Is(err, ErrFetch) || KindOf(err) != KindParse {
+		t.Errorf("the parse classification must survive wrapping: kind %q", KindOf(err))
+	}
+	if err.Error() != "scrape http://x/metrics: line 3: bad value" {
+		t.Errorf("message changed: %q", err)
+	}
+	if got := KindOf(fmt.Errorf("upload: %w", ErrWrite)); got != KindWrite {
+		t.Errorf("KindOf(sentinel) = %q", got)
+	}
+	if KindOf(errors.New("boom")) != "" || Wrap(KindFetch, nil) != nil {
+		t.Error("unclassified and nil errors must stay so")
+	}
+}
@@ -0,0 +1,293 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+// GrafanaOptions tunes GrafanaDashboard.
+type GrafanaOptions struct {
+	// Title of the dashboard ("" => "SLO lab").
+	Title string
+	// UID of the dashboard ("" => derived from Title, so regenerating updates the same dashboard).
+	UID string
+	// Selector adds label matchers to every query, e.g. `suite="e2e"`.
+	Selector string
+}
+
+// Dashboard is the subset of the Grafana dashboard model GrafanaDashboard fills in. It imports as
+// is (Dashboards > Import) and provisions from a file.
+type Dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags"`
+	Editable      bool       `json:"editable"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Time          TimeRange  `json:"time"`
+	Templating    Templating `json:"templating"`
+	Panels        []Panel    `json:"panels"`
+}
+
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+// Variable is a dashboard template variable.
+type Variable struct {
+	Name       string      `json:"name"`
+	Label      string      `json:"label,omitempty"`
+	Type       string      `json:"type"`
+	Query      string      `json:"query"`
+	Datasource *Datasource `json:"datasource,omitempty"`
+	Multi      bool        `json:"multi,omitempty"`
+	IncludeAll bool        `json:"includeAll,omitempty"`
+	Refresh    int         `json:"refresh,omitempty"`
+}
+
+type Datasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+// Panel is a row or a time series panel.
+type Panel struct {
+	ID          int          `json:"id"`
+	Type        string       `json:"type"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	GridPos     GridPos      `json:"gridPos"`
+	Datasource  *Datasource  `json:"datasource,omitempty"`
+	Targets     []Target     `json:"targets,omitempty"`
+	FieldConfig *FieldConfig `json:"fieldConfig,omitempty"`
+}
+
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type Target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+}
+
+type FieldConfig struct {
+	Defaults FieldDefaults `json:"defaults"`
+}
+
+type FieldDefaults struct {
+	Unit       string         `json:"unit,omitempty"`
+	Thresholds Thresholds     `json:"thresholds"`
+	Custom     map[string]any `json:"custom,omitempty"`
+}
+
+type Thresholds struct {
+	Mode  string          `json:"mode"`
+	Steps []ThresholdStep `json:"steps"`
+}
+
+// ThresholdStep colors values from Value up (nil => from -Inf).
+type ThresholdStep struct {
+	Color string   `json:"color"`
+	Value *float64 `json:"value"`
+}
+
+// Grafana colors of the SLI verdicts.
+const (
+	colorPass = "green"
+	colorWarn = "orange"
+	colorFail = "red"
+)
+
+// grafanaUnits maps SLI units to Grafana unit ids; others are shown as a custom suffix.
+var grafanaUnits = map[string]string{
+	"seconds": "s",
+	"bytes":   "bytes",
+	"ratio":   "percentunit",
+	"1/s":     "ops",
+	"count":   "short",
+	"items":   "short",
+}
+
+// GrafanaDashboard builds a dashboard over the series of WriteOpenMetrics (backfilled into
+// Prometheus): one time series panel of slo_sli_value per spec with its judge rules drawn as
+// threshold lines (red where a fail rule is breached, orange for warn), regular SLIs first, then
+// resource measurements. Template variables select the data source and test cases.
+func GrafanaDashboard(specs []spec.SLISpec, opts GrafanaOptions) Dashboard {
+	title := opts.Title
+	if title == "" {
+		title = "SLO lab"
+	}
+	uid := opts.UID
+	if uid == "" {
+		sum := sha256.Sum256([]byte(title))
+		uid = "slolab-" + hex.EncodeToString(sum[:])[:8]
+	}
+	ds := &Datasource{Type: "prometheus", UID: "${datasource}"}
+
+	d := Dashboard{
+		UID:           uid,
+		Title:         title,
+		Tags:          []string{"slo"},
+		Editable:      true,
+		SchemaVersion: 39,
+		Time:          TimeRange{From: "now-30d", To: "now"},
+		Templating: Templating{List: []Variable{
+			{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"},
+			{
+				Name: "test_case", Label: "Test case", Type: "query", Datasource: ds,
+				Query: fmt.Sprintf("label_values(%s, test_case)", MetricSLIValue),
+				Multi: true, IncludeAll: true, Refresh: 2,
+			},
+		}},
+	}
+
+	selector := `test_case=~"$test_case"`
+	if s := strings.TrimSpace(opts.Selector); s != "" {
+		selector += "," + s
+	}
+
+	const perRow, width, height = 2, 12, 8
+	id, y := 0, 0
+	addRow := func(name string, specs []spec.SLISpec) {
+		if len(specs) == 0 {
+			return
+		}
+		id++
+		d.Panels = append(d.Panels, Panel{ID: id, Type: "row", Title: name, GridPos: GridPos{H: 1, W: 24, Y: y}})
+		y++
+		for i, s := range specs {
+			id++
+			d.Panels = append(d.Panels, sliPanel(id, s, selector, ds, GridPos{
+				H: height, W: width, X: (i % perRow) * width, Y: y + (i/perRow)*height,
+			}))
+		}
+		y += (len(specs) + perRow - 1) / perRow * height
+	}
+	var slis, resources []spec.SLISpec
+	for _, s := range specs {
+		if s.Category == spec.CategoryResource {
+			resources = append(resources, s)
+		} else {
+			slis = append(slis, s)
+		}
+	}
+	addRow("SLIs", slis)
+	addRow("Resources", resources)
+	return d
+}
+
+// WriteGrafanaDashboard writes GrafanaDashboard as indented JSON.
+func WriteGrafanaDashboard(w io.Writer, specs []spec.SLISpec, opts GrafanaOptions) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(GrafanaDashboard(specs, opts))
+}
+
+func sliPanel(id int, s spec.SLISpec, selector string, ds *Datasource, pos GridPos) Panel {
+	title := s.Title
+	if title == "" {
+		title = s.ID
+	}
+	desc := s.Description
+	if s.Owner != "" {
+		desc = strings.TrimSpace(desc + "\n\nOwner: " + s.Owner)
+	}
+	var rules []spec.Rule
+	if s.Judge != nil {
+		rules = s.Judge.Rules
+	}
+	steps := thresholdSteps(rules)
+	thresholdsStyle := "off"
+	if len(steps) > 1 {
+		thresholdsStyle = "line+area"
+	}
+	// one point per run: draw the points, the line only connects runs
+	custom := map[string]any{
+		"drawStyle":       "line",
+		"showPoints":      "always",
+		"thresholdsStyle": map[string]string{"mode": thresholdsStyle},
+	}
+	unit, ok := grafanaUnits[s.Unit]
+	if !ok && s.Unit != "" {
+		unit = "suffix: " + s.Unit
+	}
+	return Panel{
+		ID:          id,
+		Type:        "timeseries",
+		Title:       title,
+		Description: desc,
+		GridPos:     pos,
+		Datasource:  ds,
+		Targets: []Target{{
+			RefID:        "A",
+			Expr:         fmt.Sprintf(`%s{sli=%q,%s}`, MetricSLIValue, s.ID, selector),
+			LegendFormat: "{{test_case}}",
+		}},
+		FieldConfig: &FieldConfig{Defaults: FieldDefaults{
+			Unit:       unit,
+			Thresholds: Thresholds{Mode: "absolute", Steps: steps},
+			Custom:     custom,
+		}},
+	}
+}
+
+// thresholdSteps turns judge rules into Grafana threshold steps: the rule targets split the value
+// axis into ranges, each colored by the verdict a value inside it gets (fail dominates warn, as in
+// the engine). Values exactly on a target may differ (Grafana steps start at their value).
+func thresholdSteps(rules []spec.Rule) []ThresholdStep {
+	var targets []float64
+	for _, r := range rules {
+		if r.Metric == "" || r.Metric == "value" {
+			targets = append(targets, r.Target)
+		}
+	}
+	slices.Sort(targets)
+	targets = slices.Compact(targets)
+
+	colorAt := func(v float64) string {
+		color := colorPass
+		for _, r := range rules {
+			if (r.Metric != "" && r.Metric != "value") || !r.Op.Holds(v, r.Target) {
+				continue
+			}
+			switch r.Level {
+			case spec.LevelFail:
+				return colorFail
+			case spec.LevelWarn:
+				color = colorWarn
+			}
+		}
+		return color
+	}
+
+	if len(targets) == 0 {
+		return []ThresholdStep{{Color: colorPass}}
+	}
+	steps := []ThresholdStep{{Color: colorAt(targets[0] - 1)}}
+	for i, t := range targets {
+		above := t + 1
+		if i+1 < len(targets) {
+			above = (t + targets[i+1]) / 2
+		}
+		if c := colorAt(above); c != steps[len(steps)-1].Color {
+			steps = append(steps, ThresholdStep{Color: c, Value: &t})
+		}
+	}
+	return steps
+}
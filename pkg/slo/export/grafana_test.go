@@ -0,0 +1,81 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+func TestThresholdSteps(t *testing.T) {
+	for name, tc := range map[string]struct {
+		rules []spec.Rule
+		want  string // color@value ...
+	}{
+		"none":        {nil, "green"},
+		"upper bound": {[]spec.Rule{{Op: spec.OpGT, Target: 0, Level: spec.LevelFail}}, "green red@0"},
+		"warn then fail": {[]spec.Rule{
+			{Op: spec.OpGT, Target: 5, Level: spec.LevelWarn},
+			{Op: spec.OpGT, Target: 10, Level: spec.LevelFail},
+		}, "green orange@5 red@10"},
+		"lower bound": {[]spec.Rule{{Op: spec.OpLT, Target: 1, Level: spec.LevelWarn}}, "orange green@1"},
+		"other field": {[]spec.Rule{{Metric: "p99", Op: spec.OpGT, Target: 1, Level: spec.LevelFail}}, "green"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			for _, s := range thresholdSteps(tc.rules) {
+				if s.Value == nil {
+					got = append(got, s.Color)
+				} else {
+					got = append(got, s.Color+"@"+strconv.FormatFloat(*s.Value, 'g', -1, 64))
+				}
+			}
+			if strings.Join(got, " ") != tc.want {
+				t.Errorf("steps = %v, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGrafanaDashboard(t *testing.T) {
+	specs := []spec.SLISpec{
+		{ID: "reconcile_error_delta", Unit: "count", Judge: &spec.JudgeSpec{Rules: []spec.Rule{
+			{Op: spec.OpGT, Target: 0, Level: spec.LevelFail},
+		}}},
+		{ID: "convergence_p99", Title: "convergence p99", Unit: "seconds"},
+		{ID: "process_resident_memory_max", Unit: "bytes", Category: spec.CategoryResource},
+	}
+	var buf bytes.Buffer
+	if err := WriteGrafanaDashboard(&buf, specs, GrafanaOptions{Selector: `suite="e2e"`}); err != nil {
+		t.Fatal(err)
+	}
+	var d Dashboard
+	if err := json.Unmarshal(buf.Bytes(), &d); err != nil {
+		t.Fatal(err)
+	}
+	var layout []string
+	for _, p := range d.Panels {
+		layout = append(layout, p.Type+":"+p.Title)
+	}
+	want := "row:SLIs timeseries:reconcile_error_delta timeseries:convergence p99 row:Resources " +
+		"timeseries:process_resident_memory_max"
+	if strings.Join(layout, " ") != want {
+		t.Errorf("panels = %v", layout)
+	}
+	p := d.Panels[1]
+	if got := p.Targets[0].Expr; got != `slo_sli_value{sli="reconcile_error_delta",test_case=~"$test_case",suite="e2e"}` {
+		t.Errorf("expr = %s", got)
+	}
+	if steps := p.FieldConfig.Defaults.Thresholds.Steps; len(steps) != 2 || steps[1].Color != colorFail {
+		t.Errorf("steps = %+v", steps)
+	}
+	if d.Panels[2].FieldConfig.Defaults.Unit != "s" || d.Panels[2].GridPos.X != 12 || d.Panels[4].GridPos.Y != 10 {
+		t.Errorf("unit/layout: %+v / %+v", d.Panels[2], d.Panels[4].GridPos)
+	}
+	if d.UID == "" || d.UID != GrafanaDashboard(nil, GrafanaOptions{}).UID {
+		t.Errorf("uid %q should be stable for a title", d.UID)
+	}
+}
@@ -0,0 +1,237 @@
+// Package export converts SLO summaries into formats other systems can ingest.
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/slo/common/gzfile"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// Metric families written by WriteOpenMetrics.
+const (
+	MetricSLIValue  = "slo_sli_value"
+	MetricSLIField  = "slo_sli_field"
+	MetricSLIStatus = "slo_sli_status"
+)
+
+// OpenMetricsOptions tunes WriteOpenMetrics.
+type OpenMetricsOptions struct {
+	// IncludeRunID adds a run_id label. It makes every run its own series (one point each),
+	// which is rarely what PromQL analysis wants, so it is off by default.
+	IncludeRunID bool
+}
+
+// sample is one OpenMetrics line.
+type sample struct {
+	labels string // rendered {…}
+	value  float64
+	tsMs   int64
+}
+
+// WriteOpenMetrics writes sums as an OpenMetrics text exposition with timestamps
+// (the window end of each summary), suitable for backfilling with
+// `promtool tsdb create-blocks-from openmetrics <file> <data dir>`.
+//
+// Per result it emits slo_sli_value (when a value exists), slo_sli_field per extra field,
+// and slo_sli_status{status=...} = 1 (plus error_kind for a classified skip). Labels are sli plus
+// the run tags (except run_id, see OpenMetricsOptions); see tagLabels for how tag keys become label
+// names. Samples are grouped per family and sorted by time, and duplicate series/timestamp pairs
+// are dropped, as promtool requires.
+func WriteOpenMetrics(w io.Writer, sums []summary.Summary, opts OpenMetricsOptions) error {
+	families := map[string][]sample{}
+	seen := map[string]bool{}
+	add := func(family string, labels map[string]string, v float64, tsMs int64) {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return
+		}
+		l := renderLabels(labels)
+		key := family + l + "@" + strconv.FormatInt(tsMs, 10)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		families[family] = append(families[family], sample{labels: l, value: v, tsMs: tsMs})
+	}
+
+	for _, s := range sums {
+		ts := s.Config.FinishedAt
+		if ts.IsZero() {
+			ts = s.GeneratedAt
+		}
+		tsMs := ts.UnixMilli()
+
+		base := tagLabels(s.Config.Tags)
+		if opts.IncludeRunID && s.Config.RunID != "" {
+			base["run_id"] = s.Config.RunID
+		}
+
+		for _, r := range s.Results {
+			labels := copyLabels(base)
+			labels["sli"] = r.ID
+			if r.Unit != "" {
+				labels["unit"] = r.Unit
+			}
+
+			if r.Value != nil {
+				add(MetricSLIValue, labels, *r.Value, tsMs)
+			}
+			for field, v := range r.Fields {
+				fl := copyLabels(labels)
+				fl["field"] = field
+				add(MetricSLIField, fl, v, tsMs)
+			}
+			sl := copyLabels(labels)
+			sl["status"] = string(r.Status)
+			if r.ErrorKind != "" {
+				sl["error_kind"] = r.ErrorKind
+			}
+			add(MetricSLIStatus, sl, 1, tsMs)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	help := map[string]string{
+		MetricSLIValue:  "SLI value measured over the test window.",
+		MetricSLIField:  "Additional named SLI fields (e.g. percentiles).",
+		MetricSLIStatus: "SLI evaluation status (1 for the recorded status).",
+	}
+	for _, family := range []string{MetricSLIValue, MetricSLIField, MetricSLIStatus} {
+		samples := families[family]
+		if len(samples) == 0 {
+			continue
+		}
+		sort.SliceStable(samples, func(i, j int) bool {
+			if samples[i].labels != samples[j].labels {
+				return samples[i].labels < samples[j].labels
+			}
+			return samples[i].tsMs < samples[j].tsMs
+		})
+		_, _ = fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", family, help[family], family)
+		for _, sm := range samples {
+			_, _ = fmt.Fprintf(bw, "%s%s %s %s\n", family, sm.labels,
+				strconv.FormatFloat(sm.value, 'g', -1, 64), formatTimestamp(sm.tsMs))
+		}
+	}
+	_, _ = fmt.Fprintln(bw, "# EOF")
+	return bw.Flush()
+}
+
+// LoadSummaries loads every *.json (or *.json.gz) summary under dir (recursively).
+// Files that are not valid summaries (e.g. artifacts-index.json) are reported in skipped, not failed on.
+func LoadSummaries(dir string) (sums []summary.Summary, skipped []error, err error) {
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() || !gzfile.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		s, err := summary.Load(path)
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("%s: %w", path, err))
+			return nil
+		}
+		sums = append(sums, *s)
+		return nil
+	})
+	return sums, skipped, err
+}
+
+func renderLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(labels[k]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// reservedLabels are set by WriteOpenMetrics itself; a tag must never overwrite them.
+var reservedLabels = map[string]bool{
+	"sli": true, "unit": true, "field": true, "status": true, "error_kind": true, "run_id": true,
+}
+
+// tagLabels maps run tags to labels. The run_id tag is dropped (the label comes from
+// OpenMetricsOptions.IncludeRunID), keys are sanitized, and a key that sanitizes to a reserved
+// label or to a name starting with "__" gets a "tag_" prefix. When two tags still end up with the
+// same name, the one with the smaller key wins, so the output does not depend on map order.
+func tagLabels(tags map[string]string) map[string]string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		if k != "run_id" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]string, len(keys))
+	for _, k := range keys {
+		name := sanitizeLabelName(k)
+		if reservedLabels[name] || strings.HasPrefix(name, "__") {
+			name = "tag_" + name
+		}
+		if _, dup := out[name]; !dup {
+			out[name] = tags[k]
+		}
+	}
+	return out
+}
+
+// sanitizeLabelName maps a tag key to a valid label name ([a-zA-Z_][a-zA-Z0-9_]*).
+func sanitizeLabelName(k string) string {
+	var b strings.Builder
+	for i, c := range k {
+		ok := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')
+		if ok {
+			b.WriteRune(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+func copyLabels(in map[string]string) map[string]string {
+	out := make(map[string]string, len(in)+2)
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+// formatTimestamp renders milliseconds as OpenMetrics seconds.
+func formatTimestamp(ms int64) string {
+	return strconv.FormatFloat(float64(ms)/1000, 'f', 3, 64)
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+func TestWriteOpenMetrics(t *testing.T) {
+	v := 3.0
+	at := time.Unix(1700000000, 0)
+	s := summary.Summary{
+		SchemaVersion: summary.CurrentSchemaVersion,
+		Config: summary.RunConfig{
+			RunID:      "r1",
+			FinishedAt: at,
+			Tags:       map[string]string{"suite": "e2e", "run_id": "r1", "test-case": `say "hi"`},
+		},
+		Results: []summary.SLIResult{
+			{ID: "reconcile_total_delta", Unit: "count", Value: &v, Status: summary.StatusPass},
+			{ID: "missing", Status: summary.StatusSkip, ErrorKind: "metric_missing"},
+		},
+	}
+
+	var b strings.Builder
+	// the same summary twice must not produce duplicate samples
+	if err := WriteOpenMetrics(&b, []summary.Summary{s, s}, OpenMetricsOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	wantValue := `slo_sli_value{sli="reconcile_total_delta",suite="e2e",test_case="say \"hi\"",unit="count"} 3 1700000000.000`
+	if !strings.Contains(out, wantValue+"\n") {
+		t.Fatalf("missing value line %q in:\n%s", wantValue, out)
+	}
+	if strings.Count(out, "slo_sli_value{") != 1 {
+		t.Fatalf("expected exactly one value sample:\n%s", out)
+	}
+	if strings.Contains(out, "run_id") {
+		t.Fatalf("run_id must be omitted by default:\n%s", out)
+	}
+	wantStatus := `slo_sli_status{error_kind="metric_missing",sli="missing",status="skip",suite="e2e",` +
+		`test_case="say \"hi\""} 1`
+	if !strings.Contains(out, wantStatus) {
+		t.Fatalf("missing status line:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Fatalf("missing # EOF terminator")
+	}
+}
+
+func TestWriteOpenMetricsTagCollisions(t *testing.T) {
+	v := 1.0
+	s := summary.Summary{
+		Config: summary.RunConfig{
+			FinishedAt: time.Unix(1700000000, 0),
+			Tags:       map[string]string{"sli": "from-tag", "status": "x", "__name__": "y", "a-b": "1", "a.b": "2"},
+		},
+		Results: []summary.SLIResult{{ID: "p99", Value: &v, Status: summary.StatusPass}},
+	}
+
+	var b strings.Builder
+	if err := WriteOpenMetrics(&b, []summary.Summary{s}, OpenMetricsOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		`slo_sli_value{a_b="1",sli="p99",tag___name__="y",tag_sli="from-tag",tag_status="x"} 1 `,
+		`slo_sli_status{a_b="1",sli="p99",status="pass",tag___name__="y",tag_sli="from-tag",tag_status="x"} 1 `,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
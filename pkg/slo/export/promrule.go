@@ -0,0 +1,350 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/common/promkey"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+// PrometheusRuleOptions tunes PrometheusRules.
+type PrometheusRuleOptions struct {
+	// Name and Namespace of the PrometheusRule ("" name => "slolab-slos").
+	Name      string
+	Namespace string
+	// Labels of the PrometheusRule, e.g. the ruleSelector of the Prometheus Operator.
+	Labels map[string]string
+	// Selector adds label matchers to every input metric, e.g. `namespace="my-operator-system"`.
+	Selector string
+	// Window of the recorded SLI values that threshold alerts compare (0 => 5m). The e2e window
+	// is the test; in production a delta SLI becomes the increase over Window.
+	Window time.Duration
+	// For of threshold alerts (0 => 10m).
+	For time.Duration
+}
+
+// PrometheusRule is a monitoring.coreos.com/v1 PrometheusRule. It marshals to the JSON form of the
+// manifest (convert to YAML with any JSON to YAML converter, e.g. slocli rules).
+type PrometheusRule struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Metadata   RuleMetadata       `json:"metadata"`
+	Spec       PrometheusRuleSpec `json:"spec"`
+}
+
+type RuleMetadata struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type PrometheusRuleSpec struct {
+	Groups []RuleGroup `json:"groups"`
+}
+
+type RuleGroup struct {
+	Name  string     `json:"name"`
+	Rules []PromRule `json:"rules"`
+}
+
+// PromRule is a recording rule (Record) or an alerting rule (Alert).
+type PromRule struct {
+	Record      string            `json:"record,omitempty"`
+	Alert       string            `json:"alert,omitempty"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// burnWindow is one multi-window burn-rate alert: it fires when the error ratio over both windows
+// exceeds Factor times the error budget (Google SRE workbook, 30 day budget).
+type burnWindow struct {
+	long, short time.Duration
+	factor      float64
+	severity    string
+}
+
+var burnWindows = []burnWindow{
+	{time.Hour, 5 * time.Minute, 14.4, "critical"},
+	{6 * time.Hour, 30 * time.Minute, 6, "critical"},
+	{24 * time.Hour, 2 * time.Hour, 3, "warning"},
+	{72 * time.Hour, 6 * time.Hour, 1, "warning"},
+}
+
+// Alert names of the generated rules; the sli label tells the SLIs apart.
+const (
+	AlertBurnRate = "SLOErrorBudgetBurn"
+	AlertBreach   = "SLOObjectiveBreach"
+)
+
+// RecordName is the recording rule of spec id over window, e.g. slo:reconcile_error_ratio:1h.
+func RecordName(id string, window time.Duration) string {
+	return "slo:" + id + ":" + promDuration(window)
+}
+
+// PrometheusRules turns the SLI specs into production rules, so the objectives judged in e2e also
+// drive the alerts:
+//   - a recording rule per SLI over Window (delta counters become increase(), quantiles
+//     histogram_quantile over the bucket increase, derived SLIs are computed from their operands);
+//   - for ratio SLIs (derived ratio or unit "ratio") whose objective is an upper bound, the error
+//     budget is that bound and multi-window burn-rate alerts are generated, with recording rules for
+//     their windows;
+//   - every other judge rule becomes a threshold alert on the recorded value (fail => critical,
+//     warn => warning).
+//
+// Specs whose value cannot be expressed in PromQL (unknown modes, missing operands) are returned in
+// skipped.
+func PrometheusRules(specs []spec.SLISpec, opts PrometheusRuleOptions) (PrometheusRule, []string) {
+	if opts.Name == "" {
+		opts.Name = "slolab-slos"
+	}
+	if opts.Window <= 0 {
+		opts.Window = 5 * time.Minute
+	}
+	if opts.For <= 0 {
+		opts.For = 10 * time.Minute
+	}
+	b := ruleBuilder{byID: map[string]spec.SLISpec{}, selector: strings.Trim(strings.TrimSpace(opts.Selector), "{}")}
+	for _, s := range specs {
+		b.byID[s.ID] = s
+	}
+
+	var recording, alerts []PromRule
+	var skipped []string
+	recorded := map[string]bool{}
+	record := func(s spec.SLISpec, w time.Duration) bool {
+		name := RecordName(s.ID, w)
+		if recorded[name] {
+			return true
+		}
+		expr, ok := b.expr(s, w, 0)
+		if !ok {
+			return false
+		}
+		recorded[name] = true
+		recording = append(recording, PromRule{Record: name, Expr: expr, Labels: map[string]string{"sli": s.ID}})
+		return true
+	}
+
+	for _, s := range specs {
+		if !record(s, opts.Window) {
+			skipped = append(skipped, s.ID)
+			continue
+		}
+		if s.Judge == nil {
+			continue
+		}
+		budgetRule, burn := budget(s)
+		for _, r := range s.Judge.Rules {
+			if r.Metric != "" && r.Metric != "value" {
+				continue
+			}
+			if burn && r == budgetRule {
+				for _, bw := range burnWindows {
+					threshold := bw.factor * r.Target
+					if threshold >= 1 {
+						continue // the ratio cannot exceed 1: this window would never fire
+					}
+					record(s, bw.long)
+					record(s, bw.short)
+					th := strconv.FormatFloat(threshold, 'g', 10, 64) // 14.4*0.01 => 0.144, not 0.14400000000000002
+					alerts = append(alerts, PromRule{
+						Alert: AlertBurnRate,
+						Expr:  fmt.Sprintf("%s > %s and %s > %s", RecordName(s.ID, bw.long), th, RecordName(s.ID, bw.short), th),
+						Labels: map[string]string{
+							"sli": s.ID, "severity": bw.severity,
+							"long_window": promDuration(bw.long), "short_window": promDuration(bw.short),
+						},
+						Annotations: annotations(s, fmt.Sprintf("%s burns its error budget (%s) %sx too fast",
+							s.ID, formatFloat(r.Target), formatFloat(bw.factor))),
+					})
+				}
+				continue
+			}
+			severity := "warning"
+			if r.Level == spec.LevelFail {
+				severity = "critical"
+			}
+			alerts = append(alerts, PromRule{
+				Alert:  AlertBreach,
+				Expr:   fmt.Sprintf("%s %s %s", RecordName(s.ID, opts.Window), promOp(r.Op), formatFloat(r.Target)),
+				For:    promDuration(opts.For),
+				Labels: map[string]string{"sli": s.ID, "severity": severity, "level": string(r.Level)},
+				Annotations: annotations(s, fmt.Sprintf("%s over %s is %s %s (objective level %s)",
+					s.ID, promDuration(opts.Window), r.Op, formatFloat(r.Target), r.Level)),
+			})
+		}
+	}
+
+	groups := []RuleGroup{{Name: "slo-recording", Rules: recording}}
+	if len(alerts) > 0 {
+		groups = append(groups, RuleGroup{Name: "slo-alerts", Rules: alerts})
+	}
+	return PrometheusRule{
+		APIVersion: "monitoring.coreos.com/v1",
+		Kind:       "PrometheusRule",
+		Metadata:   RuleMetadata{Name: opts.Name, Namespace: opts.Namespace, Labels: opts.Labels},
+		Spec:       PrometheusRuleSpec{Groups: groups},
+	}, skipped
+}
+
+// WritePrometheusRules writes PrometheusRules as indented JSON and returns the skipped spec IDs.
+func WritePrometheusRules(w io.Writer, specs []spec.SLISpec, opts PrometheusRuleOptions) ([]string, error) {
+	rule, skipped := PrometheusRules(specs, opts)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return skipped, enc.Encode(rule)
+}
+
+// budget returns the rule whose target is the error budget of a ratio SLI: the fail (else warn)
+// upper bound with a target in (0, 1). ok is false for other SLIs.
+func budget(s spec.SLISpec) (spec.Rule, bool) {
+	ratio := s.Unit == "ratio" || (s.Derived != nil && s.Derived.Op == spec.DerivedRatio)
+	if !ratio || s.Judge == nil {
+		return spec.Rule{}, false
+	}
+	var best spec.Rule
+	found := false
+	for _, r := range s.Judge.Rules {
+		upper := r.Op == spec.OpGT || r.Op == spec.OpGE
+		if (r.Metric != "" && r.Metric != "value") || !upper || r.Target <= 0 || r.Target >= 1 {
+			continue
+		}
+		if !found || (r.Level == spec.LevelFail && best.Level != spec.LevelFail) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+func annotations(s spec.SLISpec, text string) map[string]string {
+	a := map[string]string{"summary": text}
+	if s.Description != "" {
+		a["description"] = s.Description
+	}
+	if s.Owner != "" {
+		a["owner"] = s.Owner
+	}
+	return a
+}
+
+type ruleBuilder struct {
+	byID     map[string]spec.SLISpec
+	selector string
+}
+
+// maxDerivedDepth bounds derived-of-derived expansion (and breaks cycles).
+const maxDerivedDepth = 8
+
+// expr returns the PromQL of s over window w.
+func (b ruleBuilder) expr(s spec.SLISpec, w time.Duration, depth int) (string, bool) {
+	if s.Derived != nil {
+		if depth >= maxDerivedDepth {
+			return "", false
+		}
+		var ops []string
+		for _, id := range s.Derived.Operands {
+			o, ok := b.byID[id]
+			if !ok {
+				return "", false
+			}
+			e, ok := b.expr(o, w, depth+1)
+			if !ok {
+				return "", false
+			}
+			ops = append(ops, "("+e+")")
+		}
+		switch {
+		case s.Derived.Op == spec.DerivedRatio && len(ops) == 2:
+			return ops[0] + " / " + ops[1], true
+		case s.Derived.Op == spec.DerivedRate && len(ops) == 1:
+			return ops[0] + " / " + strconv.Itoa(int(w.Seconds())), true
+		}
+		return "", false
+	}
+	if len(s.Inputs) == 0 {
+		return "", false
+	}
+
+	var terms []string
+	for _, in := range s.Inputs {
+		sel, ok := b.vector(in.Key)
+		if !ok {
+			return "", false
+		}
+		switch s.Compute.Mode {
+		case spec.ComputeDelta:
+			terms = append(terms, fmt.Sprintf("sum(increase(%s[%s]))", sel, promDuration(w)))
+		case spec.ComputeSingle, spec.ComputeEnd:
+			terms = append(terms, fmt.Sprintf("sum(%s)", sel))
+		case spec.ComputeMax:
+			terms = append(terms, fmt.Sprintf("max_over_time(sum(%s)[%s:])", sel, promDuration(w)))
+		case spec.ComputeQuantile:
+			terms = append(terms, fmt.Sprintf("histogram_quantile(%s, sum by (le) (increase(%s[%s])))",
+				formatFloat(s.Compute.Quantile), sel, promDuration(w)))
+		default:
+			return "", false
+		}
+	}
+	if s.Compute.Mode == spec.ComputeQuantile && len(terms) > 1 {
+		return "", false // a quantile of several histograms is not the sum of their quantiles
+	}
+	return strings.Join(terms, " + "), true
+}
+
+// vector returns the PromQL selector of a metric key plus the configured selector.
+func (b ruleBuilder) vector(key string) (string, bool) {
+	name, labels, err := promkey.Parse(key)
+	if err != nil {
+		return "", false
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var matchers []string
+	for _, k := range keys {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", k, labels[k]))
+	}
+	if b.selector != "" {
+		matchers = append(matchers, b.selector)
+	}
+	if len(matchers) == 0 {
+		return name, true
+	}
+	return name + "{" + strings.Join(matchers, ",") + "}", true
+}
+
+// promOp is the PromQL comparison of a judge op.
+func promOp(op spec.Op) string {
+	if op == spec.OpEQ {
+		return "=="
+	}
+	return string(op)
+}
+
+// promDuration formats d as a Prometheus duration (1d, 6h, 30m, 45s).
+func promDuration(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	case d%time.Hour == 0:
+		return strconv.Itoa(int(d/time.Hour)) + "h"
+	case d%time.Minute == 0:
+		return strconv.Itoa(int(d/time.Minute)) + "m"
+	default:
+		return strconv.Itoa(int(d/time.Second)) + "s"
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
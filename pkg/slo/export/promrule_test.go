@@ -0,0 +1,94 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+func TestPrometheusRules(t *testing.T) {
+	errs := spec.SLISpec{ID: "reconcile_errors", Kind: spec.KindDeltaCounter,
+		Inputs:  []spec.MetricRef{{Key: `controller_runtime_reconcile_total{result="error"}`}},
+		Compute: spec.ComputeSpec{Mode: spec.ComputeDelta}}
+	total := spec.SLISpec{ID: "reconcile_total", Kind: spec.KindDeltaCounter,
+		Inputs:  []spec.MetricRef{{Key: "controller_runtime_reconcile_total"}},
+		Compute: spec.ComputeSpec{Mode: spec.ComputeDelta}}
+	specs := []spec.SLISpec{
+		errs, total,
+		{ID: "reconcile_error_ratio", Unit: "ratio", Kind: spec.KindDerived,
+			Derived: &spec.DerivedSpec{Op: spec.DerivedRatio, Operands: []string{"reconcile_errors", "reconcile_total"}},
+			Judge: &spec.JudgeSpec{Rules: []spec.Rule{
+				{Op: spec.OpGT, Target: 0.01, Level: spec.LevelFail},
+				{Op: spec.OpGT, Target: 0.005, Level: spec.LevelWarn},
+			}}},
+		{ID: "convergence_p99", Unit: "seconds", Kind: spec.KindHistogram,
+			Inputs:  []spec.MetricRef{{Key: "convergence_seconds_bucket"}},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeQuantile, Quantile: 0.99},
+			Judge:   &spec.JudgeSpec{Rules: []spec.Rule{{Op: spec.OpGT, Target: 30, Level: spec.LevelFail}}}},
+		{ID: "broken", Derived: &spec.DerivedSpec{Op: spec.DerivedRatio, Operands: []string{"missing", "reconcile_total"}}},
+	}
+
+	rule, skipped := PrometheusRules(specs, PrometheusRuleOptions{Selector: `namespace="sys"`})
+	if len(skipped) != 1 || skipped[0] != "broken" {
+		t.Errorf("skipped = %v", skipped)
+	}
+	if rule.Kind != "PrometheusRule" || rule.Metadata.Name != "slolab-slos" || len(rule.Spec.Groups) != 2 {
+		t.Fatalf("rule = %+v", rule)
+	}
+
+	records := map[string]string{}
+	for _, r := range rule.Spec.Groups[0].Rules {
+		records[r.Record] = r.Expr
+	}
+	wantRatio := `(sum(increase(controller_runtime_reconcile_total{result="error",namespace="sys"}[1h]))) / ` +
+		`(sum(increase(controller_runtime_reconcile_total{namespace="sys"}[1h])))`
+	if got := records[RecordName("reconcile_error_ratio", time.Hour)]; got != wantRatio {
+		t.Errorf("ratio over 1h = %s", got)
+	}
+	wantP99 := `histogram_quantile(0.99, sum by (le) (increase(convergence_seconds_bucket{namespace="sys"}[5m])))`
+	if got := records["slo:convergence_p99:5m"]; got != wantP99 {
+		t.Errorf("p99 = %s", got)
+	}
+
+	var burn, breach []PromRule
+	for _, r := range rule.Spec.Groups[1].Rules {
+		switch r.Alert {
+		case AlertBurnRate:
+			burn = append(burn, r)
+		case AlertBreach:
+			breach = append(breach, r)
+		}
+	}
+	// budget 1%: all four windows stay below a ratio of 1
+	if len(burn) != 4 || burn[0].Labels["severity"] != "critical" || burn[3].Labels["severity"] != "warning" {
+		t.Fatalf("burn alerts = %+v", burn)
+	}
+	if want := "slo:reconcile_error_ratio:1h > 0.144 and slo:reconcile_error_ratio:5m > 0.144"; burn[0].Expr != want {
+		t.Errorf("burn expr = %s", burn[0].Expr)
+	}
+	// the warn bound of the ratio and the p99 bound become threshold alerts
+	if len(breach) != 2 {
+		t.Fatalf("breach alerts = %+v", breach)
+	}
+	if breach[0].Expr != "slo:reconcile_error_ratio:5m > 0.005" || breach[0].Labels["severity"] != "warning" {
+		t.Errorf("warn alert = %+v", breach[0])
+	}
+	p99 := breach[1]
+	if p99.Expr != "slo:convergence_p99:5m > 30" || p99.For != "10m" || p99.Labels["severity"] != "critical" {
+		t.Errorf("p99 alert = %+v", p99)
+	}
+}
+
+func TestPromDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		72 * time.Hour:   "3d",
+		6 * time.Hour:    "6h",
+		30 * time.Minute: "30m",
+		90 * time.Second: "90s",
+	} {
+		if got := promDuration(d); got != want {
+			t.Errorf("promDuration(%v) = %s, want %s", d, got, want)
+		}
+	}
+}
@@ -3,12 +3,36 @@ package fetch
 import (
 	"context"
 	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
 )
 
 // Sample is one snapshot at a point in time.
 type Sample struct {
 	At     time.Time
 	Values map[string]float64 // metricKey -> value
+
+	// Exemplars maps series keys to their OpenMetrics exemplar (only when captured, see
+	// promtext.Options.Exemplars).
+	Exemplars map[string]promtext.Exemplar
+
+	// Provenance describes how the snapshot was obtained (optional).
+	Provenance *Provenance
+}
+
+// Provenance records where a Sample came from, so a surprising number can be traced
+// back to the scrape that produced it.
+type Provenance struct {
+	Fetcher string // e.g. "http", "curl-pod"
+	Target  string // scraped URL or "<ns>/<service>" (a Service is load-balanced: it does not pin a pod)
+	Via     string // intermediary, e.g. "<ns>/<curl pod>" (optional)
+	Parser  string // parser version, e.g. promtext.ParserVersion
+	Format  string // exposition format, e.g. "text", "openmetrics", "protobuf" (scrapes only)
+
+	ScrapedAt time.Time      // when the scrape completed
+	Series    map[string]int // metricKey -> number of series summed into Values[metricKey]
+	// Attempts is the number of scrapes RetryTruncated took (> 1 => earlier ones looked truncated).
+	Attempts int
 }
 
 // MetricsFetcher fetches one snapshot. Implementations decide how to obtain it.
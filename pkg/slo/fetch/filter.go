@@ -0,0 +1,100 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MetricFilter narrows snapshots to the metrics an evaluation reads, so a session or a replay
+// bundle does not keep every series of every scrape.
+type MetricFilter struct {
+	// Include keeps only these metric names, e.g. spec.MetricNames(specs) plus extras (empty => all).
+	Include []string
+	// Exclude drops the metric names matching this regular expression, after Include ("" => none).
+	Exclude string
+}
+
+// Validate reports an invalid Exclude expression.
+func (f MetricFilter) Validate() error {
+	_, err := f.compile()
+	return err
+}
+
+type compiledFilter struct {
+	include map[string]bool
+	exclude *regexp.Regexp
+}
+
+func (f MetricFilter) compile() (compiledFilter, error) {
+	var c compiledFilter
+	if len(f.Include) > 0 {
+		c.include = make(map[string]bool, len(f.Include))
+		for _, n := range f.Include {
+			c.include[n] = true
+		}
+	}
+	if f.Exclude != "" {
+		re, err := regexp.Compile(f.Exclude)
+		if err != nil {
+			return c, fmt.Errorf("metric filter exclude %q: %w", f.Exclude, err)
+		}
+		c.exclude = re
+	}
+	return c, nil
+}
+
+func (c compiledFilter) keep(key string) bool {
+	name, _, _ := strings.Cut(key, "{")
+	if c.include != nil && !c.include[name] {
+		return false
+	}
+	return c.exclude == nil || !c.exclude.MatchString(name)
+}
+
+// FilterMetrics returns a fetcher dropping the series of every metric f does not keep from inner's
+// samples (values, exemplars and provenance series counts). Errors of inner pass through.
+func FilterMetrics(inner MetricsFetcher, f MetricFilter) (MetricsFetcher, error) {
+	c, err := f.compile()
+	if err != nil {
+		return nil, err
+	}
+	return filterFetcher{inner: inner, filter: c}, nil
+}
+
+type filterFetcher struct {
+	inner  MetricsFetcher
+	filter compiledFilter
+}
+
+func (f filterFetcher) Unwrap() MetricsFetcher { return f.inner }
+
+func (f filterFetcher) Fetch(ctx context.Context, at time.Time) (Sample, error) {
+	s, err := f.inner.Fetch(ctx, at)
+	if err != nil {
+		return s, err
+	}
+	s.Values = filterKeys(s.Values, f.filter)
+	s.Exemplars = filterKeys(s.Exemplars, f.filter)
+	if s.Provenance != nil {
+		p := *s.Provenance
+		p.Series = filterKeys(p.Series, f.filter)
+		s.Provenance = &p
+	}
+	return s, nil
+}
+
+func filterKeys[V any](m map[string]V, c compiledFilter) map[string]V {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]V, len(m))
+	for k, v := range m {
+		if c.keep(k) {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package fetch
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFilterMetrics(t *testing.T) {
+	inner := &scriptedFetcher{
+		samples: []Sample{{
+			Values: map[string]float64{
+				"reconcile_total": 3, `reconcile_total{result="error"}`: 3,
+				"workqueue_depth": 1, "go_goroutines": 40, "go_gc_duration_seconds_count": 9,
+			},
+			Provenance: &Provenance{Series: map[string]int{"reconcile_total": 1, "go_goroutines": 1}},
+		}},
+		errs: []error{nil},
+	}
+	f, err := FilterMetrics(inner, MetricFilter{
+		Include: []string{"reconcile_total", "go_goroutines", "go_gc_duration_seconds_count"},
+		Exclude: "^go_gc_",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := f.Fetch(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Values) != 3 || s.Values[`reconcile_total{result="error"}`] != 3 || s.Values["go_goroutines"] != 40 {
+		t.Fatalf("unexpected values %v", s.Values)
+	}
+	if len(s.Provenance.Series) != 2 {
+		t.Fatalf("unexpected series counts %v", s.Provenance.Series)
+	}
+
+	if err := (MetricFilter{Exclude: "("}).Validate(); err == nil {
+		t.Fatal("expected an invalid exclude expression to be an error")
+	}
+}
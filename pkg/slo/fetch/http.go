@@ -0,0 +1,126 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promproto"
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
+)
+
+// HTTPFetcher scrapes a Prometheus metrics endpoint directly over HTTP(S).
+// It is the simplest fetcher: useful for local runs, port-forwards and self tests.
+type HTTPFetcher struct {
+	URL    string
+	Header http.Header
+
+	// Client may be nil (uses a client with a 30s timeout).
+	Client *http.Client
+
+	// OpenMetrics asks for application/openmetrics-text (falling back to the text format). The
+	// response Content-Type picks the parser either way, so a cut-off OpenMetrics body is an error.
+	OpenMetrics bool
+	// Exemplars captures OpenMetrics exemplars into Sample.Exemplars.
+	Exemplars bool
+	// Protobuf asks for the delimited protobuf format first (decoded without text parsing, for
+	// large payloads), then OpenMetrics if set, then text.
+	Protobuf bool
+	// Names keeps only these metric (sample) names, e.g. spec.MetricNames(specs). The body is
+	// parsed as it streams in, so memory is bounded by the kept series (nil => keep all).
+	Names []string
+}
+
+// accept is the Accept header negotiating the preferred formats ("" => the server's default).
+func (f *HTTPFetcher) accept() string {
+	var formats []string
+	if f.Protobuf {
+		formats = append(formats, promproto.ContentType)
+	}
+	if f.OpenMetrics {
+		formats = append(formats, promtext.OpenMetricsContentType+";version=1.0.0;q=0.75")
+	}
+	if len(formats) == 0 {
+		return ""
+	}
+	return strings.Join(append(formats, "text/plain;version=0.0.4;q=0.5", "*/*;q=0.1"), ",")
+}
+
+// NewHTTPFetcher returns a fetcher for url with default client settings.
+func NewHTTPFetcher(url string) *HTTPFetcher {
+	return &HTTPFetcher{URL: url}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, at time.Time) (Sample, error) {
+	client := f.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.URL, nil)
+	if err != nil {
+		return Sample{}, err
+	}
+	if accept := f.accept(); accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	for k, vs := range f.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Sample{}, slo.Wrap(slo.KindFetch, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Sample{}, slo.Errorf(slo.KindFetch, "scrape %s: unexpected status %d: %s", f.URL, resp.StatusCode, body)
+	}
+
+	body := &countingReader{r: resp.Body}
+	prov := &Provenance{Fetcher: "http", Target: f.URL}
+	contentType := resp.Header.Get("Content-Type")
+	var s Sample
+	if promproto.IsContentType(contentType) {
+		s, err = SampleFromProtobufReader(at, body, promproto.Options{Names: f.Names}, prov)
+	} else {
+		opts := promtext.Options{
+			Format:    promtext.FormatFromContentType(contentType),
+			Exemplars: f.Exemplars,
+			Names:     f.Names,
+		}
+		s, err = SampleFromReader(at, body, opts, prov)
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		// the connection closed before Content-Length bytes arrived
+		err = slo.Wrap(slo.KindFetch, fmt.Errorf("%w: %v", ErrTruncatedScrape, err))
+	}
+	if err == nil {
+		err = slo.Wrap(slo.KindFetch, CheckLength(body.n, resp.ContentLength))
+	}
+	if err != nil {
+		return Sample{}, fmt.Errorf("scrape %s: %w", f.URL, err)
+	}
+	return s, nil
+}
+
+// countingReader counts the body bytes read, for the Content-Length check.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,122 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
+)
+
+// ErrTruncatedScrape marks a scrape body that looks cut off (e.g. the curl pod was killed
+// mid-transfer). Such a body often parses fine with silently low sums, so fetchers report it as
+// an error and RetryTruncated scrapes again.
+var ErrTruncatedScrape = errors.New("truncated scrape")
+
+// IsTruncated reports whether err is a suspected truncation (ErrTruncatedScrape or an OpenMetrics
+// body without its EOF marker).
+func IsTruncated(err error) bool {
+	return errors.Is(err, ErrTruncatedScrape) || errors.Is(err, promtext.ErrTruncated)
+}
+
+// CheckLength compares the received body size to the announced Content-Length (< 0 => unknown).
+func CheckLength(received, contentLength int64) error {
+	if contentLength >= 0 && received < contentLength {
+		return fmt.Errorf("%w: received %d of %d bytes", ErrTruncatedScrape, received, contentLength)
+	}
+	return nil
+}
+
+// TruncationOptions controls RetryTruncated.
+type TruncationOptions struct {
+	// Attempts is the number of scrapes per Fetch (default 3).
+	Attempts int
+	// Backoff is the pause between attempts (default 2s).
+	Backoff time.Duration
+	// MinNames is the fewest metric names a complete scrape has (0 => no floor). On top of it, a
+	// scrape with less than half the names of the largest scrape seen so far is suspect.
+	MinNames int
+	Logger   slo.Logger
+}
+
+// RetryTruncated returns a fetcher that scrapes again when inner reports a truncation
+// (IsTruncated) or returns a sample with suspiciously few metric names. After the last attempt
+// the error (or the short sample's ErrTruncatedScrape) is returned; other errors are not retried.
+// The sample's provenance records the attempts taken.
+func RetryTruncated(inner MetricsFetcher, opts TruncationOptions) MetricsFetcher {
+	if opts.Attempts <= 0 {
+		opts.Attempts = 3
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 2 * time.Second
+	}
+	opts.Logger = slo.NewLogger(opts.Logger)
+	return &truncationRetry{inner: inner, opts: opts}
+}
+
+type truncationRetry struct {
+	inner MetricsFetcher
+	opts  TruncationOptions
+
+	mu       sync.Mutex
+	maxNames int
+}
+
+func (r *truncationRetry) Unwrap() MetricsFetcher { return r.inner }
+
+func (r *truncationRetry) Fetch(ctx context.Context, at time.Time) (Sample, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		var s Sample
+		s, err = r.inner.Fetch(ctx, at)
+		if err == nil {
+			err = r.checkNames(s)
+		}
+		if err == nil {
+			if s.Provenance != nil {
+				p := *s.Provenance
+				p.Attempts = attempt
+				s.Provenance = &p
+			}
+			return s, nil
+		}
+		if !IsTruncated(err) || attempt >= r.opts.Attempts {
+			return Sample{}, err
+		}
+		slo.Warnf(r.opts.Logger, "scrape looks truncated (attempt %d/%d), scraping again: %v",
+			attempt, r.opts.Attempts, err)
+		select {
+		case <-ctx.Done():
+			return Sample{}, errors.Join(err, ctx.Err())
+		case <-time.After(r.opts.Backoff):
+		}
+	}
+}
+
+// checkNames applies the metric name count heuristic and remembers the largest count seen.
+func (r *truncationRetry) checkNames(s Sample) error {
+	n := MetricNames(s)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n < r.opts.MinNames || 2*n < r.maxNames {
+		return fmt.Errorf("%w: %d metric names (expected at least %d)",
+			ErrTruncatedScrape, n, max(r.opts.MinNames, (r.maxNames+1)/2))
+	}
+	r.maxNames = max(r.maxNames, n)
+	return nil
+}
+
+// MetricNames counts the distinct metric names of a sample (histogram _bucket/_sum/_count count
+// separately).
+func MetricNames(s Sample) int {
+	names := map[string]bool{}
+	for key := range s.Values {
+		name, _, _ := strings.Cut(key, "{")
+		names[name] = true
+	}
+	return len(names)
+}
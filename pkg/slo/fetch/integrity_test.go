@@ -0,0 +1,49 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type scriptedFetcher struct {
+	samples []Sample
+	errs    []error
+	calls   int
+}
+
+func (f *scriptedFetcher) Fetch(context.Context, time.Time) (Sample, error) {
+	i := f.calls
+	f.calls++
+	return f.samples[i], f.errs[i]
+}
+
+func TestRetryTruncated(t *testing.T) {
+	full := Sample{Values: map[string]float64{"a": 1, "b": 1, "c{x=\"y\"}": 1, "d": 1}, Provenance: &Provenance{}}
+	short := Sample{Values: map[string]float64{"a": 1}, Provenance: &Provenance{}}
+	inner := &scriptedFetcher{
+		samples: []Sample{full, {}, short, full},
+		errs:    []error{nil, ErrTruncatedScrape, nil, nil},
+	}
+	f := RetryTruncated(inner, TruncationOptions{Backoff: time.Millisecond})
+
+	if _, err := f.Fetch(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// a truncation error, then a sample with far fewer names than the first one: both scraped again
+	s, err := f.Fetch(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 4 || s.Provenance.Attempts != 3 || len(s.Values) != 4 {
+		t.Fatalf("expected the third attempt's full sample, got %d calls, %+v", inner.calls, s)
+	}
+
+	other := errors.New("connection refused")
+	inner = &scriptedFetcher{samples: []Sample{{}}, errs: []error{other}}
+	_, err = RetryTruncated(inner, TruncationOptions{}).Fetch(context.Background(), time.Now())
+	if !errors.Is(err, other) || inner.calls != 1 {
+		t.Fatalf("expected other errors to be returned without a retry, got %v after %d calls", err, inner.calls)
+	}
+}
@@ -0,0 +1,49 @@
+package fetch
+
+import (
+	"context"
+	"time"
+)
+
+// Merge returns a fetcher that adds the values of extra fetchers (e.g. Events-derived series) to
+// the primary sample. The primary decides success and provenance; a failing extra fetcher only
+// leaves its series out, so the SLIs reading them are skipped as missing inputs.
+func Merge(primary MetricsFetcher, extra ...MetricsFetcher) MetricsFetcher {
+	if len(extra) == 0 {
+		return primary
+	}
+	return mergeFetcher{primary: primary, extra: extra}
+}
+
+type mergeFetcher struct {
+	primary MetricsFetcher
+	extra   []MetricsFetcher
+}
+
+func (m mergeFetcher) Fetch(ctx context.Context, at time.Time) (Sample, error) {
+	s, err := m.primary.Fetch(ctx, at)
+	if err != nil {
+		return s, err
+	}
+	values := make(map[string]float64, len(s.Values))
+	for k, v := range s.Values {
+		values[k] = v
+	}
+	for _, f := range m.extra {
+		x, err := f.Fetch(ctx, at)
+		if err != nil {
+			continue
+		}
+		for k, v := range x.Values {
+			if _, ok := values[k]; !ok {
+				values[k] = v
+			}
+		}
+	}
+	s.Values = values
+	return s, nil
+}
+
+func (m mergeFetcher) Unwrap() []MetricsFetcher {
+	return append([]MetricsFetcher{m.primary}, m.extra...)
+}
@@ -0,0 +1,34 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fetcherFunc func(context.Context, time.Time) (Sample, error)
+
+func (f fetcherFunc) Fetch(ctx context.Context, at time.Time) (Sample, error) { return f(ctx, at) }
+
+func values(v map[string]float64) fetcherFunc {
+	return func(_ context.Context, at time.Time) (Sample, error) { return Sample{At: at, Values: v}, nil }
+}
+
+func TestMerge(t *testing.T) {
+	failing := fetcherFunc(func(context.Context, time.Time) (Sample, error) {
+		return Sample{}, errors.New("boom")
+	})
+	f := Merge(values(map[string]float64{"a": 1}), failing, values(map[string]float64{"a": 5, "b": 2}))
+	s, err := f.Fetch(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Values) != 2 || s.Values["a"] != 1 || s.Values["b"] != 2 {
+		t.Fatalf("unexpected values %v", s.Values)
+	}
+
+	if _, err := Merge(failing, values(nil)).Fetch(context.Background(), time.Now()); err == nil {
+		t.Fatal("expected the primary error")
+	}
+}
@@ -0,0 +1,130 @@
+package fetch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/clock"
+)
+
+// PeakSource is implemented by fetchers that observed samples between the window edges.
+// The engine uses the peaks for gauges computed with spec.ComputeMax.
+type PeakSource interface {
+	Peaks() map[string]float64
+}
+
+// FindPeaks returns the first PeakSource in f's chain of wrappers. Wrappers expose what they wrap
+// like errors do: Unwrap() MetricsFetcher, or Unwrap() []MetricsFetcher for several fetchers.
+func FindPeaks(f MetricsFetcher) (PeakSource, bool) {
+	switch x := f.(type) {
+	case nil:
+		return nil, false
+	case PeakSource:
+		return x, true
+	case interface{ Unwrap() MetricsFetcher }:
+		return FindPeaks(x.Unwrap())
+	case interface{ Unwrap() []MetricsFetcher }:
+		for _, inner := range x.Unwrap() {
+			if ps, ok := FindPeaks(inner); ok {
+				return ps, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// PeakTracker wraps a fetcher and, between Start and Stop, polls it every Interval to keep
+// the highest value seen per key. Edge fetches (Fetch) also count.
+// Poll errors are ignored: a missed poll only makes the peak less precise.
+type PeakTracker struct {
+	Inner    MetricsFetcher
+	Interval time.Duration // 0 => 10s
+	Clock    clock.Clock   // ticks the polls (nil => clock.Real)
+
+	mu    sync.Mutex
+	peaks map[string]float64
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+func (t *PeakTracker) Fetch(ctx context.Context, at time.Time) (Sample, error) {
+	s, err := t.Inner.Fetch(ctx, at)
+	if err == nil {
+		t.observe(s.Values)
+	}
+	return s, err
+}
+
+// Start forgets earlier peaks and begins polling in the background, so one tracker can serve
+// consecutive windows. Calling Start on a running tracker is a no-op.
+func (t *PeakTracker) Start(ctx context.Context) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop != nil {
+		return
+	}
+	interval := t.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	t.stop, t.done = stop, done
+	t.peaks = nil
+
+	tick := clock.Or(t.Clock, nil).NewTicker(interval)
+	go func() {
+		defer close(done)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case now := <-tick.C():
+				if s, err := t.Inner.Fetch(ctx, now); err == nil {
+					t.observe(s.Values)
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends polling and waits for an in-flight poll.
+func (t *PeakTracker) Stop() {
+	t.mu.Lock()
+	stop, done := t.stop, t.done
+	t.stop, t.done = nil, nil
+	t.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// Peaks returns a copy of the highest value seen per key.
+func (t *PeakTracker) Peaks() map[string]float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]float64, len(t.peaks))
+	for k, v := range t.peaks {
+		out[k] = v
+	}
+	return out
+}
+
+func (t *PeakTracker) Unwrap() MetricsFetcher { return t.Inner }
+
+func (t *PeakTracker) observe(values map[string]float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.peaks == nil {
+		t.peaks = map[string]float64{}
+	}
+	for k, v := range values {
+		if cur, ok := t.peaks[k]; !ok || v > cur {
+			t.peaks[k] = v
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package fetch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/clock"
+)
+
+func TestPeakTracker(t *testing.T) {
+	depths := []float64{2, 9, 3, 1} // start edge, two polls, end edge
+	fetched := make(chan struct{}, len(depths))
+	calls := 0
+	inner := fetcherFunc(func(_ context.Context, at time.Time) (Sample, error) {
+		v := depths[calls]
+		calls++
+		fetched <- struct{}{}
+		return Sample{At: at, Values: map[string]float64{"depth": v}}, nil
+	})
+	c := clock.NewFake(time.Unix(1700000000, 0))
+	tracker := &PeakTracker{Inner: inner, Interval: time.Second, Clock: c}
+	// the session wraps the tracker like any other fetcher
+	f := CountFetches(Merge(tracker, values(map[string]float64{"other": 1})), &Stats{})
+
+	tracker.Start(context.Background())
+	if _, err := f.Fetch(context.Background(), c.Now()); err != nil {
+		t.Fatal(err)
+	}
+	<-fetched
+	for range 2 {
+		c.Advance(time.Second)
+		<-fetched
+	}
+	tracker.Stop()
+	if _, err := f.Fetch(context.Background(), c.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	ps, ok := FindPeaks(f)
+	if !ok {
+		t.Fatal("the tracker must be found through the wrappers")
+	}
+	if got := ps.Peaks(); got["depth"] != 9 || len(got) != 1 {
+		t.Fatalf("peaks = %v, want depth 9", got)
+	}
+
+	// a new window forgets the old peaks
+	tracker.Start(context.Background())
+	tracker.Stop()
+	if got := tracker.Peaks(); len(got) != 0 {
+		t.Fatalf("peaks not reset: %v", got)
+	}
+	if _, ok := FindPeaks(values(nil)); ok {
+		t.Fatal("a plain fetcher has no peaks")
+	}
+}
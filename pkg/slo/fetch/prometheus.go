@@ -0,0 +1,155 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/common/promkey"
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
+)
+
+// PromQLParserVersion identifies the Prometheus query API decoding in result provenance.
+const PromQLParserVersion = "promql-vector.v1"
+
+// PrometheusFetcher reads snapshots from a Prometheus server (query API, instant queries at
+// the snapshot time) instead of scraping the operator's /metrics endpoint.
+// Because Prometheus keeps history, the start snapshot is exact even when it is fetched after
+// the window, and no token/RBAC path to the operator's metrics endpoint is needed.
+//
+// Series carry the labels Prometheus added (job, instance, pod, ...); SLI inputs with a label
+// subset still match (see engine lookup), and bare names aggregate all series as usual.
+type PrometheusFetcher struct {
+	// URL of the Prometheus server, e.g. http://prometheus-k8s.monitoring.svc:9090.
+	URL string
+	// Names of the metrics to query (required), e.g. spec.MetricNames(specs).
+	Names []string
+	// Selector adds label matchers to the query, e.g. `namespace="my-operator-system"`.
+	Selector string
+
+	Header http.Header
+	// Client may be nil (uses a client with a 30s timeout).
+	Client *http.Client
+}
+
+// NewPrometheusFetcher returns a fetcher querying names from the Prometheus server at baseURL.
+func NewPrometheusFetcher(baseURL string, names []string, selector string) *PrometheusFetcher {
+	return &PrometheusFetcher{URL: baseURL, Names: names, Selector: selector}
+}
+
+// Query returns the PromQL instant vector selector used for every snapshot.
+func (f *PrometheusFetcher) Query() string {
+	quoted := make([]string, 0, len(f.Names))
+	for _, n := range f.Names {
+		quoted = append(quoted, regexp.QuoteMeta(n))
+	}
+	matchers := []string{fmt.Sprintf(`__name__=~%q`, strings.Join(quoted, "|"))}
+	if sel := strings.TrimSpace(f.Selector); sel != "" {
+		matchers = append(matchers, strings.Trim(sel, "{}"))
+	}
+	return "{" + strings.Join(matchers, ",") + "}"
+}
+
+// promResponse is the subset of the /api/v1/query response used here.
+type promResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  [2]any            `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+func (f *PrometheusFetcher) Fetch(ctx context.Context, at time.Time) (Sample, error) {
+	if len(f.Names) == 0 {
+		return Sample{}, errors.New("prometheus fetcher: no metric names to query")
+	}
+	client := f.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	q := url.Values{}
+	q.Set("query", f.Query())
+	q.Set("time", strconv.FormatFloat(float64(at.UnixMilli())/1000, 'f', 3, 64))
+	endpoint := strings.TrimRight(f.URL, "/") + "/api/v1/query"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(q.Encode()))
+	if err != nil {
+		return Sample{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for k, vs := range f.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Sample{}, slo.Wrap(slo.KindFetch, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Sample{}, slo.Errorf(slo.KindFetch, "query %s: %w", endpoint, err)
+	}
+	var pr promResponse
+	if err := json.Unmarshal(body, &pr); err != nil {
+		if len(body) > 512 {
+			body = body[:512]
+		}
+		return Sample{}, slo.Errorf(slo.KindFetch, "query %s: status %d: %s", endpoint, resp.StatusCode, body)
+	}
+	if pr.Status != "success" {
+		return Sample{}, slo.Errorf(slo.KindFetch, "query %s: %s: %s", endpoint, pr.ErrorType, pr.Error)
+	}
+	if pr.Data.ResultType != "vector" {
+		return Sample{}, slo.Errorf(slo.KindParse, "query %s: unexpected result type %q", endpoint, pr.Data.ResultType)
+	}
+
+	base := make(map[string]float64, len(pr.Data.Result))
+	for _, r := range pr.Data.Result {
+		labels := make(map[string]string, len(r.Metric))
+		for k, v := range r.Metric {
+			if k != "__name__" {
+				labels[k] = v
+			}
+		}
+		raw, ok := r.Value[1].(string)
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return Sample{}, slo.Errorf(slo.KindParse, "query %s: value %q: %w", endpoint, raw, err)
+		}
+		base[promkey.Format(r.Metric["__name__"], labels)] += v
+	}
+
+	return Sample{
+		At:     at,
+		Values: promtext.AggregateByName(base),
+		Provenance: &Provenance{
+			Fetcher:   "prometheus",
+			Target:    f.URL,
+			Parser:    PromQLParserVersion,
+			ScrapedAt: time.Now(),
+			Series:    promtext.SeriesCountByName(base),
+		},
+	}, nil
+}
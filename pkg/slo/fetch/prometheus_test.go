@@ -0,0 +1,39 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPrometheusFetcher(t *testing.T) {
+	var gotQuery, gotTime string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery, gotTime = r.FormValue("query"), r.FormValue("time")
+		_, _ = fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[
+{"metric":{"__name__":"reconcile_total","result":"success","pod":"a"},"value":[1700000000,"3"]},
+{"metric":{"__name__":"reconcile_total","result":"error","pod":"a"},"value":[1700000000,"1"]}]}}`)
+	}))
+	defer srv.Close()
+
+	f := NewPrometheusFetcher(srv.URL, []string{"reconcile_total", "workqueue_depth"}, `namespace="ns"`)
+	s, err := f.Fetch(context.Background(), time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{__name__=~"reconcile_total|workqueue_depth",namespace="ns"}`; gotQuery != want {
+		t.Fatalf("query = %s, want %s", gotQuery, want)
+	}
+	if gotTime != "1700000000.000" {
+		t.Fatalf("time = %s", gotTime)
+	}
+	if s.Values["reconcile_total"] != 4 || s.Values[`reconcile_total{pod="a",result="error"}`] != 1 {
+		t.Fatalf("values = %v", s.Values)
+	}
+	if s.Provenance == nil || s.Provenance.Fetcher != "prometheus" || s.Provenance.Series["reconcile_total"] != 2 {
+		t.Fatalf("provenance = %+v", s.Provenance)
+	}
+}
@@ -0,0 +1,344 @@
+// Package promproto decodes the Prometheus protobuf exposition format (length-delimited
+// io.prometheus.client.MetricFamily messages) into the same flat map as promtext, without a
+// protobuf dependency. Decoding skips the text tokenizing and float parsing, which dominates the
+// snapshot latency of large (tens of thousands of series) scrapes.
+package promproto
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/slo/common/promkey"
+)
+
+// ParserVersion identifies this decoder in result provenance.
+const ParserVersion = "promproto.v1"
+
+// ContentType is the media type of the delimited protobuf format, to put in an Accept header.
+const ContentType = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited`
+
+// IsContentType reports whether a response Content-Type is the delimited protobuf format.
+func IsContentType(contentType string) bool {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/vnd.google.protobuf" &&
+		params["proto"] == "io.prometheus.client.MetricFamily" && params["encoding"] == "delimited"
+}
+
+// maxMessageSize bounds one MetricFamily message (a corrupt length prefix must not allocate GBs).
+const maxMessageSize = 64 << 20
+
+// metric types (io.prometheus.client.MetricType)
+const (
+	typeCounter = iota
+	typeGauge
+	typeSummary
+	typeUntyped
+	typeHistogram
+	typeGaugeHistogram
+)
+
+// ParseToMap decodes a delimited protobuf scrape into canonical series keys, exactly as the text
+// format of the same families parses with promtext.ParseTextToMap: summaries become
+// name{quantile=...}, name_sum and name_count; histograms name_bucket{le=...} (with the +Inf
+// bucket the protobuf omits), name_sum and name_count. Timestamps and exemplars are dropped.
+func ParseToMap(r io.Reader) (map[string]float64, error) {
+	return Parse(r, Options{})
+}
+
+// Options controls Parse.
+type Options struct {
+	// Names keeps only the series of these sample names (as in promtext.Options.Names); families
+	// with no kept name are skipped without decoding their metrics (nil => keep all).
+	Names []string
+}
+
+// Parse is ParseToMap with options. r is read one family at a time.
+func Parse(r io.Reader, opts Options) (map[string]float64, error) {
+	var keep map[string]bool
+	if len(opts.Names) > 0 {
+		keep = make(map[string]bool, len(opts.Names))
+		for _, n := range opts.Names {
+			keep[n] = true
+		}
+	}
+	out := map[string]float64{}
+	br := bufio.NewReader(r)
+	for i := 0; ; i++ {
+		size, err := binary.ReadUvarint(br)
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("metric family %d: length: %w", i, err)
+		}
+		if size > maxMessageSize {
+			return nil, fmt.Errorf("metric family %d: %d bytes exceeds %d", i, size, maxMessageSize)
+		}
+		msg := make([]byte, size)
+		if _, err := io.ReadFull(br, msg); err != nil {
+			return nil, fmt.Errorf("metric family %d: %w", i, err)
+		}
+		if err := decodeFamily(msg, keep, out); err != nil {
+			return nil, fmt.Errorf("metric family %d: %w", i, err)
+		}
+	}
+}
+
+func decodeFamily(msg []byte, keep map[string]bool, out map[string]float64) error {
+	var name string
+	typ := typeCounter
+	var metrics [][]byte
+	err := fields(msg, func(num int, v value) error {
+		switch num {
+		case 1:
+			name = string(v.bytes)
+		case 3:
+			typ = int(v.varint)
+		case 4:
+			metrics = append(metrics, v.bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if keep != nil && !keep[name] && !keep[name+"_bucket"] && !keep[name+"_sum"] && !keep[name+"_count"] {
+		return nil
+	}
+	dst := out
+	if keep != nil {
+		// a histogram/summary family also yields the siblings of its kept names: decode apart
+		dst = map[string]float64{}
+	}
+	for _, m := range metrics {
+		if err := decodeMetric(m, name, typ, dst); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if keep != nil {
+		for key, v := range dst {
+			if n, _, _ := strings.Cut(key, "{"); keep[n] {
+				out[key] = v
+			}
+		}
+	}
+	return nil
+}
+
+func decodeMetric(msg []byte, name string, typ int, out map[string]float64) error {
+	labels := map[string]string{}
+	var counter, gauge, untyped, summary, histogram []byte
+	err := fields(msg, func(num int, v value) error {
+		switch num {
+		case 1:
+			return decodeLabel(v.bytes, labels)
+		case 2:
+			gauge = v.bytes
+		case 3:
+			counter = v.bytes
+		case 4:
+			summary = v.bytes
+		case 5:
+			untyped = v.bytes
+		case 7:
+			histogram = v.bytes
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch typ {
+	case typeCounter:
+		return simpleValue(counter, name, labels, out)
+	case typeGauge:
+		return simpleValue(gauge, name, labels, out)
+	case typeUntyped:
+		return simpleValue(untyped, name, labels, out)
+	case typeSummary:
+		return decodeSummary(summary, name, labels, out)
+	case typeHistogram, typeGaugeHistogram:
+		return decodeHistogram(histogram, name, labels, out)
+	}
+	return nil // unknown type: skipped like a malformed text line
+}
+
+func decodeLabel(msg []byte, labels map[string]string) error {
+	var k, v string
+	err := fields(msg, func(num int, val value) error {
+		switch num {
+		case 1:
+			k = string(val.bytes)
+		case 2:
+			v = string(val.bytes)
+		}
+		return nil
+	})
+	labels[k] = v
+	return err
+}
+
+// simpleValue reads field 1 (double) of a Counter, Gauge or Untyped message.
+func simpleValue(msg []byte, name string, labels map[string]string, out map[string]float64) error {
+	var v float64
+	err := fields(msg, func(num int, val value) error {
+		if num == 1 {
+			v = val.double()
+		}
+		return nil
+	})
+	out[promkey.Format(name, labels)] = v
+	return err
+}
+
+func decodeSummary(msg []byte, name string, labels map[string]string, out map[string]float64) error {
+	var count, sum float64
+	err := fields(msg, func(num int, v value) error {
+		switch num {
+		case 1:
+			count = float64(v.varint)
+		case 2:
+			sum = v.double()
+		case 3:
+			var q, qv float64
+			if err := fields(v.bytes, func(num int, v value) error {
+				switch num {
+				case 1:
+					q = v.double()
+				case 2:
+					qv = v.double()
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			out[promkey.Format(name, with(labels, "quantile", formatFloat(q)))] = qv
+		}
+		return nil
+	})
+	out[promkey.Format(name+"_sum", labels)] = sum
+	out[promkey.Format(name+"_count", labels)] = count
+	return err
+}
+
+func decodeHistogram(msg []byte, name string, labels map[string]string, out map[string]float64) error {
+	var count, sum float64
+	sawInf := false
+	err := fields(msg, func(num int, v value) error {
+		switch num {
+		case 1:
+			count = float64(v.varint)
+		case 2:
+			sum = v.double()
+		case 4: // sample_count_float (float histograms)
+			count = v.double()
+		case 3:
+			var upper, cumulative float64
+			if err := fields(v.bytes, func(num int, v value) error {
+				switch num {
+				case 1:
+					cumulative = float64(v.varint)
+				case 2:
+					upper = v.double()
+				case 4: // cumulative_count_float
+					cumulative = v.double()
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			sawInf = sawInf || math.IsInf(upper, +1)
+			out[promkey.Format(name+"_bucket", with(labels, "le", formatFloat(upper)))] = cumulative
+		}
+		return nil
+	})
+	if !sawInf {
+		out[promkey.Format(name+"_bucket", with(labels, "le", "+Inf"))] = count
+	}
+	out[promkey.Format(name+"_sum", labels)] = sum
+	out[promkey.Format(name+"_count", labels)] = count
+	return err
+}
+
+func with(labels map[string]string, k, v string) map[string]string {
+	out := make(map[string]string, len(labels)+1)
+	for lk, lv := range labels {
+		out[lk] = lv
+	}
+	out[k] = v
+	return out
+}
+
+// formatFloat formats le/quantile label values like the text exposition.
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, +1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// value is one decoded protobuf field: varint, fixed64 or length-delimited bytes.
+type value struct {
+	varint  uint64
+	fixed64 uint64
+	bytes   []byte
+}
+
+func (v value) double() float64 { return math.Float64frombits(v.fixed64) }
+
+// fields calls fn for each field of a protobuf message, in wire order.
+func fields(msg []byte, fn func(num int, v value) error) error {
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return errors.New("malformed field key")
+		}
+		msg = msg[n:]
+		var v value
+		switch wire := key & 7; wire {
+		case 0:
+			v.varint, n = binary.Uvarint(msg)
+			if n <= 0 {
+				return errors.New("malformed varint")
+			}
+			msg = msg[n:]
+		case 1:
+			if len(msg) < 8 {
+				return errors.New("short fixed64")
+			}
+			v.fixed64 = binary.LittleEndian.Uint64(msg)
+			msg = msg[8:]
+		case 2:
+			size, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < size {
+				return errors.New("malformed length-delimited field")
+			}
+			v.bytes = msg[n : n+int(size)]
+			msg = msg[n+int(size):]
+		case 5:
+			if len(msg) < 4 {
+				return errors.New("short fixed32")
+			}
+			msg = msg[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d", wire)
+		}
+		if err := fn(int(key>>3), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package promproto_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promproto"
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
+)
+
+// testdata/families.pb and families.prom are the same client_golang registry (a counter vec, a
+// gauge vec, a histogram vec and a summary) encoded by expfmt as delimited protobuf and as text.
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// frames splits a delimited stream into its length-prefixed messages, prefix included.
+func frames(t *testing.T, b []byte) map[string][]byte {
+	t.Helper()
+	out := map[string][]byte{}
+	for len(b) > 0 {
+		size, n := binary.Uvarint(b)
+		if n <= 0 || int(size) > len(b)-n {
+			t.Fatalf("bad testdata frame")
+		}
+		frame := b[:n+int(size)]
+		// field 1 (name) is the first field expfmt writes: tag 0x0a, length, name
+		name := string(frame[n+2 : n+2+int(frame[n+1])])
+		out[name] = frame
+		b = b[n+int(size):]
+	}
+	return out
+}
+
+func TestParseFamilies(t *testing.T) {
+	byName := frames(t, readTestdata(t, "families.pb"))
+
+	for _, tc := range []struct {
+		family string
+		want   map[string]float64
+	}{
+		{
+			family: "controller_runtime_reconcile_total",
+			want: map[string]float64{
+				`controller_runtime_reconcile_total{controller="joboperator",result="error"}`:   1,
+				`controller_runtime_reconcile_total{controller="joboperator",result="success"}`: 25,
+			},
+		},
+		{
+			family: "workqueue_depth",
+			want:   map[string]float64{`workqueue_depth{name="joboperator"}`: 3},
+		},
+		{
+			// the protobuf omits the +Inf bucket; it is added from the count
+			family: "controller_runtime_reconcile_time_seconds",
+			want: map[string]float64{
+				`controller_runtime_reconcile_time_seconds_bucket{controller="joboperator",le="0.005"}`: 1,
+				`controller_runtime_reconcile_time_seconds_bucket{controller="joboperator",le="0.1"}`:   3,
+				`controller_runtime_reconcile_time_seconds_bucket{controller="joboperator",le="1"}`:     4,
+				`controller_runtime_reconcile_time_seconds_bucket{controller="joboperator",le="10"}`:    4,
+				`controller_runtime_reconcile_time_seconds_bucket{controller="joboperator",le="+Inf"}`:  5,
+				`controller_runtime_reconcile_time_seconds_sum{controller="joboperator"}`:               20.602,
+				`controller_runtime_reconcile_time_seconds_count{controller="joboperator"}`:             5,
+			},
+		},
+		{
+			family: "rest_client_request_latency_seconds",
+			want: map[string]float64{
+				`rest_client_request_latency_seconds{quantile="0.5"}`:  0.5,
+				`rest_client_request_latency_seconds{quantile="0.99"}`: 1,
+				`rest_client_request_latency_seconds_sum`:              1.75,
+				`rest_client_request_latency_seconds_count`:            3,
+			},
+		},
+	} {
+		t.Run(tc.family, func(t *testing.T) {
+			frame, ok := byName[tc.family]
+			if !ok {
+				t.Fatalf("family %s not in testdata", tc.family)
+			}
+			got, err := promproto.ParseToMap(bytes.NewReader(frame))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tc.want) {
+				t.Errorf("got %d series, want %d: %v", len(got), len(tc.want), got)
+			}
+			for k, v := range tc.want {
+				if g, ok := got[k]; !ok || g != v {
+					t.Errorf("%s = %v (present %v), want %v", k, g, ok, v)
+				}
+			}
+		})
+	}
+}
+
+// The whole delimited stream decodes to exactly what the text encoding of the same registry
+// parses to.
+func TestParseMatchesText(t *testing.T) {
+	pb, err := promproto.ParseToMap(bytes.NewReader(readTestdata(t, "families.pb")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := promtext.ParseTextToMap(bytes.NewReader(readTestdata(t, "families.prom")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pb) != len(text) {
+		t.Errorf("protobuf %d series, text %d", len(pb), len(text))
+	}
+	for k, v := range text {
+		if g, ok := pb[k]; !ok || g != v {
+			t.Errorf("%s: protobuf %v (present %v), text %v", k, g, ok, v)
+		}
+	}
+}
+
+func TestParseNames(t *testing.T) {
+	got, err := promproto.Parse(bytes.NewReader(readTestdata(t, "families.pb")), promproto.Options{
+		Names: []string{"workqueue_depth", "controller_runtime_reconcile_time_seconds_count"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`controller_runtime_reconcile_time_seconds_count{controller="joboperator"}`,
+		`workqueue_depth{name="joboperator"}`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want only %v", got, want)
+	}
+	for _, k := range want {
+		if _, ok := got[k]; !ok {
+			t.Errorf("missing %s", k)
+		}
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	stream := readTestdata(t, "families.pb")
+	// the counter family with its last metric (field 4) cut short, re-prefixed with the new length
+	counter := frames(t, stream)["controller_runtime_reconcile_total"]
+	_, n := binary.Uvarint(counter)
+	body := counter[n : len(counter)-4]
+	cutMetric := append(binary.AppendUvarint(nil, uint64(len(body))), body...)
+
+	for name, in := range map[string][]byte{
+		"truncated stream":        stream[:len(stream)-7],
+		"truncated length prefix": {0x80},
+		"length beyond the data":  {0x10, 0x0a, 0x02, 'u', 'p'},
+		"oversized message":       binary.AppendUvarint(nil, 1<<40),
+		"unsupported wire type":   {0x01, 0x0f},
+		"malformed field key":     {0x02, 0x80, 0x80},
+		"short fixed64":           {0x03, 0x09, 0x00, 0x00},
+		"nested length overflow":  {0x03, 0x22, 0x7f, 0x00},
+		"truncated metric":        cutMetric,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := promproto.ParseToMap(bytes.NewReader(in)); err == nil {
+				t.Fatal("expected an error")
+			} else if !strings.Contains(err.Error(), "metric family") {
+				t.Fatalf("error %q does not name the family", err)
+			}
+		})
+	}
+
+	if got, err := promproto.ParseToMap(bytes.NewReader(nil)); err != nil || len(got) != 0 {
+		t.Fatalf("empty input: %v, %v", got, err)
+	}
+}
+
+func TestIsContentType(t *testing.T) {
+	for ct, want := range map[string]bool{
+		promproto.ContentType: true,
+		`application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited`: true,
+		`application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=text`:      false,
+		"text/plain; version=0.0.4": false,
+	} {
+		if got := promproto.IsContentType(ct); got != want {
+			t.Errorf("IsContentType(%q) = %v, want %v", ct, got, want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package promtext
+
+import "strings"
+
+// AggregateByName returns a copy of values where each labeled series is also
+// summed into its bare metric name (e.g. foo{a="b"} contributes to foo).
+// This lets SLI inputs reference a metric name without labels.
+func AggregateByName(values map[string]float64) map[string]float64 {
+	out := make(map[string]float64, len(values))
+	for key, val := range values {
+		out[key] = val
+		if idx := strings.Index(key, "{"); idx > 0 {
+			name := key[:idx]
+			out[name] = out[name] + val
+		}
+	}
+	return out
+}
+
+// SeriesCountByName reports how many series contribute to each key of AggregateByName(values):
+// 1 for every concrete series, and the number of labeled series for each bare metric name.
+func SeriesCountByName(values map[string]float64) map[string]int {
+	out := make(map[string]int, len(values))
+	for key := range values {
+		out[key]++
+		if idx := strings.Index(key, "{"); idx > 0 {
+			out[key[:idx]]++
+		}
+	}
+	return out
+}
@@ -2,6 +2,7 @@ package promtext
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
@@ -10,6 +11,65 @@ import (
 	"github.com/yeongki/my-operator/pkg/slo/common/promkey"
 )
 
+// ParserVersion identifies this parser in result provenance.
+const ParserVersion = "promtext.v4"
+
+// Format is an exposition format.
+type Format string
+
+const (
+	// FormatText is the Prometheus text format (text/plain; version=0.0.4).
+	FormatText Format = "text"
+	// FormatOpenMetrics is OpenMetrics text (application/openmetrics-text), terminated by "# EOF".
+	FormatOpenMetrics Format = "openmetrics"
+)
+
+// OpenMetricsContentType is the media type to put first in an Accept header to negotiate OpenMetrics.
+const OpenMetricsContentType = "application/openmetrics-text"
+
+// ErrTruncated is returned for an OpenMetrics scrape without its "# EOF" marker (cut off mid-body).
+var ErrTruncated = errors.New("openmetrics scrape truncated: no # EOF")
+
+// FormatFromContentType maps a scrape's Content-Type to its format ("" => unknown, detect from the body).
+func FormatFromContentType(contentType string) Format {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case OpenMetricsContentType:
+		return FormatOpenMetrics
+	case "text/plain":
+		return FormatText
+	}
+	return ""
+}
+
+// Options controls Parse.
+type Options struct {
+	// Format of the payload ("" => OpenMetrics when the body has a "# EOF" or "# UNIT" line, text
+	// otherwise). Only a known OpenMetrics payload can be detected as truncated.
+	Format Format
+	// Exemplars captures the exemplar of each sample line that has one (ignored otherwise).
+	Exemplars bool
+	// Names keeps only the series of these metric (sample) names, e.g. spec.MetricNames(specs);
+	// the others are dropped while parsing (nil => keep all).
+	Names []string
+}
+
+// Exemplar is an OpenMetrics exemplar, e.g. the trace of one observation in a histogram bucket.
+type Exemplar struct {
+	Labels    map[string]string `json:"labels"`
+	Value     float64           `json:"value"`
+	Timestamp float64           `json:"timestamp,omitempty"` // unix seconds, 0 => none
+}
+
+// Result is a parsed scrape.
+type Result struct {
+	Format Format
+	// Values maps canonical series keys to values (see ParseTextToMap).
+	Values map[string]float64
+	// Exemplars maps series keys to their exemplar (nil unless Options.Exemplars).
+	Exemplars map[string]Exemplar
+}
+
 // ParseTextToMap parses Prometheus exposition format (text) into a flat map.
 // Key format example:
 //
@@ -20,38 +80,184 @@ import (
 //	metric_name
 //
 // v3: minimal parser for common cases (counters/gauges).
+// v4: OpenMetrics is accepted as well (see Parse).
 func ParseTextToMap(r io.Reader) (map[string]float64, error) {
-	out := map[string]float64{}
-	sc := bufio.NewScanner(r)
+	res, err := Parse(r, Options{})
+	if err != nil {
+		return nil, err
+	}
+	return res.Values, nil
+}
+
+// maxLineSize bounds one exposition line (long label values); longer lines are an error.
+const maxLineSize = 1 << 20
 
+// Parse parses a Prometheus text or OpenMetrics scrape. For OpenMetrics, sample keys already follow
+// the text format (counters end in _total), exemplars and timestamps are dropped, and the
+// _created series of counters/histograms/summaries (creation times, absent from the text format)
+// are skipped, so both formats of the same registry give the same map. A missing "# EOF" is
+// ErrTruncated and anything after it is an error.
+//
+// r is read line by line: memory is bounded by the kept series (Options.Names), not by the body.
+func Parse(r io.Reader, opts Options) (Result, error) {
+	res := Result{Format: opts.Format, Values: map[string]float64{}}
+	if opts.Exemplars {
+		res.Exemplars = map[string]Exemplar{}
+	}
+	keep := newNameFilter(opts.Names)
+	// om: an OpenMetrics payload, known from the content type or detected by its first
+	// "# UNIT"/"# EOF" line
+	om := res.Format == FormatOpenMetrics
+
+	types := map[string]string{} // family -> TYPE (one per family, whatever Names keeps)
+	eof := false
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineSize)
 	for sc.Scan() {
 		line := strings.TrimSpace(sc.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
+		if line == "" {
 			continue
 		}
-		// split "key value"
-		fields := strings.Fields(line)
-		if len(fields) < 2 {
+		if eof {
+			return Result{}, fmt.Errorf("openmetrics: content after # EOF: %q", line)
+		}
+		if strings.HasPrefix(line, "#") {
+			if opts.Format == "" && (line == "# EOF" || strings.HasPrefix(line, "# UNIT ")) {
+				om = true
+			}
+			if om && line == "# EOF" {
+				eof = true
+			} else if f := strings.Fields(line); len(f) >= 4 && f[1] == "TYPE" {
+				types[f[2]] = f[3]
+			}
+			continue
+		}
+
+		rawKey, rest, ok := splitSample(line)
+		if !ok {
+			continue
+		}
+		if name, _, _ := strings.Cut(rawKey, "{"); !keep.name(name) {
 			continue
 		}
-		rawKey := fields[0]
 		key, err := promkey.Canonicalize(rawKey)
 		if err != nil {
 			// v3 policy: skip malformed metric lines (best-effort parser)
 			continue
 		}
-		valStr := fields[1]
-		v, err := strconv.ParseFloat(valStr, 64)
+		if isCreated(key, types) {
+			continue
+		}
+		sample, exemplar, _ := strings.Cut(rest, "#")
+		fields := strings.Fields(sample)
+		if len(fields) == 0 {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
 		if err != nil {
-			return nil, fmt.Errorf("parse float: %q: %w", line, err)
+			return Result{}, fmt.Errorf("parse float: %q: %w", line, err)
 		}
+		res.Values[key] = v
 
-		out[key] = v
+		if res.Exemplars != nil && strings.TrimSpace(exemplar) != "" {
+			ex, err := parseExemplar(exemplar)
+			if err != nil {
+				return Result{}, fmt.Errorf("parse exemplar: %q: %w", line, err)
+			}
+			res.Exemplars[key] = ex
+		}
 	}
-
 	if err := sc.Err(); err != nil {
-		return nil, err
+		return Result{}, err
+	}
+	if res.Format == "" {
+		res.Format = FormatText
+		if om {
+			res.Format = FormatOpenMetrics
+		}
 	}
+	if om && !eof {
+		return Result{}, ErrTruncated
+	}
+	return res, nil
+}
+
+// nameFilter is the Options.Names allowlist (nil => keep everything).
+type nameFilter map[string]bool
+
+func newNameFilter(names []string) nameFilter {
+	if len(names) == 0 {
+		return nil
+	}
+	f := make(nameFilter, len(names))
+	for _, n := range names {
+		f[n] = true
+	}
+	return f
+}
 
-	return out, nil
+func (f nameFilter) name(name string) bool {
+	return f == nil || f[name]
+}
+
+// splitSample splits a sample line after its series token (name plus {labels}); label values may
+// contain spaces and '#'.
+func splitSample(line string) (key, rest string, ok bool) {
+	inLabels, inQuote := false, false
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case inQuote && ch == '\\':
+			i++
+		case ch == '"' && inLabels:
+			inQuote = !inQuote
+		case inQuote:
+		case ch == '{':
+			inLabels = true
+		case ch == '}':
+			inLabels = false
+		case !inLabels && (ch == ' ' || ch == '\t'):
+			return line[:i], line[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// isCreated reports whether key is the _created series of a counter/histogram/summary family.
+func isCreated(key string, types map[string]string) bool {
+	name, _, _ := strings.Cut(key, "{")
+	family, ok := strings.CutSuffix(name, "_created")
+	if !ok {
+		return false
+	}
+	switch types[family] {
+	case "counter", "histogram", "summary":
+		return true
+	}
+	return false
+}
+
+// parseExemplar parses ` {trace_id="abc"} 0.5 [timestamp]` (the part after a sample's '#').
+func parseExemplar(s string) (Exemplar, error) {
+	token, rest, ok := splitSample("x" + strings.TrimSpace(s))
+	if !ok {
+		return Exemplar{}, errors.New("missing value")
+	}
+	_, labels, err := promkey.Parse(token)
+	if err != nil {
+		return Exemplar{}, err
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return Exemplar{}, errors.New("missing value")
+	}
+	ex := Exemplar{Labels: labels}
+	if ex.Value, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return Exemplar{}, err
+	}
+	if len(fields) > 1 {
+		if ex.Timestamp, err = strconv.ParseFloat(fields[1], 64); err != nil {
+			return Exemplar{}, err
+		}
+	}
+	return ex, nil
 }
@@ -0,0 +1,143 @@
+package promtext_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/diff"
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
+)
+
+// Spellings the parser must treat as the same series (label order, whitespace, comments,
+// timestamps) diff as empty; a real difference is reported per series.
+func TestParseTextToMapCanonicalSeries(t *testing.T) {
+	a := `# HELP reconcile_total Total reconciliations.
+# TYPE reconcile_total counter
+reconcile_total{controller="a",result="success"} 3
+reconcile_total{controller="a",result="error"} 1
+path_requests_total{path="/a\"b"} 2
+up 1
+`
+	b := `reconcile_total{result="success",controller="a"} 3 1700000000000
+  reconcile_total{result="error",controller="a"}   1
+
+path_requests_total{path="/a\"b"} 2
+up 1
+`
+	rep, err := diff.Text(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.Empty() || rep.Unchanged != 4 {
+		t.Fatalf("expected the same 4 series, got %+v", rep)
+	}
+
+	rep, err = diff.Text(a, "reconcile_total{controller=\"a\",result=\"success\"} 4\nup 1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Changed) != 1 || rep.Changed[0].Delta() != 1 || len(rep.Removed) != 2 || len(rep.Added) != 0 {
+		t.Fatalf("unexpected report %+v", rep)
+	}
+}
+
+func TestParseOpenMetrics(t *testing.T) {
+	text := `# TYPE reconcile_total counter
+reconcile_total{result="success"} 3
+# TYPE reconcile_time_seconds histogram
+reconcile_time_seconds_bucket{le="0.5"} 2
+reconcile_time_seconds_bucket{le="+Inf"} 3
+reconcile_time_seconds_sum 0.9
+reconcile_time_seconds_count 3
+`
+	om := `# TYPE reconcile counter
+# HELP reconcile Total reconciliations.
+reconcile_total{result="success"} 3 1700000000.000
+reconcile_created{result="success"} 1700000000.000
+# TYPE reconcile_time_seconds histogram
+# UNIT reconcile_time_seconds seconds
+reconcile_time_seconds_bucket{le="0.5"} 2 # {trace_id="a b#c"} 0.25 1700000000.5
+reconcile_time_seconds_bucket{le="+Inf"} 3
+reconcile_time_seconds_sum 0.9
+reconcile_time_seconds_count 3
+reconcile_time_seconds_created 1700000000.000
+# EOF
+`
+	rep, err := diff.Text(text, om)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.Empty() {
+		t.Fatalf("expected OpenMetrics to parse like the text format, got %+v", rep)
+	}
+
+	res, err := promtext.Parse(strings.NewReader(om), promtext.Options{Exemplars: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex, ok := res.Exemplars[`reconcile_time_seconds_bucket{le="0.5"}`]
+	if res.Format != promtext.FormatOpenMetrics || !ok || len(res.Exemplars) != 1 {
+		t.Fatalf("expected one exemplar from an OpenMetrics scrape, got %+v", res)
+	}
+	if ex.Labels["trace_id"] != "a b#c" || ex.Value != 0.25 || ex.Timestamp != 1700000000.5 {
+		t.Fatalf("unexpected exemplar %+v", ex)
+	}
+
+	truncated := om[:strings.Index(om, "# EOF")]
+	_, err = promtext.Parse(strings.NewReader(truncated), promtext.Options{Format: promtext.FormatOpenMetrics})
+	if !errors.Is(err, promtext.ErrTruncated) {
+		t.Fatalf("expected ErrTruncated, got %v", err)
+	}
+	if _, err := promtext.Parse(strings.NewReader(om+"up 1\n"), promtext.Options{}); err == nil {
+		t.Fatal("expected content after # EOF to be an error")
+	}
+	if f := promtext.FormatFromContentType("application/openmetrics-text; version=1.0.0; charset=utf-8"); f !=
+		promtext.FormatOpenMetrics {
+		t.Fatalf("unexpected format %q", f)
+	}
+}
+
+// seriesReader streams n series of a high-cardinality family after a small one, never holding
+// the body in memory.
+type seriesReader struct {
+	n, i int
+	buf  []byte
+}
+
+func (r *seriesReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		switch {
+		case r.i == 0:
+			r.buf = []byte("# TYPE up gauge\nup 1\n# TYPE pods_info gauge\n")
+		case r.i <= r.n:
+			r.buf = fmt.Appendf(nil, "pods_info{pod=\"pod-%d\"} 1\n", r.i)
+		default:
+			return 0, io.EOF
+		}
+		r.i++
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func TestParseNamesAllowlist(t *testing.T) {
+	res, err := promtext.Parse(&seriesReader{n: 200000}, promtext.Options{Names: []string{"up"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Values) != 1 || res.Values["up"] != 1 {
+		t.Fatalf("expected only the allowlisted series, got %d series", len(res.Values))
+	}
+
+	all, err := promtext.ParseTextToMap(&seriesReader{n: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1001 {
+		t.Fatalf("expected every series without an allowlist, got %d", len(all))
+	}
+}
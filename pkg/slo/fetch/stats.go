@@ -0,0 +1,65 @@
+package fetch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// Stats counts fetch outcomes (summary.MeasurementStats). Safe for concurrent use; the zero value
+// is ready. One Stats may be shared by several fetchers, e.g. to total a whole test suite.
+type Stats struct {
+	mu sync.Mutex
+	m  summary.MeasurementStats
+}
+
+// Observe counts one fetch: a success (and its retries, Provenance.Attempts - 1) or a failure by
+// kind.
+func (st *Stats) Observe(s Sample, err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	switch {
+	case err == nil:
+		st.m.Snapshots++
+		if s.Provenance != nil && s.Provenance.Attempts > 1 {
+			st.m.Retries += s.Provenance.Attempts - 1
+		}
+	case slo.KindOf(err) == slo.KindParse:
+		st.m.ParseErrors++
+	default:
+		st.m.FetchFailures++
+	}
+}
+
+// Report returns the counts so far.
+func (st *Stats) Report() summary.MeasurementStats {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.m
+}
+
+// CountFetches returns a fetcher observing every fetch of inner in each of stats (nil entries are
+// skipped), e.g. a per-session and a suite-wide Stats. Samples and errors pass through.
+func CountFetches(inner MetricsFetcher, stats ...*Stats) MetricsFetcher {
+	return countingFetcher{inner: inner, stats: stats}
+}
+
+type countingFetcher struct {
+	inner MetricsFetcher
+	stats []*Stats
+}
+
+func (c countingFetcher) Fetch(ctx context.Context, at time.Time) (Sample, error) {
+	s, err := c.inner.Fetch(ctx, at)
+	for _, st := range c.stats {
+		if st != nil {
+			st.Observe(s, err)
+		}
+	}
+	return s, err
+}
+
+func (c countingFetcher) Unwrap() MetricsFetcher { return c.inner }
@@ -0,0 +1,42 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+func TestCountFetches(t *testing.T) {
+	results := []struct {
+		s   Sample
+		err error
+	}{
+		{s: Sample{Provenance: &Provenance{Attempts: 3}}},
+		{err: errors.New("connection refused")},
+		{err: slo.Errorf(slo.KindParse, "line 1: bad value")},
+		{s: Sample{}},
+	}
+	i := 0
+	inner := fetcherFunc(func(context.Context, time.Time) (Sample, error) {
+		r := results[i]
+		i++
+		return r.s, r.err
+	})
+	var session, suite Stats
+	f := CountFetches(inner, &session, nil, &suite)
+	for range results {
+		_, _ = f.Fetch(context.Background(), time.Now())
+	}
+
+	want := summary.MeasurementStats{Snapshots: 2, FetchFailures: 1, ParseErrors: 1, Retries: 2}
+	if got := session.Report(); got != want || suite.Report() != want {
+		t.Errorf("stats = %+v / %+v, want %+v", got, suite.Report(), want)
+	}
+	if got := want.SuccessRate(); got != 0.5 {
+		t.Errorf("success rate = %v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package fetch
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promproto"
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
+)
+
+// SampleFromText parses a Prometheus text (or OpenMetrics) scrape into a Sample.
+// Labeled series are also summed per metric name (promtext.AggregateByName) and prov
+// (may be nil) is completed with the parser version, format, scrape time and series counts.
+func SampleFromText(at time.Time, raw string, prov *Provenance) (Sample, error) {
+	return SampleFromTextOptions(at, raw, promtext.Options{}, prov)
+}
+
+// SampleFromTextOptions is SampleFromText with an explicit format (e.g. from the response
+// Content-Type, so a truncated OpenMetrics scrape is an error) and optional exemplar capture.
+func SampleFromTextOptions(at time.Time, raw string, opts promtext.Options, prov *Provenance) (Sample, error) {
+	return SampleFromReader(at, strings.NewReader(raw), opts, prov)
+}
+
+// SampleFromReader is SampleFromTextOptions parsing r as it is read, so a large body is never
+// held in memory; with opts.Names only the listed series are.
+func SampleFromReader(at time.Time, r io.Reader, opts promtext.Options, prov *Provenance) (Sample, error) {
+	res, err := promtext.Parse(r, opts)
+	if errors.Is(err, promtext.ErrTruncated) {
+		// the scrape was cut off, not malformed
+		return Sample{}, slo.Wrap(slo.KindFetch, err)
+	}
+	if err != nil {
+		return Sample{}, slo.Wrap(slo.KindParse, err)
+	}
+	s := sampleFromValues(at, res.Values, promtext.ParserVersion, string(res.Format), prov)
+	s.Exemplars = res.Exemplars
+	return s, nil
+}
+
+// SampleFromProtobuf decodes a delimited protobuf scrape (promproto.ContentType) into a Sample,
+// with the same keys, aggregation and provenance as SampleFromText.
+func SampleFromProtobuf(at time.Time, raw []byte, prov *Provenance) (Sample, error) {
+	return SampleFromProtobufReader(at, bytes.NewReader(raw), promproto.Options{}, prov)
+}
+
+// SampleFromProtobufReader is SampleFromProtobuf decoding r as it is read.
+func SampleFromProtobufReader(at time.Time, r io.Reader, opts promproto.Options, prov *Provenance) (Sample, error) {
+	base, err := promproto.Parse(r, opts)
+	if err != nil {
+		return Sample{}, slo.Wrap(slo.KindParse, err)
+	}
+	return sampleFromValues(at, base, promproto.ParserVersion, "protobuf", prov), nil
+}
+
+func sampleFromValues(at time.Time, base map[string]float64, parser, format string, prov *Provenance) Sample {
+	if prov != nil {
+		p := *prov
+		p.Parser = parser
+		p.Format = format
+		if p.ScrapedAt.IsZero() {
+			p.ScrapedAt = time.Now()
+		}
+		p.Series = promtext.SeriesCountByName(base)
+		prov = &p
+	}
+
+	return Sample{
+		At:         at,
+		Values:     promtext.AggregateByName(base),
+		Provenance: prov,
+	}
+}
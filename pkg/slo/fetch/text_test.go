@@ -0,0 +1,64 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
+)
+
+func TestSampleFromTextProvenance(t *testing.T) {
+	raw := `# TYPE reconcile_total counter
+reconcile_total{result="success"} 3
+reconcile_total{result="error"} 1
+workqueue_depth 2
+`
+	s, err := SampleFromText(time.Now(), raw, &Provenance{Fetcher: "http", Target: "http://x/metrics"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Values["reconcile_total"] != 4 {
+		t.Fatalf("expected name aggregate 4, got %v", s.Values["reconcile_total"])
+	}
+
+	p := s.Provenance
+	if p == nil || p.Parser == "" || p.ScrapedAt.IsZero() {
+		t.Fatalf("provenance not completed: %+v", p)
+	}
+	if p.Series["reconcile_total"] != 2 || p.Series["workqueue_depth"] != 1 ||
+		p.Series[`reconcile_total{result="error"}`] != 1 {
+		t.Fatalf("unexpected series counts: %v", p.Series)
+	}
+}
+
+func TestHTTPFetcherOpenMetrics(t *testing.T) {
+	body := "# TYPE up gauge\nup 1 # {trace_id=\"x\"} 1\n# EOF\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Accept"), promtext.OpenMetricsContentType) {
+			t.Errorf("expected OpenMetrics to be negotiated, got Accept %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		_, _ = io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	f := &HTTPFetcher{URL: srv.URL, OpenMetrics: true, Exemplars: true}
+	s, err := f.Fetch(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Values["up"] != 1 || s.Provenance.Format != "openmetrics" || s.Exemplars["up"].Labels["trace_id"] != "x" {
+		t.Fatalf("unexpected sample %+v", s)
+	}
+
+	body = "# TYPE up gauge\nup 1\n" // cut off before # EOF
+	if _, err := f.Fetch(context.Background(), time.Now()); !errors.Is(err, promtext.ErrTruncated) {
+		t.Fatalf("expected a truncated scrape error, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package fetch
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// TLSOptions configures server verification and the client certificate (mTLS, e.g. behind
+// kube-rbac-proxy with a client CA) of the HTTP-based fetchers.
+type TLSOptions struct {
+	// CAFile is a PEM bundle the server certificate is verified against (empty => system roots).
+	CAFile string
+	// CertFile/KeyFile are the PEM client certificate and key, presented when the server asks.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name verified in the server certificate (e.g. when port-forwarding).
+	ServerName string
+	// InsecureSkipVerify skips server verification; the client certificate is still presented.
+	InsecureSkipVerify bool
+}
+
+// IsZero reports whether no option is set (the default transport is fine).
+func (o TLSOptions) IsZero() bool { return o == TLSOptions{} }
+
+// Config builds the tls.Config described by o.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return nil, errors.New("tls: client certificate and key must be set together")
+	}
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify, //nolint:gosec // explicit opt-in
+	}
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: read CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificates in %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// NewHTTPClient returns a client using o, or nil (the fetcher's default client) when o is zero.
+func NewHTTPClient(o TLSOptions, timeout time.Duration) (*http.Client, error) {
+	if o.IsZero() {
+		return nil, nil
+	}
+	cfg, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = cfg
+	return &http.Client{Timeout: timeout, Transport: tr}, nil
+}
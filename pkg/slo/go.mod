@@ -0,0 +1,3 @@
+module github.com/yeongki/my-operator/pkg/slo
+
+go 1.24.0
@@ -0,0 +1,351 @@
+// Package history keeps SLI results across runs, so trend, regression and burn-rate checks read a
+// durable local history instead of globbing summary artifacts.
+//
+// The store is a directory of JSON Lines files, one per month (history-2026-10.jsonl), one line
+// per session. It needs no database (pkg/slo stays stdlib only), stays greppable, and appends
+// never rewrite stored lines: a crash mid-append leaves at most a partial last line, which
+// readers skip.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// SchemaVersion identifies the line format.
+const SchemaVersion = "slo-history.v1"
+
+// SessionResult is one stored session: the run it belongs to and the result of each SLI.
+type SessionResult struct {
+	SchemaVersion string            `json:"schemaVersion"`
+	RunID         string            `json:"runId,omitempty"`
+	StartedAt     time.Time         `json:"startedAt"`
+	FinishedAt    time.Time         `json:"finishedAt"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	Build         *summary.Build    `json:"build,omitempty"`
+	Results       []Result          `json:"results"`
+	// Extras are the session-level numbers of the summary (summary.Summary.Extras).
+	Extras map[string]float64 `json:"extras,omitempty"`
+}
+
+// Result is one SLI of a session.
+type Result struct {
+	SLI    string         `json:"sli"`
+	Value  *float64       `json:"value,omitempty"`
+	Unit   string         `json:"unit,omitempty"`
+	Status summary.Status `json:"status"`
+	// ErrorKind classifies a skip (summary.SLIResult.ErrorKind).
+	ErrorKind string `json:"errorKind,omitempty"`
+}
+
+// FromSummary keeps what the history needs of s: run identity, window, tags, build provenance and
+// result values.
+func FromSummary(s summary.Summary) SessionResult {
+	r := SessionResult{
+		SchemaVersion: SchemaVersion,
+		RunID:         s.Config.RunID,
+		StartedAt:     s.Config.StartedAt.UTC(),
+		FinishedAt:    s.Config.FinishedAt.UTC(),
+		Tags:          s.Config.Tags,
+		Build:         s.Build,
+		Extras:        s.Extras,
+	}
+	for _, res := range s.Results {
+		r.Results = append(r.Results, Result{
+			SLI: res.ID, Value: res.Value, Unit: res.Unit, Status: res.Status, ErrorKind: res.ErrorKind,
+		})
+	}
+	return r
+}
+
+// Summary converts r back into a summary of the current schema, the inverse of FromSummary for
+// everything the history keeps. It is registered with summary.RegisterDecoder, so standalone
+// session artifacts (WriteSessionResult) load wherever summaries do.
+func (r SessionResult) Summary() summary.Summary {
+	s := summary.Summary{
+		SchemaVersion: summary.CurrentSchemaVersion,
+		GeneratedAt:   r.FinishedAt,
+		Config: summary.RunConfig{
+			RunID:      r.RunID,
+			StartedAt:  r.StartedAt,
+			FinishedAt: r.FinishedAt,
+			Tags:       r.Tags,
+		},
+		Build:   r.Build,
+		Extras:  r.Extras,
+		Results: make([]summary.SLIResult, 0, len(r.Results)),
+	}
+	for _, res := range r.Results {
+		s.Results = append(s.Results, summary.SLIResult{
+			ID: res.SLI, Value: res.Value, Unit: res.Unit, Status: res.Status, ErrorKind: res.ErrorKind,
+		})
+	}
+	return s
+}
+
+func init() {
+	summary.RegisterDecoder(SchemaVersion, func(b []byte) (*summary.Summary, error) {
+		var r SessionResult
+		if err := json.Unmarshal(b, &r); err != nil {
+			return nil, err
+		}
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+		s := r.Summary()
+		return &s, nil
+	})
+}
+
+// Validate checks that r is a well-formed session result of the current SchemaVersion.
+func (r SessionResult) Validate() error {
+	if r.SchemaVersion != SchemaVersion {
+		return fmt.Errorf("history: unsupported schemaVersion %q (want %q)", r.SchemaVersion, SchemaVersion)
+	}
+	var problems []string
+	if r.FinishedAt.IsZero() {
+		problems = append(problems, "finishedAt is not set")
+	} else if r.FinishedAt.Before(r.StartedAt) {
+		problems = append(problems, "finishedAt is before startedAt")
+	}
+	for i, res := range r.Results {
+		if res.SLI == "" {
+			problems = append(problems, fmt.Sprintf("results[%d]: sli is empty", i))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("history: invalid session result: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// WriteSessionResult writes r as a standalone artifact with w (e.g. next to the summary, for a
+// history import elsewhere): an unset schemaVersion is set to SchemaVersion and r must pass
+// Validate. It lives here rather than in artifacts, which history depends on.
+func WriteSessionResult(w *artifacts.JSONWriter, path string, r SessionResult) error {
+	if r.SchemaVersion == "" {
+		r.SchemaVersion = SchemaVersion
+	}
+	return w.WriteArtifact(path, r)
+}
+
+// Key identifies a session for de-duplication: importing the same artifacts twice stores them once.
+func (r SessionResult) Key() string {
+	return strings.Join([]string{r.RunID, r.Tags["test_case"], r.FinishedAt.UTC().Format(time.RFC3339Nano)}, "\x00")
+}
+
+// Window bounds a query by session end time; a zero bound is open.
+type Window struct {
+	From time.Time
+	To   time.Time
+}
+
+// Last returns the window of the last d up to now.
+func Last(d time.Duration) Window {
+	return Window{From: time.Now().Add(-d)}
+}
+
+func (w Window) contains(t time.Time) bool {
+	return (w.From.IsZero() || !t.Before(w.From)) && (w.To.IsZero() || !t.After(w.To))
+}
+
+// Point is the result of one SLI in one session, as returned by Query.
+type Point struct {
+	At     time.Time         `json:"at"` // session end
+	RunID  string            `json:"runId,omitempty"`
+	Value  *float64          `json:"value,omitempty"`
+	Unit   string            `json:"unit,omitempty"`
+	Status summary.Status    `json:"status"`
+	Tags   map[string]string `json:"tags,omitempty"`
+	Build  *summary.Build    `json:"build,omitempty"`
+	// ErrorKind classifies a skip ("" => measured).
+	ErrorKind string `json:"errorKind,omitempty"`
+}
+
+// Store is a history directory. It is safe for concurrent use, also by parallel processes.
+type Store struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// Open returns the store in dir, creating the directory.
+func Open(dir string) (*Store, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, errors.New("history: directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("history: %w", err)
+	}
+	return &Store{Dir: dir}, nil
+}
+
+const (
+	filePrefix = "history-"
+	fileExt    = ".jsonl"
+)
+
+// partition returns the file holding sessions that finished at t.
+func (s *Store) partition(t time.Time) string {
+	return filepath.Join(s.Dir, filePrefix+t.UTC().Format("2006-01")+fileExt)
+}
+
+// Append stores r unless a session with the same Key is stored already; added reports which.
+func (s *Store) Append(r SessionResult) (added bool, err error) {
+	if r.FinishedAt.IsZero() {
+		return false, errors.New("history: session has no finishedAt")
+	}
+	r.SchemaVersion = SchemaVersion
+	line, err := json.Marshal(r)
+	if err != nil {
+		return false, fmt.Errorf("history: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.partition(r.FinishedAt)
+	unlock, err := artifacts.LockFile(path + ".lock")
+	if err != nil {
+		return false, fmt.Errorf("history: %w", err)
+	}
+	defer unlock()
+
+	key := r.Key()
+	dup := false
+	if err := readFile(path, func(stored SessionResult) bool {
+		dup = stored.Key() == key
+		return !dup
+	}); err != nil {
+		return false, err
+	}
+	if dup {
+		return false, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return false, fmt.Errorf("history: %w", err)
+	}
+	// terminate a partial line left by a crashed append, so it does not swallow this one
+	if fi, err := f.Stat(); err == nil && fi.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, fi.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return false, fmt.Errorf("history: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return false, fmt.Errorf("history: %w", err)
+	}
+	return true, f.Close()
+}
+
+// Sessions returns the stored sessions that finished within w, oldest first.
+func (s *Store) Sessions(w Window) ([]SessionResult, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("history: %w", err)
+	}
+	var out []SessionResult
+	for _, e := range entries {
+		month, ok := partitionMonth(e.Name())
+		if !ok || !w.overlapsMonth(month) {
+			continue
+		}
+		err := readFile(filepath.Join(s.Dir, e.Name()), func(r SessionResult) bool {
+			if w.contains(r.FinishedAt) {
+				out = append(out, r)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	slices.SortStableFunc(out, func(a, b SessionResult) int { return a.FinishedAt.Compare(b.FinishedAt) })
+	return out, nil
+}
+
+// Query returns the results of sli in the sessions that finished within w, oldest first.
+func (s *Store) Query(sli string, w Window) ([]Point, error) {
+	sessions, err := s.Sessions(w)
+	if err != nil {
+		return nil, err
+	}
+	var out []Point
+	for _, r := range sessions {
+		for _, res := range r.Results {
+			if res.SLI == sli {
+				out = append(out, Point{
+					At: r.FinishedAt, RunID: r.RunID, Value: res.Value, Unit: res.Unit, Status: res.Status, Tags: r.Tags,
+					Build: r.Build, ErrorKind: res.ErrorKind,
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+// partitionMonth parses the month of a partition file name.
+func partitionMonth(name string) (time.Time, bool) {
+	m, ok := strings.CutPrefix(name, filePrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	if m, ok = strings.CutSuffix(m, fileExt); !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01", m)
+	return t, err == nil
+}
+
+// overlapsMonth reports whether any instant of the month starting at month lies within w.
+func (w Window) overlapsMonth(month time.Time) bool {
+	end := month.AddDate(0, 1, 0)
+	return (w.From.IsZero() || w.From.Before(end)) && (w.To.IsZero() || !w.To.Before(month))
+}
+
+// readFile calls fn for each session stored in path until it returns false. A missing file is
+// empty; lines that do not decode (a partial line of a crashed append, another schema) are skipped.
+func readFile(path string, fn func(SessionResult) bool) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		var r SessionResult
+		if json.Unmarshal(sc.Bytes(), &r) != nil || r.SchemaVersion != SchemaVersion {
+			continue
+		}
+		if !fn(r) {
+			return nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("history %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+func session(runID string, finished time.Time, value float64) SessionResult {
+	return FromSummary(summary.Summary{
+		Config: summary.RunConfig{
+			RunID: runID, StartedAt: finished.Add(-time.Minute), FinishedAt: finished,
+			Tags: map[string]string{"test_case": "convergence"},
+		},
+		Results: []summary.SLIResult{
+			{ID: "convergence_p99", Value: &value, Unit: "seconds", Status: summary.StatusPass},
+			{ID: "reconcile_error_delta", Status: summary.StatusSkip},
+		},
+	})
+}
+
+func TestAppendAndQuery(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sept := time.Date(2026, 9, 30, 23, 0, 0, 0, time.UTC)
+	oct := time.Date(2026, 10, 2, 8, 0, 0, 0, time.UTC)
+	for _, r := range []SessionResult{session("r2", oct, 4), session("r1", sept, 3)} {
+		if added, err := s.Append(r); err != nil || !added {
+			t.Fatalf("Append %s: %v, %v", r.RunID, added, err)
+		}
+	}
+	if added, err := s.Append(session("r1", sept, 3)); err != nil || added {
+		t.Errorf("re-import of r1: added=%v, %v", added, err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(s.Dir, "history-*.jsonl")); len(files) != 2 {
+		t.Errorf("partitions = %v, want one per month", files)
+	}
+
+	points, err := s.Query("convergence_p99", Window{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 2 || points[0].RunID != "r1" || *points[1].Value != 4 || points[1].Unit != "seconds" {
+		t.Fatalf("points = %+v", points)
+	}
+	points, _ = s.Query("convergence_p99", Window{From: sept.Add(time.Hour)})
+	if len(points) != 1 || points[0].RunID != "r2" {
+		t.Errorf("windowed points = %+v", points)
+	}
+	if points, _ := s.Query("nope", Window{}); len(points) != 0 {
+		t.Errorf("unknown sli: %+v", points)
+	}
+}
+
+func TestAppendAfterPartialLine(t *testing.T) {
+	s, _ := Open(t.TempDir())
+	at := time.Date(2026, 10, 2, 8, 0, 0, 0, time.UTC)
+	if _, err := s.Append(session("r1", at, 1)); err != nil {
+		t.Fatal(err)
+	}
+	// a crash in the middle of the next append
+	f, err := os.OpenFile(s.partition(at), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"schemaVersion":"slo-history.v1","runId":"r`)
+	_ = f.Close()
+
+	if _, err := s.Append(session("r2", at.Add(time.Hour), 2)); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := s.Sessions(Window{})
+	if err != nil || len(sessions) != 2 || sessions[1].RunID != "r2" {
+		t.Fatalf("sessions = %+v, %v", sessions, err)
+	}
+}
+
+func TestWriteSessionResult(t *testing.T) {
+	w := artifacts.NewJSONWriter(artifacts.Options{})
+	path := filepath.Join(t.TempDir(), "session.json")
+	r := session("r1", time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), 2)
+	r.SchemaVersion = ""
+	if err := WriteSessionResult(w, path, r); err != nil {
+		t.Fatalf("WriteSessionResult: %v", err)
+	}
+	if b, err := os.ReadFile(path); err != nil || !strings.Contains(string(b), `"schemaVersion": "`+SchemaVersion) {
+		t.Errorf("content %s, %v", b, err)
+	}
+
+	r.Results[0].SLI = ""
+	if err := WriteSessionResult(w, path+".bad", r); err == nil {
+		t.Error("a result without sli must not be written")
+	}
+}
+
+// A standalone session artifact loads as a summary through the registered decoder.
+func TestSessionResultLoadsAsSummary(t *testing.T) {
+	w := artifacts.NewJSONWriter(artifacts.Options{})
+	path := filepath.Join(t.TempDir(), "session.json")
+	r := session("r1", time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), 2)
+	r.Extras = map[string]float64{"objects_created": 5}
+	if err := WriteSessionResult(w, path, r); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := summary.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s.SchemaVersion != summary.CurrentSchemaVersion || s.Config.RunID != "r1" || s.Extras["objects_created"] != 5 {
+		t.Fatalf("summary = %+v", s)
+	}
+	if len(s.Results) != 2 || s.Results[0].ID != "convergence_p99" || *s.Results[0].Value != 2 {
+		t.Fatalf("results = %+v", s.Results)
+	}
+	if back := FromSummary(*s); back.Key() != r.Key() || len(back.Results) != len(r.Results) {
+		t.Fatalf("round trip = %+v", back)
+	}
+}
@@ -0,0 +1,11 @@
+package slo
+
+import "github.com/yeongki/my-operator/pkg/slo/summary"
+
+// LoadSummary reads the summary artifact at path (gzip-compressed or not) and validates it; see
+// summary.Load. Documents of another schemaVersion are rejected with summary.ErrUnsupportedSchema
+// unless a decoder for that version is registered (e.g. by importing pkg/slo/history), in which
+// case they are converted to the current schema.
+func LoadSummary(path string) (*summary.Summary, error) {
+	return summary.Load(path)
+}
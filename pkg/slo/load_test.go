@@ -0,0 +1,48 @@
+package slo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+func TestLoadSummary(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	good := filepath.Join(dir, "summary.json")
+	s := summary.Summary{
+		SchemaVersion: summary.CurrentSchemaVersion,
+		GeneratedAt:   now,
+		Config:        summary.RunConfig{StartedAt: now.Add(-time.Minute), FinishedAt: now},
+		Results:       []summary.SLIResult{{ID: "a", Status: summary.StatusPass}},
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(good, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := slo.LoadSummary(good)
+	if err != nil {
+		t.Fatalf("LoadSummary: %v", err)
+	}
+	if got.SchemaVersion != summary.CurrentSchemaVersion || len(got.Results) != 1 {
+		t.Fatalf("unexpected summary: %+v", got)
+	}
+
+	old := filepath.Join(dir, "old.json")
+	if err := os.WriteFile(old, []byte(`{"schemaVersion":"slo.v1","results":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := slo.LoadSummary(old); !errors.Is(err, summary.ErrUnsupportedSchema) {
+		t.Fatalf("expected ErrUnsupportedSchema, got %v", err)
+	}
+}
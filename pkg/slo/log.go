@@ -1,10 +1,91 @@
 package slo
 
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
 // Logger is the minimal logging contract for pkg/slo.
+//
+// A Logger that also implements LevelLogger keeps the level of each line; Debugf, Infof and Warnf
+// log through any Logger, so callers never need to know which kind they were given.
 type Logger interface {
 	Logf(format string, args ...any)
 }
 
+// Level is the severity of a log line. The zero value is LevelInfo, so a zero minimum level drops
+// only debug lines.
+type Level int8
+
+const (
+	LevelDebug Level = -1
+	LevelInfo  Level = 0
+	LevelWarn  Level = 1
+)
+
+func (l Level) String() string {
+	switch {
+	case l <= LevelDebug:
+		return "debug"
+	case l >= LevelWarn:
+		return "warn"
+	}
+	return "info"
+}
+
+// ParseLevel parses "debug", "info" or "warn"/"warning" (case-insensitive; "" => info).
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "", "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	}
+	return LevelInfo, fmt.Errorf("slo: unknown log level %q (debug, info, warn)", s)
+}
+
+// LevelLogger is a Logger that keeps the level of a line (e.g. to filter or tag it). Logf logs at
+// LevelInfo.
+type LevelLogger interface {
+	Logger
+	LogLevel(level Level, msg string)
+}
+
+// Debugf logs at LevelDebug. A plain Logger gets the line prefixed with "DEBUG: ".
+func Debugf(l Logger, format string, args ...any) { Log(l, LevelDebug, format, args...) }
+
+// Infof logs at LevelInfo; for a plain Logger it is Logf.
+func Infof(l Logger, format string, args ...any) { Log(l, LevelInfo, format, args...) }
+
+// Warnf logs at LevelWarn. A plain Logger gets the line prefixed with "WARNING: ".
+func Warnf(l Logger, format string, args ...any) { Log(l, LevelWarn, format, args...) }
+
+// Log logs at level through l (nil => no-op): a LevelLogger gets the level, a plain Logger the
+// line with the level's prefix.
+func Log(l Logger, level Level, format string, args ...any) {
+	switch l := l.(type) {
+	case nil:
+	case LevelLogger:
+		l.LogLevel(level, fmt.Sprintf(format, args...))
+	default:
+		l.Logf("%s%s", levelPrefix(level), fmt.Sprintf(format, args...))
+	}
+}
+
+// levelPrefix is the marker of a level in plain text output ("" for info).
+func levelPrefix(level Level) string {
+	switch {
+	case level <= LevelDebug:
+		return "DEBUG: "
+	case level >= LevelWarn:
+		return "WARNING: "
+	}
+	return ""
+}
+
 type nopLogger struct{}
 
 func (nopLogger) Logf(string, ...any) {}
@@ -19,3 +100,48 @@ func NewLogger(l Logger) Logger {
 
 // NopLogger exported singleton if you like
 var NopLogger Logger = nopLogger{}
+
+// MinLevel returns a logger passing only the lines of level >= min to l (nil => no-op). The level
+// is kept when l is a LevelLogger.
+func MinLevel(l Logger, min Level) LevelLogger {
+	return minLevelLogger{l: NewLogger(l), min: min}
+}
+
+type minLevelLogger struct {
+	l   Logger
+	min Level
+}
+
+func (m minLevelLogger) Logf(format string, args ...any) {
+	m.LogLevel(LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (m minLevelLogger) LogLevel(level Level, msg string) {
+	if level >= m.min {
+		Log(m.l, level, "%s", msg)
+	}
+}
+
+// StdLogger adapts a stdlib *log.Logger (nil => log.Default()), dropping the lines below min.
+// Debug and warning lines get the DEBUG: / WARNING: prefix.
+func StdLogger(l *log.Logger, min Level) LevelLogger {
+	if l == nil {
+		l = log.Default()
+	}
+	return stdLogger{l: l, min: min}
+}
+
+type stdLogger struct {
+	l   *log.Logger
+	min Level
+}
+
+func (s stdLogger) Logf(format string, args ...any) {
+	s.LogLevel(LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (s stdLogger) LogLevel(level Level, msg string) {
+	if level >= s.min {
+		s.l.Print(levelPrefix(level) + msg)
+	}
+}
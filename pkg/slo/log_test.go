@@ -0,0 +1,48 @@
+package slo
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"testing"
+)
+
+type lines []string
+
+func (l *lines) Logf(format string, args ...any) { *l = append(*l, fmt.Sprintf(format, args...)) }
+
+func TestLeveledLogging(t *testing.T) {
+	var plain lines
+	Debugf(&plain, "poll %d", 1)
+	Infof(&plain, "started")
+	Warnf(&plain, "fetch failed: %v", "boom")
+	Warnf(nil, "dropped")
+	want := lines{"DEBUG: poll 1", "started", "WARNING: fetch failed: boom"}
+	if fmt.Sprint(plain) != fmt.Sprint(want) {
+		t.Errorf("plain logger got %q, want %q", plain, want)
+	}
+
+	var filtered lines
+	l := MinLevel(&filtered, LevelInfo)
+	Debugf(l, "poll")
+	l.Logf("info")
+	Warnf(l, "warn")
+	if fmt.Sprint(filtered) != fmt.Sprint(lines{"info", "WARNING: warn"}) {
+		t.Errorf("MinLevel(info) got %q", filtered)
+	}
+
+	var buf bytes.Buffer
+	std := StdLogger(log.New(&buf, "", 0), LevelWarn)
+	std.Logf("info")
+	Warnf(std, "disk full")
+	if buf.String() != "WARNING: disk full\n" {
+		t.Errorf("StdLogger(warn) wrote %q", buf.String())
+	}
+
+	if lv, err := ParseLevel("WARNING"); err != nil || lv != LevelWarn {
+		t.Errorf("ParseLevel(WARNING) = %v, %v", lv, err)
+	}
+	if _, err := ParseLevel("trace"); err == nil {
+		t.Error("ParseLevel(trace) must fail")
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/yeongki/my-operator/pkg/slo/logradapter
+
+go 1.24.0
+
+require (
+	github.com/go-logr/logr v1.4.2
+	github.com/yeongki/my-operator/pkg/slo v0.0.0-00010101000000-000000000000
+)
+
+// logradapter is its own module so pkg/slo stays stdlib-only; the manager (or any logr user)
+// depends on it instead.
+replace github.com/yeongki/my-operator/pkg/slo => ../
@@ -0,0 +1,40 @@
+// Package logradapter logs pkg/slo through a logr.Logger, so code running inside the manager
+// (the SLO agent, an in-cluster measurement sidecar) writes to controller-runtime's log instead of
+// GinkgoWriter or stdout. It is a separate module: pkg/slo itself stays stdlib-only.
+//
+//	agent, err := sloagent.New(sloagent.Options{Logger: logradapter.New(ctrl.Log.WithName("sloagent"))})
+//
+// klog users pass klog.Background() (a logr.Logger as well).
+package logradapter
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// DebugV is the logr verbosity of slo.LevelDebug lines.
+const DebugV = 1
+
+// New adapts l to slo.Logger. It is an slo.LevelLogger: debug lines go to V(DebugV), and since
+// logr has no warning level, warnings are info lines with "warning"=true.
+func New(l logr.Logger) slo.LevelLogger {
+	return logger{l: l}
+}
+
+type logger struct{ l logr.Logger }
+
+func (l logger) Logf(format string, args ...any) { l.l.Info(fmt.Sprintf(format, args...)) }
+
+func (l logger) LogLevel(level slo.Level, msg string) {
+	switch {
+	case level <= slo.LevelDebug:
+		l.l.V(DebugV).Info(msg)
+	case level >= slo.LevelWarn:
+		l.l.Info(msg, "warning", true)
+	default:
+		l.l.Info(msg)
+	}
+}
@@ -0,0 +1,28 @@
+package logradapter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+func TestNew(t *testing.T) {
+	var got []string
+	l := funcr.New(func(prefix, args string) { got = append(got, args) }, funcr.Options{Verbosity: 0})
+	log := New(l.WithName("sloagent"))
+
+	log.Logf("window %d written", 3)
+	slo.Debugf(log, "sample taken")
+	slo.Warnf(log, "upload failed: %v", "denied")
+
+	want := []string{
+		`"level"=0 "msg"="window 3 written"`,
+		`"level"=0 "msg"="upload failed: denied" "warning"=true`,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
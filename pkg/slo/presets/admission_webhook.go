@@ -0,0 +1,56 @@
+package presets
+
+import "github.com/yeongki/my-operator/pkg/slo/spec"
+
+// AdmissionWebhook covers the kube-apiserver admission webhook metrics: the latency the API server
+// observed calling webhook (its configured webhook name, e.g. "vjoboperator.kb.io"; "" => all
+// webhooks) and the requests it rejected. These metrics come from the API server's /metrics, not
+// from the operator.
+func AdmissionWebhook(webhook string) []spec.SLISpec {
+	var labels spec.Labels
+	scope := "all webhooks"
+	if webhook != "" {
+		labels = spec.Labels{"name": webhook}
+		scope = "webhook " + webhook
+	}
+	return []spec.SLISpec{
+		{
+			ID:          "admission_webhook_calls_delta",
+			Title:       "admission webhook calls delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: "Delta of apiserver_admission_webhook_admission_duration_seconds_count (" + scope + ").",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("apiserver_admission_webhook_admission_duration_seconds_count", labels),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:    "admission_webhook_latency_p99",
+			Title: "admission webhook latency p99",
+			Unit:  "seconds",
+			Kind:  spec.KindHistogram,
+			Description: "p99 of apiserver_admission_webhook_admission_duration_seconds over the test window (" +
+				scope + "): webhook call latency as seen by the API server.",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("apiserver_admission_webhook_admission_duration_seconds_bucket", labels),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeQuantile, Quantile: 0.99},
+		},
+		{
+			ID:    "admission_webhook_rejections_delta",
+			Title: "admission webhook rejections delta",
+			Unit:  "count",
+			Kind:  spec.KindDeltaCounter,
+			Description: "Delta of apiserver_admission_webhook_rejection_count (" + scope + "): denied requests " +
+				`(error_type="no_error") and failed calls, per error_type in fields.`,
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("apiserver_admission_webhook_rejection_count", labels),
+			},
+			Compute:     spec.ComputeSpec{Mode: spec.ComputeDelta},
+			BreakdownBy: []string{"error_type"},
+		},
+	}
+}
+
+func admissionWebhookAll() []spec.SLISpec { return AdmissionWebhook("") }
@@ -0,0 +1,78 @@
+// Package presets holds reusable SLI spec sets, shared by the Ginkgo harness and slocli.
+package presets
+
+import "github.com/yeongki/my-operator/pkg/slo/spec"
+
+// ControllerRuntime is the baseline preset set for controller-runtime based operators:
+// controller-runtime reconcile + workqueue + rest-client (client-go) metrics.
+// No spec sets BreakdownBy: per-label fields are opt-in, e.g. set ["controller"] on the
+// reconcile specs for an operator running several controllers.
+func ControllerRuntime() []spec.SLISpec {
+	specs := reconcile()
+	specs = append(specs, Workqueue()...)
+	return append(specs, RESTClient()...)
+}
+
+// reconcile covers controller_runtime_reconcile_total.
+func reconcile() []spec.SLISpec {
+	return []spec.SLISpec{
+		// ---------------------------
+		// controller-runtime reconcile
+		// ---------------------------
+		{
+			ID:          "reconcile_total_delta",
+			Title:       "reconcile total delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: "Delta of controller_runtime_reconcile_total during the test window (all results).",
+			Inputs: []spec.MetricRef{
+				// name-only aggregation is supported by promtext.AggregateByName (out[name]+=val)
+				spec.PromMetric("controller_runtime_reconcile_total", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "reconcile_success_delta",
+			Title:       "reconcile success delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: `Delta of controller_runtime_reconcile_total{result="success"}.`,
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("controller_runtime_reconcile_total", spec.Labels{"result": "success"}),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "reconcile_error_delta",
+			Title:       "reconcile error delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: `Delta of controller_runtime_reconcile_total{result="error"}.`,
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("controller_runtime_reconcile_total", spec.Labels{"result": "error"}),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+			// Optional judge example: error delta should be 0
+			// Judge: &spec.JudgeSpec{Rules: []spec.Rule{{Op: spec.OpGT, Target: 0, Level: spec.LevelFail}}},
+		},
+		{
+			ID:          "reconcile_error_ratio",
+			Title:       "reconcile error ratio",
+			Unit:        "ratio",
+			Kind:        spec.KindDerived,
+			Description: "reconcile_error_delta / reconcile_total_delta (skipped when nothing was reconciled).",
+			Derived: &spec.DerivedSpec{
+				Op:       spec.DerivedRatio,
+				Operands: []string{"reconcile_error_delta", "reconcile_total_delta"},
+			},
+		},
+		{
+			ID:          "reconcile_rate",
+			Title:       "reconciles per second",
+			Unit:        "1/s",
+			Kind:        spec.KindDerived,
+			Description: "reconcile_total_delta / window seconds.",
+			Derived:     &spec.DerivedSpec{Op: spec.DerivedRate, Operands: []string{"reconcile_total_delta"}},
+		},
+	}
+}
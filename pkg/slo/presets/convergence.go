@@ -0,0 +1,58 @@
+package presets
+
+import "github.com/yeongki/my-operator/pkg/slo/spec"
+
+// Convergence covers the controller-timed convergence histograms: myoperator_convergence_seconds
+// (from the test/start-time annotation, devutil.SetTestStartTimeAnno, until the JobOperator is
+// first marked Ready; only annotated objects are counted) and myoperator_deletion_duration_seconds
+// (from the deletion request until the finalizer cleaned up and released the object).
+func Convergence() []spec.SLISpec {
+	return []spec.SLISpec{
+		{
+			ID:          "myoperator_converged_delta",
+			Title:       "converged objects delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: "Delta of myoperator_convergence_seconds_count: annotated objects that became Ready.",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("myoperator_convergence_seconds_count", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:    "myoperator_convergence_p99",
+			Title: "convergence p99",
+			Unit:  "seconds",
+			Kind:  spec.KindHistogram,
+			Description: "p99 of myoperator_convergence_seconds over the test window: test/start-time to Ready " +
+				"as timed by the controller.",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("myoperator_convergence_seconds_bucket", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeQuantile, Quantile: 0.99},
+		},
+		{
+			ID:          "myoperator_deleted_delta",
+			Title:       "deleted objects delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: "Delta of myoperator_deletion_duration_seconds_count: objects whose finalizer was released.",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("myoperator_deletion_duration_seconds_count", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:    "myoperator_deletion_p99",
+			Title: "deletion convergence p99",
+			Unit:  "seconds",
+			Kind:  spec.KindHistogram,
+			Description: "p99 of myoperator_deletion_duration_seconds over the test window: deletion request to " +
+				"finalizer release (dependent resources cleaned up).",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("myoperator_deletion_duration_seconds_bucket", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeQuantile, Quantile: 0.99},
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package presets
+
+import "github.com/yeongki/my-operator/pkg/slo/spec"
+
+// KubeEvents covers the series derived from Kubernetes Events of the test namespace (see
+// harness.EventsFetcher): warnings and back-offs the scheduler and kubelet report, which the
+// operator's own metrics never see.
+func KubeEvents() []spec.SLISpec {
+	return []spec.SLISpec{
+		{
+			ID:          "k8s_warning_events_delta",
+			Title:       "warning events delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: `Warning Events recorded during the window (k8s_events_total{type="Warning"}), per reason in fields.`,
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("k8s_events_total", spec.Labels{"type": "Warning"}),
+			},
+			Compute:     spec.ComputeSpec{Mode: spec.ComputeDelta},
+			BreakdownBy: []string{"reason"},
+		},
+		{
+			ID:          "k8s_backoff_events_delta",
+			Title:       "back-off events delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: `BackOff Events (crash loops, image pull back-off) recorded during the window.`,
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("k8s_events_total", spec.Labels{"reason": "BackOff"}),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:    "k8s_pod_first_event_to_started_seconds",
+			Title: "pod first event to container started",
+			Unit:  "seconds",
+			Kind:  spec.KindGauge,
+			Description: "Slowest pod of the window from its first Event (usually Scheduled) to its last " +
+				"Started Event: scheduling, image pull and kubelet delays.",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("k8s_event_pod_first_to_started_seconds", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeEnd},
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package presets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+// registry maps preset names (as used on the slocli command line) to their constructors.
+var registry = map[string]func() []spec.SLISpec{
+	"controller-runtime": ControllerRuntime,
+	"workqueue":          Workqueue,
+	"process":            Process,
+	"rest-client":        RESTClient,
+	"admission-webhook":  admissionWebhookAll,
+	"kube-events":        KubeEvents,
+	"convergence":        Convergence,
+}
+
+// ByName returns a fresh copy of the named preset.
+func ByName(name string) ([]spec.SLISpec, bool) {
+	f, ok := registry[name]
+	if !ok {
+		return nil, false
+	}
+	return f(), true
+}
+
+// Resolve returns the concatenation of comma-separated presets, e.g. "controller-runtime,process".
+// Presets overlap (controller-runtime includes workqueue and rest-client), so an SLI ID that is
+// already resolved is dropped.
+func Resolve(names string) ([]spec.SLISpec, error) {
+	var out []spec.SLISpec
+	seen := map[string]bool{}
+	for _, n := range strings.Split(names, ",") {
+		n = strings.TrimSpace(n)
+		if n == "" {
+			continue
+		}
+		specs, ok := ByName(n)
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %q", n)
+		}
+		for _, s := range specs {
+			if !seen[s.ID] {
+				seen[s.ID] = true
+				out = append(out, s)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no preset in %q", names)
+	}
+	return out, nil
+}
+
+// Names lists the registered presets in sorted order.
+func Names() []string {
+	out := make([]string, 0, len(registry))
+	for n := range registry {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
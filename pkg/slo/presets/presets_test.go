@@ -0,0 +1,35 @@
+package presets
+
+import "testing"
+
+func TestResolveOverlapping(t *testing.T) {
+	specs, err := Resolve("controller-runtime, workqueue,rest-client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := len(ControllerRuntime()); len(specs) != want {
+		t.Fatalf("expected the %d controller-runtime specs once, got %d", want, len(specs))
+	}
+	seen := map[string]bool{}
+	for _, s := range specs {
+		if seen[s.ID] {
+			t.Fatalf("duplicate SLI ID %q", s.ID)
+		}
+		seen[s.ID] = true
+	}
+
+	if _, err := Resolve("workqueue,nope"); err == nil {
+		t.Fatal("expected an unknown preset to fail")
+	}
+	if _, err := Resolve(" , "); err == nil {
+		t.Fatal("expected an empty preset list to fail")
+	}
+}
+
+func TestControllerRuntimeBreakdownOptIn(t *testing.T) {
+	for _, s := range ControllerRuntime() {
+		if len(s.BreakdownBy) > 0 {
+			t.Errorf("%s: BreakdownBy %v must be left to the caller", s.ID, s.BreakdownBy)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package presets
+
+import "github.com/yeongki/my-operator/pkg/slo/spec"
+
+// Process covers the Go runtime and process metrics of the measured manager
+// (process_*, go_*). They are resource-usage measurements (spec.CategoryResource), so
+// reconcile throughput regressions can be correlated with memory/CPU growth.
+// Gauges report their window max, with start/end in Fields.
+func Process() []spec.SLISpec {
+	return []spec.SLISpec{
+		{
+			ID:          "process_resident_memory_max",
+			Title:       "resident memory max",
+			Unit:        "bytes",
+			Kind:        spec.KindGauge,
+			Category:    spec.CategoryResource,
+			Description: "Highest process_resident_memory_bytes seen in the window (start/end in fields).",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("process_resident_memory_bytes", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeMax},
+		},
+		{
+			ID:          "go_goroutines_max",
+			Title:       "goroutines max",
+			Unit:        "goroutines",
+			Kind:        spec.KindGauge,
+			Category:    spec.CategoryResource,
+			Description: "Highest go_goroutines seen in the window (start/end in fields).",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("go_goroutines", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeMax},
+		},
+		{
+			ID:          "process_cpu_seconds_delta",
+			Title:       "CPU seconds",
+			Unit:        "seconds",
+			Kind:        spec.KindDeltaCounter,
+			Category:    spec.CategoryResource,
+			Description: "Delta of process_cpu_seconds_total: CPU time (user+system) used during the window.",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("process_cpu_seconds_total", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "process_cpu_cores",
+			Title:       "CPU cores (average)",
+			Unit:        "cores",
+			Kind:        spec.KindDerived,
+			Category:    spec.CategoryResource,
+			Description: "process_cpu_seconds_delta / window seconds.",
+			Derived:     &spec.DerivedSpec{Op: spec.DerivedRate, Operands: []string{"process_cpu_seconds_delta"}},
+		},
+		{
+			ID:          "go_gc_cycles_delta",
+			Title:       "GC cycles",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Category:    spec.CategoryResource,
+			Description: "Delta of go_gc_duration_seconds_count: completed GC cycles during the window.",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("go_gc_duration_seconds_count", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "go_gc_pause_seconds_delta",
+			Title:       "GC pause seconds",
+			Unit:        "seconds",
+			Kind:        spec.KindDeltaCounter,
+			Category:    spec.CategoryResource,
+			Description: "Delta of go_gc_duration_seconds_sum: total GC pause time during the window.",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("go_gc_duration_seconds_sum", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "go_gc_pause_mean",
+			Title:       "GC pause mean",
+			Unit:        "seconds",
+			Kind:        spec.KindDerived,
+			Category:    spec.CategoryResource,
+			Description: "go_gc_pause_seconds_delta / go_gc_cycles_delta (skipped when no GC ran).",
+			Derived: &spec.DerivedSpec{
+				Op:       spec.DerivedRatio,
+				Operands: []string{"go_gc_pause_seconds_delta", "go_gc_cycles_delta"},
+			},
+		},
+	}
+}
@@ -0,0 +1,103 @@
+package presets
+
+import "github.com/yeongki/my-operator/pkg/slo/spec"
+
+// RESTClient covers client-go rest_client_requests_total: API server traffic of the operator,
+// writes and error codes, and the write amplification per reconcile (writes and PATCH calls per
+// reconcile_total_delta, skipped when the reconcile preset is not evaluated). Method and code
+// selectors read 0 while the operator made no such call (spec.MetricRef.OrZero).
+func RESTClient() []spec.SLISpec {
+	return []spec.SLISpec{
+		{
+			ID:          "rest_client_requests_total_delta",
+			Title:       "rest client requests total delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: "Delta of rest_client_requests_total during the test window.",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("rest_client_requests_total", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "rest_client_writes_delta",
+			Title:       "rest client writes delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: "Delta of rest_client_requests_total for POST, PUT, PATCH and DELETE.",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("rest_client_requests_total", spec.Labels{"method": "POST"}).OrZero(),
+				spec.PromMetric("rest_client_requests_total", spec.Labels{"method": "PUT"}).OrZero(),
+				spec.PromMetric("rest_client_requests_total", spec.Labels{"method": "PATCH"}).OrZero(),
+				spec.PromMetric("rest_client_requests_total", spec.Labels{"method": "DELETE"}).OrZero(),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "rest_client_patch_delta",
+			Title:       "rest client PATCH delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: `Delta of rest_client_requests_total{method="PATCH"}.`,
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("rest_client_requests_total", spec.Labels{"method": "PATCH"}).OrZero(),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "rest_client_writes_per_reconcile",
+			Title:       "API writes per reconcile",
+			Unit:        "ratio",
+			Kind:        spec.KindDerived,
+			Description: "rest_client_writes_delta / reconcile_total_delta: API write amplification.",
+			Derived: &spec.DerivedSpec{
+				Op:       spec.DerivedRatio,
+				Operands: []string{"rest_client_writes_delta", "reconcile_total_delta"},
+			},
+		},
+		{
+			ID:          "rest_client_patch_per_reconcile",
+			Title:       "PATCH calls per reconcile",
+			Unit:        "ratio",
+			Kind:        spec.KindDerived,
+			Description: "rest_client_patch_delta / reconcile_total_delta.",
+			Derived: &spec.DerivedSpec{
+				Op:       spec.DerivedRatio,
+				Operands: []string{"rest_client_patch_delta", "reconcile_total_delta"},
+			},
+		},
+		{
+			ID:          "rest_client_429_delta",
+			Title:       "rest client 429 delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: `Delta of rest_client_requests_total{code="429"}. Indicates API server throttling.`,
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("rest_client_requests_total", spec.Labels{"code": "429"}).OrZero(),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "rest_client_403_delta",
+			Title:       "rest client 403 delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: `Delta of rest_client_requests_total{code="403"}. Requests the operator's RBAC does not allow.`,
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("rest_client_requests_total", spec.Labels{"code": "403"}).OrZero(),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "rest_client_5xx_delta",
+			Title:       "rest client 5xx delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: `Delta of rest_client_requests_total{code="5xx"}. Some client-go versions aggregate 5xx as "5xx".`,
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("rest_client_requests_total", spec.Labels{"code": "5xx"}).OrZero(),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package presets
+
+import "github.com/yeongki/my-operator/pkg/slo/spec"
+
+// Workqueue covers the client-go workqueue metrics exposed by controller-runtime.
+// Counters are delta'd over the window, the depth gauge is sampled (end and max) and
+// the queue duration histogram is reduced to a p99 of the window.
+func Workqueue() []spec.SLISpec {
+	return []spec.SLISpec{
+		{
+			ID:          "workqueue_adds_total_delta",
+			Title:       "workqueue adds total delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: "Delta of workqueue_adds_total during the test window (all queues).",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("workqueue_adds_total", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "workqueue_retries_total_delta",
+			Title:       "workqueue retries total delta",
+			Unit:        "count",
+			Kind:        spec.KindDeltaCounter,
+			Description: "Delta of workqueue_retries_total during the test window (all queues).",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("workqueue_retries_total", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:          "workqueue_depth_end",
+			Title:       "workqueue depth at end",
+			Unit:        "items",
+			Kind:        spec.KindGauge,
+			Description: "workqueue_depth gauge snapshot at the end time (all queues).",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("workqueue_depth", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeEnd},
+		},
+		{
+			ID:          "workqueue_depth_max",
+			Title:       "workqueue depth max",
+			Unit:        "items",
+			Kind:        spec.KindGauge,
+			Description: "Highest workqueue_depth seen in the window (edges, plus polls with SessionV4Config.PeakInterval or a fetch.PeakTracker).",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("workqueue_depth", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeMax},
+		},
+		{
+			ID:    "workqueue_queue_duration_p99",
+			Title: "workqueue queue duration p99",
+			Unit:  "seconds",
+			Kind:  spec.KindHistogram,
+			Description: "p99 of workqueue_queue_duration_seconds over the test window (all queues): " +
+				"how long items waited before being processed.",
+			Inputs: []spec.MetricRef{
+				spec.PromMetric("workqueue_queue_duration_seconds_bucket", nil),
+			},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeQuantile, Quantile: 0.99},
+		},
+	}
+}
@@ -0,0 +1,134 @@
+// Package replay records everything an evaluation consumed (specs, snapshots, run config)
+// into a bundle and re-evaluates it later, so an engine upgrade can be checked against
+// historical verdicts before it is trusted to gate a release.
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/common/fsname"
+	"github.com/yeongki/my-operator/pkg/slo/common/gzfile"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// SchemaVersion identifies the bundle session format written by Recorder.
+const SchemaVersion = "slo-bundle.v1"
+
+// ErrUnsupportedBundle is returned when a bundle file has a missing or unknown schemaVersion.
+var ErrUnsupportedBundle = errors.New("unsupported bundle schema")
+
+// Snapshot is one recorded fetch: the values the engine saw, or the error it got.
+type Snapshot struct {
+	At     time.Time          `json:"at"`
+	Values map[string]float64 `json:"values,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// Session is one recorded evaluation: the inputs plus the summary produced at the time.
+// The run config (window, mode, tags, disruptions) is taken from Summary.
+type Session struct {
+	SchemaVersion string           `json:"schemaVersion"`
+	RecordedAt    time.Time        `json:"recordedAt"`
+	Specs         []spec.SLISpec   `json:"specs"`
+	Snapshots     []Snapshot       `json:"snapshots"`
+	Summary       *summary.Summary `json:"summary"`
+}
+
+// Recorder writes sessions into a bundle directory, one JSON file per session.
+type Recorder struct {
+	Dir string
+
+	w   *artifacts.JSONWriter
+	mu  sync.Mutex
+	seq int
+
+	// opts names the session files (Gzip => *.json.gz).
+	opts artifacts.Options
+}
+
+// NewRecorder returns a Recorder writing into dir (created on first write).
+func NewRecorder(dir string, opts artifacts.Options) *Recorder {
+	return &Recorder{Dir: dir, w: artifacts.NewJSONWriter(opts), opts: opts}
+}
+
+// Record writes one session and returns its path. An empty Dir is a no-op.
+func (r *Recorder) Record(specs []spec.SLISpec, snaps []Snapshot, sum *summary.Summary) (string, error) {
+	if r == nil || strings.TrimSpace(r.Dir) == "" {
+		return "", nil
+	}
+	if sum == nil {
+		return "", errors.New("replay: no summary to record")
+	}
+
+	r.mu.Lock()
+	r.seq++
+	seq := r.seq
+	r.mu.Unlock()
+
+	now := time.Now()
+	name := fmt.Sprintf("session.%s.%s.%d-%d.json",
+		fsname.Sanitize(sum.Config.RunID), fsname.Sanitize(sum.Config.Tags["test_case"]), now.UnixNano(), seq)
+	path := r.opts.Name(filepath.Join(r.Dir, name))
+
+	return path, r.w.WriteJSON(path, Session{
+		SchemaVersion: SchemaVersion,
+		RecordedAt:    now,
+		Specs:         specs,
+		Snapshots:     snaps,
+		Summary:       sum,
+	})
+}
+
+// LoadSession reads one bundle session file (gzip-compressed or not).
+func LoadSession(path string) (*Session, error) {
+	b, err := gzfile.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	if s.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("%w: %q (want %q)", ErrUnsupportedBundle, s.SchemaVersion, SchemaVersion)
+	}
+	if s.Summary == nil {
+		return nil, errors.New("replay: session has no recorded summary")
+	}
+	return &s, nil
+}
+
+// LoadBundle reads every session file (*.json, *.json.gz) directly under dir, sorted by file name.
+// Any unreadable session fails the load: a replay that silently drops history proves nothing.
+func LoadBundle(dir string) (paths []string, sessions []*Session, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !gzfile.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		s, err := LoadSession(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", p, err)
+		}
+		sessions = append(sessions, s)
+	}
+	return paths, sessions, nil
+}
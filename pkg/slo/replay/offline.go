@@ -0,0 +1,211 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/common/gzfile"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+)
+
+// FileFetcher is the offline (dry-run) fetcher: it serves recorded snapshots from files instead of
+// scraping a cluster, so the whole fetch -> engine -> writer chain (a harness session, slocli
+// measure) runs in plain unit tests and demos without Kubernetes.
+//
+// Each Fetch returns the next snapshot in order; once all were served the last one repeats, so
+// extra fetches (checkpoints, peak sampling) see a steady state. Snapshots come from:
+//   - *.json: a Snapshot (as written by RecordingFetcher) or a bundle session (all its snapshots);
+//   - any other file: a Prometheus text or OpenMetrics scrape, e.g. a saved /metrics body or a
+//     CaptureScrapes *.prom.gz.
+//
+// A snapshot keeps its recorded time (a scrape: the file's modification time), so the replayed
+// window and rates are the original ones. Gzip-compressed files are read transparently. A
+// recorded fetch error is returned again.
+type FileFetcher struct {
+	snaps []fileSnapshot
+
+	mu   sync.Mutex
+	next int
+}
+
+type fileSnapshot struct {
+	path string
+	snap *Snapshot // nil => raw scrape in path, parsed on Fetch
+	at   time.Time // of a raw scrape: the file's modification time
+}
+
+// snapshotExt lists the file names NewFileFetcher picks up from a directory.
+var snapshotExt = []string{".json", ".prom", ".txt", ".metrics"}
+
+// NewFileFetcher loads the snapshots of paths, in the order given. A directory stands for its
+// snapshot files (*.json, *.prom, *.txt, *.metrics, optionally .gz) sorted by name.
+func NewFileFetcher(paths ...string) (*FileFetcher, error) {
+	f := &FileFetcher{}
+	for _, p := range paths {
+		files, err := snapshotFiles(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			snaps, err := loadSnapshots(file)
+			if err != nil {
+				return nil, fmt.Errorf("replay: %s: %w", file, err)
+			}
+			f.snaps = append(f.snaps, snaps...)
+		}
+	}
+	if len(f.snaps) == 0 {
+		return nil, fmt.Errorf("replay: no snapshots in %s", strings.Join(paths, ", "))
+	}
+	return f, nil
+}
+
+// Len is the number of loaded snapshots.
+func (f *FileFetcher) Len() int { return len(f.snaps) }
+
+func (f *FileFetcher) Fetch(_ context.Context, at time.Time) (fetch.Sample, error) {
+	f.mu.Lock()
+	fs := f.snaps[min(f.next, len(f.snaps)-1)]
+	f.next++
+	f.mu.Unlock()
+
+	if fs.snap == nil {
+		raw, err := gzfile.ReadFile(fs.path)
+		if err != nil {
+			return fetch.Sample{}, err
+		}
+		if !fs.at.IsZero() {
+			at = fs.at
+		}
+		return fetch.SampleFromText(at, string(raw), &fetch.Provenance{Fetcher: "file", Target: fs.path})
+	}
+	if fs.snap.Error != "" {
+		return fetch.Sample{}, errors.New(fs.snap.Error)
+	}
+	// the recorded time keeps the window of the original run (rates, derived SLIs)
+	if !fs.snap.At.IsZero() {
+		at = fs.snap.At
+	}
+	prov := &fetch.Provenance{Fetcher: "file", Target: fs.path}
+	return fetch.Sample{At: at, Values: fs.snap.Values, Provenance: prov}, nil
+}
+
+func snapshotFiles(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		for _, ext := range snapshotExt {
+			if gzfile.HasSuffix(e.Name(), ext) {
+				files = append(files, filepath.Join(path, e.Name()))
+				break
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func loadSnapshots(path string) ([]fileSnapshot, error) {
+	if !gzfile.HasSuffix(filepath.Base(path), ".json") {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		return []fileSnapshot{{path: path, at: fi.ModTime()}}, nil
+	}
+	b, err := gzfile.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var probe struct {
+		SchemaVersion string `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return nil, err
+	}
+	if probe.SchemaVersion == SchemaVersion {
+		s, err := LoadSession(path)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]fileSnapshot, 0, len(s.Snapshots))
+		for i := range s.Snapshots {
+			out = append(out, fileSnapshot{path: path, snap: &s.Snapshots[i]})
+		}
+		return out, nil
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return nil, err
+	}
+	return []fileSnapshot{{path: path, snap: &snap}}, nil
+}
+
+// RecordingFetcher wraps a live fetcher and saves every snapshot it returns (or its error) into Dir
+// as snapshot.<time>-<seq>.json, for a FileFetcher to serve later. Saving is best-effort: a write
+// failure is logged and the live sample is still returned.
+type RecordingFetcher struct {
+	Inner fetch.MetricsFetcher
+	Dir   string
+	// Options of the snapshot files (Gzip => *.json.gz).
+	Options artifacts.Options
+	Logger  slo.Logger
+
+	mu  sync.Mutex
+	seq int
+	w   *artifacts.JSONWriter
+}
+
+// NewRecordingFetcher records the snapshots of inner into dir (created on first write).
+func NewRecordingFetcher(
+	inner fetch.MetricsFetcher, dir string, opts artifacts.Options, l slo.Logger,
+) *RecordingFetcher {
+	return &RecordingFetcher{Inner: inner, Dir: dir, Options: opts, Logger: l}
+}
+
+func (r *RecordingFetcher) Fetch(ctx context.Context, at time.Time) (fetch.Sample, error) {
+	s, err := r.Inner.Fetch(ctx, at)
+	snap := Snapshot{At: at, Values: s.Values}
+	if err != nil {
+		snap = Snapshot{At: at, Error: err.Error()}
+	}
+
+	r.mu.Lock()
+	r.seq++
+	seq := r.seq
+	if r.w == nil {
+		r.w = artifacts.NewJSONWriter(r.Options)
+	}
+	w := r.w
+	r.mu.Unlock()
+
+	// the fixed-width time keeps the files of several runs in one directory in capture order
+	name := fmt.Sprintf("snapshot.%019d-%04d.json", time.Now().UnixNano(), seq)
+	if werr := w.WriteJSON(r.Options.Name(filepath.Join(r.Dir, name)), snap); werr != nil {
+		slo.Warnf(r.Logger, "replay: recording snapshot failed: %v", werr)
+	}
+	return s, err
+}
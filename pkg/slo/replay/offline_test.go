@@ -0,0 +1,58 @@
+package replay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+func TestRecordingAndFileFetcher(t *testing.T) {
+	key := spec.PromMetric("controller_runtime_reconcile_total", spec.Labels{"result": "error"})
+	dir := t.TempDir()
+	rec := NewRecordingFetcher(&stepFetcher{values: []map[string]float64{
+		{key.Key: 1}, {key.Key: 3},
+	}}, dir, artifacts.Options{Gzip: true}, nil)
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < 2; i++ {
+		if _, err := rec.Fetch(context.Background(), start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a saved scrape after the recorded snapshots
+	scrape := filepath.Join(dir, "z-last.prom")
+	body := `controller_runtime_reconcile_total{result="error"} 7` + "\n"
+	if err := os.WriteFile(scrape, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewFileFetcher(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", f.Len())
+	}
+	now := time.Unix(1800000000, 0)
+	for i, want := range []float64{1, 3, 7, 7} {
+		s, err := f.Fetch(context.Background(), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Values[key.Key] != want {
+			t.Errorf("fetch %d: value = %v, want %v", i, s.Values[key.Key], want)
+		}
+		if i == 1 && !s.At.Equal(start.Add(time.Minute)) {
+			t.Errorf("fetch %d: at = %v, want the recorded time", i, s.At)
+		}
+	}
+
+	if _, err := NewFileFetcher(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without snapshots")
+	}
+}
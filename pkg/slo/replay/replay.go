@@ -0,0 +1,165 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// CaptureFetcher wraps a fetcher and keeps every snapshot (or error) it returned, in order.
+type CaptureFetcher struct {
+	Inner fetch.MetricsFetcher
+
+	mu    sync.Mutex
+	snaps []Snapshot
+}
+
+func (c *CaptureFetcher) Fetch(ctx context.Context, at time.Time) (fetch.Sample, error) {
+	s, err := c.Inner.Fetch(ctx, at)
+	snap := Snapshot{At: at, Values: s.Values}
+	if err != nil {
+		snap = Snapshot{At: at, Error: err.Error()}
+	}
+	c.mu.Lock()
+	c.snaps = append(c.snaps, snap)
+	c.mu.Unlock()
+	return s, err
+}
+
+func (c *CaptureFetcher) Unwrap() fetch.MetricsFetcher { return c.Inner }
+
+// Snapshots returns a copy of what was captured so far.
+func (c *CaptureFetcher) Snapshots() []Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Snapshot(nil), c.snaps...)
+}
+
+// playbackFetcher serves recorded snapshots in the order they were captured.
+type playbackFetcher struct {
+	snaps []Snapshot
+	next  int
+}
+
+func (p *playbackFetcher) Fetch(_ context.Context, at time.Time) (fetch.Sample, error) {
+	if p.next >= len(p.snaps) {
+		return fetch.Sample{}, fmt.Errorf("replay: no recorded snapshot #%d", p.next+1)
+	}
+	snap := p.snaps[p.next]
+	p.next++
+	if snap.Error != "" {
+		return fetch.Sample{}, errors.New(snap.Error)
+	}
+	return fetch.Sample{At: at, Values: snap.Values}, nil
+}
+
+type discardWriter struct{}
+
+func (discardWriter) Write(string, summary.Summary) error { return nil }
+
+// Evaluate re-runs the current engine on the recorded inputs of s. Nothing is written.
+func Evaluate(ctx context.Context, s *Session, l slo.Logger) (*summary.Summary, error) {
+	cfg := s.Summary.Config
+	eng := engine.New(&playbackFetcher{snaps: s.Snapshots}, discardWriter{}, l)
+	return eng.Execute(ctx, engine.ExecuteRequest{
+		Config: engine.RunConfig{
+			RunID:         cfg.RunID,
+			StartedAt:     cfg.StartedAt,
+			FinishedAt:    cfg.FinishedAt,
+			Mode:          engine.RunMode{Location: cfg.Mode.Location, Trigger: cfg.Mode.Trigger},
+			Tags:          cfg.Tags,
+			Format:        cfg.Format,
+			EvidencePaths: cfg.EvidencePaths,
+			Disruptions:   s.Summary.Disruptions,
+			Load:          s.Summary.Load,
+		},
+		Specs: s.Specs,
+	})
+}
+
+// Diff is one difference between a recorded and a replayed result.
+type Diff struct {
+	ID       string
+	Field    string // "status" | "value" | "result"
+	Recorded string
+	Replayed string
+}
+
+// Verdict reports whether the diff changes an outcome (status or presence), not just a number.
+func (d Diff) Verdict() bool { return d.Field != "value" }
+
+// Compare lists per-SLI differences between the recorded and replayed summaries, ordered by ID.
+// Values within tolerance (absolute) are considered equal.
+func Compare(recorded, replayed *summary.Summary, tolerance float64) []Diff {
+	rec := resultsByID(recorded)
+	rep := resultsByID(replayed)
+
+	ids := make([]string, 0, len(rec)+len(rep))
+	for id := range rec {
+		ids = append(ids, id)
+	}
+	for id := range rep {
+		if _, ok := rec[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var out []Diff
+	for _, id := range ids {
+		a, okA := rec[id]
+		b, okB := rep[id]
+		switch {
+		case !okA:
+			out = append(out, Diff{ID: id, Field: "result", Recorded: "absent", Replayed: string(b.Status)})
+		case !okB:
+			out = append(out, Diff{ID: id, Field: "result", Recorded: string(a.Status), Replayed: "absent"})
+		default:
+			if a.Status != b.Status {
+				out = append(out, Diff{ID: id, Field: "status", Recorded: string(a.Status), Replayed: string(b.Status)})
+			}
+			if !sameValue(a.Value, b.Value, tolerance) {
+				out = append(out, Diff{ID: id, Field: "value", Recorded: formatValue(a.Value), Replayed: formatValue(b.Value)})
+			}
+		}
+	}
+	return out
+}
+
+func resultsByID(s *summary.Summary) map[string]summary.SLIResult {
+	out := map[string]summary.SLIResult{}
+	if s == nil {
+		return out
+	}
+	for _, r := range s.Results {
+		out[r.ID] = r
+	}
+	return out
+}
+
+func sameValue(a, b *float64, tolerance float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if math.IsNaN(*a) || math.IsNaN(*b) {
+		return math.IsNaN(*a) && math.IsNaN(*b)
+	}
+	return math.Abs(*a-*b) <= tolerance
+}
+
+func formatValue(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*v, 'g', -1, 64)
+}
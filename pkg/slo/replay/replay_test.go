@@ -0,0 +1,83 @@
+package replay
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+type stepFetcher struct{ values []map[string]float64 }
+
+func (f *stepFetcher) Fetch(_ context.Context, at time.Time) (fetch.Sample, error) {
+	v := f.values[0]
+	f.values = f.values[1:]
+	return fetch.Sample{At: at, Values: v}, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	key := spec.PromMetric("controller_runtime_reconcile_total", spec.Labels{"result": "error"})
+	specs := []spec.SLISpec{{
+		ID:      "reconcile_error_delta",
+		Kind:    "delta_counter",
+		Inputs:  []spec.MetricRef{key},
+		Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		Judge: &spec.JudgeSpec{Rules: []spec.Rule{
+			{Metric: "value", Op: spec.OpGT, Target: 0, Level: spec.LevelFail},
+		}},
+	}}
+
+	capture := &CaptureFetcher{Inner: &stepFetcher{values: []map[string]float64{
+		{key.Key: 1}, {key.Key: 3},
+	}}}
+	start := time.Unix(1700000000, 0)
+	sum, err := engine.New(capture, discardWriter{}, nil).Execute(context.Background(), engine.ExecuteRequest{
+		Config: engine.RunConfig{
+			RunID:      "r1",
+			StartedAt:  start,
+			FinishedAt: start.Add(time.Minute),
+			Tags:       map[string]string{"test_case": "replay"},
+		},
+		Specs: specs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if _, err := NewRecorder(dir, artifacts.Options{}).Record(specs, capture.Snapshots(), sum); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := NewRecorder(dir, artifacts.Options{Gzip: true}).Record(specs, capture.Snapshots(), sum)
+	if err != nil || !strings.HasSuffix(gz, ".json.gz") {
+		t.Fatalf("compressed session %q: %v", gz, err)
+	}
+	_, sessions, err := LoadBundle(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2 (plain and compressed)", len(sessions))
+	}
+
+	replayed, err := Evaluate(context.Background(), sessions[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := Compare(sessions[0].Summary, replayed, 0); len(diffs) != 0 {
+		t.Fatalf("unexpected diffs: %+v", diffs)
+	}
+
+	// a historical verdict that the engine no longer reproduces must be reported
+	sessions[0].Summary.Results[0].Status = summary.StatusPass
+	diffs := Compare(sessions[0].Summary, replayed, 0)
+	if len(diffs) != 1 || diffs[0].Field != "status" || !diffs[0].Verdict() {
+		t.Fatalf("diffs = %+v, want one status change", diffs)
+	}
+}
@@ -0,0 +1,136 @@
+// Package runid names measurement runs. A run ID ends up in summary meta (Config.RunID), tags and
+// labels (run_id) and artifact filenames, so it must never be empty and should differ between runs:
+// an explicit ID wins, then the CI system's run (GitHub Actions, GitLab CI, Jenkins), then a ULID.
+package runid
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source tells where a run ID came from.
+type Source string
+
+const (
+	SourceExplicit Source = "explicit"
+	SourceGitHub   Source = "github"
+	SourceGitLab   Source = "gitlab"
+	SourceJenkins  Source = "jenkins"
+	SourceULID     Source = "ulid"
+)
+
+// Provider resolves run IDs. The zero value reads the process environment, the wall clock and
+// crypto/rand.
+type Provider struct {
+	Getenv  func(string) string
+	Now     func() time.Time
+	Entropy io.Reader
+}
+
+// Resolve returns explicit (trimmed, kept as is) when set, else the CI run (FromCI), else a new ULID.
+func (p Provider) Resolve(explicit string) (string, Source) {
+	if id := strings.TrimSpace(explicit); id != "" {
+		return id, SourceExplicit
+	}
+	getenv := p.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	if id, src, ok := FromCI(getenv); ok {
+		return id, src
+	}
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+	entropy := p.Entropy
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+	id, err := NewULID(now(), entropy)
+	if err != nil {
+		// entropy failed: the time alone is still unique enough for a local run
+		id = fmt.Sprintf("local-%d", now().UnixNano())
+	}
+	return id, SourceULID
+}
+
+// FromCI derives the ID of the current CI run. The IDs differ between retries of the same pipeline
+// (GitHub run attempt, GitLab job ID, Jenkins build number); matrix legs of one GitHub job share
+// theirs, so set an explicit ID per leg when they upload to the same place.
+func FromCI(getenv func(string) string) (string, Source, bool) {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true" && getenv("GITHUB_RUN_ID") != "":
+		attempt := getenv("GITHUB_RUN_ATTEMPT")
+		if attempt == "" {
+			attempt = "1"
+		}
+		return Sanitize(fmt.Sprintf("gh-%s-%s", getenv("GITHUB_RUN_ID"), attempt)), SourceGitHub, true
+	case getenv("GITLAB_CI") == "true" && getenv("CI_JOB_ID") != "":
+		return Sanitize(fmt.Sprintf("gl-%s-%s", getenv("CI_PIPELINE_ID"), getenv("CI_JOB_ID"))), SourceGitLab, true
+	case getenv("JENKINS_URL") != "" && getenv("BUILD_TAG") != "":
+		// BUILD_TAG is jenkins-${JOB_NAME}-${BUILD_NUMBER}
+		return Sanitize(getenv("BUILD_TAG")), SourceJenkins, true
+	}
+	return "", "", false
+}
+
+var defaultID = sync.OnceValue(func() string {
+	id, _ := Provider{}.Resolve("")
+	return id
+})
+
+// Resolve returns explicit when set, else this process's default run ID (resolved once, so every
+// session of a suite shares it).
+func Resolve(explicit string) string {
+	if id := strings.TrimSpace(explicit); id != "" {
+		return id
+	}
+	return defaultID()
+}
+
+// Sanitize trims s and replaces anything but letters, digits, '.', '_' and '-' with '-', so the
+// ID is safe in filenames, label values and object keys.
+func Sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '-'
+	}, strings.TrimSpace(s))
+}
+
+// crockford is the ULID alphabet (Crockford's base32).
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a ULID: 48 bits of milliseconds since the epoch and 80 random bits, as 26
+// characters that sort by time.
+func NewULID(t time.Time, entropy io.Reader) (string, error) {
+	var b [16]byte
+	ms := uint64(t.UnixMilli())
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (8 * (5 - i)))
+	}
+	if _, err := io.ReadFull(entropy, b[6:]); err != nil {
+		return "", fmt.Errorf("ulid entropy: %w", err)
+	}
+
+	// 128 bits as 26 base32 digits: the first digit holds the top 3 bits
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		var carry uint16
+		for j := 0; j < 16; j++ {
+			v := carry<<8 | uint16(b[j])
+			b[j] = byte(v / 32)
+			carry = v % 32
+		}
+		out[i] = crockford[carry]
+	}
+	return string(out), nil
+}
@@ -0,0 +1,122 @@
+package runid
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func env(kv map[string]string) func(string) string {
+	return func(k string) string { return kv[k] }
+}
+
+func TestResolveOrder(t *testing.T) {
+	ci := env(map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_RUN_ID": "42", "GITHUB_RUN_ATTEMPT": "2"})
+	p := Provider{Getenv: ci}
+	if id, src := p.Resolve(" nightly/7 "); id != "nightly/7" || src != SourceExplicit {
+		t.Errorf("explicit: %q %s", id, src)
+	}
+	if id, src := p.Resolve(""); id != "gh-42-2" || src != SourceGitHub {
+		t.Errorf("github: %q %s", id, src)
+	}
+	p.Getenv = env(nil)
+	if id, src := p.Resolve(""); len(id) != 26 || src != SourceULID {
+		t.Errorf("fallback: %q %s", id, src)
+	}
+	p.Entropy = bytes.NewReader(nil)
+	p.Now = func() time.Time { return time.Unix(1700000000, 0) }
+	if id, src := p.Resolve(""); id != "local-1700000000000000000" || src != SourceULID {
+		t.Errorf("entropy failure: %q %s", id, src)
+	}
+}
+
+func TestFromCI(t *testing.T) {
+	for name, tc := range map[string]struct {
+		env  map[string]string
+		want string
+		src  Source
+	}{
+		"github default attempt": {map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_RUN_ID": "9"}, "gh-9-1", SourceGitHub},
+		"gitlab": {
+			map[string]string{"GITLAB_CI": "true", "CI_PIPELINE_ID": "100", "CI_JOB_ID": "555"}, "gl-100-555", SourceGitLab,
+		},
+		"jenkins folder job": {
+			map[string]string{"JENKINS_URL": "http://ci/", "BUILD_TAG": "jenkins-team/op e2e-12"},
+			"jenkins-team-op-e2e-12", SourceJenkins,
+		},
+		"not ci": {map[string]string{"CI_JOB_ID": "1", "BUILD_TAG": "x"}, "", ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			id, src, ok := FromCI(env(tc.env))
+			if id != tc.want || src != tc.src || ok != (tc.want != "") {
+				t.Errorf("got %q %s %v, want %q %s", id, src, ok, tc.want, tc.src)
+			}
+		})
+	}
+}
+
+// TestUniqueAcrossRuns: retries of a CI pipeline and local runs (even within one millisecond)
+// never share an ID.
+func TestUniqueAcrossRuns(t *testing.T) {
+	seen := map[string]bool{}
+	add := func(id string) {
+		if seen[id] {
+			t.Fatalf("duplicate run id %q", id)
+		}
+		seen[id] = true
+	}
+	for _, attempt := range []string{"1", "2"} {
+		id, _, _ := FromCI(env(map[string]string{
+			"GITHUB_ACTIONS": "true", "GITHUB_RUN_ID": "42", "GITHUB_RUN_ATTEMPT": attempt,
+		}))
+		add(id)
+	}
+	for _, job := range []string{"1", "2"} {
+		id, _, _ := FromCI(env(map[string]string{"GITLAB_CI": "true", "CI_PIPELINE_ID": "7", "CI_JOB_ID": job}))
+		add(id)
+	}
+
+	at := time.Unix(1700000000, 0)
+	p := Provider{Getenv: env(nil), Now: func() time.Time { return at }}
+	for range 10000 {
+		id, _ := p.Resolve("")
+		add(id)
+	}
+}
+
+func TestULIDSortsByTime(t *testing.T) {
+	ones := func() *bytes.Reader { return bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)) }
+	var ids []string
+	for i := range 5 {
+		id, err := NewULID(time.UnixMilli(int64(1700000000000+i)), ones())
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if !slices.IsSorted(ids) {
+		t.Errorf("ULIDs not in time order: %v", ids)
+	}
+	// 2^48-1 ms and all-ones entropy is the largest ULID
+	if id, _ := NewULID(time.UnixMilli(1<<48-1), ones()); id != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("max ULID = %s", id)
+	}
+	if _, err := NewULID(time.Now(), errReader{}); err == nil {
+		t.Error("entropy error should be returned")
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("boom") }
+
+func TestResolveDefaultIsStable(t *testing.T) {
+	if a, b := Resolve(""), Resolve("  "); a == "" || a != b {
+		t.Errorf("default run id not stable: %q %q", a, b)
+	}
+	if got := Resolve("ci-1"); got != "ci-1" {
+		t.Errorf("explicit = %q", got)
+	}
+}
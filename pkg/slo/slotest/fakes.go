@@ -0,0 +1,154 @@
+package slotest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// FakeFetcher is a fetch.MetricsFetcher serving scripted snapshots, for unit tests of harness glue
+// without a metrics endpoint. Calls are counted and their times kept. Safe for concurrent use.
+type FakeFetcher struct {
+	// Samples are returned in order; once used up the last one repeats. A zero At is set to the
+	// requested time.
+	Samples []fetch.Sample
+	// Errors fails the call with the given index (0-based) instead; a failed call does not use up a
+	// sample, so a retry gets it.
+	Errors map[int]error
+	// Err, when set, fails every call (e.g. an unreachable endpoint).
+	Err error
+
+	mu    sync.Mutex
+	next  int
+	times []time.Time
+}
+
+// NewFakeFetcher scripts one sample per values map.
+func NewFakeFetcher(values ...map[string]float64) *FakeFetcher {
+	f := &FakeFetcher{}
+	for _, v := range values {
+		f.Samples = append(f.Samples, fetch.Sample{Values: v})
+	}
+	return f
+}
+
+// FailAt makes call number call (0-based) fail with err and returns f.
+func (f *FakeFetcher) FailAt(call int, err error) *FakeFetcher {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Errors == nil {
+		f.Errors = map[int]error{}
+	}
+	f.Errors[call] = err
+	return f
+}
+
+func (f *FakeFetcher) Fetch(ctx context.Context, at time.Time) (fetch.Sample, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	call := len(f.times)
+	f.times = append(f.times, at)
+	if err := ctx.Err(); err != nil {
+		return fetch.Sample{}, err
+	}
+	if err := f.Errors[call]; err != nil {
+		return fetch.Sample{}, err
+	}
+	if f.Err != nil {
+		return fetch.Sample{}, f.Err
+	}
+	if len(f.Samples) == 0 {
+		return fetch.Sample{At: at, Values: map[string]float64{}}, nil
+	}
+	s := f.Samples[min(f.next, len(f.Samples)-1)]
+	f.next++
+	if s.At.IsZero() {
+		s.At = at
+	}
+	return s, nil
+}
+
+// Calls is the number of Fetch calls so far, failed ones included.
+func (f *FakeFetcher) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.times)
+}
+
+// Times are the requested times of all calls, in order.
+func (f *FakeFetcher) Times() []time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Time(nil), f.times...)
+}
+
+// FakeWrite is one successful FakeWriter.Write.
+type FakeWrite struct {
+	Path    string
+	Summary summary.Summary
+}
+
+// FakeWriter is a summary.Writer keeping the summaries in memory, with the same error injection as
+// FakeFetcher. Safe for concurrent use.
+type FakeWriter struct {
+	// Errors fails the call with the given index (0-based); Err fails every call.
+	Errors map[int]error
+	Err    error
+
+	mu     sync.Mutex
+	calls  int
+	writes []FakeWrite
+}
+
+// FailAt makes call number call (0-based) fail with err and returns w.
+func (w *FakeWriter) FailAt(call int, err error) *FakeWriter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.Errors == nil {
+		w.Errors = map[int]error{}
+	}
+	w.Errors[call] = err
+	return w
+}
+
+func (w *FakeWriter) Write(path string, s summary.Summary) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	call := w.calls
+	w.calls++
+	if err := w.Errors[call]; err != nil {
+		return err
+	}
+	if w.Err != nil {
+		return w.Err
+	}
+	w.writes = append(w.writes, FakeWrite{Path: path, Summary: s})
+	return nil
+}
+
+// Calls is the number of Write calls so far, failed ones included.
+func (w *FakeWriter) Calls() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.calls
+}
+
+// Writes are the successful writes, in order.
+func (w *FakeWriter) Writes() []FakeWrite {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]FakeWrite(nil), w.writes...)
+}
+
+// Last is the most recently written summary; ok is false before the first successful write.
+func (w *FakeWriter) Last() (s summary.Summary, ok bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.writes) == 0 {
+		return s, false
+	}
+	return w.writes[len(w.writes)-1].Summary, true
+}
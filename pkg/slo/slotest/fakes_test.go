@@ -0,0 +1,49 @@
+package slotest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+func TestFakes(t *testing.T) {
+	key := spec.PromMetric("controller_runtime_reconcile_total", spec.Labels{"result": "error"})
+	specs := []spec.SLISpec{{
+		ID: "reconcile_error_delta", Inputs: []spec.MetricRef{key}, Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+	}}
+	start := time.Unix(1700000000, 0)
+	req := engine.ExecuteRequest{
+		Config: engine.RunConfig{RunID: "r1", StartedAt: start, FinishedAt: start.Add(time.Minute)},
+		Specs:  specs,
+	}
+
+	f := NewFakeFetcher(map[string]float64{key.Key: 1}, map[string]float64{key.Key: 4}).
+		FailAt(1, errors.New("connection refused"))
+	w := &FakeWriter{}
+	sum, err := engine.New(f, w, nil).Execute(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sum.Warnings) != 1 || !strings.Contains(sum.Warnings[0], "connection refused") {
+		t.Errorf("warnings = %q, want the injected end fetch error", sum.Warnings)
+	}
+	if last, ok := w.Last(); !ok || last.Config.RunID != "r1" {
+		t.Errorf("writer did not get the degraded summary: %+v", w.Writes())
+	}
+
+	sum, err = engine.New(f, w.FailAt(1, errors.New("disk full")), nil).Execute(context.Background(), req)
+	if err == nil || sum != nil {
+		t.Fatalf("expected the injected write error, got %v", err)
+	}
+	if f.Calls() != 4 || !f.Times()[3].Equal(start.Add(time.Minute)) {
+		t.Errorf("fetch calls = %d at %v", f.Calls(), f.Times())
+	}
+	if w.Calls() != 2 || len(w.Writes()) != 1 {
+		t.Errorf("write calls = %d, writes = %d", w.Calls(), len(w.Writes()))
+	}
+}
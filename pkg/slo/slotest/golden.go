@@ -0,0 +1,258 @@
+// Package slotest helps consumers of pkg/slo snapshot-test what their summary writers produce:
+// output is normalized (timestamps and run IDs scrubbed, JSON keys sorted) and compared against a
+// golden file, so a test only fails when the content changes, not when it runs at another time.
+// FakeFetcher and FakeWriter stand in for a metrics endpoint and an artifact store, with injectable
+// errors, so harness glue can be tested without a cluster.
+//
+// Set SLOLAB_UPDATE_GOLDEN=true to (re)write the golden files from the current output:
+//
+//	SLOLAB_UPDATE_GOLDEN=true go test ./...
+package slotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// UpdateEnv rewrites golden files instead of comparing against them when set to true.
+const UpdateEnv = "SLOLAB_UPDATE_GOLDEN"
+
+// Placeholders substituted by Normalize.
+const (
+	TimePlaceholder     = "<time>"
+	RunIDPlaceholder    = "<run-id>"
+	ScrubbedPlaceholder = "<scrubbed>"
+)
+
+// runIDKeys are the JSON keys (summary config, tags, labels) whose value is a run ID.
+var runIDKeys = []string{"runId", "runID", "run_id"}
+
+var (
+	timestampRe = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+	// a run ID in text output: a JSON field (result lines) or a Prometheus label
+	textRunIDRe = regexp.MustCompile(`(?:"run(?:Id|ID|_id)"\s*:\s*"|run_id=")([^"]+)"`)
+)
+
+// Options tune Normalize. The zero value scrubs timestamps and run IDs only.
+type Options struct {
+	// RunIDs are scrubbed wherever they appear (paths, messages), in addition to the run IDs found
+	// under the runId / run_id keys of the output.
+	RunIDs []string
+	// Keys are JSON object keys whose values vary between runs for other reasons (e.g.
+	// "durationSeconds", "generatedBy"); their values become "<scrubbed>".
+	Keys []string
+}
+
+// Normalize returns b with the run-dependent parts replaced by placeholders. JSON (a document or
+// JSON Lines) is re-encoded with sorted keys and two-space indentation, one value per line; anything else is treated as
+// text. The zero time (0001-01-01T00:00:00Z) is kept, so "unset" stays visible in the golden file.
+func Normalize(b []byte, opts Options) []byte {
+	if docs, ok := decodeJSON(b); ok {
+		n := normalizer{opts: opts, runIDs: append([]string(nil), opts.RunIDs...)}
+		for _, d := range docs {
+			n.collect(d, "")
+		}
+		var out bytes.Buffer
+		enc := json.NewEncoder(&out)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false) // keep the placeholders readable
+		for _, d := range docs {
+			if err := enc.Encode(n.value(d, "")); err != nil {
+				// decoded JSON always re-encodes; fall back to text rather than losing the output
+				return normalizeText(b, opts)
+			}
+		}
+		return out.Bytes()
+	}
+	return normalizeText(b, opts)
+}
+
+// AssertGolden fails t when the normalized got differs from the golden file (already normalized),
+// reporting the first differing line. With SLOLAB_UPDATE_GOLDEN=true it writes the file instead.
+func AssertGolden(t testing.TB, golden string, got []byte, opts Options) {
+	t.Helper()
+	got = Normalize(got, opts)
+	if update, _ := strconv.ParseBool(os.Getenv(UpdateEnv)); update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatalf("slotest: %v", err)
+		}
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("slotest: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("slotest: %v (run with %s=true to create it)", err, UpdateEnv)
+	}
+	if msg := diffLines(want, got); msg != "" {
+		t.Errorf("slotest: output differs from %s (run with %s=true to update):\n%s", golden, UpdateEnv, msg)
+	}
+}
+
+// AssertGoldenFile is AssertGolden with the output a writer left in path.
+func AssertGoldenFile(t testing.TB, golden, path string, opts Options) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("slotest: %v", err)
+	}
+	AssertGolden(t, golden, got, opts)
+}
+
+// AssertSummary compares the JSON encoding of s against the golden file.
+func AssertSummary(t testing.TB, golden string, s summary.Summary, opts Options) {
+	t.Helper()
+	got, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("slotest: %v", err)
+	}
+	AssertGolden(t, golden, got, opts)
+}
+
+// decodeJSON decodes b as one or more JSON values (numbers kept verbatim); ok is false for text.
+func decodeJSON(b []byte) (docs []any, ok bool) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	for {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) && len(docs) > 0 {
+				return docs, true
+			}
+			return nil, false
+		}
+		docs = append(docs, v)
+	}
+}
+
+type normalizer struct {
+	opts   Options
+	runIDs []string
+}
+
+// collect records the run IDs of v, so they can be scrubbed from other strings (artifact paths).
+func (n *normalizer) collect(v any, key string) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			n.collect(e, k)
+		}
+	case []any:
+		for _, e := range v {
+			n.collect(e, key)
+		}
+	case string:
+		if isRunIDKey(key) && v != "" {
+			n.runIDs = append(n.runIDs, v)
+		}
+	}
+}
+
+func (n *normalizer) value(v any, key string) any {
+	if key != "" && containsKey(n.opts.Keys, key) {
+		return ScrubbedPlaceholder
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[n.scrub(k)] = n.value(e, k)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = n.value(e, key)
+		}
+		return out
+	case string:
+		if isRunIDKey(key) && v != "" {
+			return RunIDPlaceholder
+		}
+		return n.scrub(v)
+	}
+	return v
+}
+
+func (n *normalizer) scrub(s string) string {
+	return scrubTimes(replaceRunIDs(s, n.runIDs))
+}
+
+func normalizeText(b []byte, opts Options) []byte {
+	s := string(b)
+	ids := append([]string(nil), opts.RunIDs...)
+	for _, m := range textRunIDRe.FindAllStringSubmatch(s, -1) {
+		ids = append(ids, m[1])
+	}
+	return []byte(scrubTimes(replaceRunIDs(s, ids)))
+}
+
+// replaceRunIDs replaces the longest IDs first, so an ID that prefixes another leaves no remainder.
+func replaceRunIDs(s string, ids []string) string {
+	if len(ids) == 0 {
+		return s
+	}
+	sorted := append([]string(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, id := range sorted {
+		if id != "" {
+			s = strings.ReplaceAll(s, id, RunIDPlaceholder)
+		}
+	}
+	return s
+}
+
+func scrubTimes(s string) string {
+	return timestampRe.ReplaceAllStringFunc(s, func(ts string) string {
+		if strings.HasPrefix(ts, "0001-01-01T00:00:00") {
+			return ts
+		}
+		return TimePlaceholder
+	})
+}
+
+func isRunIDKey(k string) bool { return containsKey(runIDKeys, k) }
+
+func containsKey(keys []string, k string) bool {
+	for _, key := range keys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// diffLines describes the first line where want and got differ ("" => equal).
+func diffLines(want, got []byte) string {
+	if bytes.Equal(want, got) {
+		return ""
+	}
+	w := strings.Split(string(want), "\n")
+	g := strings.Split(string(got), "\n")
+	for i := 0; i < len(w) || i < len(g); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl || i >= len(w) || i >= len(g) {
+			return fmt.Sprintf("line %d:\n  want: %s\n  got:  %s", i+1, wl, gl)
+		}
+	}
+	return "line endings differ"
+}
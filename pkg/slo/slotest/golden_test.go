@@ -0,0 +1,82 @@
+package slotest
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// sampleSummary differs between calls only in its times and run ID.
+func sampleSummary() summary.Summary {
+	now := time.Now()
+	runID := fmt.Sprintf("run-%d", now.UnixNano())
+	v := 3.0
+	return summary.Summary{
+		SchemaVersion: summary.CurrentSchemaVersion,
+		GeneratedAt:   now,
+		Config: summary.RunConfig{
+			RunID:         runID,
+			StartedAt:     now.Add(-time.Minute),
+			FinishedAt:    now,
+			Mode:          summary.RunMode{Location: "outside", Trigger: "none"},
+			Tags:          map[string]string{"test_case": "golden", "run_id": runID},
+			EvidencePaths: map[string]string{"summary": "artifacts/sli-summary." + runID + ".json"},
+		},
+		Results: []summary.SLIResult{{ID: "reconcile_error_delta", Value: &v, Status: summary.StatusWarn}},
+	}
+}
+
+type recordTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordTB) Helper() {}
+
+func (r *recordTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGoldenFile(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("summary-%d.json", i))
+		if err := artifacts.NewSummaryWriter(artifacts.Options{}).Write(path, sampleSummary()); err != nil {
+			t.Fatal(err)
+		}
+		AssertGoldenFile(t, "testdata/summary.golden.json", path, Options{})
+	}
+
+	var out bytes.Buffer
+	if err := (&artifacts.ResultLineWriter{Out: &out}).Write("", sampleSummary()); err != nil {
+		t.Fatal(err)
+	}
+	AssertGolden(t, "testdata/result-line.golden.txt", out.Bytes(), Options{})
+
+	golden := filepath.Join(dir, "sub", "summary.golden.json")
+	t.Setenv(UpdateEnv, "true")
+	AssertSummary(t, golden, sampleSummary(), Options{})
+	t.Setenv(UpdateEnv, "false")
+	changed := sampleSummary()
+	changed.Results[0].Status = summary.StatusFail
+	rec := &recordTB{TB: t}
+	AssertSummary(rec, golden, changed, Options{})
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], `"status": "fail"`) {
+		t.Errorf("changed status: errors = %q", rec.errors)
+	}
+}
+
+func TestNormalizeKeys(t *testing.T) {
+	got := string(Normalize([]byte(`{"b":1.50,"durationSeconds":12.3,"at":"0001-01-01T00:00:00Z"}`),
+		Options{Keys: []string{"durationSeconds"}}))
+	want := "{\n  \"at\": \"0001-01-01T00:00:00Z\",\n  \"b\": 1.50,\n  \"durationSeconds\": \"<scrubbed>\"\n}\n"
+	if got != want {
+		t.Errorf("Normalize = %q, want %q", got, want)
+	}
+}
@@ -11,8 +11,11 @@ import (
 // v3: simplest form uses a canonical Prometheus "text key" string.
 // Example: controller_runtime_reconcile_total{result="success"}
 type MetricRef struct {
-	Key   string
-	Alias string // optional
+	Key   string `json:"key"`
+	Alias string `json:"alias,omitempty"` // optional
+	// ZeroIfNoSeries reads a label selector as 0 when the metric is exposed but no series matches
+	// (e.g. no PATCH call was made yet) instead of missing, which skips the SLI.
+	ZeroIfNoSeries bool `json:"zeroIfNoSeries,omitempty"`
 }
 
 func UnsafePromKey(key string) MetricRef { return MetricRef{Key: key} }
@@ -23,17 +26,49 @@ func PromMetric(name string, labels Labels) MetricRef {
 	return MetricRef{Key: promkey.Format(name, map[string]string(labels))}
 }
 
+// OrZero returns r with ZeroIfNoSeries set, for counters of events that may not happen in a run.
+func (r MetricRef) OrZero() MetricRef {
+	r.ZeroIfNoSeries = true
+	return r
+}
+
+// MetricNames returns the distinct metric names the specs read, in first-use order
+// (e.g. to build a Prometheus query). Derived specs read no metric.
+func MetricNames(specs []SLISpec) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, s := range specs {
+		for _, in := range s.Inputs {
+			name, _, err := promkey.Parse(in.Key)
+			if err != nil || seen[name] {
+				continue
+			}
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
 // TODO spec_v4 참고 향후 통합될 예정임.
 type ComputeMode string
 
 const (
 	ComputeSingle ComputeMode = "single" // use start snapshot only
+	ComputeEnd    ComputeMode = "end"    // use end snapshot only (gauges at the end of the window)
+	ComputeMax    ComputeMode = "max"    // highest gauge value seen (start, end, and tracked peaks)
 	ComputeDelta  ComputeMode = "delta"  // end - start
+
+	// ComputeQuantile estimates ComputeSpec.Quantile from the window delta of histogram buckets,
+	// like PromQL histogram_quantile(q, increase(<name>_bucket[window])). Inputs name the _bucket series.
+	ComputeQuantile ComputeMode = "quantile"
 )
 
 // ComputeSpec describes how to compute the SLI.
 type ComputeSpec struct {
-	Mode ComputeMode
+	Mode ComputeMode `json:"mode"`
+	// Quantile in (0, 1] for ComputeQuantile, e.g. 0.99.
+	Quantile float64 `json:"quantile,omitempty"`
 }
 
 type Level string
@@ -53,6 +88,24 @@ const (
 	OpEQ Op = "=="
 )
 
+// Holds reports whether "v o target" is true (an unknown op never holds).
+func (o Op) Holds(v, target float64) bool {
+	switch o {
+	case OpLE:
+		return v <= target
+	case OpGE:
+		return v >= target
+	case OpLT:
+		return v < target
+	case OpGT:
+		return v > target
+	case OpEQ:
+		return v == target
+	default:
+		return false
+	}
+}
+
 func (o *Op) UnmarshalText(text []byte) error {
 	op, ok := NormalizeOp(string(text))
 	if !ok {
@@ -64,28 +117,65 @@ func (o *Op) UnmarshalText(text []byte) error {
 
 // Rule is a tiny evaluation rule for v3.
 type Rule struct {
-	Metric string  // usually "value" for v3
-	Op     Op      // OpLE/OpGE/...
-	Target float64 // threshold
-	Level  Level   // LevelWarn | LevelFail
+	Metric string  `json:"metric,omitempty"` // usually "value" for v3
+	Op     Op      `json:"op"`               // OpLE/OpGE/...
+	Target float64 `json:"target"`           // threshold
+	Level  Level   `json:"level"`            // LevelWarn | LevelFail
 }
 
 type JudgeSpec struct {
-	Rules []Rule
+	Rules []Rule `json:"rules"`
 }
 
+// SLISpec.Kind values. The kind decides which compute modes make sense:
+// counters are delta'd, gauges are sampled (single/end/max), histograms use quantile.
+const (
+	KindDeltaCounter = "delta_counter"
+	KindGauge        = "gauge"
+	KindHistogram    = "histogram"
+	KindDerived      = "derived"
+)
+
+// CategoryResource marks resource-usage measurements (memory, CPU, goroutines, GC) of the
+// measured process, reported next to the SLIs so throughput regressions can be correlated.
+const CategoryResource = "resource"
+
 // SLISpec is a declarative SLI definition.
 // It is intentionally small in v3.
 type SLISpec struct {
-	ID          string
-	Title       string
-	Unit        string
-	Kind        string // "delta_counter" | "gauge" | "derived" (v3 minimal)
-	Description string
-
-	Inputs  []MetricRef
-	Compute ComputeSpec
-	Judge   *JudgeSpec
+	ID          string `json:"id"`
+	Title       string `json:"title,omitempty"`
+	Unit        string `json:"unit,omitempty"`
+	Kind        string `json:"kind,omitempty"` // KindDeltaCounter | KindGauge | KindHistogram | KindDerived
+	Description string `json:"description,omitempty"`
+	Owner       string `json:"owner,omitempty"`    // owning team/contact, surfaced in results and breach messages
+	Category    string `json:"category,omitempty"` // e.g. CategoryResource; empty => regular SLI
+
+	Inputs  []MetricRef `json:"inputs"`
+	Compute ComputeSpec `json:"compute"`
+	Judge   *JudgeSpec  `json:"judge,omitempty"`
+
+	// BreakdownBy (opt-in) also reports the value per label value in result Fields,
+	// e.g. ["controller"] => Fields[`controller_runtime_reconcile_total{controller="foo"}`].
+	// The judged value stays the sum over all series.
+	BreakdownBy []string `json:"breakdownBy,omitempty"`
+
+	// Derived computes the value from other SLIs of the same run instead of Inputs (Kind "derived").
+	Derived *DerivedSpec `json:"derived,omitempty"`
+}
+
+type DerivedOp string
+
+const (
+	DerivedRatio DerivedOp = "ratio" // Operands[0] / Operands[1]
+	DerivedRate  DerivedOp = "rate"  // Operands[0] / window seconds
+)
+
+// DerivedSpec is a small expression over the values of other SLIs (by ID).
+// Operands are evaluated first, wherever they appear in the spec list.
+type DerivedSpec struct {
+	Op       DerivedOp `json:"op"`
+	Operands []string  `json:"operands"`
 }
 
 func NormalizeOp(s string) (Op, bool) {
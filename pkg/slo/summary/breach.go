@@ -0,0 +1,57 @@
+package summary
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Breaches returns the results that violated a rule (warn or fail).
+func (s Summary) Breaches() []SLIResult {
+	var out []SLIResult
+	for _, r := range s.Results {
+		if r.Status == StatusWarn || r.Status == StatusFail {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// HasPolicyFailures reports whether any result was judged as fail by its rules.
+// Warnings do not count; skip results are measurement gaps, not policy failures.
+func (s Summary) HasPolicyFailures() bool {
+	for _, r := range s.Results {
+		if r.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// BreachMessage formats a one-line notice for a breached result.
+// It always names the owner so the message can be routed (e.g. to a team channel).
+func BreachMessage(r SLIResult) string {
+	owner := strings.TrimSpace(r.Owner)
+	if owner == "" {
+		owner = "unassigned"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SLO breach [%s] %s", r.Status, r.ID)
+	if r.Title != "" {
+		fmt.Fprintf(&b, " (%s)", r.Title)
+	}
+	if r.Value != nil {
+		fmt.Fprintf(&b, " value=%v", *r.Value)
+		if r.Unit != "" {
+			fmt.Fprintf(&b, " %s", r.Unit)
+		}
+	}
+	fmt.Fprintf(&b, " owner=%s", owner)
+	if r.Reason != "" {
+		fmt.Fprintf(&b, ": %s", r.Reason)
+	}
+	if r.Description != "" {
+		fmt.Fprintf(&b, " -- %s", r.Description)
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package summary
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Optional SLIResult fields controlled by RedactPolicy.KeepResultFields.
+// id, value, fields, status, unit and kind are always kept: without them a result is meaningless.
+const (
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldOwner       = "owner"
+	FieldReason      = "reason"
+	FieldInputs      = "inputs" // inputsUsed + inputsMissing
+	FieldProvenance  = "provenance"
+)
+
+// RedactPolicy describes which metadata may leave the machine for a given destination.
+// The zero value keeps everything.
+type RedactPolicy struct {
+	// KeepTags is an allow-list of config tag keys; nil keeps all tags.
+	KeepTags []string
+	// MaskTags replaces the values of these tag keys with a stable hash (still joinable, not readable).
+	MaskTags []string
+	// KeepResultFields is an allow-list of optional result fields (Field* constants); nil keeps all.
+	KeepResultFields []string
+
+	DropWarnings      bool
+	DropEvidencePaths bool
+}
+
+// IsZero reports whether p keeps everything.
+func (p RedactPolicy) IsZero() bool {
+	return p.KeepTags == nil && len(p.MaskTags) == 0 && p.KeepResultFields == nil &&
+		!p.DropWarnings && !p.DropEvidencePaths
+}
+
+// Redact returns a copy of s filtered by p. s itself is not modified.
+func (s Summary) Redact(p RedactPolicy) Summary {
+	out := s
+
+	if s.Config.Tags != nil {
+		keep := toSet(p.KeepTags)
+		mask := toSet(p.MaskTags)
+		tags := make(map[string]string, len(s.Config.Tags))
+		for k, v := range s.Config.Tags {
+			if p.KeepTags != nil && !keep[k] && !mask[k] {
+				continue
+			}
+			if mask[k] {
+				v = MaskValue(v)
+			}
+			tags[k] = v
+		}
+		out.Config.Tags = tags
+	}
+	if p.DropEvidencePaths {
+		out.Config.EvidencePaths = nil
+	}
+	if p.DropWarnings {
+		out.Warnings = nil
+	}
+
+	if p.KeepResultFields != nil {
+		keep := toSet(p.KeepResultFields)
+		out.Results = make([]SLIResult, len(s.Results))
+		for i, r := range s.Results {
+			if !keep[FieldTitle] {
+				r.Title = ""
+			}
+			if !keep[FieldDescription] {
+				r.Description = ""
+			}
+			if !keep[FieldOwner] {
+				r.Owner = ""
+			}
+			if !keep[FieldReason] {
+				r.Reason = ""
+			}
+			if !keep[FieldInputs] {
+				r.InputsUsed, r.InputsMissing = nil, nil
+			}
+			if !keep[FieldProvenance] {
+				r.Provenance = nil
+			}
+			out.Results[i] = r
+		}
+	}
+	return out
+}
+
+// MaskValue returns a short, stable hash of v ("" stays "").
+func MaskValue(v string) string {
+	if v == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(v))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// ParseList splits a comma-separated list (used for policy env vars/flags).
+// An unset value ("") returns nil, i.e. "no allow-list".
+func ParseList(v string) []string {
+	if strings.TrimSpace(v) == "" {
+		return nil
+	}
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	if out == nil {
+		out = []string{}
+	}
+	return out
+}
+
+func toSet(list []string) map[string]bool {
+	m := make(map[string]bool, len(list))
+	for _, s := range list {
+		m[s] = true
+	}
+	return m
+}
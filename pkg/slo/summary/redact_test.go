@@ -0,0 +1,34 @@
+package summary
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	s := Summary{
+		Config: RunConfig{Tags: map[string]string{"suite": "e2e", "namespace": "team-a", "test_case": "secret spec"}},
+		Results: []SLIResult{
+			{ID: "a", Description: "desc", Owner: "team-a", Status: StatusPass, InputsUsed: []string{"x"}},
+		},
+		Warnings: []string{"fetch(start) failed: pod team-a/x"},
+	}
+
+	r := s.Redact(RedactPolicy{
+		KeepTags:         []string{"suite"},
+		MaskTags:         []string{"namespace"},
+		KeepResultFields: []string{FieldOwner},
+		DropWarnings:     true,
+	})
+
+	if len(r.Config.Tags) != 2 || r.Config.Tags["suite"] != "e2e" || r.Config.Tags["namespace"] != MaskValue("team-a") {
+		t.Fatalf("unexpected tags: %v", r.Config.Tags)
+	}
+	if r.Results[0].Description != "" || r.Results[0].InputsUsed != nil || r.Results[0].Owner != "team-a" {
+		t.Fatalf("unexpected result: %+v", r.Results[0])
+	}
+	if r.Warnings != nil {
+		t.Fatalf("warnings not dropped")
+	}
+	// the original is untouched (the local JSON keeps everything)
+	if s.Results[0].Description != "desc" || s.Config.Tags["test_case"] != "secret spec" {
+		t.Fatalf("original summary was modified")
+	}
+}
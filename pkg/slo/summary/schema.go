@@ -12,6 +12,10 @@ const (
 	StatusSkip Status = "skip"
 )
 
+// CurrentSchemaVersion is the schemaVersion written by the engine and accepted by Load.
+// Bump it whenever the JSON shape changes incompatibly.
+const CurrentSchemaVersion = "slo.v3"
+
 // Summary is the contract output. All measurement methods must converge to this schema.
 type Summary struct {
 	SchemaVersion string    `json:"schemaVersion"`
@@ -21,8 +25,171 @@ type Summary struct {
 
 	Results  []SLIResult `json:"results"`
 	Warnings []string    `json:"warnings,omitempty"`
+
+	// Disruptions injected during the window (e.g. chaos pod kills), so counter resets can be explained.
+	Disruptions []Disruption `json:"disruptions,omitempty"`
+
+	// Load is the synthetic load applied during the window (optional), used to normalize deltas.
+	Load *LoadReport `json:"load,omitempty"`
+
+	// Phases split the window at checkpoints (optional); Results still cover the whole window.
+	Phases []Phase `json:"phases,omitempty"`
+
+	// Convergences observed by watching objects during the window (optional).
+	Convergences []Convergence `json:"convergences,omitempty"`
+
+	// Waits are the polling waits of the spec (optional, e.g. Gomega Eventually), to see which wait
+	// dominates the run time and how close each came to its timeout.
+	Waits []Wait `json:"waits,omitempty"`
+
+	// Build identifies the measured code and image (optional), so a bad delta maps back to a commit.
+	Build *Build `json:"build,omitempty"`
+
+	// Measurement counts how the snapshots of the window were obtained (optional), so a decaying
+	// measurement success rate is visible instead of hidden behind best-effort skips.
+	Measurement *MeasurementStats `json:"measurement,omitempty"`
+
+	// Extras are session-level numbers that are not SLIs (optional), e.g. a count recorded by the
+	// test itself. Per-SLI numbers belong in SLIResult.Fields.
+	Extras map[string]float64 `json:"extras,omitempty"`
+}
+
+// MeasurementStats counts snapshot fetches.
+type MeasurementStats struct {
+	// Snapshots is the number of successful fetches.
+	Snapshots int `json:"snapshots"`
+	// FetchFailures and ParseErrors are the failed fetches, by cause (slo.KindParse => ParseErrors,
+	// anything else => FetchFailures).
+	FetchFailures int `json:"fetchFailures"`
+	ParseErrors   int `json:"parseErrors"`
+	// Retries are the extra scrapes taken by successful fetches (e.g. after a truncated body).
+	Retries int `json:"retries"`
+}
+
+// Attempts is the number of fetches, successful or not.
+func (m MeasurementStats) Attempts() int { return m.Snapshots + m.FetchFailures + m.ParseErrors }
+
+// SuccessRate is Snapshots / Attempts (1 without attempts: nothing failed).
+func (m MeasurementStats) SuccessRate() float64 {
+	if m.Attempts() == 0 {
+		return 1
+	}
+	return float64(m.Snapshots) / float64(m.Attempts())
+}
+
+// Add returns the sum of m and o.
+func (m MeasurementStats) Add(o MeasurementStats) MeasurementStats {
+	return MeasurementStats{
+		Snapshots:     m.Snapshots + o.Snapshots,
+		FetchFailures: m.FetchFailures + o.FetchFailures,
+		ParseErrors:   m.ParseErrors + o.ParseErrors,
+		Retries:       m.Retries + o.Retries,
+	}
+}
+
+// Build is the provenance of what was measured (see buildinfo.Resolve). Unknown fields are empty.
+type Build struct {
+	Commit string `json:"commit,omitempty"`
+	// Dirty is set when the working tree had uncommitted changes.
+	Dirty bool `json:"dirty,omitempty"`
+	// Builder is who built and ran it, e.g. "github-actions" or "local".
+	Builder string `json:"builder,omitempty"`
+	// Image and ImageDigest are the operator image as deployed (digest "sha256:...").
+	Image       string `json:"image,omitempty"`
+	ImageDigest string `json:"imageDigest,omitempty"`
+}
+
+// Merge fills the empty fields of b from o (Dirty: either) and returns b; nil b => a copy of o.
+func (b *Build) Merge(o Build) *Build {
+	if b == nil {
+		return &o
+	}
+	if b.Commit == "" {
+		b.Commit = o.Commit
+	}
+	b.Dirty = b.Dirty || o.Dirty
+	if b.Builder == "" {
+		b.Builder = o.Builder
+	}
+	if b.Image == "" {
+		b.Image = o.Image
+	}
+	if b.ImageDigest == "" {
+		b.ImageDigest = o.ImageDigest
+	}
+	return b
+}
+
+// Wait is how long one polling wait of the spec took.
+type Wait struct {
+	Name            string    `json:"name"`
+	StartedAt       time.Time `json:"startedAt"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	// TimeoutSeconds is 0 when the wait ran with the default timeout.
+	TimeoutSeconds float64 `json:"timeoutSeconds,omitempty"`
+	Succeeded      bool    `json:"succeeded"`
 }
 
+// BudgetUsed is the fraction of its timeout the wait took (0 when the timeout is unknown).
+func (w Wait) BudgetUsed() float64 {
+	if w.TimeoutSeconds <= 0 {
+		return 0
+	}
+	return w.DurationSeconds / w.TimeoutSeconds
+}
+
+// Convergence is the time an object took to reach a condition (e.g. Ready=True), taken from the
+// watch event instead of a polling loop.
+type Convergence struct {
+	Target    string    `json:"target"`              // e.g. "<kind>/<ns>/<name>"
+	Condition string    `json:"condition,omitempty"` // e.g. "Ready"
+	StartedAt time.Time `json:"startedAt"`
+
+	// ConvergedAt is nil when the condition was not observed before the window ended.
+	ConvergedAt *time.Time `json:"convergedAt,omitempty"`
+	Seconds     float64    `json:"seconds,omitempty"`
+}
+
+// Converged reports whether the condition was observed.
+func (c Convergence) Converged() bool { return c.ConvergedAt != nil }
+
+// Phase is the part of the window that ended at a named checkpoint (the last phase ends at the
+// window end), evaluated with the same specs as the whole window.
+type Phase struct {
+	Name            string      `json:"name"`
+	StartedAt       time.Time   `json:"startedAt"`
+	FinishedAt      time.Time   `json:"finishedAt"`
+	DurationSeconds float64     `json:"durationSeconds"`
+	Results         []SLIResult `json:"results"`
+	Warnings        []string    `json:"warnings,omitempty"`
+}
+
+// LoadReport counts the operations a load generator applied during the window.
+type LoadReport struct {
+	Objects int `json:"objects"` // distinct objects churned
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"`
+	Failed  int `json:"failed"`
+}
+
+// Applied is the number of successful operations.
+func (l LoadReport) Applied() int { return l.Created + l.Updated + l.Deleted }
+
+// Disruption records one injected fault and how the system recovered from it.
+type Disruption struct {
+	Kind      string    `json:"kind"`             // e.g. "pod-kill"
+	Target    string    `json:"target,omitempty"` // e.g. "<ns>/<pod>"
+	StartedAt time.Time `json:"startedAt"`
+
+	// RecoveredAt is nil when recovery was not observed before the window ended.
+	RecoveredAt *time.Time `json:"recoveredAt,omitempty"`
+	Detail      string     `json:"detail,omitempty"`
+}
+
+// Recovered reports whether recovery was observed.
+func (d Disruption) Recovered() bool { return d.RecoveredAt != nil }
+
 // RunConfig is embedded in the summary (so analysis tools can be method-agnostic).
 type RunConfig struct {
 	RunID      string            `json:"runId,omitempty"`
@@ -47,6 +214,8 @@ type SLIResult struct {
 	Unit        string `json:"unit,omitempty"`
 	Kind        string `json:"kind,omitempty"`
 	Description string `json:"description,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Category    string `json:"category,omitempty"` // "resource" for resource-usage measurements
 
 	// v3: a single numeric result. Future: Fields for p50/p99 etc.
 	Value  *float64           `json:"value,omitempty"`
@@ -55,7 +224,31 @@ type SLIResult struct {
 	Status Status `json:"status"` // "pass" | "warn" | "fail" | "skip"
 
 	Reason string `json:"reason,omitempty"`
+	// ErrorKind classifies why the SLI could not be measured (a slo.Kind: "fetch", "parse",
+	// "metric_missing", ...); empty when it was measured or the reason is not a failure.
+	ErrorKind string `json:"errorKind,omitempty"`
 
 	InputsUsed    []string `json:"inputsUsed,omitempty"`
 	InputsMissing []string `json:"inputsMissing,omitempty"`
+
+	// Provenance tells how the snapshots behind Value were obtained (optional).
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// Provenance of a result: one entry per snapshot the value was computed from
+// (Start only for single-snapshot SLIs).
+type Provenance struct {
+	Start *ScrapeProvenance `json:"start,omitempty"`
+	End   *ScrapeProvenance `json:"end,omitempty"`
+}
+
+type ScrapeProvenance struct {
+	Fetcher   string    `json:"fetcher,omitempty"`
+	Target    string    `json:"target,omitempty"`
+	Via       string    `json:"via,omitempty"`
+	Parser    string    `json:"parser,omitempty"`
+	ScrapedAt time.Time `json:"scrapedAt"`
+
+	// Series is the number of series summed per input key (only the inputs of this result).
+	Series map[string]int `json:"series,omitempty"`
 }
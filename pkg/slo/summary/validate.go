@@ -0,0 +1,129 @@
+package summary
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/yeongki/my-operator/pkg/slo/common/gzfile"
+)
+
+// ErrUnsupportedSchema is returned by Load/Validate for artifacts written with a different schemaVersion.
+var ErrUnsupportedSchema = errors.New("unsupported summary schemaVersion")
+
+// Validate checks that s is a well-formed summary of the current schema.
+func (s Summary) Validate() error {
+	if s.SchemaVersion != CurrentSchemaVersion {
+		return fmt.Errorf("%w: %q (supported: %q)", ErrUnsupportedSchema, s.SchemaVersion, CurrentSchemaVersion)
+	}
+
+	var problems []string
+	if s.GeneratedAt.IsZero() {
+		problems = append(problems, "generatedAt is not set")
+	}
+	if s.Config.StartedAt.IsZero() || s.Config.FinishedAt.IsZero() {
+		problems = append(problems, "config.startedAt/finishedAt must be set")
+	} else if s.Config.FinishedAt.Before(s.Config.StartedAt) {
+		problems = append(problems, "config.finishedAt is before config.startedAt")
+	}
+
+	seen := map[string]bool{}
+	for i, r := range s.Results {
+		if r.ID == "" {
+			problems = append(problems, fmt.Sprintf("results[%d]: id is empty", i))
+			continue
+		}
+		if seen[r.ID] {
+			problems = append(problems, fmt.Sprintf("results[%d]: duplicate id %q", i, r.ID))
+		}
+		seen[r.ID] = true
+
+		switch r.Status {
+		case StatusPass, StatusWarn, StatusFail, StatusSkip:
+		default:
+			problems = append(problems, fmt.Sprintf("results[%d] (%s): invalid status %q", i, r.ID, r.Status))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid summary: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Load reads a summary artifact from path (gzip-compressed or not) and validates it.
+// Artifacts from another schemaVersion are rejected with ErrUnsupportedSchema
+// instead of being decoded into a partially-filled struct.
+func Load(path string) (*Summary, error) {
+	b, err := gzfile.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(b)
+}
+
+// DecodeFunc converts a result document of another shape into a Summary. The Summary it
+// returns is validated by Decode like any other.
+type DecodeFunc func(b []byte) (*Summary, error)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]DecodeFunc{}
+)
+
+// RegisterDecoder makes Decode (and Load) accept documents of schemaVersion by converting them
+// with fn. Packages with their own result shape register an adapter from init, so every reader
+// of summaries (export, diff, report) also reads that shape. It panics on an empty or already
+// registered version, including CurrentSchemaVersion.
+func RegisterDecoder(schemaVersion string, fn DecodeFunc) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	if schemaVersion == "" || schemaVersion == CurrentSchemaVersion || fn == nil {
+		panic(fmt.Sprintf("summary: invalid decoder registration for %q", schemaVersion))
+	}
+	if _, dup := decoders[schemaVersion]; dup {
+		panic(fmt.Sprintf("summary: decoder for %q registered twice", schemaVersion))
+	}
+	decoders[schemaVersion] = fn
+}
+
+// Decode parses and validates a summary JSON document. Documents of a schemaVersion with a
+// registered decoder (see RegisterDecoder) are converted first.
+func Decode(b []byte) (*Summary, error) {
+	var head struct {
+		SchemaVersion string `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(b, &head); err != nil {
+		return nil, fmt.Errorf("decode summary: %w", err)
+	}
+	if head.SchemaVersion == "" {
+		return nil, fmt.Errorf("%w: schemaVersion is missing", ErrUnsupportedSchema)
+	}
+	if head.SchemaVersion != CurrentSchemaVersion {
+		decodersMu.RLock()
+		fn := decoders[head.SchemaVersion]
+		decodersMu.RUnlock()
+		if fn == nil {
+			return nil, fmt.Errorf("%w: %q (supported: %q)", ErrUnsupportedSchema, head.SchemaVersion, CurrentSchemaVersion)
+		}
+		s, err := fn(b)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", head.SchemaVersion, err)
+		}
+		if err := s.Validate(); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", head.SchemaVersion, err)
+		}
+		return s, nil
+	}
+
+	var s Summary
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("decode summary: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,87 @@
+package summary
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDecodeRejectsUnknownSchema(t *testing.T) {
+	_, err := Decode([]byte(`{"schemaVersion":"slo.v9","results":[]}`))
+	if !errors.Is(err, ErrUnsupportedSchema) {
+		t.Fatalf("expected ErrUnsupportedSchema, got %v", err)
+	}
+
+	_, err = Decode([]byte(`{"results":[]}`))
+	if !errors.Is(err, ErrUnsupportedSchema) {
+		t.Fatalf("expected ErrUnsupportedSchema for missing version, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Now()
+	s := Summary{
+		SchemaVersion: CurrentSchemaVersion,
+		GeneratedAt:   now,
+		Config:        RunConfig{StartedAt: now.Add(-time.Minute), FinishedAt: now},
+		Results: []SLIResult{
+			{ID: "a", Status: StatusPass},
+			{ID: "b", Status: StatusSkip},
+		},
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("expected valid summary, got %v", err)
+	}
+
+	s.Results = append(s.Results, SLIResult{ID: "a", Status: "unknown"})
+	if err := s.Validate(); err == nil {
+		t.Fatalf("expected duplicate id / invalid status to be rejected")
+	}
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	RegisterDecoder("test.v1", func(b []byte) (*Summary, error) {
+		now := time.Unix(1700000000, 0)
+		return &Summary{
+			SchemaVersion: CurrentSchemaVersion,
+			GeneratedAt:   now,
+			Config:        RunConfig{StartedAt: now, FinishedAt: now},
+			Extras:        map[string]float64{"bytes": float64(len(b))},
+		}, nil
+	})
+
+	s, err := Decode([]byte(`{"schemaVersion":"test.v1"}`))
+	if err != nil || s.Extras["bytes"] != 27 {
+		t.Fatalf("Decode = %+v, %v", s, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering the current schema must panic")
+		}
+	}()
+	RegisterDecoder(CurrentSchemaVersion, func([]byte) (*Summary, error) { return nil, nil })
+}
+
+func TestJSONFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "sli-summary.json")
+	now := time.Unix(1700000000, 0)
+	s := Summary{
+		GeneratedAt: now,
+		Config:      RunConfig{StartedAt: now, FinishedAt: now},
+		Results:     []SLIResult{{ID: "a", Status: StatusPass}},
+	}
+	if err := NewJSONFileWriter().Write(path, s); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil || got.SchemaVersion != CurrentSchemaVersion || len(got.Results) != 1 {
+		t.Fatalf("Load = %+v, %v", got, err)
+	}
+
+	s.Results = append(s.Results, SLIResult{ID: "a", Status: StatusPass})
+	if err := NewJSONFileWriter().Write(path+".bad", s); err == nil {
+		t.Fatal("an invalid summary must not be written")
+	}
+}
@@ -2,75 +2,46 @@ package summary
 
 import (
 	"encoding/json"
-	"os"
-	"path/filepath"
+	"io"
+
+	"github.com/yeongki/my-operator/pkg/slo/common/atomicfile"
 )
 
 // Writer writes a Summary artifact to a destination.
+// File-based implementations live in pkg/slo/artifacts.
 type Writer interface {
 	Write(path string, s Summary) error
 }
 
+// JSONFileWriter writes summaries as indented JSON, atomically and durably.
+//
+// Deprecated: use artifacts.NewSummaryWriter(artifacts.DefaultOptions()). JSONFileWriter writes
+// through the same atomic write path with the same settings and, like it, fills an unset
+// schemaVersion and rejects summaries that fail Validate.
 type JSONFileWriter struct{}
 
+// NewJSONFileWriter returns a JSONFileWriter.
+//
+// Deprecated: use artifacts.NewSummaryWriter(artifacts.DefaultOptions()).
 func NewJSONFileWriter() *JSONFileWriter { return &JSONFileWriter{} }
 
-// Write sync=true for atomic writer durability (fsync)
+// Write writes s to path; an empty path is a no-op (no output configured).
 func (w *JSONFileWriter) Write(path string, s Summary) error {
 	if path == "" {
-		// skip (no output path configured)
 		return nil
 	}
-	return writeJSONAtomic(path, s, 0o644, 0o755, true)
-}
-
-// writeJSONAtomic writes JSON to a temp file in the same directory and then renames it.
-// - Atomic replace is provided by os.Rename (same filesystem).
-// - If doSync is true, it fsyncs the temp file before close for stronger durability.
-func writeJSONAtomic(path string, s Summary, fileMode, dirMode os.FileMode, doSync bool) error {
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, dirMode); err != nil {
-		return err
-	}
-
-	f, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
-	if err != nil {
-		return err
-	}
-	tmp := f.Name()
-
-	success := false
-	defer func() {
-		if !success {
-			_ = f.Close()
-			_ = os.Remove(tmp)
-		}
-	}()
-
-	enc := json.NewEncoder(f)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(s); err != nil {
-		return err
-	}
-
-	if doSync {
-		if err := f.Sync(); err != nil {
-			return err
-		}
-	}
-
-	if err := f.Close(); err != nil {
-		return err
+	if s.SchemaVersion == "" {
+		s.SchemaVersion = CurrentSchemaVersion
 	}
-
-	if err := os.Chmod(tmp, fileMode); err != nil {
+	if err := s.Validate(); err != nil {
 		return err
 	}
-
-	if err := os.Rename(tmp, path); err != nil {
-		return err
-	}
-
-	success = true
-	return nil
+	return atomicfile.Write(path, atomicfile.Options{Sync: true, Durable: true}, func(out io.Writer) error {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	})
 }
+
+// Compile-time check
+var _ Writer = (*JSONFileWriter)(nil)
@@ -0,0 +1,157 @@
+// Package sloagent measures the operator from inside its own process. The Agent is a manager
+// runnable that samples the metrics registry directly (no HTTP, no token, no RBAC) and writes one
+// summary per measurement window to a directory (e.g. a mounted volume) and/or an upload URL.
+package sloagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/presets"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+	"github.com/yeongki/my-operator/pkg/slogather"
+)
+
+// Options configures an Agent. Zero values fall back to the defaults noted per field.
+type Options struct {
+	// Gatherer is sampled every window (nil => the controller-runtime metrics registry).
+	Gatherer prometheus.Gatherer
+	// Specs are evaluated per window (nil => presets.ControllerRuntime()).
+	Specs []spec.SLISpec
+	// Window is the length of one measurement window (0 => 10m). Windows are consecutive.
+	Window time.Duration
+
+	// Dir receives one summary per window plus artifacts-index.json (optional).
+	Dir string
+	// UploadURL (s3://, gs://, https://) additionally uploads each summary (optional).
+	UploadURL string
+
+	// RunID names the summaries (default "agent-<unix start time>"); Tags are recorded as is.
+	RunID  string
+	Tags   map[string]string
+	Logger slo.Logger
+}
+
+// Agent implements manager.Runnable (and LeaderElectionRunnable: it runs on every replica,
+// since each one has its own registry).
+type Agent struct {
+	opts    Options
+	fetcher fetch.MetricsFetcher
+	writer  summary.Writer
+}
+
+// New validates opts and returns an Agent; add it to the manager with mgr.Add.
+func New(opts Options) (*Agent, error) {
+	if strings.TrimSpace(opts.Dir) == "" && strings.TrimSpace(opts.UploadURL) == "" {
+		return nil, errors.New("sloagent: Dir or UploadURL is required")
+	}
+	if opts.Specs == nil {
+		opts.Specs = presets.ControllerRuntime()
+	}
+	if opts.Window <= 0 {
+		opts.Window = 10 * time.Minute
+	}
+	opts.Logger = slo.NewLogger(opts.Logger)
+
+	var w summary.Writer = discardWriter{}
+	if strings.TrimSpace(opts.Dir) != "" {
+		w = artifacts.NewIndexedSummaryWriter(opts.Dir, artifacts.DefaultOptions())
+	}
+	if strings.TrimSpace(opts.UploadURL) != "" {
+		uw, err := artifacts.NewUploadSummaryWriter(w, opts.UploadURL, opts.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("sloagent: %w", err)
+		}
+		w = uw
+	}
+	return &Agent{opts: opts, fetcher: slogather.New(opts.Gatherer), writer: w}, nil
+}
+
+// NeedLeaderElection is false: every replica measures itself.
+func (a *Agent) NeedLeaderElection() bool { return false }
+
+// Start measures consecutive windows until ctx is done; the last, partial window is evaluated
+// on shutdown. Measurement failures are logged and never stop the manager.
+func (a *Agent) Start(ctx context.Context) error {
+	runID := a.opts.RunID
+	if runID == "" {
+		runID = fmt.Sprintf("agent-%d", time.Now().Unix())
+	}
+
+	start, err := a.fetcher.Fetch(ctx, time.Now())
+	if err != nil {
+		slo.Warnf(a.opts.Logger, "sloagent: start sample failed, agent disabled: %v", err)
+		<-ctx.Done()
+		return nil
+	}
+
+	ticker := time.NewTicker(a.opts.Window)
+	defer ticker.Stop()
+	for seq := 1; ; seq++ {
+		select {
+		case <-ctx.Done():
+			a.measure(context.WithoutCancel(ctx), runID, seq, start, time.Now())
+			return nil
+		case now := <-ticker.C:
+			if end, ok := a.measure(ctx, runID, seq, start, now); ok {
+				start = end
+			}
+		}
+	}
+}
+
+// measure evaluates the window [start, now] and returns the end sample, which starts the next window.
+func (a *Agent) measure(
+	ctx context.Context, runID string, seq int, start fetch.Sample, now time.Time,
+) (fetch.Sample, bool) {
+	end, err := a.fetcher.Fetch(ctx, now)
+	if err != nil {
+		slo.Warnf(a.opts.Logger, "sloagent: window %d: sample failed, extending the window: %v", seq, err)
+		return fetch.Sample{}, false
+	}
+
+	// without Dir only the base name matters (upload key)
+	outPath := filepath.Join(a.opts.Dir, fmt.Sprintf("sli-summary.agent.%s.%04d.json", runID, seq))
+	eng := engine.New(pairFetcher{start: start, end: end}, a.writer, a.opts.Logger)
+	if _, err := eng.Execute(ctx, engine.ExecuteRequest{
+		Config: engine.RunConfig{
+			RunID:      runID,
+			StartedAt:  start.At,
+			FinishedAt: end.At,
+			Mode:       engine.RunMode{Location: "inside", Trigger: "none"},
+			Tags:       a.opts.Tags,
+		},
+		Specs:   a.opts.Specs,
+		OutPath: outPath,
+	}); err != nil {
+		slo.Warnf(a.opts.Logger, "sloagent: window %d: write summary failed: %v", seq, err)
+	}
+	return end, true
+}
+
+// pairFetcher replays two already taken samples: start for the window start, end otherwise.
+type pairFetcher struct {
+	start, end fetch.Sample
+}
+
+func (p pairFetcher) Fetch(_ context.Context, at time.Time) (fetch.Sample, error) {
+	if at.Equal(p.start.At) {
+		return p.start, nil
+	}
+	return p.end, nil
+}
+
+type discardWriter struct{}
+
+func (discardWriter) Write(string, summary.Summary) error { return nil }
@@ -0,0 +1,56 @@
+package sloagent
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+func TestAgentWritesSummaryOnShutdown(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	total := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "reconcile_total"}, []string{"result"})
+	reg.MustRegister(total)
+	total.WithLabelValues("success").Add(2)
+
+	dir := t.TempDir()
+	agent, err := New(Options{
+		Gatherer: reg,
+		Specs: []spec.SLISpec{{
+			ID:      "reconcile_delta",
+			Inputs:  []spec.MetricRef{{Key: `reconcile_total{result="success"}`}},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		}},
+		Window: time.Hour,
+		Dir:    dir,
+		RunID:  "r1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- agent.Start(ctx) }()
+
+	// the start sample is taken right away; let it happen before changing the counter
+	time.Sleep(100 * time.Millisecond)
+	total.WithLabelValues("success").Add(3)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := summary.Load(filepath.Join(dir, "sli-summary.agent.r1.0001.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Results) != 1 || s.Results[0].Value == nil || *s.Results[0].Value != 3 {
+		t.Fatalf("unexpected results %+v", s.Results)
+	}
+}
@@ -0,0 +1,108 @@
+package slogather
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/yeongki/my-operator/pkg/slo/diff"
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promproto"
+	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
+)
+
+// registry exposes every metric type, with n series per vector.
+func registry(n int) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "reconcile_total"}, []string{"controller", "result"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "workqueue_depth"})
+	hist := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "reconcile_time_seconds"}, []string{"controller"})
+	summ := prometheus.NewSummary(prometheus.SummaryOpts{
+		Name: "rest_latency_seconds", Objectives: map[float64]float64{0.5: 0.05, 0.99: 0.001},
+	})
+	reg.MustRegister(counter, gauge, hist, summ)
+	for i := range n {
+		c := fmt.Sprintf("c-%d", i)
+		counter.WithLabelValues(c, `we"ird`).Add(float64(i))
+		hist.WithLabelValues(c).Observe(float64(i) / 10)
+	}
+	gauge.Set(3)
+	summ.Observe(0.2)
+	summ.Observe(1.5)
+	return reg
+}
+
+func encode(t testing.TB, reg *prometheus.Registry, format expfmt.Format) []byte {
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, format)
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// The protobuf decoder must give exactly the series the text parser gives for the same families.
+func TestProtobufMatchesText(t *testing.T) {
+	reg := registry(3)
+	text, err := promtext.ParseTextToMap(bytes.NewReader(encode(t, reg, expfmt.NewFormat(expfmt.TypeTextPlain))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb := encode(t, reg, expfmt.NewFormat(expfmt.TypeProtoDelim))
+	proto, err := promproto.ParseToMap(bytes.NewReader(pb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep := diff.Maps(text, proto); !rep.Empty() {
+		var b strings.Builder
+		_ = rep.WriteText(&b)
+		t.Fatalf("protobuf and text differ:\n%s", b.String())
+	}
+	// the allowlist keeps the same series in both decoders
+	names := []string{"reconcile_time_seconds_bucket", "workqueue_depth"}
+	textBody := encode(t, reg, expfmt.NewFormat(expfmt.TypeTextPlain))
+	textKept, err := promtext.Parse(bytes.NewReader(textBody), promtext.Options{Names: names})
+	if err != nil {
+		t.Fatal(err)
+	}
+	protoKept, err := promproto.Parse(bytes.NewReader(pb), promproto.Options{Names: names})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep := diff.Maps(textKept.Values, protoKept); !rep.Empty() || len(protoKept) != 3*12+1 {
+		t.Fatalf("allowlisted series differ (%d kept): %+v", len(protoKept), rep)
+	}
+
+	if !promproto.IsContentType(string(expfmt.NewFormat(expfmt.TypeProtoDelim))) {
+		t.Fatal("expected the expfmt protobuf content type to be recognized")
+	}
+
+	if _, err := promproto.ParseToMap(bytes.NewReader(pb[:len(pb)-3])); err == nil {
+		t.Fatal("expected a cut-off protobuf scrape to be an error")
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	reg := registry(2700) // ~40k series: 15 per controller with the histogram buckets
+	text := encode(b, reg, expfmt.NewFormat(expfmt.TypeTextPlain))
+	pb := encode(b, reg, expfmt.NewFormat(expfmt.TypeProtoDelim))
+	b.Run("text", func(b *testing.B) {
+		for b.Loop() {
+			_, _ = promtext.ParseTextToMap(bytes.NewReader(text))
+		}
+	})
+	b.Run("protobuf", func(b *testing.B) {
+		for b.Loop() {
+			_, _ = promproto.ParseToMap(bytes.NewReader(pb))
+		}
+	})
+}
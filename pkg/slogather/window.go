@@ -0,0 +1,70 @@
+package slogather
+
+import (
+	"context"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// Window is a measurement window over a live fetcher: the start sample is taken by Start, the
+// end sample by End, and both are evaluated with the regular engine.
+//
+//	w, err := slogather.Start(ctx, slogather.New(nil))
+//	... reconcile ...
+//	sum, err := w.End(ctx, presets.ControllerRuntime())
+type Window struct {
+	fetcher fetch.MetricsFetcher
+	start   fetch.Sample
+
+	// RunID and Tags are recorded in the summary (optional).
+	RunID string
+	Tags  map[string]string
+}
+
+// Start takes the start sample of a window.
+func Start(ctx context.Context, f fetch.MetricsFetcher) (*Window, error) {
+	s, err := f.Fetch(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &Window{fetcher: f, start: s}, nil
+}
+
+// End takes the end sample and evaluates specs over the window. Nothing is written.
+func (w *Window) End(ctx context.Context, specs []spec.SLISpec) (*summary.Summary, error) {
+	end, err := w.fetcher.Fetch(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	eng := engine.New(pairFetcher{start: w.start, end: end}, discardWriter{}, nil)
+	return eng.Execute(ctx, engine.ExecuteRequest{
+		Config: engine.RunConfig{
+			RunID:      w.RunID,
+			StartedAt:  w.start.At,
+			FinishedAt: end.At,
+			Mode:       engine.RunMode{Location: "inside", Trigger: "none"},
+			Tags:       w.Tags,
+		},
+		Specs: specs,
+	})
+}
+
+// pairFetcher replays two already taken samples: start for the window start, end otherwise.
+type pairFetcher struct {
+	start, end fetch.Sample
+}
+
+func (p pairFetcher) Fetch(_ context.Context, at time.Time) (fetch.Sample, error) {
+	if at.Equal(p.start.At) {
+		return p.start, nil
+	}
+	return p.end, nil
+}
+
+type discardWriter struct{}
+
+func (discardWriter) Write(string, summary.Summary) error { return nil }
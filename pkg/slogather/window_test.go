@@ -0,0 +1,52 @@
+package slogather
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+func TestWindowMeasuresRegistryDelta(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	total := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "reconcile_total"}, []string{"result"})
+	hist := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "reconcile_seconds", Buckets: []float64{1}})
+	reg.MustRegister(total, hist)
+	total.WithLabelValues("success").Add(5)
+
+	ctx := context.Background()
+	w, err := Start(ctx, New(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	total.WithLabelValues("success").Add(2)
+	total.WithLabelValues("error").Inc()
+	hist.Observe(0.5)
+
+	sum, err := w.End(ctx, []spec.SLISpec{
+		{
+			ID:      "reconcile_success_delta",
+			Inputs:  []spec.MetricRef{{Key: `reconcile_total{result="success"}`}},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+		{
+			ID:      "reconcile_seconds_count_delta",
+			Inputs:  []spec.MetricRef{{Key: "reconcile_seconds_count"}},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []float64{2, 1} {
+		r := sum.Results[i]
+		if r.Value == nil || *r.Value != want {
+			t.Fatalf("%s: got %+v, want %v", r.ID, r, want)
+		}
+	}
+	if p := sum.Results[0].Provenance; p == nil || p.End == nil || p.End.Fetcher != "gatherer" {
+		t.Fatalf("unexpected provenance %+v", p)
+	}
+}
@@ -0,0 +1,241 @@
+// Package slolab loads slolab.yaml profiles: one file describing what the SLO lab measures
+// (presets, metric definitions, objectives) and where results go (fetcher, writers, policies),
+// shared by the e2e harness (SLOLAB_PROFILE) and slocli (-profile).
+//
+//	presets: [controller-runtime, process]
+//	metrics:
+//	  - id: joboperator_reconciles
+//	    kind: delta_counter
+//	    inputs: [{key: joboperator_reconcile_total}]
+//	    compute: {mode: delta}
+//	objectives:
+//	  - sli: reconcile_error_delta
+//	    rules: [{op: "<=", target: 0, level: fail}]
+//	fetcher: {type: prometheus, url: "http://prometheus:9090", selector: 'namespace="my-operator-system"'}
+//	writers:
+//	  - {type: local, target: /tmp/slo}
+//	  - {type: upload, target: "s3://bucket/slo"}
+//	policies:
+//	  failOnPolicy: true
+//	  upload: {maskTags: [cluster], dropWarnings: true}
+package slolab
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/yeongki/my-operator/pkg/slo/presets"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// Fetcher types.
+const (
+	FetcherCurl       = "curl"       // e2e only: a curl pod scrapes the metrics Service
+	FetcherHTTP       = "http"       // scrape URL directly (slocli)
+	FetcherPrometheus = "prometheus" // query the Prometheus server at URL
+)
+
+// Writer types.
+const (
+	WriterLocal  = "local"  // Target: directory of the summary JSON files
+	WriterUpload = "upload" // Target: s3://, gs:// or https:// URL (see artifacts.NewUploader)
+	WriterOTLP   = "otlp"   // Target: OTLP/HTTP collector base URL
+	WriterBundle = "bundle" // Target: directory of replay bundles (e2e only)
+	WriterStdout = "stdout" // no Target: one SLOLAB_RESULT JSON line per summary (artifacts.ResultLineWriter)
+)
+
+// Profile is a parsed slolab.yaml. Every section is optional: unset parts keep the caller's
+// defaults (env vars, flags, the baseline presets).
+type Profile struct {
+	// Presets are preset names (presets.Names) whose specs are measured.
+	Presets []string `json:"presets,omitempty"`
+	// Metrics are additional SLI definitions, in spec.SLISpec form.
+	Metrics []spec.SLISpec `json:"metrics,omitempty"`
+	// Objectives replace the judge rules of SLIs by ID (presets or Metrics).
+	Objectives []Objective `json:"objectives,omitempty"`
+	Fetcher    *Fetcher    `json:"fetcher,omitempty"`
+	Writers    []Writer    `json:"writers,omitempty"`
+	Policies   Policies    `json:"policies,omitempty"`
+}
+
+// Objective sets the rules an SLI is judged by.
+type Objective struct {
+	SLI   string      `json:"sli"`
+	Rules []spec.Rule `json:"rules"`
+}
+
+// Fetcher selects where snapshots come from.
+type Fetcher struct {
+	Type string `json:"type"`
+	// URL is the metrics endpoint (http) or Prometheus server (prometheus).
+	URL string `json:"url,omitempty"`
+	// Selector narrows Prometheus queries, e.g. namespace="my-operator-system".
+	Selector string `json:"selector,omitempty"`
+}
+
+// Writer is one destination of the summaries.
+type Writer struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+// Policies control judging and what leaves the machine.
+type Policies struct {
+	// FailOnPolicy fails the run when an SLI rule at level fail is violated.
+	FailOnPolicy bool `json:"failOnPolicy,omitempty"`
+	// Upload filters uploaded and exported summaries (the local copy keeps everything).
+	Upload *UploadPolicy `json:"upload,omitempty"`
+}
+
+// UploadPolicy is summary.RedactPolicy in profile form.
+type UploadPolicy struct {
+	KeepTags         []string `json:"keepTags,omitempty"`
+	MaskTags         []string `json:"maskTags,omitempty"`
+	KeepResultFields []string `json:"keepResultFields,omitempty"`
+	DropWarnings     bool     `json:"dropWarnings,omitempty"`
+}
+
+// RedactPolicy converts p (nil => the zero policy, keep everything).
+func (p *UploadPolicy) RedactPolicy() summary.RedactPolicy {
+	if p == nil {
+		return summary.RedactPolicy{}
+	}
+	return summary.RedactPolicy{
+		KeepTags:         p.KeepTags,
+		MaskTags:         p.MaskTags,
+		KeepResultFields: p.KeepResultFields,
+		DropWarnings:     p.DropWarnings,
+	}
+}
+
+// Load reads and validates the profile at path.
+func Load(path string) (*Profile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("slolab profile: %w", err)
+	}
+	p, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("slolab profile %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse decodes a YAML (or JSON) profile strictly, so a misspelled key is an error rather than
+// a silently ignored setting, and validates it.
+func Parse(data []byte) (*Profile, error) {
+	var p Profile
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks preset names, the fetcher and writer types and that every objective names an
+// SLI of the profile (when it defines any).
+func (p *Profile) Validate() error {
+	var errs []error
+	for _, n := range p.Presets {
+		if _, ok := presets.ByName(n); !ok {
+			errs = append(errs, fmt.Errorf("unknown preset %q", n))
+		}
+	}
+	for i, m := range p.Metrics {
+		if m.ID == "" {
+			errs = append(errs, fmt.Errorf("metrics[%d]: id is required", i))
+		}
+	}
+	for i, o := range p.Objectives {
+		if o.SLI == "" || len(o.Rules) == 0 {
+			errs = append(errs, fmt.Errorf("objectives[%d]: sli and rules are required", i))
+		}
+	}
+	if f := p.Fetcher; f != nil {
+		switch f.Type {
+		case FetcherCurl:
+		case FetcherHTTP, FetcherPrometheus:
+			if f.URL == "" {
+				errs = append(errs, fmt.Errorf("fetcher %s: url is required", f.Type))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unknown fetcher type %q", f.Type))
+		}
+	}
+	for i, w := range p.Writers {
+		if !slices.Contains([]string{WriterLocal, WriterUpload, WriterOTLP, WriterBundle, WriterStdout}, w.Type) {
+			errs = append(errs, fmt.Errorf("writers[%d]: unknown type %q", i, w.Type))
+		} else if w.Target == "" && w.Type != WriterStdout {
+			errs = append(errs, fmt.Errorf("writers[%d]: target is required", i))
+		}
+	}
+	if len(errs) == 0 && (len(p.Presets) > 0 || len(p.Metrics) > 0) {
+		_, err := p.Specs(nil)
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Specs returns the specs of the profile's presets (an SLI shared by two presets once) followed by
+// its Metrics, or defaults when it names neither, with the Objectives applied. A metric reusing a
+// measured ID or an objective for an SLI that is not measured is an error.
+func (p *Profile) Specs(defaults []spec.SLISpec) ([]spec.SLISpec, error) {
+	if len(p.Presets) == 0 && len(p.Metrics) == 0 {
+		return p.applyObjectives(slices.Clone(defaults))
+	}
+	var out []spec.SLISpec
+	seen := map[string]bool{}
+	for _, n := range p.Presets {
+		specs, ok := presets.ByName(n)
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %q", n)
+		}
+		for _, s := range specs {
+			if !seen[s.ID] {
+				seen[s.ID] = true
+				out = append(out, s)
+			}
+		}
+	}
+	for _, s := range p.Metrics {
+		if seen[s.ID] {
+			return nil, fmt.Errorf("metric %q is already defined (use objectives to change its rules)", s.ID)
+		}
+		seen[s.ID] = true
+		out = append(out, s)
+	}
+	return p.applyObjectives(out)
+}
+
+func (p *Profile) applyObjectives(out []spec.SLISpec) ([]spec.SLISpec, error) {
+	for _, o := range p.Objectives {
+		i := slices.IndexFunc(out, func(s spec.SLISpec) bool { return s.ID == o.SLI })
+		if i < 0 {
+			return nil, fmt.Errorf("objective for unknown sli %q", o.SLI)
+		}
+		out[i].Judge = &spec.JudgeSpec{Rules: slices.Clone(o.Rules)}
+	}
+	return out, nil
+}
+
+// Writer returns the target of the first writer of type t ("" => none; see HasWriter for stdout).
+func (p *Profile) Writer(t string) string {
+	for _, w := range p.Writers {
+		if w.Type == t {
+			return w.Target
+		}
+	}
+	return ""
+}
+
+// HasWriter reports whether the profile has a writer of type t.
+func (p *Profile) HasWriter(t string) bool {
+	return slices.ContainsFunc(p.Writers, func(w Writer) bool { return w.Type == t })
+}
@@ -0,0 +1,99 @@
+package slolab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+const testProfile = `
+presets: [controller-runtime, workqueue]
+metrics:
+  - id: joboperator_reconciles
+    kind: delta_counter
+    inputs: [{key: joboperator_reconcile_total}]
+    compute: {mode: delta}
+objectives:
+  - sli: reconcile_error_delta
+    rules: [{op: "<=", target: 0, level: fail}]
+  - sli: joboperator_reconciles
+    rules: [{op: ge, target: 1, level: warn}]
+fetcher: {type: prometheus, url: "http://prometheus:9090"}
+writers:
+  - {type: local, target: /tmp/slo}
+policies:
+  failOnPolicy: true
+  upload: {maskTags: [cluster]}
+`
+
+func TestParseAndSpecs(t *testing.T) {
+	p, err := Parse([]byte(testProfile))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	specs, err := p.Specs(nil)
+	if err != nil {
+		t.Fatalf("Specs: %v", err)
+	}
+	byID := map[string]spec.SLISpec{}
+	for _, s := range specs {
+		if _, dup := byID[s.ID]; dup {
+			t.Errorf("sli %s listed twice", s.ID)
+		}
+		byID[s.ID] = s
+	}
+	if s := byID["reconcile_error_delta"]; s.Judge == nil || len(s.Judge.Rules) != 1 ||
+		s.Judge.Rules[0].Op != spec.OpLE || s.Judge.Rules[0].Level != spec.LevelFail {
+		t.Errorf("objective not applied: %+v", s.Judge)
+	}
+	if s := byID["joboperator_reconciles"]; s.Judge == nil || s.Judge.Rules[0].Op != spec.OpGE {
+		t.Errorf("metric objective not applied (op alias): %+v", s.Judge)
+	}
+	if specs[len(specs)-1].ID != "joboperator_reconciles" {
+		t.Errorf("metrics should follow the presets, last = %s", specs[len(specs)-1].ID)
+	}
+	if got := p.Writer(WriterLocal); got != "/tmp/slo" {
+		t.Errorf("local writer = %q", got)
+	}
+	if pol := p.Policies.Upload.RedactPolicy(); len(pol.MaskTags) != 1 || !p.Policies.FailOnPolicy {
+		t.Errorf("policies = %+v", p.Policies)
+	}
+}
+
+func TestSpecsDefaults(t *testing.T) {
+	defaults := []spec.SLISpec{{ID: "a"}, {ID: "b"}}
+	p := &Profile{Objectives: []Objective{{SLI: "b", Rules: []spec.Rule{{Op: spec.OpLT, Target: 1}}}}}
+	specs, err := p.Specs(defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 2 || specs[1].Judge == nil || defaults[1].Judge != nil {
+		t.Errorf("objective should apply to a copy of the defaults: %+v / %+v", specs, defaults)
+	}
+	if _, err := (&Profile{}).Specs(defaults); err != nil {
+		t.Errorf("empty profile: %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, tc := range map[string]struct{ yaml, want string }{
+		"unknown key":     {"presets: [process]\nfetchr: {type: curl}", "fetchr"},
+		"unknown preset":  {"presets: [nope]", `unknown preset "nope"`},
+		"fetcher type":    {"fetcher: {type: carrier-pigeon}", "unknown fetcher type"},
+		"fetcher url":     {"fetcher: {type: http}", "url is required"},
+		"writer type":     {"writers: [{type: ftp, target: x}]", "unknown type"},
+		"writer target":   {"writers: [{type: upload}]", "target is required"},
+		"objective sli":   {"presets: [process]\nobjectives: [{sli: nope, rules: [{op: '<', target: 1}]}]", "unknown sli"},
+		"duplicate id":    {"presets: [process]\nmetrics: [{id: go_goroutines_max, inputs: []}]", "already defined"},
+		"invalid op":      {"objectives: [{sli: a, rules: [{op: '~', target: 1}]}]", "invalid op"},
+		"empty objective": {"objectives: [{sli: a}]", "sli and rules are required"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.yaml))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// Package watchconv measures how long an object takes to reach a condition (e.g. Ready=True) from
+// informer events. Polling with Eventually rounds convergence up to the poll interval; the watcher
+// records the time the event that flipped the condition was delivered.
+//
+//	w, err := watchconv.Watch(ctx, watchconv.Options{GVK: gvk, Key: key})
+//	... kubectl apply ...
+//	_ = w.Wait(ctx)
+//	sess.AddConvergence(w.Result())
+//	w.Stop()
+package watchconv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// ErrNotConverged is returned by Wait when ctx ends before the condition was observed.
+var ErrNotConverged = errors.New("watchconv: condition not observed")
+
+// Condition reports whether obj reached the watched state.
+type Condition func(obj *unstructured.Unstructured) bool
+
+// ConditionTrue holds when status.conditions has an entry of type typ with status "True".
+func ConditionTrue(typ string) Condition {
+	return func(obj *unstructured.Unstructured) bool {
+		conds, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, c := range conds {
+			m, ok := c.(map[string]any)
+			if ok && m["type"] == typ && m["status"] == "True" {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Options selects the watched object.
+type Options struct {
+	// Config is the API server to watch (default: the kubeconfig/in-cluster config).
+	Config *rest.Config
+	GVK    schema.GroupVersionKind
+	Key    types.NamespacedName
+
+	// Condition defaults to ConditionTrue("Ready"); ConditionName labels the result (default "Ready").
+	Condition     Condition
+	ConditionName string
+
+	Now func() time.Time
+}
+
+func (o Options) withDefaults() Options {
+	if o.Condition == nil {
+		o.Condition = ConditionTrue("Ready")
+		if o.ConditionName == "" {
+			o.ConditionName = "Ready"
+		}
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	return o
+}
+
+// Watcher records the first time the watched object satisfied its condition.
+type Watcher struct {
+	opts    Options
+	target  string
+	started time.Time
+	cancel  context.CancelFunc
+
+	mu        sync.Mutex
+	converged *time.Time
+	done      chan struct{}
+}
+
+func newWatcher(opts Options) *Watcher {
+	opts = opts.withDefaults()
+	target := strings.ToLower(opts.GVK.Kind) + "/" + opts.Key.Name
+	if opts.Key.Namespace != "" {
+		target = strings.ToLower(opts.GVK.Kind) + "/" + opts.Key.Namespace + "/" + opts.Key.Name
+	}
+	return &Watcher{
+		opts:    opts,
+		target:  target,
+		started: opts.Now(),
+		done:    make(chan struct{}),
+	}
+}
+
+// Watch starts an informer restricted to the object and returns once its cache has synced.
+// The convergence clock starts now, so call Watch before creating or changing the object.
+func Watch(ctx context.Context, opts Options) (*Watcher, error) {
+	w := newWatcher(opts)
+	cfg := w.opts.Config
+	if cfg == nil {
+		var err error
+		if cfg, err = config.GetConfig(); err != nil {
+			return nil, fmt.Errorf("watchconv: %w", err)
+		}
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(w.opts.GVK)
+	cacheOpts := cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			obj: {Field: fields.OneTermEqualSelector("metadata.name", w.opts.Key.Name)},
+		},
+	}
+	if ns := w.opts.Key.Namespace; ns != "" {
+		cacheOpts.DefaultNamespaces = map[string]cache.Config{ns: {}}
+	}
+	c, err := cache.New(cfg, cacheOpts)
+	if err != nil {
+		return nil, fmt.Errorf("watchconv: %w", err)
+	}
+	inf, err := c.GetInformer(ctx, obj)
+	if err != nil {
+		return nil, fmt.Errorf("watchconv: informer for %s: %w", w.opts.GVK, err)
+	}
+	if _, err := inf.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    w.observe,
+		UpdateFunc: func(_, obj any) { w.observe(obj) },
+	}); err != nil {
+		return nil, fmt.Errorf("watchconv: %w", err)
+	}
+
+	wctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	go func() { _ = c.Start(wctx) }()
+	if !c.WaitForCacheSync(ctx) {
+		cancel()
+		return nil, fmt.Errorf("watchconv: cache for %s did not sync", w.target)
+	}
+	return w, nil
+}
+
+// observe handles an informer event; only the first event satisfying the condition counts.
+func (w *Watcher) observe(obj any) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok || u.GetName() != w.opts.Key.Name || !w.opts.Condition(u) {
+		return
+	}
+	at := w.opts.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.converged != nil {
+		return
+	}
+	w.converged = &at
+	close(w.done)
+}
+
+// Wait blocks until the condition was observed or ctx ends (ErrNotConverged).
+func (w *Watcher) Wait(ctx context.Context) error {
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %s %s: %v", ErrNotConverged, w.target, w.opts.ConditionName, ctx.Err())
+	}
+}
+
+// Result is the convergence observed so far (ConvergedAt is nil when not yet observed).
+func (w *Watcher) Result() summary.Convergence {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	c := summary.Convergence{
+		Target:    w.target,
+		Condition: w.opts.ConditionName,
+		StartedAt: w.started,
+	}
+	if w.converged != nil {
+		at := *w.converged
+		c.ConvergedAt = &at
+		c.Seconds = at.Sub(w.started).Seconds()
+	}
+	return c
+}
+
+// Stop stops the informer. It is safe to call more than once.
+func (w *Watcher) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+}
@@ -0,0 +1,57 @@
+package watchconv
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func object(name, ready string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"status": map[string]any{"conditions": []any{
+			map[string]any{"type": "Progressing", "status": "True"},
+			map[string]any{"type": "Ready", "status": ready},
+		}},
+	}}
+	u.SetName(name)
+	return u
+}
+
+func TestWatcherRecordsFirstFlip(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	now := t0
+	w := newWatcher(Options{
+		GVK: schema.GroupVersionKind{Group: "batch.example.com", Version: "v1", Kind: "JobOperator"},
+		Key: types.NamespacedName{Namespace: "ns", Name: "sample"},
+		Now: func() time.Time { return now },
+	})
+
+	now = t0.Add(time.Second)
+	w.observe(object("sample", "False"))
+	w.observe(object("other", "True"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := w.Wait(ctx); !errors.Is(err, ErrNotConverged) {
+		t.Fatalf("expected ErrNotConverged, got %v", err)
+	}
+	if w.Result().Converged() {
+		t.Fatalf("expected no convergence yet, got %+v", w.Result())
+	}
+
+	now = t0.Add(1500 * time.Millisecond)
+	w.observe(object("sample", "True"))
+	now = t0.Add(3 * time.Second)
+	w.observe(object("sample", "True"))
+	if err := w.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := w.Result()
+	if got.Target != "joboperator/ns/sample" || got.Condition != "Ready" || got.Seconds != 1.5 {
+		t.Fatalf("unexpected result %+v", got)
+	}
+}
@@ -0,0 +1,310 @@
+// Package bootstrap installs the operator for e2e runs without shelling out to make/kustomize/kubectl:
+// the kustomize overlays are built in process (krusty) and applied with server-side apply.
+//
+//	b, err := bootstrap.New(bootstrap.Options{ProjectDir: rootDir, Image: projectImage, Logger: logger})
+//	err = b.Install(ctx) // config/crd, waits for the CRDs to be Established
+//	err = b.Deploy(ctx)  // config/default (or DeployDir), waits for the Deployments to roll out
+//	...
+//	err = b.Undeploy(ctx)
+//	err = b.Uninstall(ctx)
+//
+// Each step logs one line per object and is bounded by StepTimeout on top of ctx, so a stuck API
+// server fails the step with a context error instead of hanging BeforeAll.
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
+)
+
+const (
+	// DefaultCRDDir and DefaultDeployDir are what `make install` / `make deploy` build.
+	DefaultCRDDir    = "config/crd"
+	DefaultDeployDir = "config/default"
+	// DefaultFieldManager owns the applied fields; each step appends its name ("-install", ...).
+	DefaultFieldManager = "my-operator-e2e"
+	// ManagerContainer is the container whose image Options.Image replaces
+	// (what `kustomize edit set image controller=IMG` does for `make deploy`).
+	ManagerContainer = "manager"
+)
+
+// Options configures a Bootstrapper.
+type Options struct {
+	// Config is the API server (default: the kubeconfig/in-cluster config).
+	Config *rest.Config
+	// ProjectDir is the repository root the kustomize directories are relative to.
+	ProjectDir string
+	// CRDDir (default DefaultCRDDir) and DeployDir (default DefaultDeployDir, e.g. "config/namespaced").
+	CRDDir    string
+	DeployDir string
+	// Image replaces the image of the manager container ("" keeps the kustomization's image).
+	Image string
+	// FieldManager of the server-side applies (default DefaultFieldManager).
+	FieldManager string
+	// StepTimeout bounds each step including its wait (default 5m); Interval is the poll interval
+	// (default 1s).
+	StepTimeout time.Duration
+	Interval    time.Duration
+
+	Logger slo.Logger
+}
+
+func (o Options) withDefaults() Options {
+	if o.CRDDir == "" {
+		o.CRDDir = DefaultCRDDir
+	}
+	if o.DeployDir == "" {
+		o.DeployDir = DefaultDeployDir
+	}
+	if o.FieldManager == "" {
+		o.FieldManager = DefaultFieldManager
+	}
+	if o.StepTimeout <= 0 {
+		o.StepTimeout = 5 * time.Minute
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Second
+	}
+	o.Logger = slo.NewLogger(o.Logger)
+	return o
+}
+
+// Bootstrapper applies and deletes the operator manifests.
+type Bootstrapper struct {
+	opts Options
+	c    client.Client
+}
+
+// New connects to the API server; nothing is applied yet.
+func New(opts Options) (*Bootstrapper, error) {
+	opts = opts.withDefaults()
+	cfg := opts.Config
+	if cfg == nil {
+		var err error
+		if cfg, err = config.GetConfig(); err != nil {
+			return nil, fmt.Errorf("bootstrap: %w", err)
+		}
+	}
+	c, err := client.New(cfg, client.Options{Scheme: e2eutil.Scheme})
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: %w", err)
+	}
+	return &Bootstrapper{opts: opts, c: c}, nil
+}
+
+// Client is the client the Bootstrapper applies with (e.g. for e2eutil.ApplyYAML).
+func (b *Bootstrapper) Client() client.Client { return b.c }
+
+// Build renders the kustomize directory dir (relative to projectDir) like `kustomize build`,
+// with the manager image replaced by image ("" keeps it).
+func Build(projectDir, dir, image string) ([]*unstructured.Unstructured, error) {
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	m, err := k.Run(filesys.MakeFsOnDisk(), filepath.Join(projectDir, dir))
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: build %s: %w", dir, err)
+	}
+	out := make([]*unstructured.Unstructured, 0, len(m.Resources()))
+	for _, r := range m.Resources() {
+		// through JSON, so numbers are int64/float64 as unstructured helpers expect
+		raw, err := r.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("bootstrap: build %s: %w", dir, err)
+		}
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(raw); err != nil {
+			return nil, fmt.Errorf("bootstrap: build %s: %w", dir, err)
+		}
+		if image != "" {
+			if err := setManagerImage(u, image); err != nil {
+				return nil, fmt.Errorf("bootstrap: build %s: %w", dir, err)
+			}
+		}
+		out = append(out, u)
+	}
+	return out, nil
+}
+
+func setManagerImage(u *unstructured.Unstructured, image string) error {
+	if u.GetKind() != "Deployment" {
+		return nil
+	}
+	containers, _, err := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+	if err != nil {
+		return err
+	}
+	for i, c := range containers {
+		if m, ok := c.(map[string]any); ok && m["name"] == ManagerContainer {
+			m["image"] = image
+			containers[i] = m
+		}
+	}
+	return unstructured.SetNestedSlice(u.Object, containers, "spec", "template", "spec", "containers")
+}
+
+// Install applies CRDDir and waits until every CRD is Established.
+func (b *Bootstrapper) Install(ctx context.Context) error {
+	objs, err := Build(b.opts.ProjectDir, b.opts.CRDDir, "")
+	if err != nil {
+		return err
+	}
+	return b.step(ctx, "install", func(ctx context.Context) error {
+		if err := b.Apply(ctx, "install", objs); err != nil {
+			return err
+		}
+		return b.waitAll(ctx, objs, "CustomResourceDefinition", crdEstablished)
+	})
+}
+
+// Deploy applies DeployDir with the manager image and waits until its Deployments rolled out.
+func (b *Bootstrapper) Deploy(ctx context.Context) error {
+	return b.DeployImage(ctx, b.opts.Image)
+}
+
+// DeployImage is Deploy with another manager image (e.g. the previous release of an upgrade test).
+func (b *Bootstrapper) DeployImage(ctx context.Context, image string) error {
+	objs, err := Build(b.opts.ProjectDir, b.opts.DeployDir, image)
+	if err != nil {
+		return err
+	}
+	return b.step(ctx, "deploy", func(ctx context.Context) error {
+		if err := b.Apply(ctx, "deploy", objs); err != nil {
+			return err
+		}
+		return b.waitAll(ctx, objs, "Deployment", deploymentRolledOut)
+	})
+}
+
+// ApplyManifest applies a rendered multi-document manifest as the step owner (which also names
+// its field manager, so it does not drop fields another step applied to the same object).
+func (b *Bootstrapper) ApplyManifest(ctx context.Context, owner, manifest string) error {
+	objs, err := e2eutil.SplitYAML(manifest)
+	if err != nil {
+		return err
+	}
+	return b.step(ctx, owner, func(ctx context.Context) error { return b.Apply(ctx, owner, objs) })
+}
+
+// Undeploy deletes the objects of DeployDir (missing objects and kinds are ignored).
+func (b *Bootstrapper) Undeploy(ctx context.Context) error {
+	objs, err := Build(b.opts.ProjectDir, b.opts.DeployDir, "")
+	if err != nil {
+		return err
+	}
+	return b.step(ctx, "undeploy", func(ctx context.Context) error { return b.Delete(ctx, objs) })
+}
+
+// Uninstall deletes the CRDs of CRDDir (missing CRDs are ignored).
+func (b *Bootstrapper) Uninstall(ctx context.Context) error {
+	objs, err := Build(b.opts.ProjectDir, b.opts.CRDDir, "")
+	if err != nil {
+		return err
+	}
+	return b.step(ctx, "uninstall", func(ctx context.Context) error { return b.Delete(ctx, objs) })
+}
+
+func (b *Bootstrapper) step(ctx context.Context, name string, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, b.opts.StepTimeout)
+	defer cancel()
+	start := time.Now()
+	b.opts.Logger.Logf("bootstrap: %s started", name)
+	if err := fn(ctx); err != nil {
+		return fmt.Errorf("bootstrap: %s: %w", name, err)
+	}
+	b.opts.Logger.Logf("bootstrap: %s done in %s", name, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// Apply server-side applies objs in order with the field manager FieldManager-<owner>, taking
+// over fields from other managers (e.g. a previous `kubectl apply`).
+func (b *Bootstrapper) Apply(ctx context.Context, owner string, objs []*unstructured.Unstructured) error {
+	a := &e2eutil.Applier{
+		Client:       b.c,
+		FieldManager: b.opts.FieldManager + "-" + owner,
+		Force:        true,
+		Logger:       b.opts.Logger,
+	}
+	_, err := a.ApplyObjects(ctx, objs)
+	return err
+}
+
+// Delete deletes objs in reverse order; objects (or kinds) that no longer exist are skipped.
+func (b *Bootstrapper) Delete(ctx context.Context, objs []*unstructured.Unstructured) error {
+	var errs []error
+	for i := len(objs) - 1; i >= 0; i-- {
+		err := b.c.Delete(ctx, objs[i].DeepCopy(), client.PropagationPolicy("Background"))
+		switch {
+		case err == nil:
+			b.opts.Logger.Logf("bootstrap: deleted %s", e2eutil.ObjectRef(objs[i]))
+		case apierrors.IsNotFound(err) || meta.IsNoMatchError(err):
+		default:
+			errs = append(errs, fmt.Errorf("delete %s: %w", e2eutil.ObjectRef(objs[i]), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// waitAll polls every object of kind until ready reports true.
+func (b *Bootstrapper) waitAll(ctx context.Context, objs []*unstructured.Unstructured, kind string,
+	ready func(*unstructured.Unstructured) bool) error {
+	for _, u := range objs {
+		if u.GetKind() != kind {
+			continue
+		}
+		cur := &unstructured.Unstructured{}
+		cur.SetGroupVersionKind(u.GroupVersionKind())
+		err := wait.PollUntilContextCancel(ctx, b.opts.Interval, true, func(ctx context.Context) (bool, error) {
+			if err := b.c.Get(ctx, client.ObjectKeyFromObject(u), cur); err != nil {
+				return false, client.IgnoreNotFound(err)
+			}
+			return ready(cur), nil
+		})
+		if err != nil {
+			return fmt.Errorf("waiting for %s: %w", e2eutil.ObjectRef(u), err)
+		}
+		b.opts.Logger.Logf("bootstrap: %s ready", e2eutil.ObjectRef(u))
+	}
+	return nil
+}
+
+func crdEstablished(u *unstructured.Unstructured) bool {
+	conds, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conds {
+		if m, ok := c.(map[string]any); ok && m["type"] == "Established" && m["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// deploymentRolledOut mirrors `kubectl rollout status`: the latest generation was observed and all
+// replicas are updated and available.
+func deploymentRolledOut(u *unstructured.Unstructured) bool {
+	observed, _, _ := unstructured.NestedInt64(u.Object, "status", "observedGeneration")
+	if observed < u.GetGeneration() {
+		return false
+	}
+	replicas, found, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	updated, _, _ := unstructured.NestedInt64(u.Object, "status", "updatedReplicas")
+	available, _, _ := unstructured.NestedInt64(u.Object, "status", "availableReplicas")
+	total, _, _ := unstructured.NestedInt64(u.Object, "status", "replicas")
+	return updated == replicas && available == replicas && total == replicas
+}
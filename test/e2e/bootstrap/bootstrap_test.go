@@ -0,0 +1,69 @@
+package bootstrap
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const projectDir = "../../.."
+
+func TestBuildReplacesManagerImage(t *testing.T) {
+	objs, err := Build(projectDir, DefaultDeployDir, "example.com/my-operator:e2e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]int{}
+	var image string
+	for _, u := range objs {
+		kinds[u.GetKind()]++
+		if u.GetKind() != "Deployment" {
+			continue
+		}
+		containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+		for _, c := range containers {
+			if m := c.(map[string]any); m["name"] == ManagerContainer {
+				image, _ = m["image"].(string)
+			}
+		}
+	}
+	if image != "example.com/my-operator:e2e" {
+		t.Fatalf("expected the manager image to be replaced, got %q", image)
+	}
+	if kinds["CustomResourceDefinition"] == 0 || kinds["Namespace"] != 1 {
+		t.Fatalf("expected the CRDs and the namespace in %s, got %v", DefaultDeployDir, kinds)
+	}
+
+	crds, err := Build(projectDir, DefaultCRDDir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(crds) == 0 || crds[0].GetKind() != "CustomResourceDefinition" {
+		t.Fatalf("expected CRDs, got %d objects", len(crds))
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	d := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"generation": int64(2)},
+		"spec":     map[string]any{"replicas": int64(1)},
+		"status": map[string]any{
+			"observedGeneration": int64(1), "replicas": int64(1),
+			"updatedReplicas": int64(1), "availableReplicas": int64(1),
+		},
+	}}
+	if deploymentRolledOut(d) {
+		t.Fatal("expected an unobserved generation not to be rolled out")
+	}
+	_ = unstructured.SetNestedField(d.Object, int64(2), "status", "observedGeneration")
+	if !deploymentRolledOut(d) {
+		t.Fatal("expected the deployment to be rolled out")
+	}
+
+	crd := &unstructured.Unstructured{Object: map[string]any{"status": map[string]any{
+		"conditions": []any{map[string]any{"type": "Established", "status": "True"}},
+	}}}
+	if !crdEstablished(crd) {
+		t.Fatal("expected the CRD to be established")
+	}
+}
@@ -1,10 +1,13 @@
 package curlmetrics
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/yeongki/my-operator/pkg/kubeutil"
@@ -14,16 +17,35 @@ import (
 const PodLabelSelector = "app=curl-metrics"
 
 // Client runs curl-metrics pods and fetches logs.
-// It is test-oriented (uses kubectl + curlimages/curl).
+// It is test-oriented (uses kubectl + curlimages/curl, see DefaultImage).
 type Client struct {
 	Logger slo.Logger
 	Runner kubeutil.CmdRunner
 
 	// Tunables (optional)
-	Image            string
-	LabelSelector    string
-	PodNamePrefix    string
-	ServiceURLFormat string // e.g. "https://%s.%s.svc:8443/metrics"
+	Image         string
+	LabelSelector string
+	PodNamePrefix string
+	// Endpoint of the metrics service (zero => DefaultEndpoint).
+	Endpoint Endpoint
+	// ServiceURLFormat overrides the URL built from Endpoint, e.g. "https://%s.%s.svc:8443/metrics"
+	// (service, namespace). TLS options still come from Endpoint.
+	ServiceURLFormat string
+	// ImagePullPolicy of the curl container (PullAlways/PullIfNotPresent/PullNever, "" => cluster
+	// default) and ImagePullSecrets (in the scrape namespace) for a private registry or mirror.
+	ImagePullPolicy  string
+	ImagePullSecrets []string
+	// RunAsUser is the curl container's UID (0 => DefaultRunAsUser, RunAsUserUnset => none, for an
+	// admission that assigns it). RunAsUserFromNamespace uses the first UID of the namespace's
+	// OpenShift range (openshift.io/sa.scc.uid-range) when it has one, so the pod passes the
+	// restricted SCC unmodified; see ParseRunAsUser.
+	RunAsUser              int64
+	RunAsUserFromNamespace bool
+	// Scheduling sizes and places the pod (New: DefaultScheduling; zero => BestEffort, anywhere).
+	Scheduling PodScheduling
+
+	mu            sync.Mutex
+	namespaceUIDs map[string]int64 // RunAsUserFromNamespace lookups (0 => no range)
 }
 
 // New creates a client with safe defaults.
@@ -33,40 +55,72 @@ func New(logger slo.Logger, r kubeutil.CmdRunner) *Client {
 		r = kubeutil.DefaultRunner{}
 	}
 	return &Client{
-		Logger:           slo.NewLogger(logger),
-		Runner:           r,
-		Image:            "curlimages/curl:latest",
-		LabelSelector:    PodLabelSelector,
-		PodNamePrefix:    "curl-metrics",
-		ServiceURLFormat: "https://%s.%s.svc:8443/metrics",
+		Logger:        slo.NewLogger(logger),
+		Runner:        r,
+		Image:         DefaultImage,
+		LabelSelector: PodLabelSelector,
+		PodNamePrefix: "curl-metrics",
+		Endpoint:      DefaultEndpoint(),
+		Scheduling:    DefaultScheduling(),
+	}
+}
+
+// runner and logger resolve the defaults without writing them back: one Client is shared by
+// sessions running in parallel.
+func (c *Client) runner() kubeutil.CmdRunner {
+	if c.Runner == nil {
+		return kubeutil.DefaultRunner{}
 	}
+	return c.Runner
+}
+
+func (c *Client) logger() slo.Logger { return slo.NewLogger(c.Logger) }
+
+// URL returns the metrics URL scraped for service svc in namespace ns.
+func (c *Client) URL(svc, ns string) string {
+	if c.ServiceURLFormat != "" {
+		return fmt.Sprintf(c.ServiceURLFormat, svc, ns)
+	}
+	return c.Endpoint.URL(svc, ns)
 }
 
 // RunOnce creates a short-lived curl pod that scrapes /metrics.
 // It returns the created pod name.
 // It does NOT wait; call WaitDone then Logs.
+// The token goes into a Secret named after the pod (applied from stdin) and owned by it, which the
+// pod mounts and curl reads from file: it never appears in argv, the pod spec or the logs, and is
+// garbage collected with the pod (DeletePodNoWait/CleanupByLabel delete it too). With
+// Endpoint.ProjectedToken the pod mounts its own ServiceAccount token and token is not used.
+// Pods of earlier scrapes are left alone: parallel processes share the namespace, so leftovers are
+// removed by their own DeletePodNoWait or by age (kubeutil.ReapPods), never by label here.
 func (c *Client) RunOnce(ctx context.Context, ns, token, metricsSvcName, serviceAccountName string) (string, error) {
-	c.Logger = slo.NewLogger(c.Logger)
-	if c.Runner == nil {
-		c.Runner = kubeutil.DefaultRunner{}
+	image := c.Image
+	if image == "" {
+		image = DefaultImage
+	}
+	if err := validatePullPolicy(c.ImagePullPolicy); err != nil {
+		return "", err
 	}
-
-	// best-effort cleanup of previous curl-metrics pods
-	_ = c.CleanupByLabel(ctx, ns)
 
 	podName := fmt.Sprintf("%s-%d", c.PodNamePrefix, time.Now().UnixNano())
-	metricsURL := fmt.Sprintf(c.ServiceURLFormat, metricsSvcName, ns)
-
-	// keep -k for self-signed cert in test env, keep output clean (no -v)
-	curlCmd := fmt.Sprintf(`set -euo pipefail;
-curl -ksS --fail-with-body -H "Authorization: Bearer %s" "%s";`, token, metricsURL)
+	tokenSecret := ""
+	if token != "" && !c.Endpoint.ProjectedToken {
+		if err := c.applyTokenSecret(ctx, ns, podName, token); err != nil {
+			return podName, err
+		}
+		tokenSecret = podName
+	}
+	// keep output clean (no -v); TLS verification follows c.Endpoint
+	curlCmd := c.Endpoint.curlScript(tokenSecret != "" || c.Endpoint.ProjectedToken, c.URL(metricsSvcName, ns))
+	volumes, mounts := c.Endpoint.podVolumes(tokenSecret)
 
 	cmd := exec.Command(
 		"kubectl", "run", podName,
 		"--restart=Never",
 		"--namespace", ns,
-		"--image", c.Image,
+		"--image", image,
 		"--labels", c.LabelSelector,
+		"-o", "jsonpath={.metadata.uid}",
 		"--overrides",
 		fmt.Sprintf(`{
   "apiVersion":"v1",
@@ -78,33 +132,74 @@ curl -ksS --fail-with-body -H "Authorization: Bearer %s" "%s";`, token, metricsU
   },
   "spec":{
     "serviceAccountName":"%s",
-    "restartPolicy":"Never",
+    "restartPolicy":"Never",%s
+    "imagePullSecrets":%s,
     "containers":[{
       "name":"curl",
-      "image":"%s",
+      "image":"%s",%s
       "command":["/bin/sh","-c",%q],
-      "securityContext":{
-        "allowPrivilegeEscalation": false,
-        "capabilities": { "drop": ["ALL"] },
-        "runAsNonRoot": true,
-        "runAsUser": 1000,
-        "seccompProfile": { "type": "RuntimeDefault" }
-      }
-    }]
+      "env":%s,
+      "volumeMounts":%s,
+      "resources":%s,
+      "securityContext":%s
+    }],
+    "volumes":%s
   }
-}`, podName, ns, serviceAccountName, c.Image, curlCmd),
+}`, podName, ns, serviceAccountName, c.Scheduling.podFields(), pullSecretsJSON(c.ImagePullSecrets),
+			image, pullPolicyField(c.ImagePullPolicy), curlCmd, c.Endpoint.podEnv(), mounts,
+			c.Scheduling.resourcesJSON(), securityContextJSON(c.runAsUser(ctx, ns)), volumes),
 	)
 
-	_, err := c.Runner.Run(ctx, c.Logger, cmd)
-	return podName, err
+	uid, err := c.runner().Run(ctx, c.logger(), cmd)
+	if err != nil || tokenSecret == "" {
+		return podName, err
+	}
+	if err := c.ownTokenSecret(ctx, ns, tokenSecret, podName, strings.TrimSpace(uid)); err != nil {
+		// DeletePodNoWait/CleanupByLabel still delete the secret
+		slo.Warnf(c.logger(), "curl-metrics: token secret %s/%s not owned by its pod (skip): %v", ns, tokenSecret, err)
+	}
+	return podName, nil
+}
+
+// applyTokenSecret creates the Secret holding the scrape token, passing the manifest on stdin.
+func (c *Client) applyTokenSecret(ctx context.Context, ns, name, token string) error {
+	manifest, err := json.Marshal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": ns,
+			"labels":    map[string]string{"app": "curl-metrics"},
+		},
+		"type":       "Opaque",
+		"stringData": map[string]string{"token": token},
+	})
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("kubectl", "apply", "-n", ns, "-f", "-")
+	cmd.Stdin = bytes.NewReader(manifest)
+	if _, err := c.runner().Run(ctx, c.logger(), cmd); err != nil {
+		return fmt.Errorf("create token secret %s/%s: %w", ns, name, err)
+	}
+	return nil
+}
+
+// ownTokenSecret makes pod podName (uid) the owner of the token secret, so the garbage collector
+// deletes it with the pod even when the pod is never deleted through this client.
+func (c *Client) ownTokenSecret(ctx context.Context, ns, secret, podName, uid string) error {
+	if uid == "" {
+		return fmt.Errorf("pod %s: no uid", podName)
+	}
+	patch := fmt.Sprintf(`{"metadata":{"ownerReferences":[{"apiVersion":"v1","kind":"Pod","name":%q,"uid":%q}]}}`,
+		podName, uid)
+	cmd := exec.Command("kubectl", "patch", "secret", secret, "-n", ns, "--type", "merge", "-p", patch)
+	_, err := c.runner().Run(ctx, c.logger(), cmd)
+	return err
 }
 
 // WaitDone waits until the curl pod reaches a terminal phase (Succeeded/Failed).
 func (c *Client) WaitDone(ctx context.Context, ns, podName string, poll time.Duration) error {
-	c.Logger = slo.NewLogger(c.Logger)
-	if c.Runner == nil {
-		c.Runner = kubeutil.DefaultRunner{}
-	}
 	if poll <= 0 {
 		poll = 2 * time.Second
 	}
@@ -137,47 +232,32 @@ func (c *Client) WaitDone(ctx context.Context, ns, podName string, poll time.Dur
 
 // Logs returns kubectl logs of the given pod.
 func (c *Client) Logs(ctx context.Context, ns, podName string) (string, error) {
-	c.Logger = slo.NewLogger(c.Logger)
-	if c.Runner == nil {
-		c.Runner = kubeutil.DefaultRunner{}
-	}
-
 	cmd := exec.Command("kubectl", "logs", podName, "-n", ns)
-	return c.Runner.Run(ctx, c.Logger, cmd)
+	return c.runner().Run(ctx, c.logger(), cmd)
 }
 
-// DeletePodNoWait deletes pod best-effort without waiting.
+// DeletePodNoWait deletes pod (and its token Secret) best-effort without waiting.
 func (c *Client) DeletePodNoWait(ctx context.Context, ns, podName string) error {
-	c.Logger = slo.NewLogger(c.Logger)
-	if c.Runner == nil {
-		c.Runner = kubeutil.DefaultRunner{}
-	}
-
 	cmd := exec.Command(
-		"kubectl", "delete", "pod", podName,
+		"kubectl", "delete", "pod,secret", podName,
 		"-n", ns,
 		"--ignore-not-found=true",
 		"--wait=false",
 	)
-	_, err := c.Runner.Run(ctx, c.Logger, cmd)
+	_, err := c.runner().Run(ctx, c.logger(), cmd)
 	return err
 }
 
-// CleanupByLabel deletes all curl-metrics pods by label selector (best-effort, no wait).
+// CleanupByLabel deletes all curl-metrics pods and token Secrets by label selector (best-effort, no wait).
 func (c *Client) CleanupByLabel(ctx context.Context, ns string) error {
-	c.Logger = slo.NewLogger(c.Logger)
-	if c.Runner == nil {
-		c.Runner = kubeutil.DefaultRunner{}
-	}
-
 	cmd := exec.Command(
-		"kubectl", "delete", "pod",
+		"kubectl", "delete", "pods,secrets",
 		"-n", ns,
 		"-l", c.LabelSelector,
 		"--ignore-not-found=true",
 		"--wait=false",
 	)
-	_, err := c.Runner.Run(ctx, c.Logger, cmd)
+	_, err := c.runner().Run(ctx, c.logger(), cmd)
 	// best-effort이라 여기서 에러를 hard fail로 만들지 않으려면 호출부에서 무시해도 됨.
 	return err
 }
@@ -188,7 +268,7 @@ func (c *Client) isTerminal(ctx context.Context, ns, podName string) (bool, erro
 		"-n", ns,
 		"-o", "jsonpath={.status.phase}",
 	)
-	out, err := c.Runner.Run(ctx, c.Logger, cmd)
+	out, err := c.runner().Run(ctx, c.logger(), cmd)
 	if err != nil {
 		return false, err
 	}
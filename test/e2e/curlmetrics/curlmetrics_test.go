@@ -0,0 +1,54 @@
+package curlmetrics
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// countRunner answers every kubectl call with "Succeeded" (the pod phase WaitDone polls for).
+type countRunner struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (r *countRunner) Run(context.Context, slo.Logger, *exec.Cmd) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	return "Succeeded", nil
+}
+
+// A Client is shared by parallel sessions; run with -race.
+func TestClientConcurrentUse(t *testing.T) {
+	r := &countRunner{}
+	c := &Client{Runner: r, PodNamePrefix: "curl-metrics"}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.Background()
+			pod, err := c.RunOnce(ctx, "ns", "", "svc", "sa")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_ = c.WaitDone(ctx, "ns", pod, 0)
+			_, _ = c.Logs(ctx, "ns", pod)
+			_ = c.DeletePodNoWait(ctx, "ns", pod)
+		}()
+	}
+	wg.Wait()
+
+	if c.Logger != nil || c.Image != "" {
+		t.Fatalf("defaults written back to the shared client: logger %v, image %q", c.Logger, c.Image)
+	}
+	if r.calls == 0 {
+		t.Fatal("runner not used")
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"time"
 )
 
+// cleanupTimeout bounds the best-effort pod deletion after a (possibly cancelled) run.
+const cleanupTimeout = 30 * time.Second
+
 // CurlPodV4 encapsulates the v4 curl pod lifecycle without external adapters.
 type CurlPodV4 struct {
 	Client             *Client
@@ -13,12 +16,36 @@ type CurlPodV4 struct {
 	ServiceAccountName string
 	Token              string
 
-	Image            string
+	Image string
+	// ImagePullPolicy and ImagePullSecrets override the client's (optional).
+	ImagePullPolicy  string
+	ImagePullSecrets []string
+	// RunAsUser and RunAsUserFromNamespace override the client's when set (see Client.RunAsUser).
+	RunAsUser              int64
+	RunAsUserFromNamespace bool
+	// Scheduling overrides the client's (optional).
+	Scheduling *PodScheduling
+	// Endpoint of the metrics service (zero => the client's).
+	Endpoint Endpoint
+	// ServiceURLFormat overrides the URL built from the endpoint (optional).
 	ServiceURLFormat string
 }
 
+// RunResult is the outcome of one curl pod run.
+type RunResult struct {
+	PodName string
+	URL     string
+	Logs    string
+}
+
 // Run executes the v4 curl pod lifecycle and returns logs.
 func (c *CurlPodV4) Run(ctx context.Context, waitTimeout time.Duration, logsTimeout time.Duration) (string, error) {
+	res, err := c.RunDetailed(ctx, waitTimeout, logsTimeout)
+	return res.Logs, err
+}
+
+// RunDetailed is Run, additionally reporting the pod name and scraped URL (for provenance).
+func (c *CurlPodV4) RunDetailed(ctx context.Context, waitTimeout, logsTimeout time.Duration) (RunResult, error) {
 	client := c.Client
 	if client == nil {
 		client = New(nil, nil)
@@ -26,25 +53,52 @@ func (c *CurlPodV4) Run(ctx context.Context, waitTimeout time.Duration, logsTime
 	if c.Image != "" {
 		client.Image = c.Image
 	}
+	if c.ImagePullPolicy != "" {
+		client.ImagePullPolicy = c.ImagePullPolicy
+	}
+	if len(c.ImagePullSecrets) > 0 {
+		client.ImagePullSecrets = c.ImagePullSecrets
+	}
+	if c.RunAsUser != 0 {
+		client.RunAsUser = c.RunAsUser
+	}
+	if c.RunAsUserFromNamespace {
+		client.RunAsUserFromNamespace = true
+	}
+	if c.Scheduling != nil {
+		client.Scheduling = *c.Scheduling
+	}
+	if c.Endpoint != (Endpoint{}) {
+		client.Endpoint = c.Endpoint
+	}
 	if c.ServiceURLFormat != "" {
 		client.ServiceURLFormat = c.ServiceURLFormat
 	}
 
+	res := RunResult{URL: client.URL(c.MetricsServiceName, c.Namespace)}
 	podName, err := client.RunOnce(ctx, c.Namespace, c.Token, c.MetricsServiceName, c.ServiceAccountName)
 	if err != nil {
-		return "", err
+		return res, err
 	}
+	res.PodName = podName
+	// Cleanup must still run when ctx was cancelled (spec interrupt/timeout),
+	// otherwise the curl pod is orphaned.
+	defer c.cleanup(ctx, client, podName)
 
 	waitCtx, waitCancel := context.WithTimeout(ctx, waitTimeout)
 	defer waitCancel()
 	if err := client.WaitDone(waitCtx, c.Namespace, podName, 2*time.Second); err != nil {
-		_ = client.DeletePodNoWait(ctx, c.Namespace, podName)
-		return "", err
+		return res, err
 	}
 
 	logCtx, logCancel := context.WithTimeout(ctx, logsTimeout)
 	defer logCancel()
-	out, err := client.Logs(logCtx, c.Namespace, podName)
-	_ = client.DeletePodNoWait(ctx, c.Namespace, podName)
-	return out, err
+	res.Logs, err = client.Logs(logCtx, c.Namespace, podName)
+	return res, err
+}
+
+func (c *CurlPodV4) cleanup(ctx context.Context, client *Client, podName string) {
+	delCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+	_ = client.DeletePodNoWait(delCtx, c.Namespace, podName)
 }
@@ -0,0 +1,190 @@
+package curlmetrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Endpoint describes how the curl pod reaches the operator's metrics service.
+// The zero value means DefaultEndpoint.
+type Endpoint struct {
+	// Scheme is "https" (default) or "http".
+	Scheme string
+	// Port of the metrics service (0 => 8443 for https, 8080 for http).
+	Port int
+	// Path of the metrics handler (default "/metrics").
+	Path string
+	// InsecureSkipVerify skips verification of the serving certificate (curl -k). Ignored for http.
+	InsecureSkipVerify bool
+	// CABundle is a PEM bundle the serving certificate is verified against (curl --cacert).
+	CABundle string
+	// CASecret mounts the CA from a Secret in the scrape namespace instead, e.g. the cert-manager
+	// secret of the metrics server ("metrics-server-cert").
+	CASecret string
+	// CAConfigMap mounts the CA from a ConfigMap in the scrape namespace, e.g. "kube-root-ca.crt".
+	CAConfigMap string
+	// CAKey is the key holding the CA in CASecret/CAConfigMap (default "ca.crt").
+	CAKey string
+	// ClientCertSecret mounts a kubernetes.io/tls Secret (tls.crt/tls.key) in the scrape namespace
+	// and presents it as client certificate (mTLS, e.g. kube-rbac-proxy with a client CA).
+	ClientCertSecret string
+	// ProjectedToken authenticates with a token of the curl pod's own ServiceAccount, mounted from a
+	// projected volume (bound to the pod, so it dies with it), instead of the token given to RunOnce.
+	ProjectedToken bool
+}
+
+// TLS verification modes, in order of precedence (see Endpoint.verification).
+const (
+	verifyNone   = "insecure"
+	verifyBundle = "bundle"
+	verifyMount  = "mount"
+	verifySystem = "system"
+)
+
+// DefaultEndpoint is the kubebuilder layout: https on 8443, self-signed certificate not verified.
+func DefaultEndpoint() Endpoint {
+	return Endpoint{Scheme: "https", Port: 8443, Path: "/metrics", InsecureSkipVerify: true}
+}
+
+// WithDefaults fills the unset fields; the zero value becomes DefaultEndpoint.
+func (e Endpoint) WithDefaults() Endpoint {
+	if e == (Endpoint{}) {
+		return DefaultEndpoint()
+	}
+	e.Scheme = strings.ToLower(strings.TrimSpace(e.Scheme))
+	if e.Scheme == "" {
+		e.Scheme = "https"
+	}
+	if e.Port == 0 {
+		e.Port = 8443
+		if e.Scheme == "http" {
+			e.Port = 8080
+		}
+	}
+	if e.Path == "" {
+		e.Path = "/metrics"
+	}
+	if !strings.HasPrefix(e.Path, "/") {
+		e.Path = "/" + e.Path
+	}
+	if e.CAKey == "" {
+		e.CAKey = "ca.crt"
+	}
+	return e
+}
+
+// Verified reports whether the serving certificate is verified (https without InsecureSkipVerify).
+func (e Endpoint) Verified() bool {
+	v := e.verification()
+	return v != verifyNone && v != ""
+}
+
+// verification returns how the serving certificate is checked; "" for plain http.
+// InsecureSkipVerify is the explicit fallback and wins over any CA source.
+func (e Endpoint) verification() string {
+	e = e.WithDefaults()
+	switch {
+	case e.Scheme != "https":
+		return ""
+	case e.InsecureSkipVerify:
+		return verifyNone
+	case e.CABundle != "":
+		return verifyBundle
+	case e.CASecret != "" || e.CAConfigMap != "":
+		return verifyMount
+	default:
+		return verifySystem
+	}
+}
+
+// URL returns the in-cluster URL of the metrics service svc in namespace ns.
+func (e Endpoint) URL(svc, ns string) string {
+	e = e.WithDefaults()
+	return fmt.Sprintf("%s://%s.%s.svc:%d%s", e.Scheme, svc, ns, e.Port, e.Path)
+}
+
+// caBundlePath is where the curl pod writes CABundle before scraping; caMountDir is where
+// CASecret/CAConfigMap are mounted.
+const (
+	caBundlePath = "/tmp/metrics-ca.crt"
+	caMountDir   = "/etc/metrics-ca"
+
+	// clientCertMountDir is where ClientCertSecret is mounted.
+	clientCertMountDir = "/etc/metrics-client-cert"
+
+	// tokenMountDir is where the bearer token (key "token") is mounted, from the pod's token Secret
+	// or the projected ServiceAccount token; authHeaderPath is the curl header file built from it.
+	tokenMountDir  = "/var/run/secrets/metrics-token"
+	authHeaderPath = "/tmp/metrics-auth.header"
+
+	// projectedTokenSeconds is the projected token lifetime (the API server minimum).
+	projectedTokenSeconds = 600
+)
+
+// curlScript returns the pod's shell script scraping url, sending the bearer token mounted at
+// tokenMountDir when auth is set. curl reads it from a header file, so the token is in no argv.
+// The CA bundle, when set, is passed to the pod in the CA_BUNDLE env var (see podEnv).
+func (e Endpoint) curlScript(auth bool, url string) string {
+	e = e.WithDefaults()
+
+	var b strings.Builder
+	b.WriteString("set -euo pipefail;\n")
+	flags := "-sS --fail-with-body"
+	switch e.verification() {
+	case verifyNone:
+		flags += " -k"
+	case verifyBundle:
+		b.WriteString(`printf '%s' "$CA_BUNDLE" > ` + caBundlePath + ";\n")
+		flags += " --cacert " + caBundlePath
+	case verifyMount:
+		flags += " --cacert " + caMountDir + "/" + e.CAKey
+	}
+	if e.Scheme == "https" && e.ClientCertSecret != "" {
+		flags += " --cert " + clientCertMountDir + "/tls.crt --key " + clientCertMountDir + "/tls.key"
+	}
+	if auth {
+		b.WriteString(`printf 'Authorization: Bearer %s\n' "$(cat ` + tokenMountDir + `/token)" > ` +
+			authHeaderPath + ";\n")
+		flags += " -H @" + authHeaderPath
+	}
+	fmt.Fprintf(&b, "curl %s -w '%s' %q;", flags, trailerFormat, url)
+	return b.String()
+}
+
+// podEnv returns the container env JSON array for the curl pod.
+func (e Endpoint) podEnv() string {
+	if e.verification() != verifyBundle {
+		return "[]"
+	}
+	return fmt.Sprintf(`[{"name":"CA_BUNDLE","value":%q}]`, e.CABundle)
+}
+
+// podVolumes returns the pod volumes and container volumeMounts JSON arrays for the CA, client
+// certificate and token mounts; tokenSecret (optional) is the Secret holding RunOnce's token.
+func (e Endpoint) podVolumes(tokenSecret string) (volumes, mounts string) {
+	e = e.WithDefaults()
+	var vs, ms []string
+	add := func(name, source, dir string) {
+		vs = append(vs, fmt.Sprintf(`{"name":%q,%s}`, name, source))
+		ms = append(ms, fmt.Sprintf(`{"name":%q,"mountPath":%q,"readOnly":true}`, name, dir))
+	}
+	if e.verification() == verifyMount {
+		source := fmt.Sprintf(`"configMap":{"name":%q}`, e.CAConfigMap)
+		if e.CASecret != "" {
+			source = fmt.Sprintf(`"secret":{"secretName":%q}`, e.CASecret)
+		}
+		add("metrics-ca", source, caMountDir)
+	}
+	if e.Scheme == "https" && e.ClientCertSecret != "" {
+		add("metrics-client-cert", fmt.Sprintf(`"secret":{"secretName":%q}`, e.ClientCertSecret), clientCertMountDir)
+	}
+	switch {
+	case e.ProjectedToken:
+		add("metrics-token", fmt.Sprintf(
+			`"projected":{"sources":[{"serviceAccountToken":{"path":"token","expirationSeconds":%d}}]}`,
+			projectedTokenSeconds), tokenMountDir)
+	case tokenSecret != "":
+		add("metrics-token", fmt.Sprintf(`"secret":{"secretName":%q}`, tokenSecret), tokenMountDir)
+	}
+	return "[" + strings.Join(vs, ",") + "]", "[" + strings.Join(ms, ",") + "]"
+}
@@ -0,0 +1,121 @@
+package curlmetrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEndpointURL(t *testing.T) {
+	cases := []struct {
+		ep   Endpoint
+		want string
+	}{
+		{Endpoint{}, "https://svc.ns.svc:8443/metrics"},
+		{Endpoint{Scheme: "http"}, "http://svc.ns.svc:8080/metrics"},
+		{Endpoint{Scheme: "HTTPS", Port: 9443, Path: "custom/metrics"}, "https://svc.ns.svc:9443/custom/metrics"},
+	}
+	for _, c := range cases {
+		if got := c.ep.URL("svc", "ns"); got != c.want {
+			t.Errorf("%+v: got %q, want %q", c.ep, got, c.want)
+		}
+	}
+}
+
+func TestEndpointCurlScript(t *testing.T) {
+	insecure := Endpoint{}.curlScript(true, "https://x")
+	if !strings.Contains(insecure, " -k") || !strings.Contains(insecure, "-H @"+authHeaderPath) ||
+		!strings.Contains(insecure, tokenMountDir+"/token") {
+		t.Fatalf("default endpoint should skip verification and send the token from file:\n%s", insecure)
+	}
+
+	ca := Endpoint{Scheme: "https", CABundle: "PEM"}
+	if s := ca.curlScript(false, "https://x"); strings.Contains(s, " -k") ||
+		!strings.Contains(s, "--cacert "+caBundlePath) || strings.Contains(s, "Authorization") {
+		t.Fatalf("CA bundle should be verified against, without a token header:\n%s", s)
+	}
+	if env := ca.podEnv(); env != `[{"name":"CA_BUNDLE","value":"PEM"}]` {
+		t.Fatalf("unexpected pod env %s", env)
+	}
+
+	plain := Endpoint{Scheme: "http", InsecureSkipVerify: true}
+	if s := plain.curlScript(false, "http://x"); strings.Contains(s, " -k") {
+		t.Fatalf("http should not pass TLS flags:\n%s", s)
+	}
+}
+
+func TestEndpointCAMount(t *testing.T) {
+	ep := Endpoint{Scheme: "https", CASecret: "metrics-server-cert"}
+	if !ep.Verified() {
+		t.Fatal("a CA secret should turn verification on")
+	}
+	if s := ep.curlScript(false, "https://x"); !strings.Contains(s, "--cacert /etc/metrics-ca/ca.crt") {
+		t.Fatalf("unexpected script:\n%s", s)
+	}
+	volumes, mounts := ep.podVolumes("")
+	if volumes != `[{"name":"metrics-ca","secret":{"secretName":"metrics-server-cert"}}]` ||
+		!strings.Contains(mounts, `"mountPath":"/etc/metrics-ca"`) {
+		t.Fatalf("unexpected volumes %s / mounts %s", volumes, mounts)
+	}
+
+	ep.InsecureSkipVerify = true
+	if ep.Verified() {
+		t.Fatal("InsecureSkipVerify is the explicit fallback and wins over the CA")
+	}
+	if volumes, _ := ep.podVolumes(""); volumes != "[]" {
+		t.Fatalf("no CA volume expected when insecure, got %s", volumes)
+	}
+}
+
+func TestEndpointClientCert(t *testing.T) {
+	ep := Endpoint{Scheme: "https", CASecret: "metrics-server-cert", ClientCertSecret: "scraper-tls"}
+	s := ep.curlScript(false, "https://x")
+	if !strings.Contains(s, "--cert /etc/metrics-client-cert/tls.crt --key /etc/metrics-client-cert/tls.key") {
+		t.Fatalf("client certificate not passed:\n%s", s)
+	}
+	volumes, mounts := ep.podVolumes("")
+	want := `[{"name":"metrics-ca","secret":{"secretName":"metrics-server-cert"}},` +
+		`{"name":"metrics-client-cert","secret":{"secretName":"scraper-tls"}}]`
+	if volumes != want || !strings.Contains(mounts, `"mountPath":"/etc/metrics-client-cert"`) {
+		t.Fatalf("unexpected volumes %s / mounts %s", volumes, mounts)
+	}
+}
+
+func TestEndpointTokenVolume(t *testing.T) {
+	volumes, mounts := DefaultEndpoint().podVolumes("curl-metrics-1")
+	if volumes != `[{"name":"metrics-token","secret":{"secretName":"curl-metrics-1"}}]` ||
+		!strings.Contains(mounts, `"mountPath":"`+tokenMountDir+`"`) {
+		t.Fatalf("token secret not mounted: %s / %s", volumes, mounts)
+	}
+
+	ep := DefaultEndpoint()
+	ep.ProjectedToken = true
+	volumes, _ = ep.podVolumes("curl-metrics-1")
+	want := `[{"name":"metrics-token","projected":{"sources":[{"serviceAccountToken":` +
+		`{"path":"token","expirationSeconds":600}}]}}]`
+	if volumes != want {
+		t.Fatalf("projected token should win over the secret: %s", volumes)
+	}
+}
+
+func TestMirrorImage(t *testing.T) {
+	for ref, want := range map[string]string{
+		DefaultImage:                       "mirror.local:5000/curlimages/curl:8.11.1",
+		"curl:8":                           "mirror.local:5000/library/curl:8",
+		"ghcr.io/org/curl@sha256:abc":      "mirror.local:5000/org/curl@sha256:abc",
+		"localhost/curl:dev":               "mirror.local:5000/curl:dev",
+		"registry.k8s.io:443/curl/curl:v1": "mirror.local:5000/curl/curl:v1",
+	} {
+		if got := MirrorImage(ref, "mirror.local:5000/"); got != want {
+			t.Errorf("MirrorImage(%q) = %q, want %q", ref, got, want)
+		}
+	}
+	if got := MirrorImage(DefaultImage, ""); got != DefaultImage {
+		t.Errorf("no mirror should keep the image, got %q", got)
+	}
+	if err := validatePullPolicy("IfNotPresnt"); err == nil {
+		t.Error("invalid pull policy accepted")
+	}
+	if got := pullSecretsJSON([]string{"regcred", " "}); got != `[{"name":"regcred"}]` {
+		t.Errorf("pull secrets %s", got)
+	}
+}
@@ -0,0 +1,65 @@
+package curlmetrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultImage is the scraper image: a released curl (7.84+ for the scrape trailer) instead of
+// :latest, so runs are reproducible. Pin a digest (curlimages/curl@sha256:...) through Client.Image
+// for byte-identical pulls; MirrorImage points either at a registry mirror.
+const DefaultImage = "curlimages/curl:8.11.1"
+
+// Image pull policies (Client.ImagePullPolicy; empty => the cluster default).
+const (
+	PullAlways       = "Always"
+	PullIfNotPresent = "IfNotPresent"
+	PullNever        = "Never"
+)
+
+// MirrorImage rewrites the registry of image ref to mirror (e.g. "mirror.internal:5000" or
+// "harbor.local/dockerhub"), for clusters that cannot reach the public registry. Docker Hub
+// references without a namespace get "library/". An empty mirror returns ref unchanged.
+func MirrorImage(ref, mirror string) string {
+	mirror = strings.TrimSuffix(strings.TrimSpace(mirror), "/")
+	if mirror == "" || ref == "" {
+		return ref
+	}
+	repo := ref
+	if host, rest, ok := strings.Cut(ref, "/"); ok && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		repo = rest
+	} else if !ok {
+		repo = "library/" + ref
+	}
+	return mirror + "/" + repo
+}
+
+// validatePullPolicy rejects a pull policy the API server would refuse only after the pod is sent.
+func validatePullPolicy(p string) error {
+	switch p {
+	case "", PullAlways, PullIfNotPresent, PullNever:
+		return nil
+	}
+	return fmt.Errorf("invalid image pull policy %q (want %s, %s or %s)", p, PullAlways, PullIfNotPresent, PullNever)
+}
+
+// pullPolicyField returns the container's imagePullPolicy JSON member ("" => none).
+func pullPolicyField(p string) string {
+	if p == "" {
+		return ""
+	}
+	return fmt.Sprintf(`"imagePullPolicy":%q,`, p)
+}
+
+// pullSecretsJSON returns the pod's imagePullSecrets JSON array.
+func pullSecretsJSON(secrets []string) string {
+	refs := make([]map[string]string, 0, len(secrets))
+	for _, s := range secrets {
+		if s = strings.TrimSpace(s); s != "" {
+			refs = append(refs, map[string]string{"name": s})
+		}
+	}
+	b, _ := json.Marshal(refs)
+	return string(b)
+}
@@ -0,0 +1,122 @@
+package curlmetrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PodScheduling sizes and places the curl pod. Without requests it is BestEffort: the first pod
+// evicted on a node under pressure, which turns a measurement into a skip.
+type PodScheduling struct {
+	// Requests and Limits are the container resources, e.g. {"cpu": "10m", "memory": "32Mi"}.
+	Requests map[string]string
+	Limits   map[string]string
+	// PriorityClassName lets the pod preempt (or not be preempted by) lower priority workloads.
+	PriorityClassName string
+	// NodeSelector and Tolerations place the pod, e.g. on a dedicated or tainted node pool.
+	NodeSelector map[string]string
+	Tolerations  []Toleration
+}
+
+// Toleration is a pod toleration (corev1.Toleration).
+type Toleration struct {
+	Key               string `json:"key,omitempty"`
+	Operator          string `json:"operator,omitempty"`
+	Value             string `json:"value,omitempty"`
+	Effect            string `json:"effect,omitempty"`
+	TolerationSeconds *int64 `json:"tolerationSeconds,omitempty"`
+}
+
+// DefaultScheduling requests a little CPU and memory (Burstable QoS) and caps memory; a scrape
+// is one curl writing the body to the pod log.
+func DefaultScheduling() PodScheduling {
+	return PodScheduling{
+		Requests: map[string]string{"cpu": "10m", "memory": "32Mi"},
+		Limits:   map[string]string{"memory": "64Mi"},
+	}
+}
+
+// resourcesJSON returns the container resources JSON object.
+func (s PodScheduling) resourcesJSON() string {
+	res := map[string]map[string]string{}
+	if len(s.Requests) > 0 {
+		res["requests"] = s.Requests
+	}
+	if len(s.Limits) > 0 {
+		res["limits"] = s.Limits
+	}
+	b, _ := json.Marshal(res)
+	return string(b)
+}
+
+// podFields returns the pod spec JSON members for priority and placement, each followed by a
+// comma ("" => none).
+func (s PodScheduling) podFields() string {
+	var b strings.Builder
+	if s.PriorityClassName != "" {
+		fmt.Fprintf(&b, "\n    \"priorityClassName\":%q,", s.PriorityClassName)
+	}
+	if len(s.NodeSelector) > 0 {
+		sel, _ := json.Marshal(s.NodeSelector)
+		fmt.Fprintf(&b, "\n    \"nodeSelector\":%s,", sel)
+	}
+	if len(s.Tolerations) > 0 {
+		tol, _ := json.Marshal(s.Tolerations)
+		fmt.Fprintf(&b, "\n    \"tolerations\":%s,", tol)
+	}
+	return b.String()
+}
+
+// ParseKeyValues parses "k=v,k2=v2" entries (e.g. resources or a node selector); empty => nil.
+func ParseKeyValues(entries []string) (map[string]string, error) {
+	var m map[string]string
+	for _, e := range entries {
+		if e = strings.TrimSpace(e); e == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(e, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid key=value %q", e)
+		}
+		if m == nil {
+			m = map[string]string{}
+		}
+		m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return m, nil
+}
+
+// ParseTolerations parses tolerations in taint syntax: "key=value:Effect" (Equal), "key:Effect" or
+// "key" (Exists), ":Effect" or "*" (any key), with an optional "/seconds" after the effect.
+func ParseTolerations(entries []string) ([]Toleration, error) {
+	var out []Toleration
+	for _, e := range entries {
+		if e = strings.TrimSpace(e); e == "" {
+			continue
+		}
+		keyValue, effect, _ := strings.Cut(e, ":")
+		effect, secs, hasSecs := strings.Cut(effect, "/")
+		t := Toleration{Effect: effect, Operator: "Exists"}
+		if k, v, ok := strings.Cut(keyValue, "="); ok {
+			t.Key, t.Value, t.Operator = k, v, "Equal"
+		} else if keyValue != "*" {
+			t.Key = keyValue
+		}
+		switch t.Effect {
+		case "", "NoSchedule", "PreferNoSchedule", "NoExecute":
+		default:
+			return nil, fmt.Errorf("toleration %q: invalid effect %q", e, t.Effect)
+		}
+		if hasSecs {
+			n, err := strconv.ParseInt(secs, 10, 64)
+			if err != nil || t.Effect != "NoExecute" {
+				return nil, fmt.Errorf("toleration %q: seconds need a NoExecute effect and a number", e)
+			}
+			t.TolerationSeconds = &n
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package curlmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestParseTolerations(t *testing.T) {
+	tols, err := ParseTolerations([]string{
+		"pool=ci:NoSchedule", "dedicated", "*", "node.kubernetes.io/unreachable:NoExecute/30",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tols) != 4 || tols[0] != (Toleration{Key: "pool", Operator: "Equal", Value: "ci", Effect: "NoSchedule"}) ||
+		tols[1] != (Toleration{Key: "dedicated", Operator: "Exists"}) || tols[2] != (Toleration{Operator: "Exists"}) ||
+		tols[3].TolerationSeconds == nil || *tols[3].TolerationSeconds != 30 {
+		t.Fatalf("unexpected tolerations %+v", tols)
+	}
+	for _, bad := range []string{"pool=ci:NoSchedul", "pool:NoSchedule/30"} {
+		if _, err := ParseTolerations([]string{bad}); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func TestRunOnceScheduling(t *testing.T) {
+	r := &nsRunner{}
+	c := New(nil, r)
+	c.Scheduling.PriorityClassName = "ci-high"
+	c.Scheduling.NodeSelector = map[string]string{"pool": "ci"}
+	c.Scheduling.Tolerations = []Toleration{{Key: "pool", Operator: "Exists"}}
+	if _, err := c.RunOnce(context.Background(), "ns", "", "svc", "sa"); err != nil {
+		t.Fatal(err)
+	}
+	run := r.args[len(r.args)-1]
+	var pod struct {
+		Spec struct {
+			PriorityClassName string            `json:"priorityClassName"`
+			NodeSelector      map[string]string `json:"nodeSelector"`
+			Tolerations       []Toleration      `json:"tolerations"`
+			Containers        []struct {
+				Resources struct {
+					Requests map[string]string `json:"requests"`
+					Limits   map[string]string `json:"limits"`
+				} `json:"resources"`
+			} `json:"containers"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal([]byte(run[len(run)-1]), &pod); err != nil {
+		t.Fatalf("overrides are not valid JSON: %v", err)
+	}
+	if pod.Spec.PriorityClassName != "ci-high" || pod.Spec.NodeSelector["pool"] != "ci" ||
+		len(pod.Spec.Tolerations) != 1 || pod.Spec.Containers[0].Resources.Requests["memory"] != "32Mi" ||
+		pod.Spec.Containers[0].Resources.Limits["memory"] != "64Mi" {
+		t.Fatalf("scheduling not applied: %+v", pod.Spec)
+	}
+}
@@ -0,0 +1,49 @@
+package curlmetrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+)
+
+// trailerMarker starts the line curl writes after the body (-w trailerFormat): a comment, so
+// exposition parsers skip it, reporting what curl downloaded and what the server announced.
+const (
+	trailerMarker = "# slo-scrape-trailer "
+	trailerFormat = `\n` + trailerMarker + `size_download=%{size_download} content_length=%{header{content-length}}\n`
+)
+
+// SplitScrape separates the curl pod's log into the scraped body and curl's transfer trailer, and
+// checks the body is complete. A missing trailer (curl killed mid-transfer), fewer bytes
+// downloaded than the Content-Length announced, or fewer bytes logged than downloaded are
+// fetch.ErrTruncatedScrape.
+func SplitScrape(logs string) (string, error) {
+	i := strings.LastIndex(logs, "\n"+trailerMarker)
+	if i < 0 {
+		return logs, fmt.Errorf("%w: no curl trailer in the pod log", fetch.ErrTruncatedScrape)
+	}
+	body := logs[:i]
+	size, contentLength := int64(-1), int64(-1)
+	for _, kv := range strings.Fields(logs[i+len(trailerMarker)+1:]) {
+		k, v, _ := strings.Cut(kv, "=")
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue // e.g. no Content-Length header (chunked response)
+		}
+		switch k {
+		case "size_download":
+			size = n
+		case "content_length":
+			contentLength = n
+		}
+	}
+	if err := fetch.CheckLength(size, contentLength); err != nil {
+		return body, err
+	}
+	if err := fetch.CheckLength(int64(len(body)), size); err != nil {
+		return body, fmt.Errorf("pod log: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,38 @@
+package curlmetrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+)
+
+func TestSplitScrape(t *testing.T) {
+	body := "# TYPE up gauge\nup 1\n"
+	trailer := func(size, contentLength string) string {
+		return "\n" + trailerMarker + "size_download=" + size + " content_length=" + contentLength + "\n"
+	}
+
+	got, err := SplitScrape(body + trailer("21", "21"))
+	if err != nil || got != body {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	if _, err := SplitScrape(body + trailer("21", "")); err != nil {
+		t.Fatalf("no Content-Length should not be an error, got %v", err)
+	}
+
+	for name, logs := range map[string]string{
+		"killed mid-transfer": body[:10],
+		"short download":      body[:10] + trailer("10", "21"),
+		"short log":           body[:10] + trailer("21", "21"),
+	} {
+		if _, err := SplitScrape(logs); !errors.Is(err, fetch.ErrTruncatedScrape) {
+			t.Errorf("%s: expected a truncated scrape, got %v", name, err)
+		}
+	}
+
+	if s := (Endpoint{}).curlScript(false, "https://x"); !strings.Contains(s, "-w '\\n"+trailerMarker) {
+		t.Fatalf("curl does not write the trailer:\n%s", s)
+	}
+}
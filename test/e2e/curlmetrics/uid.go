@@ -0,0 +1,98 @@
+package curlmetrics
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// DefaultRunAsUser is the curl container's UID when nothing else decides it. The curl image runs as
+// a named user, which runAsNonRoot cannot verify, so some numeric UID must be set.
+const DefaultRunAsUser int64 = 1000
+
+// RunAsUserUnset (Client.RunAsUser) leaves runAsUser out of the pod, for platforms whose admission
+// assigns one (e.g. OpenShift's restricted SCC).
+const RunAsUserUnset int64 = -1
+
+// uidRangeAnnotation is the namespace annotation holding OpenShift's UID range ("<first>/<size>").
+const uidRangeAnnotation = "openshift.io/sa.scc.uid-range"
+
+// ParseRunAsUser parses a runAsUser setting: "" => DefaultRunAsUser, "auto" => from the namespace
+// (see Client.RunAsUserFromNamespace), "none" => RunAsUserUnset, otherwise a non-root UID.
+func ParseRunAsUser(s string) (uid int64, fromNamespace bool, err error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "":
+		return DefaultRunAsUser, false, nil
+	case "auto":
+		return DefaultRunAsUser, true, nil
+	case "none":
+		return RunAsUserUnset, false, nil
+	}
+	uid, err = strconv.ParseInt(s, 10, 64)
+	if err != nil || uid <= 0 {
+		return 0, false, fmt.Errorf("invalid runAsUser %q (want a non-root UID, auto or none)", s)
+	}
+	return uid, false, nil
+}
+
+// parseUIDRange returns the first UID of an OpenShift UID range ("1000680000/10000").
+func parseUIDRange(s string) (int64, bool) {
+	first, _, _ := strings.Cut(strings.TrimSpace(s), "/")
+	uid, err := strconv.ParseInt(first, 10, 64)
+	return uid, err == nil && uid > 0
+}
+
+// runAsUser resolves the curl container's UID in namespace ns (RunAsUserUnset => none). The
+// namespace lookup is done once per namespace; when it fails, RunAsUser applies.
+func (c *Client) runAsUser(ctx context.Context, ns string) int64 {
+	uid := c.RunAsUser
+	if uid == 0 {
+		uid = DefaultRunAsUser
+	}
+	if !c.RunAsUserFromNamespace {
+		return uid
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if nsUID, ok := c.namespaceUIDs[ns]; ok {
+		if nsUID != 0 {
+			return nsUID
+		}
+		return uid
+	}
+	cmd := exec.Command("kubectl", "get", "namespace", ns,
+		"-o", `jsonpath={.metadata.annotations.openshift\.io/sa\.scc\.uid-range}`)
+	out, err := c.runner().Run(ctx, c.logger(), cmd)
+	if err != nil {
+		slo.Warnf(c.logger(), "curl-metrics: %s of namespace %s not read, runAsUser %d: %v", uidRangeAnnotation, ns, uid, err)
+		return uid
+	}
+	nsUID, _ := parseUIDRange(out)
+	if c.namespaceUIDs == nil {
+		c.namespaceUIDs = map[string]int64{}
+	}
+	c.namespaceUIDs[ns] = nsUID
+	if nsUID != 0 {
+		return nsUID
+	}
+	return uid
+}
+
+// securityContextJSON returns the curl container's securityContext (restricted pod security).
+func securityContextJSON(uid int64) string {
+	runAs := ""
+	if uid != RunAsUserUnset {
+		runAs = fmt.Sprintf(`"runAsUser": %d,`, uid)
+	}
+	return fmt.Sprintf(`{
+        "allowPrivilegeEscalation": false,
+        "capabilities": { "drop": ["ALL"] },
+        "runAsNonRoot": true,%s
+        "seccompProfile": { "type": "RuntimeDefault" }
+      }`, runAs)
+}
@@ -0,0 +1,95 @@
+package curlmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// nsRunner answers the namespace annotation lookup and counts calls.
+type nsRunner struct {
+	out   string
+	calls int
+	args  [][]string
+}
+
+func (r *nsRunner) Run(_ context.Context, _ slo.Logger, cmd *exec.Cmd) (string, error) {
+	r.calls++
+	r.args = append(r.args, cmd.Args)
+	return r.out, nil
+}
+
+func TestRunAsUser(t *testing.T) {
+	for in, want := range map[string]int64{"": DefaultRunAsUser, "none": RunAsUserUnset, "2000": 2000} {
+		if uid, fromNS, err := ParseRunAsUser(in); err != nil || fromNS || uid != want {
+			t.Errorf("ParseRunAsUser(%q) = %d, %v, %v", in, uid, fromNS, err)
+		}
+	}
+	if _, fromNS, err := ParseRunAsUser("auto"); err != nil || !fromNS {
+		t.Errorf("auto: %v, %v", fromNS, err)
+	}
+	if _, _, err := ParseRunAsUser("0"); err == nil {
+		t.Error("root UID accepted")
+	}
+
+	r := &nsRunner{out: "1000680000/10000"}
+	c := New(nil, r)
+	c.RunAsUserFromNamespace = true
+	for range 2 {
+		if uid := c.runAsUser(context.Background(), "ns"); uid != 1000680000 {
+			t.Fatalf("uid from the OpenShift range = %d", uid)
+		}
+	}
+	if r.calls != 1 {
+		t.Fatalf("namespace looked up %d times", r.calls)
+	}
+	r.out = ""
+	if uid := c.runAsUser(context.Background(), "plain"); uid != DefaultRunAsUser {
+		t.Fatalf("namespace without range should use the default, got %d", uid)
+	}
+
+	if sc := securityContextJSON(RunAsUserUnset); strings.Contains(sc, "runAsUser") ||
+		!strings.Contains(sc, `"runAsNonRoot": true`) {
+		t.Fatalf("unset UID: %s", sc)
+	}
+	if sc := securityContextJSON(1000680000); !strings.Contains(sc, `"runAsUser": 1000680000,`) {
+		t.Fatalf("UID not set: %s", sc)
+	}
+}
+
+func TestRunOncePodSpec(t *testing.T) {
+	r := &nsRunner{out: "1000680000/10000"}
+	c := New(nil, r)
+	c.RunAsUserFromNamespace = true
+	if _, err := c.RunOnce(context.Background(), "ns", "", "svc", "sa"); err != nil {
+		t.Fatal(err)
+	}
+	// other processes' scrape pods in the namespace are not touched
+	for _, args := range r.args {
+		if args[1] == "delete" {
+			t.Fatalf("RunOnce deleted %v", args)
+		}
+	}
+	run := r.args[len(r.args)-1]
+	overrides := run[len(run)-1]
+	var pod struct {
+		Spec struct {
+			Containers []struct {
+				Image           string `json:"image"`
+				SecurityContext struct {
+					RunAsUser int64 `json:"runAsUser"`
+				} `json:"securityContext"`
+			} `json:"containers"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal([]byte(overrides), &pod); err != nil {
+		t.Fatalf("overrides are not valid JSON: %v\n%s", err, overrides)
+	}
+	if ctr := pod.Spec.Containers[0]; ctr.Image != DefaultImage || ctr.SecurityContext.RunAsUser != 1000680000 {
+		t.Fatalf("unexpected container %+v", ctr)
+	}
+}
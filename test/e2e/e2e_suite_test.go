@@ -15,15 +15,24 @@ import (
 	"github.com/yeongki/my-operator/pkg/kubeutil"
 	"github.com/yeongki/my-operator/pkg/slo"
 	"github.com/yeongki/my-operator/test/e2e/e2eutil"
+	"github.com/yeongki/my-operator/test/e2e/harness"
+	"github.com/yeongki/my-operator/test/e2e/internal/env"
 )
 
 var (
 	// Optional Environment Variables:
 	// - CERT_MANAGER_INSTALL_SKIP=true: Skips CertManager installation during test setup.
-	skipCertManagerInstall = os.Getenv("CERT_MANAGER_INSTALL_SKIP") == "true"
+	// - CERT_MANAGER_VERSION=<tag>: installs this cert-manager release instead of kubeutil.CertManagerVersion.
+	suiteOpts = env.LoadOptions()
 
-	// isCertManagerAlreadyInstalled will be set true when CertManager CRDs are found on the cluster.
-	isCertManagerAlreadyInstalled = false
+	// certManagerInstalled will be set true when the suite installed cert-manager (not found on the cluster).
+	certManagerInstalled = false
+
+	// - KIND_CLUSTER=<name>: uses this kind cluster, creating it when it does not exist. Without it, a
+	//   cluster named "kind" is provisioned only when kubectl has no current context.
+	// - KIND_NODE_IMAGE: node image of a provisioned cluster (default: kind's).
+	// - E2E_SKIP_CLEANUP=true: keeps a cluster provisioned by the suite.
+	kindClusterCreated = false
 
 	// projectImage is the name of the image which will be built and loaded with the code source changes to be tested.
 	projectImage = "example.com/my-operator:v0.0.1"
@@ -37,6 +46,9 @@ var (
 
 func TestE2E(t *testing.T) {
 	RegisterFailHandler(Fail)
+	// SLOLAB_LOG_LEVEL: also applies to the harness and e2eutil helpers logging to GinkgoLog
+	e2eutil.GinkgoLog = e2eutil.GinkgoLogger{Min: suiteOpts.LogLevel}
+	logger = e2eutil.GinkgoLog
 	logger.Logf("Starting my-operator integration test suite")
 	RunSpecs(t, "e2e suite")
 }
@@ -44,9 +56,21 @@ func TestE2E(t *testing.T) {
 var _ = BeforeSuite(func() {
 	// A reasonable default guard for setup steps.
 	// Individual kubectl commands also have their own timeouts (e.g. kubectl wait --timeout).
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
 	defer cancel()
 
+	opts, prov, err := env.Load()
+	logger.Logf("e2e config: %s", prov)
+	Expect(err).NotTo(HaveOccurred(), "invalid e2e configuration (see %s)", env.ConfigFileEnv)
+	suiteOpts = opts
+
+	if _, ok := os.LookupEnv("KIND_CLUSTER"); ok || !devutil.HasKubeContext(ctx, logger, runner) {
+		By("provisioning the kind cluster")
+		kindClusterCreated, err = devutil.EnsureKindCluster(
+			ctx, logger, runner, devutil.KindClusterName(), os.Getenv("KIND_NODE_IMAGE"))
+		Expect(err).NotTo(HaveOccurred(), "Failed to provision the kind cluster")
+	}
+
 	By("building the manager(Operator) image")
 	root, err := devutil.GetProjectDir()
 	Expect(err).NotTo(HaveOccurred())
@@ -61,38 +85,54 @@ var _ = BeforeSuite(func() {
 	Expect(devutil.LoadImageToKindClusterWithName(ctx, logger, runner, projectImage)).
 		To(Succeed(), "Failed to load the manager(Operator) image into Kind")
 
-	// Setup CertManager before the suite if not skipped and if not already installed.
-	if skipCertManagerInstall {
+	// Setup CertManager before the suite if not skipped; an existing install is reused.
+	if suiteOpts.SkipCertManagerInstall {
 		logger.Logf("CERT_MANAGER_INSTALL_SKIP=true: skipping cert-manager setup")
 		return
 	}
 
-	By("checking if cert-manager is installed already")
-	isCertManagerAlreadyInstalled = kubeutil.IsCertManagerCRDsInstalled(ctx, logger, runner)
-	if isCertManagerAlreadyInstalled {
-		logger.Logf("WARNING: cert-manager is already installed; skipping installation")
-		return
-	}
-
-	By("installing cert-manager")
-	Expect(kubeutil.InstallCertManager(ctx, logger, runner)).
-		To(Succeed(), "Failed to install cert-manager")
+	By("ensuring cert-manager is installed and its webhook is ready")
+	certManagerInstalled, err = kubeutil.EnsureCertManager(ctx, logger, runner, suiteOpts.CertManagerVersion)
+	Expect(err).NotTo(HaveOccurred(), "Failed to set up cert-manager")
 })
 
 var _ = AfterSuite(func() {
-	if skipCertManagerInstall || isCertManagerAlreadyInstalled {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// measurement reliability of all SLO sessions of this process (nothing when none ran)
+	if _, err := harness.WriteMeasurementReport(
+		suiteOpts.ArtifactsDir, suiteOpts.RunID, suiteOpts.MinMeasurementSuccess); err != nil {
+		warnf("failed to write the measurement report: %v", err)
+	}
+
+	if kindClusterCreated {
+		if suiteOpts.SkipCleanup {
+			logger.Logf("E2E_SKIP_CLEANUP=true: keeping kind cluster %q", devutil.KindClusterName())
+			return
+		}
+		// deleting the cluster removes cert-manager as well
+		By("deleting the kind cluster provisioned by the suite")
+		if err := devutil.DeleteKindCluster(ctx, logger, runner, devutil.KindClusterName()); err != nil {
+			warnf("failed to delete kind cluster: %v", err)
+		}
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
+	if !certManagerInstalled {
+		return
+	}
+	if suiteOpts.SkipCleanup {
+		logger.Logf("E2E_SKIP_CLEANUP=true: keeping cert-manager")
+		return
+	}
 
 	By("uninstalling cert-manager (best-effort)")
-	if err := kubeutil.UninstallCertManager(ctx, logger, runner); err != nil {
+	if err := kubeutil.UninstallCertManager(ctx, logger, runner, suiteOpts.CertManagerVersion); err != nil {
 		warnf("failed to uninstall cert-manager: %v", err)
 	}
 })
 
 func warnf(format string, args ...any) {
-	logger.Logf("WARNING: "+format, args...)
+	slo.Warnf(logger, format, args...)
 }
@@ -2,25 +2,46 @@ package e2e
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
 	"github.com/yeongki/my-operator/test/e2e/manifests"
 
 	"github.com/yeongki/my-operator/pkg/devutil"
+	"github.com/yeongki/my-operator/pkg/diag"
 	"github.com/yeongki/my-operator/pkg/kubeutil"
+	"github.com/yeongki/my-operator/pkg/metricdrift"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/presets"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+	"github.com/yeongki/my-operator/pkg/slolab"
+	"github.com/yeongki/my-operator/test/e2e/bootstrap"
 	"github.com/yeongki/my-operator/test/e2e/curlmetrics"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
 	"github.com/yeongki/my-operator/test/e2e/harness"
 	e2eenv "github.com/yeongki/my-operator/test/e2e/internal/env"
+	"github.com/yeongki/my-operator/test/e2e/load"
 )
 
 // TODO 이거 따로 빼야 함.
 const namespace = "my-operator-system"
 const serviceAccountName = "my-operator-controller-manager"
+const deploymentName = "my-operator-controller-manager"
 const metricsServiceName = "my-operator-controller-manager-metrics-service"
 
 var _ = Describe("Manager", Ordered, func() {
@@ -30,6 +51,17 @@ var _ = Describe("Manager", Ordered, func() {
 		rootDir string
 
 		cm *curlmetrics.Client
+		// boot installs and removes the operator (in-process kustomize build + server-side apply).
+		boot *bootstrap.Bootstrapper
+		// metricsRBAC lets the controller-manager SA read /metrics; removed in AfterAll.
+		metricsRBAC *harness.MetricsReaderRBAC
+		// profile is the SLOLAB_PROFILE profile (empty when unset).
+		profile = &slolab.Profile{}
+
+		// specFailed is set by AfterEach so AfterAll knows whether to capture diagnostics.
+		specFailed bool
+		// controllerLogs follows the controller-manager logs of the current spec.
+		controllerLogs *e2eutil.LogStream
 	)
 
 	BeforeAll(func() {
@@ -39,19 +71,41 @@ var _ = Describe("Manager", Ordered, func() {
 		var err error
 		rootDir, err = devutil.GetProjectDir()
 		Expect(err).NotTo(HaveOccurred())
+		if cfg.Profile != "" {
+			profile, err = slolab.Load(cfg.Profile)
+			Expect(err).NotTo(HaveOccurred(), e2eenv.ProfileEnv)
+		}
 
 		cm = curlmetrics.New(logger, runner)
+		if cfg.CurlImage != "" {
+			cm.Image = cfg.CurlImage
+		}
+		cm.Image = curlmetrics.MirrorImage(cm.Image, cfg.RegistryMirror)
+		cm.ImagePullPolicy = cfg.CurlImagePullPolicy
+		cm.ImagePullSecrets = cfg.CurlImagePullSecrets
+		cm.RunAsUser, cm.RunAsUserFromNamespace, err = curlmetrics.ParseRunAsUser(cfg.CurlRunAsUser)
+		Expect(err).NotTo(HaveOccurred(), "SLOLAB_CURL_RUN_AS_USER")
+		cm.Scheduling, err = curlScheduling(cfg)
+		Expect(err).NotTo(HaveOccurred(), "curl pod scheduling options")
+		cm.Endpoint, err = metricsEndpoint(cfg)
+		Expect(err).NotTo(HaveOccurred(), "metrics endpoint options")
+		By(fmt.Sprintf("metrics endpoint %s (TLS verified=%v)",
+			cm.URL(metricsServiceName, namespace), cm.Endpoint.Verified()))
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 		defer cancel()
 
-		// TODO e2eutil 로 빼자.
-		run := func(cmd *exec.Cmd, msg string) string {
-			cmd.Dir = rootDir
-			out, err := runner.Run(ctx, logger, cmd)
-			Expect(err).NotTo(HaveOccurred(), msg)
-			return out
+		deployDir := bootstrap.DefaultDeployDir
+		if cfg.Namespaced {
+			deployDir = "config/namespaced"
 		}
+		boot, err = bootstrap.New(bootstrap.Options{
+			ProjectDir: rootDir,
+			DeployDir:  deployDir,
+			Image:      projectImage,
+			Logger:     logger,
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to connect to the cluster")
 
 		By("Creating manager namespace with baseline security enforcement")
 		//		nsManifest := fmt.Sprintf(`apiVersion: v1
@@ -67,10 +121,23 @@ var _ = Describe("Manager", Ordered, func() {
 		)
 		Expect(err).NotTo(HaveOccurred())
 
-		cmd := exec.Command("kubectl", "apply", "-f", "-")
-		cmd.Dir = rootDir
-		cmd.Stdin = strings.NewReader(nsManifest)
-		run(cmd, "Failed to apply namespace with security policy")
+		Expect(boot.ApplyManifest(ctx, "namespace", nsManifest)).
+			To(Succeed(), "Failed to apply namespace with security policy")
+
+		By("reaping orphaned curl-metrics pods from interrupted runs (best-effort)")
+		if res, err := kubeutil.ReapPods(ctx, logger, runner, kubeutil.ReapOptions{
+			Namespaces:    []string{namespace},
+			LabelSelector: curlmetrics.PodLabelSelector,
+			MinAge:        cfg.ReapMinAge,
+		}); err != nil {
+			warnf("failed to reap curl-metrics pods: %v", err)
+		} else {
+			logger.Logf("reaped %d orphaned curl-metrics pod(s)", len(res.Reaped))
+		}
+		harness.PruneArtifacts(cfg.ArtifactsDir, artifacts.PruneOptions{
+			MaxAge:   cfg.ArtifactsMaxAge,
+			MaxFiles: cfg.ArtifactsMaxFiles,
+		}, logger)
 
 		//By("labeling the namespace to enforce the security policy")
 		//cmd = exec.Command("kubectl", "label", "--overwrite", "ns", namespace, "pod-security.kubernetes.io/enforce=baseline")
@@ -78,23 +145,53 @@ var _ = Describe("Manager", Ordered, func() {
 		//run(cmd, "Failed to label namespace with security policy")
 
 		By("installing CRDs")
-		run(exec.Command("make", "install"), "Failed to install CRDs")
+		Expect(boot.Install(ctx)).To(Succeed(), "Failed to install CRDs")
+
+		By("deploying the controller-manager from " + deployDir)
+		Expect(boot.Deploy(ctx)).To(Succeed(), "Failed to deploy the controller-manager")
 
-		By("deploying the controller-manager")
-		run(exec.Command("make", "deploy", fmt.Sprintf("IMG=%s", projectImage)), "Failed to deploy the controller-manager")
+		if cfg.ManagerProfile != "" {
+			By("configuring the controller-manager with the " + cfg.ManagerProfile + " profile")
+			Expect(applyManagerProfile(ctx, rootDir, cfg.ManagerProfile)).To(Succeed())
+		}
 
-		// TODO 추후 ApplyClusterRoleBinding 이걸 감싸서 구현할 수도 있는데 고민 중.
 		By("ensuring metrics reader RBAC for controller-manager SA (idempotent)")
-		Expect(kubeutil.ApplyClusterRoleBinding(
-			ctx, logger, runner,
-			"my-operator-e2e-metrics-reader",
-			"my-operator-metrics-reader",
-			namespace,
-			serviceAccountName,
-		)).To(Succeed())
+		metricsRBAC = &harness.MetricsReaderRBAC{
+			Client:             boot.Client(),
+			Name:               "my-operator-e2e-metrics-reader",
+			ClusterRole:        "my-operator-metrics-reader",
+			Namespace:          namespace,
+			ServiceAccountName: serviceAccountName,
+			FieldManager:       bootstrap.DefaultFieldManager + "-rbac",
+			SkipCleanup:        cfg.SkipCleanup,
+			Logger:             logger,
+		}
+		Expect(metricsRBAC.Ensure(ctx)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if CurrentSpecReport().Failed() {
+			specFailed = true
+		}
 	})
 
 	AfterAll(func() {
+		if cfg.Diag == e2eenv.DiagAlways || (cfg.Diag == e2eenv.DiagOnFailure && specFailed) {
+			By("capturing namespace diagnostics (best-effort)")
+			diagCtx, diagCancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			path, err := diag.Collect(diagCtx, logger, runner, diag.Options{
+				Namespaces: []string{namespace},
+				OutDir:     cfg.ArtifactsDir,
+				Name:       fmt.Sprintf("diag-%s-%d", namespace, time.Now().Unix()),
+			})
+			diagCancel()
+			if err != nil {
+				warnf("failed to capture diagnostics: %v", err)
+			} else {
+				logger.Logf("diagnostics written to %s", path)
+			}
+		}
+
 		if cfg.SkipCleanup {
 			By("E2E_SKIP_CLEANUP enabled: skipping cleanup")
 			return
@@ -105,25 +202,31 @@ var _ = Describe("Manager", Ordered, func() {
 
 		By("best-effort: cleaning up curl-metrics pods")
 		_ = cm.CleanupByLabel(ctx, namespace)
-		// TODO 기본 Makefile 에 대한 의존성이 생기지만 무시해도 될듯 한데, ????
-		By("un-deploying the controller-manager (best-effort)")
-		cmd := exec.Command("make", "undeploy")
-		cmd.Dir = rootDir
-		_, _ = runner.Run(ctx, logger, cmd)
-		// TODO 기본 Makefile 에 대한 의존성이 생기지만 무시해도 될듯 한데, ????
-		By("uninstalling CRDs (best-effort)")
-		cmd = exec.Command("make", "uninstall")
-		cmd.Dir = rootDir
-		_, _ = runner.Run(ctx, logger, cmd)
+		if metricsRBAC != nil {
+			By("removing the metrics reader binding (best-effort)")
+			if err := metricsRBAC.Cleanup(ctx); err != nil {
+				warnf("%v", err)
+			}
+		}
+		if boot != nil {
+			By("un-deploying the controller-manager (best-effort)")
+			if err := boot.Undeploy(ctx); err != nil {
+				warnf("%v", err)
+			}
+			By("uninstalling CRDs (best-effort)")
+			if err := boot.Uninstall(ctx); err != nil {
+				warnf("%v", err)
+			}
+		}
 		// TODO curlmetrics.go 사용하자.
 		By("removing manager namespace (best-effort)")
-		cmd = exec.Command("kubectl", "delete", "ns", namespace, "--ignore-not-found=true")
+		cmd := exec.Command("kubectl", "delete", "ns", namespace, "--ignore-not-found=true")
 		cmd.Dir = rootDir
 		_, _ = runner.Run(ctx, logger, cmd)
 	})
 	// TODO opts *WaitOptions 로 할지 고민 중
-	BeforeEach(func() {
-		waitCtx, waitCancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	BeforeEach(func(ctx SpecContext) {
+		waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Minute)
 		defer waitCancel()
 
 		opts := kubeutil.WaitOptions{}
@@ -138,7 +241,7 @@ var _ = Describe("Manager", Ordered, func() {
 			kubeutil.WaitServiceHasEndpoints(waitCtx, logger, runner, namespace, metricsServiceName, opts),
 		).To(Succeed())
 
-		tokCtx, tokCancel := context.WithTimeout(context.Background(), cfg.TokenRequestTimeout)
+		tokCtx, tokCancel := context.WithTimeout(ctx, cfg.TokenRequestTimeout)
 		defer tokCancel()
 
 		By("requesting service account token")
@@ -146,16 +249,51 @@ var _ = Describe("Manager", Ordered, func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(t).NotTo(BeEmpty())
 		token = t
+
+		By("streaming controller-manager logs for this spec")
+		controllerLogs = e2eutil.StreamLogsForSpec(namespace, "control-plane=controller-manager", cfg.ArtifactsDir)
 	})
 
+	// curlFns drive the curl pod for the measured specs and the metrics sanity spec.
+	curlFns := harness.CurlPodFns{
+		// per-step timeouts on top of the spec context (harness가 cancellation 을 전달)
+		RunCurlMetricsOnce: func(ctx context.Context, ns, token, metricsSvcName, sa string) (string, error) {
+			ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+			defer cancel()
+			return cm.RunOnce(ctx, ns, token, metricsSvcName, sa)
+		},
+		WaitCurlMetricsDone: func(ctx context.Context, ns, podName string) error {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+			defer cancel()
+			return cm.WaitDone(ctx, ns, podName, 2*time.Second)
+		},
+		CurlMetricsLogs: func(ctx context.Context, ns, podName string) (string, error) {
+			ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+			defer cancel()
+			return cm.Logs(ctx, ns, podName)
+		},
+		DeletePodNoWait: func(ctx context.Context, ns, podName string) error {
+			return cm.DeletePodNoWait(ctx, ns, podName)
+		},
+	}
+
 	harness.Attach(
 		func() harness.HarnessDeps {
 			return harness.HarnessDeps{
-				ArtifactsDir: cfg.ArtifactsDir,
-				Suite:        "e2e",
-				TestCase:     "",
-				RunID:        cfg.RunID,
-				Enabled:      cfg.Enabled,
+				ArtifactsDir:   cfg.ArtifactsDir,
+				Suite:          "e2e",
+				TestCase:       "",
+				RunID:          cfg.RunID,
+				Enabled:        cfg.Enabled,
+				FailOnPolicy:   cfg.FailOnPolicy,
+				UploadURL:      cfg.UploadURL,
+				BundleDir:      cfg.BundleDir,
+				OTLPEndpoint:   cfg.OTLPEndpoint,
+				CaptureScrapes: cfg.CaptureScrapes,
+				ResultLine:     cfg.ResultLine,
+				Compress:       cfg.CompressArtifacts,
+				Hooks:          clusterHooks(cfg),
+				UploadPolicy:   uploadPolicy(cfg),
 			}
 		},
 		func() harness.FetchDeps {
@@ -164,61 +302,547 @@ var _ = Describe("Manager", Ordered, func() {
 				Token:              token,
 				MetricsServiceName: metricsServiceName,
 				ServiceAccountName: serviceAccountName,
+				PrometheusURL:      cfg.PrometheusURL,
+				PrometheusSelector: prometheusSelector(cfg.PrometheusSelector),
+				MetricsEndpoint:    cm.Endpoint,
+				MetricsRBAC:        metricsRBAC,
 			}
 		},
-		harness.DefaultV3Specs,
-		harness.CurlPodFns{
-			// harness가 기존 함수 타입을 기대한다면, 여기서 얇게 어댑트만 유지
-			RunCurlMetricsOnce: func(ns, token, metricsSvcName, sa string) (string, error) {
-				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-				defer cancel()
-				return cm.RunOnce(ctx, ns, token, metricsSvcName, sa)
-			},
-			WaitCurlMetricsDone: func(ns, podName string) {
-				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-				defer cancel()
-				Expect(cm.WaitDone(ctx, ns, podName, 2*time.Second)).To(Succeed())
-			},
-			CurlMetricsLogs: func(ns, podName string) (string, error) {
-				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-				defer cancel()
-				return cm.Logs(ctx, ns, podName)
-			},
-			DeletePodNoWait: func(ns, podName string) error {
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-				defer cancel()
-				return cm.DeletePodNoWait(ctx, ns, podName)
-			},
+		func() []spec.SLISpec {
+			specs := harness.DefaultV3Specs()
+			if cfg.ProcessMetrics {
+				specs = append(specs, presets.Process()...)
+			}
+			specs, err := profile.Specs(specs)
+			Expect(err).NotTo(HaveOccurred(), e2eenv.ProfileEnv)
+			return specs
 		},
+		curlFns,
 	)
 
-	It("should ensure the metrics endpoint is serving metrics", func() {
+	It("should ensure the metrics endpoint is serving metrics", func(specCtx SpecContext) {
 		By("scraping /metrics via curl pod")
+		text, err := harness.ScrapeOnce(specCtx, harness.FetchDeps{
+			Namespace:          namespace,
+			Token:              token,
+			MetricsServiceName: metricsServiceName,
+			ServiceAccountName: serviceAccountName,
+		}, curlFns)
+		Expect(err).NotTo(HaveOccurred(), "the scrape must be complete")
+
+		families, err := metricdrift.Parse(text)
+		if err != nil || len(metricdrift.Missing(families, cfg.RequiredMetrics)) > 0 {
+			logger.Logf("metrics text head:\n%s", harness.ScrapeHead(text, 800))
+		}
+		Expect(err).NotTo(HaveOccurred(), "metrics endpoint must serve valid exposition format")
+
+		if cfg.ArtifactsDir != "" {
+			By("recording the exposed metric families for drift tracking")
+			if _, err := harness.WriteMetricFamilies(cfg.ArtifactsDir, cfg.RunID, families); err != nil {
+				warnf("failed to record metric families: %v", err)
+			}
+		}
+
+		Expect(metricdrift.Missing(families, cfg.RequiredMetrics)).To(BeEmpty(),
+			"required metric families (SLOLAB_REQUIRED_METRICS)")
+
+		if cfg.MetricsBaseline != "" {
+			By("comparing the exposed metric families with the baseline " + cfg.MetricsBaseline)
+			baseline, err := metricdrift.Load(cfg.MetricsBaseline)
+			Expect(err).NotTo(HaveOccurred())
+			drift := metricdrift.Diff(baseline, families)
+			var report strings.Builder
+			_ = drift.WriteText(&report)
+			if !drift.Empty() {
+				logger.Logf("metric drift against %s:\n%s", cfg.MetricsBaseline, report.String())
+			}
+			Expect(drift.Breaking()).To(BeFalse(), "metrics were removed, renamed or changed:\n%s", report.String())
+		}
+		By(fmt.Sprintf("done: %d families", len(families)))
+	})
 
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	It("should reconcile with the permissions granted in config/rbac", func(specCtx SpecContext) {
+		ctx, cancel := context.WithTimeout(specCtx, 10*time.Minute)
 		defer cancel()
 
-		podName, err := cm.RunOnce(ctx, namespace, token, metricsServiceName, serviceAccountName)
+		kubectl := func(args ...string) (string, error) {
+			cmd := exec.Command("kubectl", args...)
+			cmd.Dir = rootDir
+			out, err := runner.Run(ctx, logger, cmd)
+			return strings.TrimSpace(out), err
+		}
+		const sample = "joboperator-sample"
+
+		sc := sessionConfig(cfg, cm, token, "rbac")
+		sc.Specs = presets.RESTClient()
+		sess := harness.NewSessionV4(sc)
+		sess.Start(ctx)
+
+		By("exercising the reconcile surface: create, scale and delete a JobOperator")
+		_, err := kubectl("apply", "-n", namespace, "-f", "config/samples/batch_v1_joboperator.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		stsKey := client.ObjectKey{Namespace: namespace, Name: sample + "-sts"}
+		waitReplicas := func(n int32) func() error {
+			return func() error {
+				_, err := e2eutil.WaitFor(ctx, boot.Client(), stsKey, e2eutil.StatefulSetReplicas(n),
+					e2eutil.WaitOptions{Timeout: 3 * time.Minute})
+				return err
+			}
+		}
+		Expect(harness.TimeWait(sess, "statefulset created", 3*time.Minute, waitReplicas(1))).To(Succeed())
+
+		_, err = kubectl("patch", "joboperators", sample, "-n", namespace, "--type=merge", "-p", `{"spec":{"replicas":2}}`)
 		Expect(err).NotTo(HaveOccurred())
+		Expect(harness.TimeWait(sess, "statefulset scaled", 3*time.Minute, waitReplicas(2))).To(Succeed())
 
-		defer func() { _ = cm.DeletePodNoWait(context.Background(), namespace, podName) }()
+		_, err = kubectl("delete", "joboperators", sample, "-n", namespace, "--timeout=3m")
+		Expect(err).NotTo(HaveOccurred())
 
-		waitCtx, waitCancel := context.WithTimeout(context.Background(), 5*time.Minute)
-		defer waitCancel()
-		Expect(cm.WaitDone(waitCtx, namespace, podName, 2*time.Second)).To(Succeed())
+		sum, err := sess.End(ctx)
+		if err != nil {
+			warnf("rbac session: %v", err)
+		}
+		if sum != nil {
+			for _, r := range sum.Results {
+				if r.ID == "rest_client_403_delta" && r.Value != nil {
+					Expect(*r.Value).To(BeZero(), "the operator was denied API requests (403)")
+				}
+			}
+		}
+
+		if cfg.AuditLog == "" {
+			By("SLOLAB_AUDIT_LOG not set: skipping the permission diff against config/rbac")
+			return
+		}
+		By("diffing the permissions used in the audit log against config/rbac")
+		f, err := os.Open(cfg.AuditLog)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = f.Close() }()
+		used, denied, err := kubeutil.PermissionsFromAudit(f,
+			fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccountName))
+		Expect(err).NotTo(HaveOccurred())
+		rules, err := kubeutil.LoadRBACRules(
+			filepath.Join(rootDir, "config/rbac/role.yaml"),
+			filepath.Join(rootDir, "config/rbac/leader_election_role.yaml"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		diff := kubeutil.DiffRBAC(rules, used, denied)
+		if b, err := json.MarshalIndent(diff, "", "  "); err == nil && cfg.ArtifactsDir != "" {
+			_ = os.WriteFile(filepath.Join(cfg.ArtifactsDir, "rbac-diff.json"), b, 0o644)
+		}
+		// excessive permissions are advisory: a window rarely exercises every code path
+		for _, p := range diff.Excessive {
+			logger.Logf("RBAC: granted but not used: %s", p)
+		}
+		Expect(diff.Missing).To(BeEmpty(), "permissions used but not granted by config/rbac")
+	})
+
+	It("should reconcile with namespace-scoped permissions only", func(specCtx SpecContext) {
+		if !cfg.Namespaced {
+			Skip("SLOLAB_NAMESPACED not set")
+		}
+		ctx, cancel := context.WithTimeout(specCtx, 5*time.Minute)
+		defer cancel()
+
+		kubectl := func(args ...string) (string, error) {
+			cmd := exec.Command("kubectl", args...)
+			cmd.Dir = rootDir
+			out, err := runner.Run(ctx, logger, cmd)
+			return strings.TrimSpace(out), err
+		}
+		sa := fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccountName)
+
+		By("checking the manager permissions are a Role")
+		_, err := kubectl("get", "role", "my-operator-manager-role", "-n", namespace)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = kubectl("get", "clusterrole", "my-operator-manager-role")
+		Expect(err).To(HaveOccurred(), "config/namespaced must not install the manager ClusterRole")
+		// `kubectl auth can-i` exits non-zero when the answer is no
+		out, _ := kubectl("auth", "can-i", "list", "joboperators", "--all-namespaces", "--as", sa)
+		Expect(out).To(Equal("no"))
+		out, err = kubectl("auth", "can-i", "list", "joboperators", "-n", namespace, "--as", sa)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("yes"))
+
+		By("reconciling a JobOperator in the watched namespace")
+		const sample = "joboperator-sample"
+		_, err = kubectl("apply", "-n", namespace, "-f", "config/samples/batch_v1_joboperator.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func(ctx SpecContext) {
+			_, _ = runner.Run(ctx, logger, exec.Command("kubectl", "delete", "joboperators", sample,
+				"-n", namespace, "--ignore-not-found", "--wait=false"))
+		})
+		_, err = e2eutil.WaitFor(ctx, boot.Client(), client.ObjectKey{Namespace: namespace, Name: sample + "-sts"},
+			func(*appsv1.StatefulSet) bool { return true }, e2eutil.WaitOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should converge on creation and deletion as timed by the controller", func(specCtx SpecContext) {
+		ctx, cancel := context.WithTimeout(specCtx, 10*time.Minute)
+		defer cancel()
+
+		kubectl := func(args ...string) (string, error) {
+			out, err := runner.Run(ctx, logger, exec.Command("kubectl", args...))
+			return strings.TrimSpace(out), err
+		}
+		const sample = "joboperator-sample"
+
+		sc := sessionConfig(cfg, cm, token, "convergence")
+		sc.Specs = presets.Convergence()
+		sess := harness.NewSessionV4(sc)
+		sess.Start(ctx)
+
+		By("creating the sample JobOperator with the test/start-time annotation")
+		raw, err := os.ReadFile(filepath.Join(rootDir, "config/samples/batch_v1_joboperator.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		var obj map[string]any
+		Expect(yaml.Unmarshal(raw, &obj)).To(Succeed())
+		meta := obj["metadata"].(map[string]any)
+		ann := map[string]string{}
+		if m, ok := meta["annotations"].(map[string]any); ok {
+			for k, v := range m {
+				ann[k] = fmt.Sprint(v)
+			}
+		}
+		meta["annotations"] = devutil.SetTestStartTimeAnno(ann)
+		manifest, err := yaml.Marshal(obj)
+		Expect(err).NotTo(HaveOccurred())
+		apply := exec.Command("kubectl", "apply", "-n", namespace, "-f", "-")
+		apply.Stdin = strings.NewReader(string(manifest))
+		_, err = runner.Run(ctx, logger, apply)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func(ctx SpecContext) {
+			_, _ = runner.Run(ctx, logger, exec.Command("kubectl", "delete", "joboperators", sample,
+				"-n", namespace, "--ignore-not-found", "--wait=false"))
+		})
+		Expect(harness.TimeWait(sess, "sample Ready", 5*time.Minute, func() error {
+			_, err := e2eutil.WaitFor(ctx, boot.Client(), client.ObjectKey{Namespace: namespace, Name: sample},
+				e2eutil.CRReady, e2eutil.WaitOptions{Timeout: 5 * time.Minute})
+			return err
+		})).To(Succeed())
+		sess.Checkpoint(ctx, "created")
+		controllerLogs.ExpectLogContains("Reconciliation successful")
+
+		By("deleting the sample JobOperator (the finalizer removes its StatefulSet first)")
+		_, err = kubectl("delete", "joboperators", sample, "-n", namespace, "--timeout=3m")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = kubectl("get", "statefulset", sample+"-sts", "-n", namespace)
+		Expect(err).To(HaveOccurred(), "the StatefulSet must be gone once the JobOperator is deleted")
+		// optimistic-lock conflicts are retried by the controller and are not failures
+		controllerLogs.ExpectNoErrorLogs("the object has been modified")
+
+		// measurement problems only produce skip results; the lifecycle assertions decide the spec
+		sum, err := sess.End(ctx)
+		if err != nil {
+			warnf("convergence session: %v", err)
+		}
+		if sum != nil {
+			for _, r := range sum.Results {
+				if r.Value != nil {
+					By(fmt.Sprintf("%s = %g %s", r.ID, *r.Value, r.Unit))
+				}
+			}
+		}
+	})
+
+	It("should keep reconciling under custom resource churn", func(specCtx SpecContext) {
+		if cfg.LoadRate <= 0 {
+			Skip("SLOLAB_LOAD_RATE not set")
+		}
+		ctx, cancel := context.WithTimeout(specCtx, cfg.LoadDuration+5*time.Minute)
+		defer cancel()
+
+		sc := sessionConfig(cfg, cm, token, "load")
+		sc.Specs = presets.ControllerRuntime()
+		// the churn fills the workqueue between the edges
+		sc.PeakInterval = 10 * time.Second
+		sc.Load = load.New(load.Options{
+			Namespace: namespace,
+			Objects:   cfg.LoadObjects,
+			Rate:      cfg.LoadRate,
+			Runner:    runner,
+			Logger:    logger,
+		})
+		sess := harness.NewSessionV4(sc)
+		sess.Start(ctx)
+
+		By(fmt.Sprintf("churning JobOperators at %g ops/s for %s", cfg.LoadRate, cfg.LoadDuration))
+		select {
+		case <-ctx.Done():
+		case <-time.After(cfg.LoadDuration):
+		}
+
+		sum, err := sess.End(ctx)
+		if err != nil {
+			warnf("load session: %v", err)
+		}
+		Expect(sum).NotTo(BeNil())
+		Expect(sum.Load).NotTo(BeNil())
+		By(fmt.Sprintf("load: created=%d updated=%d deleted=%d failed=%d",
+			sum.Load.Created, sum.Load.Updated, sum.Load.Deleted, sum.Load.Failed))
+		Expect(sum.Load.Failed).To(BeZero(), "kubectl rejected generated JobOperators")
+		Expect(sum.Load.Created).To(BeNumerically(">", 0))
+	})
+
+	It("should report Ready only after the manager caches synced", func(specCtx SpecContext) {
+		ctx, cancel := context.WithTimeout(specCtx, 5*time.Minute)
+		defer cancel()
+
+		kubectl := func(args ...string) (string, error) {
+			out, err := runner.Run(ctx, logger, exec.Command("kubectl", args...))
+			return strings.TrimSpace(out), err
+		}
+		const selector = "control-plane=controller-manager"
+		oldPod, err := kubectl("get", "pods", "-n", namespace, "-l", selector,
+			"-o", "jsonpath={.items[0].metadata.name}")
+		Expect(err).NotTo(HaveOccurred())
 
-		text, err := cm.Logs(ctx, namespace, podName)
+		By("restarting the controller-manager pod " + oldPod)
+		_, err = kubectl("delete", "pod", oldPod, "-n", namespace, "--wait=false")
 		Expect(err).NotTo(HaveOccurred())
 
-		if !strings.Contains(text, "controller_runtime_reconcile_total") {
-			head := text
-			if len(head) > 800 {
-				head = head[:800]
+		// the pod must be seen not Ready before it turns Ready: readiness follows the checks,
+		// not the container start
+		var newPod string
+		seen := map[string]bool{}
+		Eventually(func(g Gomega) {
+			out, err := kubectl("get", "pods", "-n", namespace, "-l", selector, "-o",
+				`jsonpath={range .items[*]}{.metadata.name}={.status.conditions[?(@.type=="Ready")].status}{"\n"}{end}`)
+			g.Expect(err).NotTo(HaveOccurred())
+			for _, line := range strings.Split(out, "\n") {
+				name, ready, _ := strings.Cut(line, "=")
+				if name == "" || name == oldPod {
+					continue
+				}
+				newPod = name
+				seen[ready] = true
 			}
-			logger.Logf("metrics text head:\n%s", head)
+			g.Expect(seen).To(HaveKey("True"))
+		}).WithContext(ctx).WithPolling(time.Second).WithTimeout(4 * time.Minute).Should(Succeed())
+		Expect(seen).To(Or(HaveKey("False"), HaveKey("")), "controller-manager was Ready as soon as it appeared")
+
+		By("checking the readiness checks of " + newPod)
+		out, err := kubectl("get", "--raw",
+			fmt.Sprintf("/api/v1/namespaces/%s/pods/%s:8081/proxy/readyz?verbose", namespace, newPod))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring("[+]cache-sync ok"))
+	})
+
+	It("should upgrade from the previous release without disrupting custom resources", func(specCtx SpecContext) {
+		if cfg.UpgradeFromImage == "" {
+			Skip("SLOLAB_UPGRADE_FROM_IMAGE not set")
+		}
+		ctx, cancel := context.WithTimeout(specCtx, 15*time.Minute)
+		defer cancel()
+
+		const sample = "joboperator-sample"
+		sampleReady := func(ctx context.Context) (bool, error) {
+			out, err := runner.Run(ctx, logger, exec.Command("kubectl", "get", "joboperators", sample,
+				"-n", namespace, "-o", "jsonpath={.status.readyReplicas}"))
+			return strings.TrimSpace(out) != "" && strings.TrimSpace(out) != "0", err
+		}
+		u := &harness.UpgradeScenario{
+			ProjectDir:        rootDir,
+			Namespace:         namespace,
+			FromImage:         cfg.UpgradeFromImage,
+			ToImage:           projectImage,
+			ResourceNamespace: namespace,
+			Resources:         []string{"joboperators/" + sample, "statefulsets/" + sample + "-sts"},
+			Converged:         sampleReady,
+			Deploy:            boot.DeployImage,
+			Runner:            runner,
+		}
+
+		By("deploying the previous release " + cfg.UpgradeFromImage)
+		Expect(u.DeployFrom(ctx)).To(Succeed())
+
+		By("creating the sample JobOperator under the previous release")
+		cmd := exec.Command("kubectl", "apply", "-n", namespace, "-f", "config/samples/batch_v1_joboperator.yaml")
+		cmd.Dir = rootDir
+		_, err := runner.Run(ctx, logger, cmd)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func(ctx SpecContext) {
+			_, _ = runner.Run(ctx, logger, exec.Command("kubectl", "delete", "joboperators", sample,
+				"-n", namespace, "--ignore-not-found", "--wait=false"))
+		})
+		Eventually(sampleReady).WithContext(ctx).WithTimeout(5 * time.Minute).Should(BeTrue())
+
+		sc := sessionConfig(cfg, cm, token, "upgrade")
+		// the rollout restarts the controller pod: Events show its scheduling and back-off delays
+		sc.Events = true
+		sess := harness.NewSessionV4(sc)
+		sess.Start(ctx)
+
+		By("upgrading to the image under test")
+		d, upgradeErr := u.Upgrade(ctx, sess)
+		if d != nil {
+			By("upgrade: " + d.Detail)
 		}
 
-		Expect(text).To(ContainSubstring("controller_runtime_reconcile_total"))
-		By(fmt.Sprintf("done (timeout=%s)", 2*time.Minute))
+		// measurement problems only produce skip results; the upgrade outcome decides the spec
+		if _, err := sess.End(ctx); err != nil {
+			warnf("upgrade session: %v", err)
+		}
+		Expect(upgradeErr).NotTo(HaveOccurred())
 	})
 })
+
+// metricsEndpoint builds the curl pod's metrics endpoint; any CA source turns on verification,
+// otherwise SLOLAB_METRICS_INSECURE decides (default: not verified, self-signed cert).
+func metricsEndpoint(cfg e2eenv.Options) (curlmetrics.Endpoint, error) {
+	hasCA := cfg.MetricsCAFile != "" || cfg.MetricsCASecret != "" || cfg.MetricsCAConfigMap != ""
+	ep := curlmetrics.Endpoint{
+		Scheme:             cfg.MetricsScheme,
+		Port:               cfg.MetricsPort,
+		Path:               cfg.MetricsPath,
+		InsecureSkipVerify: cfg.MetricsInsecure && !hasCA,
+		CASecret:           cfg.MetricsCASecret,
+		CAConfigMap:        cfg.MetricsCAConfigMap,
+		CAKey:              cfg.MetricsCAKey,
+		ClientCertSecret:   cfg.MetricsClientCertSecret,
+		ProjectedToken:     cfg.MetricsProjectedToken,
+	}
+	if cfg.MetricsCAFile != "" {
+		b, err := os.ReadFile(cfg.MetricsCAFile)
+		if err != nil {
+			return ep, fmt.Errorf("read metrics CA: %w", err)
+		}
+		ep.CABundle = string(b)
+	}
+	return ep.WithDefaults(), nil
+}
+
+// curlScheduling builds the curl pod's resources and placement: DefaultScheduling, with each
+// SLOLAB_CURL_* list that is set replacing its part.
+func curlScheduling(cfg e2eenv.Options) (curlmetrics.PodScheduling, error) {
+	s := curlmetrics.DefaultScheduling()
+	s.PriorityClassName = cfg.CurlPriorityClass
+	var err error
+	if cfg.CurlRequests != nil {
+		if s.Requests, err = curlmetrics.ParseKeyValues(cfg.CurlRequests); err != nil {
+			return s, fmt.Errorf("SLOLAB_CURL_REQUESTS: %w", err)
+		}
+	}
+	if cfg.CurlLimits != nil {
+		if s.Limits, err = curlmetrics.ParseKeyValues(cfg.CurlLimits); err != nil {
+			return s, fmt.Errorf("SLOLAB_CURL_LIMITS: %w", err)
+		}
+	}
+	if s.NodeSelector, err = curlmetrics.ParseKeyValues(cfg.CurlNodeSelector); err != nil {
+		return s, fmt.Errorf("SLOLAB_CURL_NODE_SELECTOR: %w", err)
+	}
+	if s.Tolerations, err = curlmetrics.ParseTolerations(cfg.CurlTolerations); err != nil {
+		return s, fmt.Errorf("SLOLAB_CURL_TOLERATIONS: %w", err)
+	}
+	return s, nil
+}
+
+// applyManagerProfile renders the profile's config file into a ConfigMap, mounts it into the
+// controller-manager with --config and waits for the rollout.
+func applyManagerProfile(ctx context.Context, rootDir, profile string) error {
+	data, ok := manifests.ManagerProfile(profile, namespace)
+	if !ok {
+		return fmt.Errorf("unknown manager profile %q", profile)
+	}
+	manifest, err := devutil.RenderTemplateFileString(rootDir, "test/e2e/manifests/manager-config.tmpl.yaml.gotmpl", data)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("kubectl", "apply", "-f", "-")
+	cmd.Stdin = strings.NewReader(manifest)
+	if _, err := runner.Run(ctx, logger, cmd); err != nil {
+		return err
+	}
+
+	const mountPath = "/etc/manager"
+	patch := fmt.Sprintf(`[
+ {"op": "add", "path": "/spec/template/spec/volumes/-",
+  "value": {"name": "manager-config", "configMap": {"name": %q}}},
+ {"op": "add", "path": "/spec/template/spec/containers/0/volumeMounts/-",
+  "value": {"name": "manager-config", "mountPath": %q, "readOnly": true}},
+ {"op": "add", "path": "/spec/template/spec/containers/0/args/-", "value": "--config=%s/config.yaml"}
+]`, data.Name, mountPath, mountPath)
+	deployment := "deployment/" + deploymentName
+	if _, err := runner.Run(ctx, logger, exec.Command("kubectl", "patch", deployment, "-n", namespace,
+		"--type=json", "-p", patch)); err != nil {
+		return err
+	}
+	_, err = runner.Run(ctx, logger, exec.Command("kubectl", "rollout", "status", deployment, "-n", namespace,
+		"--timeout=3m"))
+	return err
+}
+
+// sessionConfig is the SessionV4Config every measuring spec starts from: the operator's metrics
+// endpoint through the curl pod of cm, and the artifacts and exporters configured by cfg. Specs set
+// what differs (Specs, Events, Load, ...) on the returned value.
+func sessionConfig(cfg e2eenv.Options, cm *curlmetrics.Client, token, testCase string) harness.SessionV4Config {
+	return harness.SessionV4Config{
+		Namespace:          namespace,
+		MetricsServiceName: metricsServiceName,
+		MetricsEndpoint:    cm.Endpoint,
+		TestCase:           testCase,
+		Suite:              "e2e",
+		RunID:              cfg.RunID,
+		ServiceAccountName: serviceAccountName,
+		Token:              token,
+		ArtifactsDir:       cfg.ArtifactsDir,
+		CaptureScrapes:     cfg.CaptureScrapes,
+		MetricFilter:       metricFilter(cfg),
+		UploadURL:          cfg.UploadURL,
+		OTLPEndpoint:       cfg.OTLPEndpoint,
+		UploadPolicy:       uploadPolicy(cfg),
+		BundleDir:          cfg.BundleDir,
+		ResultLine:         cfg.ResultLine,
+		Compress:           cfg.CompressArtifacts,
+		Hooks:              clusterHooks(cfg),
+
+		CurlImage:            cm.Image,
+		CurlImagePullPolicy:  cm.ImagePullPolicy,
+		CurlImagePullSecrets: cm.ImagePullSecrets,
+		CurlRunAsUser:        cm.RunAsUser,
+
+		CurlRunAsUserFromNamespace: cm.RunAsUserFromNamespace,
+		CurlScheduling:             &cm.Scheduling,
+	}
+}
+
+// metricFilter narrows session snapshots when SLOLAB_METRIC_FILTER is set (nil => keep all).
+func metricFilter(cfg e2eenv.Options) *fetch.MetricFilter {
+	if !cfg.MetricFilter {
+		return nil
+	}
+	return &fetch.MetricFilter{Include: cfg.MetricInclude, Exclude: cfg.MetricExclude}
+}
+
+// uploadPolicy is what the SLOLAB_UPLOAD_* filters keep of summaries leaving the cluster (upload,
+// OTLP, result line); Attach and sessionConfig use it.
+func uploadPolicy(cfg e2eenv.Options) summary.RedactPolicy {
+	return summary.RedactPolicy{
+		KeepTags:         cfg.UploadKeepTags,
+		MaskTags:         cfg.UploadMaskTags,
+		KeepResultFields: cfg.UploadKeepFields,
+		DropWarnings:     cfg.UploadDropWarnings,
+	}
+}
+
+// clusterInfo is shared by every session, so the cluster is described once per process.
+var clusterInfo = sync.OnceValue(func() engine.Hooks {
+	return harness.ClusterInfoHooks(kubeutil.ClusterInfoOptions{Namespace: namespace}, nil)
+})
+
+// clusterHooks tags summaries with the cluster shape unless SLOLAB_CLUSTER_INFO=false.
+func clusterHooks(cfg e2eenv.Options) []engine.Hooks {
+	if !cfg.ClusterInfo {
+		return nil
+	}
+	return []engine.Hooks{clusterInfo()}
+}
+
+// prometheusSelector defaults Prometheus queries to the operator namespace.
+func prometheusSelector(sel string) string {
+	if sel != "" {
+		return sel
+	}
+	return fmt.Sprintf("namespace=%q", namespace)
+}
@@ -7,14 +7,14 @@ import (
 	"strings"
 
 	"github.com/yeongki/my-operator/pkg/devutil"
+	"github.com/yeongki/my-operator/pkg/slo"
 )
 
 // TODO 일단 생각하기.
 
-// Logger is the minimal contract needed by ApplyTemplate.
-type Logger interface {
-	Logf(format string, args ...any)
-}
+// Logger is the minimal contract needed by ApplyTemplate: slo.Logger, so leveled loggers
+// (slo.Warnf, GinkgoLog) and kubeutil runners are used as is.
+type Logger = slo.Logger
 
 // Runner is the minimal contract needed by ApplyTemplate.
 // It matches the runner you already use: runner.Run(ctx, logger, cmd).
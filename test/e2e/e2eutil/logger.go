@@ -5,22 +5,41 @@ import (
 
 	"github.com/onsi/ginkgo/v2"
 
+	"github.com/yeongki/my-operator/pkg/kubeutil"
 	"github.com/yeongki/my-operator/pkg/slo"
 )
 
 // 사용 예시
 // logger := slo.NewLogger(utils.GinkgoLog) // nil이면 noop
 // logger.Logf("hello %s", "world")
+// slo.Warnf(logger, "scrape failed: %v", err) // "WARNING: scrape failed: ..."
 
-// GinkgoLogger adapts slo.Logger to GinkgoWriter.
-type GinkgoLogger struct{}
+// GinkgoLogger adapts slo.Logger to GinkgoWriter. Lines are redacted (kubeutil.Redact): GinkgoWriter
+// ends up in CI logs. It is an slo.LevelLogger: lines below Min are dropped (zero => info, so
+// slo.Debugf lines show only with Min: slo.LevelDebug) and debug/warning lines are prefixed.
+type GinkgoLogger struct {
+	Min slo.Level
+}
+
+func (g GinkgoLogger) Logf(format string, args ...any) {
+	g.LogLevel(slo.LevelInfo, fmt.Sprintf(format, args...))
+}
 
-func (GinkgoLogger) Logf(format string, args ...any) {
-	_, _ = fmt.Fprintf(ginkgo.GinkgoWriter, format+"\n", args...)
+func (g GinkgoLogger) LogLevel(level slo.Level, msg string) {
+	if level < g.Min {
+		return
+	}
+	switch level {
+	case slo.LevelDebug:
+		msg = "DEBUG: " + msg
+	case slo.LevelWarn:
+		msg = "WARNING: " + msg
+	}
+	_, _ = fmt.Fprintln(ginkgo.GinkgoWriter, kubeutil.Redact(msg))
 }
 
 // Compile-time check
-var _ slo.Logger = (*GinkgoLogger)(nil)
+var _ slo.LevelLogger = (*GinkgoLogger)(nil)
 
-// Ready-to-use instance
+// Ready-to-use instance (SLOLAB_LOG_LEVEL sets its level, see the suite setup)
 var GinkgoLog slo.Logger = GinkgoLogger{}
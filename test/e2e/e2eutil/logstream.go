@@ -0,0 +1,170 @@
+package e2eutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/common/fsname"
+)
+
+// errorLinePattern matches error-level lines of zap/logr output in JSON ("level":"error")
+// and console ("\tERROR\t") encodings.
+var errorLinePattern = regexp.MustCompile(`"level":"error"|\tERROR\t|^E\d{4} `)
+
+// LogStream follows `kubectl logs -f` for pods matching a selector and buffers the output,
+// so assertions do not need to re-fetch full logs inside Eventually loops.
+type LogStream struct {
+	Namespace string
+	Selector  string
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// lockedWriter appends to the stream buffer under its lock.
+type lockedWriter struct{ s *LogStream }
+
+func (w lockedWriter) Write(p []byte) (int, error) {
+	w.s.mu.Lock()
+	defer w.s.mu.Unlock()
+	return w.s.buf.Write(p)
+}
+
+// StartLogStream starts following logs of all containers of pods matching selector in ns.
+// Only lines logged after the stream attached are captured (--tail=0).
+func StartLogStream(ctx context.Context, ns, selector string) (*LogStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &LogStream{Namespace: ns, Selector: selector, cancel: cancel, done: make(chan struct{})}
+
+	cmd := exec.CommandContext(ctx, "kubectl", "logs", "-f",
+		"-n", ns, "-l", selector,
+		"--all-containers", "--prefix", "--tail=0", "--max-log-requests=20",
+	)
+	cmd.Stdout = lockedWriter{s}
+	cmd.Stderr = lockedWriter{s}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("start log stream (ns=%s selector=%q): %w", ns, selector, err)
+	}
+	go func() {
+		_ = cmd.Wait()
+		close(s.done)
+	}()
+	return s, nil
+}
+
+// Stop ends the stream and waits for kubectl to exit. It is safe to call more than once.
+func (s *LogStream) Stop() {
+	s.cancel()
+	select {
+	case <-s.done:
+	case <-time.After(10 * time.Second):
+	}
+}
+
+// String returns everything captured so far.
+func (s *LogStream) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+// ErrorLines returns captured error-level lines, excluding those containing any of allow.
+func (s *LogStream) ErrorLines(allow ...string) []string {
+	var out []string
+	for _, line := range strings.Split(s.String(), "\n") {
+		// with --prefix each line starts with "[pod/<name>/<container>] "
+		msg := line
+		if strings.HasPrefix(msg, "[") {
+			if idx := strings.Index(msg, "] "); idx > 0 {
+				msg = msg[idx+2:]
+			}
+		}
+		if !errorLinePattern.MatchString(msg) || containsAny(line, allow) {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// ExpectLogContains waits until substr has been logged (timeout defaults to 1m).
+func (s *LogStream) ExpectLogContains(substr string, timeout ...time.Duration) {
+	t := time.Minute
+	if len(timeout) > 0 {
+		t = timeout[0]
+	}
+	EventuallyWithOffset(1, s.String, t, time.Second).Should(ContainSubstring(substr),
+		"controller logs (ns=%s selector=%q) never contained %q", s.Namespace, s.Selector, substr)
+}
+
+// ExpectNoErrorLogs asserts that no error-level line was captured (lines containing any of allow are ignored).
+func (s *LogStream) ExpectNoErrorLogs(allow ...string) {
+	ExpectWithOffset(1, s.ErrorLines(allow...)).To(BeEmpty(),
+		"unexpected error logs (ns=%s selector=%q)", s.Namespace, s.Selector)
+}
+
+// Save writes the captured log to <dir>/logs/<name>.log and records it in the artifacts index.
+func (s *LogStream) Save(dir, name string) (string, error) {
+	path := filepath.Join(dir, "logs", sanitizeName(name)+".log")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(s.String()), 0o644); err != nil {
+		return "", err
+	}
+	idx := artifacts.NewArtifactIndexWriter(dir, artifacts.DefaultOptions())
+	_ = idx.Add(artifacts.IndexEntry{Path: path, Type: artifacts.TypeLog, Spec: name})
+	return path, nil
+}
+
+// StreamLogsForSpec starts a LogStream for the current spec and registers cleanup that stops it
+// and, when artifactsDir is set, saves the log as <artifactsDir>/logs/<spec>.log.
+// Call it from a BeforeEach or It.
+func StreamLogsForSpec(ns, selector, artifactsDir string) *LogStream {
+	s, err := StartLogStream(context.Background(), ns, selector)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	DeferCleanup(func() {
+		s.Stop()
+		if artifactsDir == "" {
+			return
+		}
+		if path, err := s.Save(artifactsDir, CurrentSpecReport().FullText()); err != nil {
+			slo.Warnf(GinkgoLog, "failed to save controller logs: %v", err)
+		} else {
+			GinkgoLog.Logf("controller logs saved to %s", path)
+		}
+	})
+	return s
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if sub != "" && strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeName is harness.SanitizeFilename (e2eutil must not import harness).
+func sanitizeName(s string) string {
+	return fsname.Sanitize(s)
+}
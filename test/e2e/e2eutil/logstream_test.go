@@ -0,0 +1,53 @@
+package e2eutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestErrorLines(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		log   string
+		allow []string
+		want  []string
+	}{
+		{name: "empty", log: "", want: nil},
+		{
+			name: "json",
+			log: `{"level":"info","msg":"Reconciliation successful"}` + "\n" +
+				`{"level":"error","msg":"Reconciler error"}` + "\n",
+			want: []string{`{"level":"error","msg":"Reconciler error"}`},
+		},
+		{
+			name: "console with kubectl prefix",
+			log: "[pod/manager-0/manager] 2026-01-01T10:00:00Z\tINFO\tstarting\n" +
+				"[pod/manager-0/manager] 2026-01-01T10:00:01Z\tERROR\tReconciler error\n",
+			want: []string{"[pod/manager-0/manager] 2026-01-01T10:00:01Z\tERROR\tReconciler error"},
+		},
+		{
+			name: "klog",
+			log:  "[pod/manager-0/manager] E0101 10:00:00.000000       1 reflector.go:1] failed to list\n",
+			want: []string{"[pod/manager-0/manager] E0101 10:00:00.000000       1 reflector.go:1] failed to list"},
+		},
+		{
+			name:  "allowed",
+			log:   `{"level":"error","msg":"Operation cannot be fulfilled: the object has been modified"}` + "\n",
+			allow: []string{"the object has been modified"},
+			want:  nil,
+		},
+		{
+			name: "error only in the message",
+			log:  `{"level":"info","msg":"retrying after error"}` + "\n" + "ERROR in text without tabs\n",
+			want: nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &LogStream{}
+			s.buf.WriteString(tc.log)
+			if got := s.ErrorLines(tc.allow...); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("ErrorLines = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package e2eutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// ApplyYAML server-side applies every document of manifest as fieldManager (no forced ownership,
+// default retries) and returns the objects as the API server persisted them.
+// A field owned by another manager fails the apply with a *ConflictError.
+func ApplyYAML(
+	ctx context.Context, c client.Client, manifest, fieldManager string,
+) ([]*unstructured.Unstructured, error) {
+	return (&Applier{Client: c, FieldManager: fieldManager}).Apply(ctx, manifest)
+}
+
+// Applier performs server-side apply (SSA) instead of piping manifests to `kubectl apply -f -`.
+type Applier struct {
+	Client       client.Client
+	FieldManager string
+
+	// DryRun validates and defaults the objects on the server without persisting them.
+	DryRun bool
+	// Force takes over fields owned by other managers (e.g. a previous `kubectl apply`) instead of
+	// failing with a *ConflictError.
+	Force bool
+	// Retries of transient errors per object (default 5; server timeouts, throttling, a kind whose
+	// CRD is not served yet). Backoff is the first delay, doubled per retry (default 500ms).
+	Retries int
+	Backoff time.Duration
+
+	// Logger reports one line per applied object (nil => no-op).
+	Logger slo.Logger
+}
+
+// FieldConflict is a field the apply wanted to set that Manager owns with a different value.
+type FieldConflict struct {
+	Manager string
+	Field   string
+}
+
+// ConflictError is returned when SSA was refused because of fields owned by other managers.
+// Re-apply with Force to take them over, or drop the fields from the manifest.
+type ConflictError struct {
+	Object    string
+	Conflicts []FieldConflict
+	Err       error
+}
+
+func (e *ConflictError) Error() string {
+	parts := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		parts = append(parts, fmt.Sprintf("%s (owned by %q)", c.Field, c.Manager))
+	}
+	return fmt.Sprintf("apply %s: field conflicts: %s", e.Object, strings.Join(parts, ", "))
+}
+
+func (e *ConflictError) Unwrap() error { return e.Err }
+
+// SplitYAML splits a multi-document YAML (or JSON) manifest into objects; empty documents are skipped.
+func SplitYAML(manifest string) ([]*unstructured.Unstructured, error) {
+	dec := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+	var out []*unstructured.Unstructured
+	for {
+		u := &unstructured.Unstructured{}
+		if err := dec.Decode(&u.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return out, nil
+			}
+			return nil, fmt.Errorf("parse manifest: %w", err)
+		}
+		if len(u.Object) > 0 {
+			out = append(out, u)
+		}
+	}
+}
+
+// Apply applies every document of manifest in order.
+func (a *Applier) Apply(ctx context.Context, manifest string) ([]*unstructured.Unstructured, error) {
+	objs, err := SplitYAML(manifest)
+	if err != nil {
+		return nil, err
+	}
+	return a.ApplyObjects(ctx, objs)
+}
+
+// ApplyObjects applies objs in order and stops at the first error; objs are not modified.
+func (a *Applier) ApplyObjects(
+	ctx context.Context, objs []*unstructured.Unstructured,
+) ([]*unstructured.Unstructured, error) {
+	if a.FieldManager == "" {
+		return nil, errors.New("apply: FieldManager is required")
+	}
+	logger := slo.NewLogger(a.Logger)
+	retries, backoff := a.Retries, a.Backoff
+	if retries <= 0 {
+		retries = 5
+	}
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+	opts := []client.PatchOption{client.FieldOwner(a.FieldManager)}
+	if a.Force {
+		opts = append(opts, client.ForceOwnership)
+	}
+	if a.DryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+
+	out := make([]*unstructured.Unstructured, 0, len(objs))
+	for i, u := range objs {
+		obj := u.DeepCopy()
+		obj.SetManagedFields(nil)
+		obj.SetResourceVersion("")
+		if err := a.patch(ctx, obj, opts, retries, backoff); err != nil {
+			return out, err
+		}
+		out = append(out, obj)
+		suffix := ""
+		if a.DryRun {
+			suffix = " (dry run)"
+		}
+		logger.Logf("applied %d/%d %s%s", i+1, len(objs), ObjectRef(u), suffix)
+	}
+	return out, nil
+}
+
+func (a *Applier) patch(ctx context.Context, obj *unstructured.Unstructured, opts []client.PatchOption,
+	retries int, backoff time.Duration) error {
+	desc := ObjectRef(obj)
+	for attempt := 0; ; attempt++ {
+		err := a.Client.Patch(ctx, obj, client.Apply, opts...)
+		switch {
+		case err == nil:
+			return nil
+		case apierrors.IsConflict(err):
+			if conflicts := fieldConflicts(err); len(conflicts) > 0 {
+				return &ConflictError{Object: desc, Conflicts: conflicts, Err: err}
+			}
+			return fmt.Errorf("apply %s: %w", desc, err)
+		case !retryable(err) || attempt >= retries:
+			return fmt.Errorf("apply %s: %w", desc, err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("apply %s: %w (last error: %v)", desc, ctx.Err(), err)
+		case <-time.After(backoff << attempt):
+		}
+	}
+}
+
+func retryable(err error) bool {
+	return apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsInternalError(err) || apierrors.IsServiceUnavailable(err) || meta.IsNoMatchError(err)
+}
+
+// fieldConflicts reads the FieldManagerConflict causes of an SSA conflict, whose messages look like
+// `conflict with "kubectl-client-side-apply" using apps/v1`.
+func fieldConflicts(err error) []FieldConflict {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil {
+		return nil
+	}
+	var out []FieldConflict
+	for _, c := range status.Status().Details.Causes {
+		if c.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		manager := c.Message
+		if _, rest, ok := strings.Cut(c.Message, `"`); ok {
+			manager, _, _ = strings.Cut(rest, `"`)
+		}
+		out = append(out, FieldConflict{Manager: manager, Field: c.Field})
+	}
+	return out
+}
+
+// ObjectRef names an object for logs and errors ("Kind namespace/name" or "Kind name").
+func ObjectRef(u *unstructured.Unstructured) string {
+	if ns := u.GetNamespace(); ns != "" {
+		return u.GetKind() + " " + ns + "/" + u.GetName()
+	}
+	return u.GetKind() + " " + u.GetName()
+}
@@ -0,0 +1,91 @@
+package e2eutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const manifest = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: a
+---
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+  namespace: a
+`
+
+func TestSplitYAML(t *testing.T) {
+	objs, err := SplitYAML(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 || ObjectRef(objs[0]) != "Namespace a" || ObjectRef(objs[1]) != "ConfigMap a/b" {
+		t.Fatalf("unexpected objects %v", objs)
+	}
+	if _, err := SplitYAML("kind: [unterminated"); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
+
+func TestApplierRetriesAndConflicts(t *testing.T) {
+	var calls int
+	var dryRun bool
+	c := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, p client.Patch,
+			opts ...client.PatchOption) error {
+			calls++
+			po := &client.PatchOptions{}
+			po.ApplyOptions(opts)
+			dryRun = len(po.DryRun) > 0
+			switch {
+			case p.Type() != types.ApplyPatchType || po.FieldManager != "e2e":
+				return errors.New("not a server-side apply")
+			case calls == 1:
+				return apierrors.NewTooManyRequests("slow down", 0)
+			case obj.GetName() == "b":
+				return &apierrors.StatusError{ErrStatus: metav1.Status{
+					Status: metav1.StatusFailure,
+					Reason: metav1.StatusReasonConflict,
+					Code:   409,
+					Details: &metav1.StatusDetails{Causes: []metav1.StatusCause{{
+						Type:    metav1.CauseTypeFieldManagerConflict,
+						Message: `conflict with "kubectl-client-side-apply" using v1`,
+						Field:   ".data.key",
+					}}},
+				}}
+			}
+			return nil
+		},
+	}).Build()
+
+	a := &Applier{Client: c, FieldManager: "e2e", DryRun: true, Backoff: 1}
+	applied, err := a.Apply(context.Background(), manifest)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+	want := FieldConflict{Manager: "kubectl-client-side-apply", Field: ".data.key"}
+	if conflict.Object != "ConfigMap a/b" || len(conflict.Conflicts) != 1 || conflict.Conflicts[0] != want {
+		t.Fatalf("unexpected conflict %+v", conflict)
+	}
+	if len(applied) != 1 || calls != 3 || !dryRun {
+		t.Fatalf("expected the namespace applied after one retry in dry run, got %d objects in %d calls",
+			len(applied), calls)
+	}
+
+	if _, err := ApplyYAML(context.Background(), c, manifest, ""); err == nil {
+		t.Fatal("expected an error without a field manager")
+	}
+}
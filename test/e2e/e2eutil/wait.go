@@ -0,0 +1,123 @@
+package e2eutil
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	batchv1 "github.com/yeongki/my-operator/api/v1"
+	"github.com/yeongki/my-operator/internal/conditions"
+)
+
+// Scheme knows the client-go types and the operator API, for clients used with WaitFor.
+var Scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(Scheme))
+	utilruntime.Must(batchv1.AddToScheme(Scheme))
+}
+
+// WaitOptions controls WaitFor polling.
+type WaitOptions struct {
+	// Timeout bounds the wait on top of ctx (default 3m).
+	Timeout time.Duration
+	// Interval is the first poll interval (default 500ms), doubled after every miss up to
+	// MaxInterval (default 5s).
+	Interval    time.Duration
+	MaxInterval time.Duration
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = 3 * time.Minute
+	}
+	if o.Interval <= 0 {
+		o.Interval = 500 * time.Millisecond
+	}
+	if o.MaxInterval < o.Interval {
+		o.MaxInterval = max(o.Interval, 5*time.Second)
+	}
+	return o
+}
+
+// WaitFor gets the object key with c until cond holds and returns it. A missing object and other
+// API errors are polled like a false cond; the last one is reported if the wait times out. The
+// client's scheme must know T (e.g. Scheme):
+//
+//	jo, err := e2eutil.WaitFor(ctx, c, key, e2eutil.CRReady, e2eutil.WaitOptions{})
+func WaitFor[T client.Object](
+	ctx context.Context, c client.Client, key client.ObjectKey, cond func(T) bool, opts WaitOptions,
+) (T, error) {
+	opts = opts.withDefaults()
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	var zero T
+	obj := reflect.New(reflect.TypeOf(zero).Elem()).Interface().(T)
+	desc := reflect.TypeOf(zero).Elem().Name() + " " + key.String()
+	interval := opts.Interval
+	var last error
+	for {
+		err := c.Get(ctx, key, obj)
+		switch {
+		case err == nil && cond(obj):
+			return obj, nil
+		case err == nil:
+			last = fmt.Errorf("condition not met (resourceVersion %s)", obj.GetResourceVersion())
+		default:
+			last = err
+		}
+		select {
+		case <-ctx.Done():
+			return obj, fmt.Errorf("wait for %s: %w (last: %v)", desc, ctx.Err(), last)
+		case <-time.After(interval):
+		}
+		interval = min(2*interval, opts.MaxInterval)
+	}
+}
+
+// DeploymentAvailable holds when the latest generation rolled out: observed, all replicas updated
+// and the Available condition True.
+func DeploymentAvailable(d *appsv1.Deployment) bool {
+	if d.Status.ObservedGeneration < d.Generation {
+		return false
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionTrue {
+			return d.Status.UpdatedReplicas == replicas && d.Status.AvailableReplicas == replicas
+		}
+	}
+	return false
+}
+
+// PodSucceeded holds when every container of the pod exited successfully.
+func PodSucceeded(p *corev1.Pod) bool {
+	return p.Status.Phase == corev1.PodSucceeded
+}
+
+// CRReady holds when the JobOperator's Ready condition is True for its current generation.
+func CRReady(jo *batchv1.JobOperator) bool {
+	c := meta.FindStatusCondition(jo.Status.Conditions, conditions.Ready)
+	return c != nil && c.Status == metav1.ConditionTrue && c.ObservedGeneration == jo.Generation
+}
+
+// StatefulSetReplicas holds when the StatefulSet asks for n replicas.
+func StatefulSetReplicas(n int32) func(*appsv1.StatefulSet) bool {
+	return func(s *appsv1.StatefulSet) bool {
+		return s.Spec.Replicas != nil && *s.Spec.Replicas == n
+	}
+}
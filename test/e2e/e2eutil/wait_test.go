@@ -0,0 +1,71 @@
+package e2eutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	batchv1 "github.com/yeongki/my-operator/api/v1"
+	"github.com/yeongki/my-operator/internal/conditions"
+)
+
+func TestWaitFor(t *testing.T) {
+	jo := &batchv1.JobOperator{ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "ns", Generation: 2}}
+	conditions.Set(&jo.Status.Conditions, 1, conditions.Ready, metav1.ConditionTrue, conditions.ReasonReplicasReady, "")
+	var gets int
+	c := fake.NewClientBuilder().WithScheme(Scheme).WithObjects(jo).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+			opts ...client.GetOption) error {
+			gets++
+			if gets == 1 {
+				return apierrors.NewNotFound(schema.GroupResource{Resource: "joboperators"}, key.Name)
+			}
+			if err := c.Get(ctx, key, obj, opts...); err != nil {
+				return err
+			}
+			if gets == 3 {
+				// the controller caught up with generation 2
+				conditions.Set(&obj.(*batchv1.JobOperator).Status.Conditions, 2, conditions.Ready,
+					metav1.ConditionTrue, conditions.ReasonReplicasReady, "")
+			}
+			return nil
+		},
+	}).Build()
+
+	opts := WaitOptions{Interval: time.Millisecond, Timeout: 5 * time.Second}
+	got, err := WaitFor(context.Background(), c, client.ObjectKeyFromObject(jo), CRReady, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gets != 3 || got.Name != "sample" {
+		t.Fatalf("expected Ready on the third get, got %d gets", gets)
+	}
+
+	opts.Timeout = 20 * time.Millisecond
+	_, err = WaitFor(context.Background(), c, client.ObjectKey{Namespace: "ns", Name: "missing"},
+		StatefulSetReplicas(1), opts)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestDeploymentAvailable(t *testing.T) {
+	d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	d.Status = appsv1.DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	if DeploymentAvailable(d) {
+		t.Fatal("expected no Available condition to mean unavailable")
+	}
+	d.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: "True"}}
+	if !DeploymentAvailable(d) {
+		t.Fatal("expected the deployment to be available")
+	}
+}
@@ -3,17 +3,21 @@ package harness
 import (
 	"context"
 	"fmt"
-	"path/filepath"
+	"os"
 	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
 	"github.com/yeongki/my-operator/pkg/slo/engine"
 	"github.com/yeongki/my-operator/pkg/slo/fetch"
-	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
+	"github.com/yeongki/my-operator/pkg/slo/replay"
 	"github.com/yeongki/my-operator/pkg/slo/spec"
 	"github.com/yeongki/my-operator/pkg/slo/summary"
+	"github.com/yeongki/my-operator/test/e2e/curlmetrics"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
 )
 
 // HarnessDeps = “Ginkgo hook + RunConfig/tags/output에 필요한 것”
@@ -22,9 +26,48 @@ type HarnessDeps struct {
 
 	Suite    string
 	TestCase string
-	RunID    string
+	// RunID names the run (empty => runid.Resolve).
+	RunID string
 
 	Enabled bool
+
+	// FailOnPolicy fails the spec when an SLI rule at LevelFail is violated.
+	// Measurement failures still only produce skip results.
+	FailOnPolicy bool
+
+	// UploadURL (s3://, gs://, https://) additionally uploads each summary (optional).
+	UploadURL string
+	// UploadPolicy filters what is uploaded; the local JSON keeps everything.
+	UploadPolicy summary.RedactPolicy
+	// OTLPEndpoint additionally exports each summary to an OpenTelemetry collector (OTLP/HTTP,
+	// optional). UploadPolicy applies to the export as well.
+	OTLPEndpoint string
+
+	// DisableFailureDumps turns off the per-spec failure dump under ArtifactsDir/failures.
+	// Failure dumps do not depend on Enabled.
+	DisableFailureDumps bool
+
+	// BundleDir records each evaluation's inputs for `slocli replay` (optional).
+	BundleDir string
+
+	// CaptureScrapes writes each raw curl-pod /metrics body to ArtifactsDir (gzip'd, named by
+	// run/test case/phase) next to the summary.
+	CaptureScrapes bool
+
+	// ResultLine prints each summary as one SLOLAB_RESULT JSON line on stdout (also without
+	// ArtifactsDir), for CI that only keeps logs. UploadPolicy applies.
+	ResultLine bool
+
+	// Compress gzips failure dumps and replay bundle sessions (<name>.gz); loaders decompress
+	// them transparently. Raw scrapes (CaptureScrapes) are always compressed.
+	Compress bool
+
+	// Hooks enrich the start/end snapshots and the summary before it is written, e.g. tag the git
+	// SHA, the cluster version or the node count (engine.Hooks, run in order).
+	Hooks []engine.Hooks
+
+	// SpecsFor (optional) overrides the SpecsProvider's specs per Ginkgo spec, e.g. SpecsByLabel.
+	SpecsFor SpecsOverride
 }
 
 // FetchDeps = “metrics를 어떻게 가져올지(inside curl-pod)에 필요한 것”
@@ -33,16 +76,36 @@ type FetchDeps struct {
 	Token              string
 	MetricsServiceName string
 	ServiceAccountName string
+
+	// PrometheusURL switches fetching to Prometheus instant queries (no curl pod, no token).
+	// PrometheusSelector narrows the series, e.g. `namespace="my-operator-system"`.
+	PrometheusURL      string
+	PrometheusSelector string
+
+	// MetricsEndpoint is the endpoint the CurlPodFns scrape (zero => https:8443/metrics); the
+	// harness only reports it as the sample's target, the fns' client must be configured alike.
+	MetricsEndpoint curlmetrics.Endpoint
+
+	// MetricsRBAC (optional) is ensured before each measured spec (once per value), so the
+	// ServiceAccount may read /metrics; its owner calls Cleanup (e.g. in AfterAll).
+	MetricsRBAC *MetricsReaderRBAC
 }
 
 // CurlPodFns are injected to avoid import cycles (harness should not import test/e2e directly).
+// ctx is the spec's context (cancelled on spec timeout/interrupt); implementations must return
+// once it is done. DeletePodNoWait gets a context detached from that cancellation, so the pod
+// is still removed after an interrupt. CurlMetricsLogs returns the pod log as written by a
+// curlmetrics pod (body plus curl trailer, see curlmetrics.SplitScrape).
 type CurlPodFns struct {
-	RunCurlMetricsOnce  func(ns, token, metricsSvc, sa string) (podName string, err error)
-	WaitCurlMetricsDone func(ns, podName string)
-	CurlMetricsLogs     func(ns, podName string) (string, error)
-	DeletePodNoWait     func(ns, podName string) error
+	RunCurlMetricsOnce  func(ctx context.Context, ns, token, metricsSvc, sa string) (podName string, err error)
+	WaitCurlMetricsDone func(ctx context.Context, ns, podName string) error
+	CurlMetricsLogs     func(ctx context.Context, ns, podName string) (string, error)
+	DeletePodNoWait     func(ctx context.Context, ns, podName string) error
 }
 
+// curlPodCleanupTimeout bounds the pod delete after a scrape, including after cancellation.
+const curlPodCleanupTimeout = 30 * time.Second
+
 // SpecsProvider provides SLI specs for this test.
 // - nil allowed: treat as empty specs (engine will still write summary with empty results).
 type SpecsProvider func() []spec.SLISpec
@@ -51,18 +114,34 @@ type SpecsProvider func() []spec.SLISpec
 // - It does NOT read env vars.
 // - It does NOT know how to obtain token.
 // - It relies on providers to supply per-test deps + SLI specs.
+// - On spec failure it dumps events/describes/controller logs via FailureCollector (see HarnessDeps).
+//
+// Attach is the compatibility layer of the v3 API: every spec is measured by a SessionV4 built
+// from the providers (newSessionFromDeps) and driven by the same hooks as AttachV4, so both
+// entrypoints write the same summaries. New specs use AttachV4 or NewSessionV4.
 func Attach(hdepsProvider func() HarnessDeps, fdepsProvider func() FetchDeps, specsProvider SpecsProvider, fns CurlPodFns) {
-	var sess *session
-	var enabled bool
+	registerFailureCollector(func() *FailureCollector {
+		hdeps := hdepsProvider()
+		if hdeps.DisableFailureDumps || strings.TrimSpace(hdeps.ArtifactsDir) == "" {
+			return nil
+		}
+		c := NewFailureCollector(hdeps.ArtifactsDir, fdepsProvider().Namespace)
+		c.Compress = hdeps.Compress
+		return c
+	})
 
-	BeforeEach(func() {
+	var failOnPolicy bool
+	attachSession("SLO(v3)", func(ctx context.Context) *SessionV4 {
 		hdeps := hdepsProvider()
 		fdeps := fdepsProvider()
+		if !hdeps.Enabled {
+			return nil
+		}
 
-		enabled = hdeps.Enabled
-		if !enabled {
-			sess = nil
-			return
+		if fdeps.MetricsRBAC != nil {
+			if err := fdeps.MetricsRBAC.Ensure(ctx); err != nil {
+				slo.Warnf(e2eutil.GinkgoLog, "SLO(v3): metrics rbac not ensured (scrapes may be denied): %v", err)
+			}
 		}
 
 		// Auto-fill TestCase if empty.
@@ -73,93 +152,109 @@ func Attach(hdepsProvider func() HarnessDeps, fdepsProvider func() FetchDeps, sp
 		var specs []spec.SLISpec
 		if specsProvider != nil {
 			specs = specsProvider()
-		} else {
-			specs = nil // empty: no SLI results, but summary still produced
-		}
-		// 일단, sess 자체가 nil 이 나올 수 없음.
-		sess = newSession(hdeps, fdeps, specs, fns)
-		sess.Start()
-	})
-
-	AfterEach(func() {
-		if !enabled || sess == nil {
-			return
 		}
-		if err := sess.End(context.Background()); err != nil {
-			_, _ = fmt.Fprintf(GinkgoWriter, "SLO(v3): End failed (skip): %v\n", err)
+		if hdeps.SpecsFor != nil {
+			specs = hdeps.SpecsFor(CurrentSpecReport(), specs)
 		}
-	})
+		failOnPolicy = hdeps.FailOnPolicy
+		return newSessionFromDeps(hdeps, fdeps, specs, fns)
+	}, func() EndOptions { return EndOptions{FailOnPolicy: failOnPolicy} })
+}
+
+// newSessionFromDeps maps the v3 deps onto a SessionV4: the curl pod runs through fns (or
+// Prometheus answers, see FetchDeps.PrometheusURL) and nil specs stay empty (no SLI results, but
+// the summary is still produced) instead of the v4 defaults.
+func newSessionFromDeps(hdeps HarnessDeps, fdeps FetchDeps, specs []spec.SLISpec, fns CurlPodFns) *SessionV4 {
+	if specs == nil {
+		specs = []spec.SLISpec{}
+	}
+	cfg := SessionV4Config{
+		Namespace:          fdeps.Namespace,
+		MetricsServiceName: fdeps.MetricsServiceName,
+		MetricsEndpoint:    fdeps.MetricsEndpoint,
+		ServiceAccountName: fdeps.ServiceAccountName,
+		Token:              fdeps.Token,
+		TestCase:           hdeps.TestCase,
+		Suite:              hdeps.Suite,
+		RunID:              hdeps.RunID,
+		ArtifactsDir:       hdeps.ArtifactsDir,
+		UploadURL:          hdeps.UploadURL,
+		UploadPolicy:       hdeps.UploadPolicy,
+		OTLPEndpoint:       hdeps.OTLPEndpoint,
+		BundleDir:          hdeps.BundleDir,
+		CaptureScrapes:     hdeps.CaptureScrapes,
+		ResultLine:         hdeps.ResultLine,
+		Compress:           hdeps.Compress,
+		Hooks:              hdeps.Hooks,
+		Specs:              specs,
+	}
+	if strings.TrimSpace(fdeps.PrometheusURL) != "" {
+		cfg.Fetcher = fetch.NewPrometheusFetcher(fdeps.PrometheusURL, spec.MetricNames(specs), fdeps.PrometheusSelector)
+	}
+	sess := NewSessionV4(cfg)
+	if cfg.Fetcher == nil {
+		// the raw scrapes go to the session's capture, named by the session's window
+		sess.fetcher = fetch.RetryTruncated(curlMetricsFetcher{
+			deps:    fdeps,
+			fns:     fns,
+			scrapes: sess.scrapes,
+		}, fetch.TruncationOptions{Logger: e2eutil.GinkgoLog})
+	}
+	return sess
 }
 
-type session struct {
-	eng     *engine.Engine
-	outPath string
-	mode    engine.RunMode
-	tags    map[string]string
-	runID   string
-	specs   []spec.SLISpec
+// reportBreaches logs one warning per breached SLI (with its owner) to GinkgoLog.
+func reportBreaches(sum *summary.Summary) {
+	if sum == nil {
+		return
+	}
+	for _, r := range sum.Breaches() {
+		slo.Warnf(e2eutil.GinkgoLog, "%s", summary.BreachMessage(r))
+	}
+}
 
-	started time.Time
+// withUpload wraps w with an uploader for uploadURL. An invalid URL only disables uploading.
+func withUpload(w summary.Writer, uploadURL string, policy summary.RedactPolicy) summary.Writer {
+	if strings.TrimSpace(uploadURL) == "" {
+		return w
+	}
+	uw, err := artifacts.NewUploadSummaryWriter(w, uploadURL, e2eutil.GinkgoLog)
+	if err != nil {
+		slo.Warnf(e2eutil.GinkgoLog, "SLO: upload disabled: %v", err)
+		return w
+	}
+	uw.Policy = policy
+	return uw
 }
 
-func newSession(hdeps HarnessDeps, fdeps FetchDeps, specs []spec.SLISpec, fns CurlPodFns) *session {
-	writer := summary.Writer(noopWriter{})
-	outPath := ""
-	if strings.TrimSpace(hdeps.ArtifactsDir) != "" {
-		filename := fmt.Sprintf(
-			"sli-summary.v3.%s.%s.json",
-			SanitizeFilename(hdeps.RunID),
-			SanitizeFilename(hdeps.TestCase),
-		)
-		outPath = filepath.Join(hdeps.ArtifactsDir, filename)
-		writer = summary.NewJSONFileWriter()
-	}
-
-	fetcher := curlMetricsFetcher{
-		deps: fdeps,
-		fns:  fns,
-	}
-
-	// v3 engine: Specs are directly injected via ExecuteRequest.
-	eng := engine.New(fetcher, writer, nil)
-
-	return &session{
-		eng:     eng,
-		outPath: outPath,
-		mode: engine.RunMode{
-			Location: "inside",
-			Trigger:  "none",
-		},
-		tags: map[string]string{
-			"suite":     hdeps.Suite,
-			"test_case": hdeps.TestCase,
-			"namespace": fdeps.Namespace,
-			"run_id":    hdeps.RunID,
-		},
-		runID: hdeps.RunID,
-		specs: specs,
+// withOTLP wraps w with an OTLP exporter to endpoint. Headers and the service name come from
+// the standard OTEL_EXPORTER_OTLP_HEADERS and OTEL_SERVICE_NAME variables.
+func withOTLP(w summary.Writer, endpoint string, policy summary.RedactPolicy) summary.Writer {
+	if strings.TrimSpace(endpoint) == "" {
+		return w
 	}
+	ow := artifacts.NewOTLPWriter(w, endpoint, e2eutil.GinkgoLog)
+	ow.Header = artifacts.ParseOTLPHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	ow.ServiceName = os.Getenv("OTEL_SERVICE_NAME")
+	ow.Policy = policy
+	return ow
 }
 
-func (s *session) Start() {
-	s.started = time.Now()
+// artifactOptions returns the write options of large artifacts (bundles, dumps).
+func artifactOptions(compress bool) artifacts.Options {
+	o := artifacts.DefaultOptions()
+	o.Gzip = compress
+	return o
 }
 
-func (s *session) End(ctx context.Context) error {
-	finished := time.Now()
-
-	_, err := s.eng.Execute(ctx, engine.ExecuteRequest{
-		Config: engine.RunConfig{
-			RunID:      s.runID,
-			StartedAt:  s.started,
-			FinishedAt: finished,
-			Mode:       s.mode,
-			Tags:       s.tags,
-		},
-		Specs:   s.specs,
-		OutPath: s.outPath,
-	})
-	return err
+// withResultLine wraps w with a SLOLAB_RESULT line on stdout when enabled.
+func withResultLine(w summary.Writer, enabled bool, policy summary.RedactPolicy) summary.Writer {
+	if !enabled {
+		return w
+	}
+	rw := artifacts.NewResultLineWriter(w)
+	rw.Policy = policy
+	return rw
 }
 
 type noopWriter struct{}
@@ -167,56 +262,74 @@ type noopWriter struct{}
 func (noopWriter) Write(path string, s summary.Summary) error { return nil }
 
 type curlMetricsFetcher struct {
-	deps FetchDeps
-	fns  CurlPodFns
+	deps    FetchDeps
+	fns     CurlPodFns
+	scrapes *scrapeCapture
 }
 
 func (f curlMetricsFetcher) Fetch(ctx context.Context, at time.Time) (fetch.Sample, error) {
-	_ = ctx
-
-	podName, err := f.fns.RunCurlMetricsOnce(
-		f.deps.Namespace,
-		f.deps.Token,
-		f.deps.MetricsServiceName,
-		f.deps.ServiceAccountName,
-	)
+	body, podName, err := scrapeCurlPod(ctx, f.deps, f.fns, f.scrapes, at)
 	if err != nil {
 		return fetch.Sample{}, err
 	}
+	return fetch.SampleFromText(at, body, &fetch.Provenance{
+		Fetcher: "curl-pod",
+		Target:  f.deps.MetricsEndpoint.URL(f.deps.MetricsServiceName, f.deps.Namespace),
+		Via:     f.deps.Namespace + "/" + podName,
+	})
+}
 
-	f.fns.WaitCurlMetricsDone(f.deps.Namespace, podName)
+// scrapeCurlPod runs one curl pod through fns and returns the exposition body (curl trailer
+// checked and removed). The pod is deleted afterwards, also when ctx is cancelled; the raw log
+// is saved to scrapes (nil => not captured).
+func scrapeCurlPod(
+	ctx context.Context, deps FetchDeps, fns CurlPodFns, scrapes *scrapeCapture, at time.Time,
+) (body, podName string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", "", err
+	}
 
-	raw, err := f.fns.CurlMetricsLogs(f.deps.Namespace, podName)
-	_ = f.fns.DeletePodNoWait(f.deps.Namespace, podName)
+	podName, err = fns.RunCurlMetricsOnce(
+		ctx,
+		deps.Namespace,
+		deps.Token,
+		deps.MetricsServiceName,
+		deps.ServiceAccountName,
+	)
 	if err != nil {
-		return fetch.Sample{}, err
+		return "", "", err
 	}
-
-	values, err := parsePrometheusText(raw)
+	// an interrupted spec still removes the pod instead of leaving it behind
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), curlPodCleanupTimeout)
+		defer cancel()
+		_ = fns.DeletePodNoWait(cleanupCtx, deps.Namespace, podName)
+	}()
+
+	if err := fns.WaitCurlMetricsDone(ctx, deps.Namespace, podName); err != nil {
+		return "", podName, fmt.Errorf("wait for curl pod %s: %w", podName, err)
+	}
+	raw, err := fns.CurlMetricsLogs(ctx, deps.Namespace, podName)
 	if err != nil {
-		return fetch.Sample{}, err
+		return "", podName, err
 	}
-
-	return fetch.Sample{
-		At:     at,
-		Values: values,
-	}, nil
-}
-
-func parsePrometheusText(raw string) (map[string]float64, error) {
-	base, err := promtext.ParseTextToMap(strings.NewReader(raw))
+	if _, err := scrapes.save(at, raw); err != nil {
+		slo.Warnf(e2eutil.GinkgoLog, "SLO(v3): %v (skip)", err)
+	}
+	body, err = curlmetrics.SplitScrape(raw)
 	if err != nil {
-		return nil, err
+		return "", podName, fmt.Errorf("curl pod %s: %w", podName, err)
 	}
+	return body, podName, nil
+}
 
-	// v3 convenience: also aggregate per-metric-name (strip label set).
-	out := map[string]float64{}
-	for key, val := range base {
-		out[key] = val
-		if idx := strings.Index(key, "{"); idx > 0 {
-			name := key[:idx]
-			out[name] = out[name] + val
-		}
+// recordBundle saves the evaluation inputs for replay. No capture (bundle disabled) is a no-op.
+func recordBundle(r *replay.Recorder, c *replay.CaptureFetcher, specs []spec.SLISpec, sum *summary.Summary) error {
+	if c == nil || sum == nil {
+		return nil
+	}
+	if _, err := r.Record(specs, c.Snapshots(), sum); err != nil {
+		return fmt.Errorf("record replay bundle: %w", err)
 	}
-	return out, nil
+	return nil
 }
@@ -0,0 +1,95 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+func TestNewSessionFromDeps(t *testing.T) {
+	scrapes := 0
+	var deleted []string
+	fns := CurlPodFns{
+		RunCurlMetricsOnce: func(context.Context, string, string, string, string) (string, error) {
+			scrapes++
+			return fmt.Sprintf("curl-%d", scrapes), nil
+		},
+		WaitCurlMetricsDone: func(context.Context, string, string) error { return nil },
+		CurlMetricsLogs: func(context.Context, string, string) (string, error) {
+			body := fmt.Sprintf("reconciles %d\n", 10*scrapes)
+			return fmt.Sprintf("%s\n# slo-scrape-trailer size_download=%d content_length=%d\n",
+				body, len(body), len(body)), nil
+		},
+		DeletePodNoWait: func(_ context.Context, _, pod string) error {
+			deleted = append(deleted, pod)
+			return nil
+		},
+	}
+	dir := t.TempDir()
+	hdeps := HarnessDeps{ArtifactsDir: dir, Suite: "e2e", TestCase: "compat", RunID: "run-1", Enabled: true}
+	fdeps := FetchDeps{Namespace: "ns", MetricsServiceName: "svc", ServiceAccountName: "sa", Token: "tok"}
+	specs := []spec.SLISpec{{
+		ID:      "reconcile_delta",
+		Inputs:  []spec.MetricRef{spec.PromMetric("reconciles", nil)},
+		Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+	}}
+
+	sess := newSessionFromDeps(hdeps, fdeps, specs, fns)
+	sess.Start(context.Background())
+	sum, err := sess.End(context.Background())
+	if err != nil {
+		t.Fatalf("End: %v", err)
+	}
+	if len(sum.Results) != 1 || sum.Results[0].Value == nil || *sum.Results[0].Value != 10 {
+		t.Fatalf("results = %+v", sum.Results)
+	}
+	if len(deleted) != 2 {
+		t.Errorf("curl pods not deleted: %v", deleted)
+	}
+	if sum.Config.Tags["namespace"] != "ns" || sum.Config.Tags["suite"] != "e2e" {
+		t.Errorf("tags = %v", sum.Config.Tags)
+	}
+	if _, err := summary.Load(filepath.Join(dir, "sli-summary.v3.run-1.compat.json")); err != nil {
+		t.Errorf("v3 summary file: %v", err)
+	}
+
+	// nil specs keep the v3 meaning: a summary without results, not the v4 default specs
+	sess = newSessionFromDeps(hdeps, fdeps, nil, fns)
+	sess.Start(context.Background())
+	if sum, err = sess.End(context.Background()); err != nil || len(sum.Results) != 0 {
+		t.Errorf("nil specs: %+v, %v", sum, err)
+	}
+}
+
+// Attach hands the BeforeEach SpecContext to Start: once Ginkgo cancels it (spec timeout or
+// interrupt) the start scrape must not run a curl pod.
+func TestNewSessionFromDepsStartCancelled(t *testing.T) {
+	pods := 0
+	fns := CurlPodFns{
+		RunCurlMetricsOnce: func(context.Context, string, string, string, string) (string, error) {
+			pods++
+			return "curl", nil
+		},
+		WaitCurlMetricsDone: func(context.Context, string, string) error { return nil },
+		CurlMetricsLogs:     func(context.Context, string, string) (string, error) { return "", nil },
+		DeletePodNoWait:     func(context.Context, string, string) error { return nil },
+	}
+	hdeps := HarnessDeps{Suite: "e2e", TestCase: "cancelled", RunID: "run-1", Enabled: true}
+	fdeps := FetchDeps{Namespace: "ns", MetricsServiceName: "svc", ServiceAccountName: "sa", Token: "tok"}
+
+	sess := newSessionFromDeps(hdeps, fdeps, nil, fns)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sess.Start(ctx)
+	if !errors.Is(sess.startErr, context.Canceled) {
+		t.Errorf("start snapshot error = %v, want context.Canceled", sess.startErr)
+	}
+	if pods != 0 {
+		t.Errorf("cancelled Start ran %d curl pods", pods)
+	}
+}
@@ -4,9 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"time"
 
 	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/clock"
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+	"github.com/yeongki/my-operator/test/e2e/curlmetrics"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
 )
 
 // AttachV4Config defines the minimal v4 inputs for InsideSnapshot.
@@ -18,9 +27,55 @@ type AttachV4Config struct {
 	RunID              string
 	ServiceAccountName string
 	Token              string
+	// MetricsEndpoint is how the metrics service is reached (zero => https:8443/metrics).
+	MetricsEndpoint curlmetrics.Endpoint
 
 	ArtifactsDir string
+	UploadURL    string
+	UploadPolicy summary.RedactPolicy
+	OTLPEndpoint string
 	Tags         map[string]string
+
+	// FailOnPolicy fails the spec when an SLI rule at LevelFail is violated.
+	FailOnPolicy bool
+
+	// Chaos kills a controller pod mid-measurement (optional, see PodKillChaos).
+	Chaos *PodKillChaos
+
+	// DisableFailureDumps turns off the per-spec failure dump under ArtifactsDir/failures.
+	DisableFailureDumps bool
+
+	// BundleDir records each evaluation's inputs for `slocli replay` (optional).
+	BundleDir string
+
+	// CaptureScrapes writes each raw /metrics body to ArtifactsDir (see SessionV4Config).
+	CaptureScrapes bool
+	// ResultLine prints each summary as a SLOLAB_RESULT line on stdout (see SessionV4Config).
+	ResultLine bool
+	// Compress gzips failure dumps and replay bundle sessions (see HarnessDeps).
+	Compress bool
+	// MetricFilter narrows snapshots to the specs' metrics (see SessionV4Config, optional).
+	MetricFilter *fetch.MetricFilter
+	// CurlImage*, CurlRunAsUser* and CurlScheduling configure the curl pod (see SessionV4Config).
+	CurlImage                  string
+	CurlImagePullPolicy        string
+	CurlImagePullSecrets       []string
+	CurlRunAsUser              int64
+	CurlRunAsUserFromNamespace bool
+	CurlScheduling             *curlmetrics.PodScheduling
+
+	// Load churns objects during each measurement (optional, e.g. *load.Generator).
+	Load LoadGenerator
+
+	// Specs are measured by every spec (nil => DefaultV3Specs).
+	Specs []spec.SLISpec
+	// SpecsFor (optional) overrides Specs per Ginkgo spec from its report, e.g. SpecsByLabel.
+	SpecsFor SpecsOverride
+
+	// Hooks enrich snapshots and summaries (see SessionV4Config).
+	Hooks []engine.Hooks
+	// Clock times the measurement windows (see SessionV4Config, nil => wall clock).
+	Clock clock.Clock
 }
 
 // AttachV4 provides a v4 Ginkgo entrypoint that does not require CurlPodFns.
@@ -40,25 +95,87 @@ func AttachV4(cfg AttachV4Config) (*SessionV4, error) {
 	session := NewSessionV4(SessionV4Config{
 		Namespace:          cfg.Namespace,
 		MetricsServiceName: cfg.MetricsServiceName,
+		MetricsEndpoint:    cfg.MetricsEndpoint,
 		TestCase:           cfg.TestCase,
 		Suite:              cfg.Suite,
 		RunID:              cfg.RunID,
 		ServiceAccountName: cfg.ServiceAccountName,
 		Token:              cfg.Token,
 		ArtifactsDir:       cfg.ArtifactsDir,
+		UploadURL:          cfg.UploadURL,
+		UploadPolicy:       cfg.UploadPolicy,
+		OTLPEndpoint:       cfg.OTLPEndpoint,
+		Chaos:              cfg.Chaos,
+		BundleDir:          cfg.BundleDir,
+		CaptureScrapes:     cfg.CaptureScrapes,
+		ResultLine:         cfg.ResultLine,
+		Compress:           cfg.Compress,
+		MetricFilter:       cfg.MetricFilter,
+		Load:               cfg.Load,
+		Hooks:              cfg.Hooks,
+		Specs:              cfg.Specs,
 		Tags:               cfg.Tags,
-		Now:                time.Now,
-	})
+		Clock:              cfg.Clock,
 
-	ginkgo.BeforeEach(func() {
-		session.Start()
+		CurlImage:                  cfg.CurlImage,
+		CurlImagePullPolicy:        cfg.CurlImagePullPolicy,
+		CurlImagePullSecrets:       cfg.CurlImagePullSecrets,
+		CurlRunAsUser:              cfg.CurlRunAsUser,
+		CurlRunAsUserFromNamespace: cfg.CurlRunAsUserFromNamespace,
+		CurlScheduling:             cfg.CurlScheduling,
 	})
 
-	ginkgo.AfterEach(func() {
-		if _, err := session.End(context.Background()); err != nil {
-			_, _ = fmt.Fprintf(ginkgo.GinkgoWriter, "SLO(v4): End failed (skip): %v\n", err)
+	if !cfg.DisableFailureDumps && cfg.ArtifactsDir != "" {
+		registerFailureCollector(func() *FailureCollector {
+			c := NewFailureCollector(cfg.ArtifactsDir, cfg.Namespace)
+			c.Compress = cfg.Compress
+			return c
+		})
+	}
+
+	attachSession("SLO(v4)", func(context.Context) *SessionV4 {
+		if cfg.SpecsFor != nil {
+			session.SetSpecs(cfg.SpecsFor(ginkgo.CurrentSpecReport(), cfg.Specs))
+		}
+		return session
+	}, func() EndOptions { return EndOptions{FailOnPolicy: cfg.FailOnPolicy} })
+	return session, nil
+}
+
+// attachSession registers the measurement hooks shared by Attach and AttachV4: before each spec
+// session returns the session measuring it (nil => the spec is not measured) and starts it; after
+// the spec it ends with opts, or aborts when the run is interrupted. Policy failures and an
+// unrecovered chaos kill fail the spec, other errors are warnings prefixed with label.
+func attachSession(label string, session func(ctx context.Context) *SessionV4, opts func() EndOptions) {
+	var sess *SessionV4
+
+	// SpecContext reaches Start too, so a spec timeout/interrupt also aborts the start snapshot.
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		sess = session(ctx)
+		if sess != nil {
+			sess.Start(ctx)
 		}
 	})
 
-	return session, nil
+	// SpecContext is cancelled on spec timeout/interrupt, which aborts in-flight scrapes.
+	ginkgo.AfterEach(func(ctx ginkgo.SpecContext) {
+		if sess == nil {
+			return
+		}
+		// an interrupted/aborted run is shutting down: don't scrape, record why nothing was measured
+		if state := ginkgo.CurrentSpecReport().State; state.Is(types.SpecStateInterrupted | types.SpecStateAborted) {
+			if _, err := sess.Abort(ctx, "spec "+state.String()); err != nil {
+				slo.Warnf(e2eutil.GinkgoLog, "%s: Abort failed (skip): %v", label, err)
+			}
+			return
+		}
+		sum, err := sess.End(ctx, opts())
+		reportBreaches(sum)
+		if errors.Is(err, ErrPolicyFailed) || errors.Is(err, ErrChaosNotRecovered) {
+			ginkgo.Fail(fmt.Sprintf("%s: %v", label, err))
+		}
+		if err != nil {
+			slo.Warnf(e2eutil.GinkgoLog, "%s: End failed (skip): %v", label, err)
+		}
+	})
 }
@@ -0,0 +1,29 @@
+package harness
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yeongki/my-operator/pkg/slo/buildinfo"
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+)
+
+// processBuild is resolved once: neither the environment nor the binary change during a run.
+var processBuild = sync.OnceValue(func() summary.Build { return buildinfo.Resolve(nil) })
+
+// BuildHooks stamps b into every summary (summary.Build), keeping the fields other hooks set.
+func BuildHooks(b summary.Build) engine.Hooks {
+	return engine.Hooks{OnResult: func(_ context.Context, s *summary.Summary) error {
+		if b != (summary.Build{}) {
+			s.Build = s.Build.Merge(b)
+		}
+		return nil
+	}}
+}
+
+// withBuild prepends the build provenance of this process (buildinfo.Resolve) to hooks, so every
+// harness summary says which commit and image it measured.
+func withBuild(hooks []engine.Hooks) []engine.Hooks {
+	return append([]engine.Hooks{BuildHooks(processBuild())}, hooks...)
+}
@@ -0,0 +1,194 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/kubeutil"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
+)
+
+// ErrChaosNotRecovered is returned by SessionV4.End when an injected disruption did not recover
+// (pods not ready again, no leader handover, or no reconcile activity after the restart).
+var ErrChaosNotRecovered = errors.New("chaos: system did not recover from disruption")
+
+// PodKillChaos deletes the controller-manager pod in the middle of a measurement window.
+// When LeaseName is set it kills the current leader and requires the lease to be handed over.
+type PodKillChaos struct {
+	Namespace string
+	// Selector of the controller pods (default "control-plane=controller-manager").
+	Selector string
+	// Delay after Start before the pod is killed.
+	Delay time.Duration
+	// RecoveryTimeout bounds waiting for ready pods and leader handover (default 3m).
+	RecoveryTimeout time.Duration
+	// LeaseName is the leader election Lease in Namespace (optional).
+	LeaseName string
+	// RecoverySLI is a result ID that must have a value > 0 after the restart (optional),
+	// e.g. "reconcile_total_delta" to assert that reconciles resume.
+	RecoverySLI string
+
+	// Runner may be nil (kubeutil.DefaultRunner).
+	Runner kubeutil.CmdRunner
+}
+
+func (c *PodKillChaos) withDefaults() PodKillChaos {
+	out := *c
+	if out.Selector == "" {
+		out.Selector = "control-plane=controller-manager"
+	}
+	if out.RecoveryTimeout <= 0 {
+		out.RecoveryTimeout = 3 * time.Minute
+	}
+	if out.Runner == nil {
+		out.Runner = kubeutil.DefaultRunner{}
+	}
+	return out
+}
+
+// chaosRun is one in-flight chaos injection of a session.
+type chaosRun struct {
+	chaos  PodKillChaos
+	stop   chan struct{}
+	done   chan struct{}
+	cancel context.CancelFunc
+
+	disruption *summary.Disruption
+	err        error
+}
+
+// start schedules the pod kill under ctx. Calling finish before Delay elapsed cancels it.
+func (c *PodKillChaos) start(ctx context.Context) *chaosRun {
+	cfg := c.withDefaults()
+	ctx, cancel := context.WithCancel(ctx)
+	run := &chaosRun{chaos: cfg, stop: make(chan struct{}), done: make(chan struct{}), cancel: cancel}
+
+	go func() {
+		defer close(run.done)
+		timer := time.NewTimer(cfg.Delay)
+		defer timer.Stop()
+		select {
+		case <-run.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		run.disruption, run.err = cfg.killAndWait(ctx)
+	}()
+	return run
+}
+
+// finish cancels a pending kill, or waits for the recovery of one already injected. When ctx is
+// done first, the recovery wait is cancelled.
+func (r *chaosRun) finish(ctx context.Context) (*summary.Disruption, error) {
+	close(r.stop)
+	defer r.cancel()
+	select {
+	case <-r.done:
+		return r.disruption, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c PodKillChaos) kubectl(ctx context.Context, args ...string) (string, error) {
+	out, err := c.Runner.Run(ctx, e2eutil.GinkgoLog, exec.Command("kubectl", args...))
+	return strings.TrimSpace(out), err
+}
+
+func (c PodKillChaos) leaseHolder(ctx context.Context) (string, error) {
+	return c.kubectl(ctx, "get", "lease", c.LeaseName, "-n", c.Namespace, "-o", "jsonpath={.spec.holderIdentity}")
+}
+
+// killAndWait deletes one controller pod (the leader when known) and waits for recovery.
+func (c PodKillChaos) killAndWait(ctx context.Context) (*summary.Disruption, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.RecoveryTimeout)
+	defer cancel()
+
+	oldHolder := ""
+	if c.LeaseName != "" {
+		h, err := c.leaseHolder(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("chaos: read lease %s/%s: %w", c.Namespace, c.LeaseName, err)
+		}
+		oldHolder = h
+	}
+
+	pod := ""
+	if oldHolder != "" {
+		// controller-runtime holder identity is "<pod name>_<uuid>"
+		pod, _, _ = strings.Cut(oldHolder, "_")
+	}
+	if pod == "" {
+		out, err := c.kubectl(ctx, "get", "pods", "-n", c.Namespace, "-l", c.Selector,
+			"-o", "jsonpath={.items[0].metadata.name}")
+		if err != nil || out == "" {
+			return nil, fmt.Errorf("chaos: no pod matches %q in %s: %v", c.Selector, c.Namespace, err)
+		}
+		pod = out
+	}
+
+	d := &summary.Disruption{
+		Kind:      "pod-kill",
+		Target:    c.Namespace + "/" + pod,
+		StartedAt: time.Now(),
+	}
+	if _, err := c.kubectl(ctx, "delete", "pod", pod, "-n", c.Namespace, "--wait=false"); err != nil {
+		return nil, fmt.Errorf("chaos: delete pod %s: %w", d.Target, err)
+	}
+
+	// the deleted pod may still report ready for a moment; give the ReplicaSet time to replace it
+	if _, err := c.kubectl(ctx, "wait", "--for=delete", "pod/"+pod, "-n", c.Namespace,
+		fmt.Sprintf("--timeout=%s", c.RecoveryTimeout)); err != nil {
+		d.Detail = fmt.Sprintf("old pod not gone: %v", err)
+		return d, fmt.Errorf("%w: %s", ErrChaosNotRecovered, d.Detail)
+	}
+	if err := kubeutil.WaitPodContainerReadyByLabel(ctx, e2eutil.GinkgoLog, c.Runner, c.Namespace, c.Selector, 0, 0,
+		kubeutil.WaitOptions{Timeout: c.RecoveryTimeout, Interval: 2 * time.Second}); err != nil {
+		d.Detail = fmt.Sprintf("pods not ready: %v", err)
+		return d, fmt.Errorf("%w: %s", ErrChaosNotRecovered, d.Detail)
+	}
+
+	if c.LeaseName != "" {
+		for {
+			h, err := c.leaseHolder(ctx)
+			if err == nil && h != "" && h != oldHolder {
+				d.Detail = fmt.Sprintf("leader handover %s -> %s", oldHolder, h)
+				break
+			}
+			select {
+			case <-ctx.Done():
+				d.Detail = fmt.Sprintf("no leader handover from %q", oldHolder)
+				return d, fmt.Errorf("%w: %s", ErrChaosNotRecovered, d.Detail)
+			case <-time.After(2 * time.Second):
+			}
+		}
+	}
+
+	recovered := time.Now()
+	d.RecoveredAt = &recovered
+	return d, nil
+}
+
+// checkRecoverySLI verifies that the configured SLI shows activity after the restart.
+func (c *PodKillChaos) checkRecoverySLI(sum *summary.Summary) error {
+	if c.RecoverySLI == "" || sum == nil {
+		return nil
+	}
+	for _, r := range sum.Results {
+		if r.ID != c.RecoverySLI {
+			continue
+		}
+		if r.Value == nil || *r.Value <= 0 {
+			return fmt.Errorf("%w: %s shows no activity after the restart", ErrChaosNotRecovered, r.ID)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: recovery SLI %q not in results", ErrChaosNotRecovered, c.RecoverySLI)
+}
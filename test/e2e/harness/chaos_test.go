@@ -0,0 +1,131 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/slotest"
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+)
+
+// chaosRunner fakes kubectl for a pod kill: the lease moves to the new pod once the old one is
+// deleted. With blockWait the wait for the old pod's deletion only returns when ctx is done.
+type chaosRunner struct {
+	blockWait bool
+	deleted   chan struct{}
+
+	mu   sync.Mutex
+	gone bool
+}
+
+func (r *chaosRunner) Run(ctx context.Context, _ slo.Logger, cmd *exec.Cmd) (string, error) {
+	line := strings.Join(cmd.Args[1:], " ")
+	r.mu.Lock()
+	gone := r.gone
+	r.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(line, "get lease"):
+		if gone {
+			return "manager-b_2", nil
+		}
+		return "manager-a_1", nil
+	case strings.HasPrefix(line, "delete pod"):
+		r.mu.Lock()
+		r.gone = true
+		r.mu.Unlock()
+		close(r.deleted)
+		return "", nil
+	case strings.HasPrefix(line, "wait --for=delete"):
+		if r.blockWait {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "", nil
+	case strings.HasPrefix(line, "get pods") && strings.Contains(line, "metadata.name"):
+		return "manager-a", nil
+	case strings.HasPrefix(line, "get pods"):
+		return "true", nil
+	}
+	return "", errors.New("unexpected command: " + line)
+}
+
+func chaosSession(chaos *PodKillChaos, end float64) *SessionV4 {
+	return NewSessionV4(SessionV4Config{
+		Namespace: "system",
+		TestCase:  "chaos",
+		Fetcher: slotest.NewFakeFetcher(
+			map[string]float64{"reconciles": 40},
+			// the restarted controller counts from zero again
+			map[string]float64{"reconciles": end},
+		),
+		Specs: []spec.SLISpec{{
+			ID:      "reconciles_delta",
+			Inputs:  []spec.MetricRef{spec.PromMetric("reconciles", nil)},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		}},
+		Chaos: chaos,
+	})
+}
+
+func TestPodKillChaos(t *testing.T) {
+	r := &chaosRunner{deleted: make(chan struct{})}
+	chaos := &PodKillChaos{LeaseName: "lease", RecoverySLI: "reconciles_delta", Runner: r}
+	session := chaosSession(chaos, 7)
+
+	session.Start(context.Background())
+	<-r.deleted
+	sum, err := session.End(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chaos.Namespace != "" {
+		t.Errorf("Start must not write into the caller's chaos, got namespace %q", chaos.Namespace)
+	}
+	if len(sum.Disruptions) != 1 {
+		t.Fatalf("expected one disruption, got %+v", sum.Disruptions)
+	}
+	d := sum.Disruptions[0]
+	if d.Kind != "pod-kill" || d.Target != "system/manager-a" || d.RecoveredAt == nil ||
+		d.Detail != "leader handover manager-a_1 -> manager-b_2" {
+		t.Fatalf("unexpected disruption %+v", d)
+	}
+	if v := sum.Results[0].Value; v == nil || *v != 7 {
+		t.Fatalf("expected the restart inside the window (reset-corrected delta 7), got %+v", sum.Results[0])
+	}
+}
+
+func TestPodKillChaosNoActivity(t *testing.T) {
+	r := &chaosRunner{deleted: make(chan struct{})}
+	session := chaosSession(&PodKillChaos{RecoverySLI: "reconciles_delta", Runner: r}, 0)
+
+	session.Start(context.Background())
+	<-r.deleted
+	if _, err := session.End(context.Background()); !errors.Is(err, ErrChaosNotRecovered) {
+		t.Fatalf("expected ErrChaosNotRecovered without reconciles after the restart, got %v", err)
+	}
+}
+
+func TestPodKillChaosAbort(t *testing.T) {
+	r := &chaosRunner{blockWait: true, deleted: make(chan struct{})}
+	session := chaosSession(&PodKillChaos{Runner: r}, 0)
+
+	session.Start(context.Background())
+	<-r.deleted
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = session.Abort(context.Background(), "interrupted")
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Abort must cancel the recovery wait")
+	}
+}
@@ -0,0 +1,47 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/yeongki/my-operator/pkg/kubeutil"
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
+)
+
+// ClusterInfoHooks tags every summary with the cluster it was measured on (kubeutil.ClusterInfo:
+// k8s_version, cluster_nodes, cluster_provider, operator_image, operator_version), so deltas of
+// runs on different cluster shapes are not compared blindly, and fills the operator image and its
+// digest into summary.Build. The cluster is described once, at the first summary, and shared by
+// the sessions using the hooks. Tags set by the caller win; a failed lookup keeps the tags it could
+// read and adds a warning. r nil => kubeutil.DefaultRunner.
+func ClusterInfoHooks(opts kubeutil.ClusterInfoOptions, r kubeutil.CmdRunner) engine.Hooks {
+	var (
+		once  sync.Once
+		tags  map[string]string
+		image summary.Build
+		err   error
+	)
+	return engine.Hooks{OnResult: func(ctx context.Context, s *summary.Summary) error {
+		once.Do(func() {
+			var info kubeutil.ClusterInfo
+			info, err = kubeutil.DescribeCluster(ctx, e2eutil.GinkgoLog, r, opts)
+			tags = info.Tags()
+			image = summary.Build{Image: info.OperatorImage, ImageDigest: info.OperatorImageDigest}
+		})
+		for k, v := range tags {
+			if s.Config.Tags[k] == "" {
+				s.Config.Tags[k] = v
+			}
+		}
+		if image.Image != "" || image.ImageDigest != "" {
+			s.Build = s.Build.Merge(image)
+		}
+		if err != nil {
+			return fmt.Errorf("cluster info incomplete: %w", err)
+		}
+		return nil
+	}}
+}
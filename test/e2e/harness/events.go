@@ -0,0 +1,137 @@
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/yeongki/my-operator/pkg/kubeutil"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+)
+
+// EventsFetcher turns the Kubernetes Events of a namespace into series for the kube-events preset:
+//
+//	k8s_events_total{type,reason}            Events so far (Event.count included), a counter
+//	k8s_event_pod_first_to_started_seconds   slowest pod whose first Event is after Since
+//
+// Only Events last observed at or before the fetch time count, so a snapshot does not pick up
+// what happened after it was due. An Event whose count grew after that time is left out as a
+// whole; the next snapshot counts all of its occurrences.
+//
+// Events expire (1h by default), so the counter only holds for windows shorter than the TTL.
+type EventsFetcher struct {
+	Namespace string
+	// Since is the window start (zero => every pod counts for the latency gauge).
+	Since time.Time
+	// Runner may be nil (kubeutil.DefaultRunner).
+	Runner kubeutil.CmdRunner
+}
+
+func (f EventsFetcher) Fetch(ctx context.Context, at time.Time) (fetch.Sample, error) {
+	r := f.Runner
+	if r == nil {
+		r = kubeutil.DefaultRunner{}
+	}
+	out, err := r.Run(ctx, nil, exec.Command("kubectl", "get", "events", "-n", f.Namespace, "-o", "json"))
+	if err != nil {
+		return fetch.Sample{}, fmt.Errorf("events: %w", err)
+	}
+	var list corev1.EventList
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		return fetch.Sample{}, fmt.Errorf("events: %w", err)
+	}
+	return fetch.SampleFromText(at, eventsExposition(list.Items, f.Since, at), &fetch.Provenance{
+		Fetcher: "kubectl-events",
+		Target:  f.Namespace,
+	})
+}
+
+// eventsExposition renders the derived series of the Events last observed by at (zero => all)
+// in the Prometheus text format.
+func eventsExposition(events []corev1.Event, since, at time.Time) string {
+	counts := map[[2]string]float64{}
+	type podTimes struct{ first, started time.Time }
+	pods := map[string]*podTimes{}
+	for _, e := range events {
+		first, last := eventTimes(e)
+		if !at.IsZero() && last.After(at) {
+			continue
+		}
+		counts[[2]string{e.Type, e.Reason}] += float64(eventCount(e))
+
+		if e.InvolvedObject.Kind != "Pod" {
+			continue
+		}
+		key := e.InvolvedObject.Namespace + "/" + e.InvolvedObject.Name
+		p := pods[key]
+		if p == nil {
+			p = &podTimes{first: first}
+			pods[key] = p
+		}
+		if first.Before(p.first) {
+			p.first = first
+		}
+		if e.Reason == "Started" && last.After(p.started) {
+			p.started = last
+		}
+	}
+
+	keys := make([][2]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1]
+	})
+	var b strings.Builder
+	b.WriteString("# TYPE k8s_events_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "k8s_events_total{type=%q,reason=%q} %g\n", k[0], k[1], counts[k])
+	}
+
+	slowest := -1.0
+	for _, p := range pods {
+		if p.started.IsZero() || p.first.Before(since) {
+			continue
+		}
+		slowest = max(slowest, p.started.Sub(p.first).Seconds())
+	}
+	if slowest >= 0 {
+		b.WriteString("# TYPE k8s_event_pod_first_to_started_seconds gauge\n")
+		fmt.Fprintf(&b, "k8s_event_pod_first_to_started_seconds %g\n", slowest)
+	}
+	return b.String()
+}
+
+// eventCount is the number of occurrences an Event stands for (core count or events.k8s.io series).
+func eventCount(e corev1.Event) int32 {
+	switch {
+	case e.Count > 0:
+		return e.Count
+	case e.Series != nil && e.Series.Count > 0:
+		return e.Series.Count
+	}
+	return 1
+}
+
+// eventTimes returns when an Event was first and last observed, whichever API wrote it.
+func eventTimes(e corev1.Event) (first, last time.Time) {
+	first = e.FirstTimestamp.Time
+	if first.IsZero() {
+		first = e.EventTime.Time
+	}
+	last = e.LastTimestamp.Time
+	if last.IsZero() && e.Series != nil {
+		last = e.Series.LastObservedTime.Time
+	}
+	if last.IsZero() {
+		last = first
+	}
+	return first, last
+}
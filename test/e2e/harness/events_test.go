@@ -0,0 +1,75 @@
+package harness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo/common/promkey"
+)
+
+const testEvents = `{"kind": "EventList", "items": [
+	  {"type": "Normal", "reason": "Scheduled", "count": 1,
+	   "involvedObject": {"kind": "Pod", "namespace": "ns", "name": "old"},
+	   "firstTimestamp": "2026-01-01T09:00:00Z", "lastTimestamp": "2026-01-01T09:00:00Z"},
+	  {"type": "Normal", "reason": "Started", "count": 1,
+	   "involvedObject": {"kind": "Pod", "namespace": "ns", "name": "old"},
+	   "firstTimestamp": "2026-01-01T09:01:00Z", "lastTimestamp": "2026-01-01T09:01:00Z"},
+	  {"type": "Normal", "reason": "Scheduled",
+	   "involvedObject": {"kind": "Pod", "namespace": "ns", "name": "web-0"},
+	   "eventTime": "2026-01-01T10:00:00.000000Z"},
+	  {"type": "Warning", "reason": "BackOff", "count": 3,
+	   "involvedObject": {"kind": "Pod", "namespace": "ns", "name": "web-0"},
+	   "firstTimestamp": "2026-01-01T10:00:05Z", "lastTimestamp": "2026-01-01T10:00:20Z"},
+	  {"type": "Normal", "reason": "Started", "count": 2,
+	   "involvedObject": {"kind": "Pod", "namespace": "ns", "name": "web-0"},
+	   "firstTimestamp": "2026-01-01T10:00:04Z", "lastTimestamp": "2026-01-01T10:00:30Z"}
+	]}`
+
+func TestEventsFetcher(t *testing.T) {
+	f := EventsFetcher{
+		Namespace: "ns",
+		Since:     time.Date(2026, 1, 1, 9, 30, 0, 0, time.UTC),
+		Runner:    fakeRunner{out: testEvents},
+	}
+	s, err := f.Fetch(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{
+		promkey.Format("k8s_events_total", map[string]string{"type": "Warning", "reason": "BackOff"}):  3,
+		promkey.Format("k8s_events_total", map[string]string{"type": "Normal", "reason": "Started"}):   3,
+		promkey.Format("k8s_events_total", map[string]string{"type": "Normal", "reason": "Scheduled"}): 2,
+		// old started before Since and is ignored
+		"k8s_event_pod_first_to_started_seconds": 30,
+	}
+	for k, v := range want {
+		if s.Values[k] != v {
+			t.Fatalf("%s: expected %v, got %v (%v)", k, v, s.Values[k], s.Values)
+		}
+	}
+}
+
+func TestEventsFetcherAt(t *testing.T) {
+	f := EventsFetcher{Namespace: "ns", Runner: fakeRunner{out: testEvents}}
+	// the BackOff and the second web-0 Started are last observed after the snapshot
+	s, err := f.Fetch(context.Background(), time.Date(2026, 1, 1, 10, 0, 10, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backOff := promkey.Format("k8s_events_total", map[string]string{"type": "Warning", "reason": "BackOff"})
+	if _, ok := s.Values[backOff]; ok {
+		t.Fatalf("BackOff after the snapshot was counted: %v", s.Values)
+	}
+	want := map[string]float64{
+		promkey.Format("k8s_events_total", map[string]string{"type": "Normal", "reason": "Started"}):   1,
+		promkey.Format("k8s_events_total", map[string]string{"type": "Normal", "reason": "Scheduled"}): 2,
+		// web-0 has not started yet at the snapshot, only old counts
+		"k8s_event_pod_first_to_started_seconds": 60,
+	}
+	for k, v := range want {
+		if s.Values[k] != v {
+			t.Fatalf("%s: expected %v, got %v (%v)", k, v, s.Values[k], s.Values)
+		}
+	}
+}
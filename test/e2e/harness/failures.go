@@ -0,0 +1,143 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+
+	"github.com/yeongki/my-operator/pkg/kubeutil"
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
+)
+
+// failureDumpTimeout bounds the whole dump; it runs after the spec context may already be cancelled.
+const failureDumpTimeout = 2 * time.Minute
+
+// FailureCollector writes cluster state for a failed spec into per-spec files under
+// <ArtifactsDir>/failures/<spec>/ instead of streaming it into GinkgoWriter.
+// Every step is best-effort: a failing kubectl call is recorded in the file it would have produced.
+type FailureCollector struct {
+	ArtifactsDir string
+	Namespace    string
+
+	// ControllerSelector selects the controller pods whose logs are collected
+	// (default "control-plane=controller-manager").
+	ControllerSelector string
+
+	// Runner may be nil (kubeutil.DefaultRunner).
+	Runner kubeutil.CmdRunner
+	// Index may be nil; when set each dump file is recorded in the artifact index.
+	Index *artifacts.ArtifactIndexWriter
+	// Compress gzips the dump files (<file>.gz); controller logs of a scaled run get large.
+	Compress bool
+}
+
+// NewFailureCollector returns a collector for namespace that also records its files
+// in the artifacts index of artifactsDir.
+func NewFailureCollector(artifactsDir, namespace string) *FailureCollector {
+	return &FailureCollector{
+		ArtifactsDir: artifactsDir,
+		Namespace:    namespace,
+		Index:        artifacts.NewArtifactIndexWriter(artifactsDir, artifacts.DefaultOptions()),
+	}
+}
+
+type failureDump struct {
+	file string
+	args []string
+}
+
+func (c *FailureCollector) dumps() []failureDump {
+	selector := c.ControllerSelector
+	if selector == "" {
+		selector = "control-plane=controller-manager"
+	}
+	ns := c.Namespace
+	return []failureDump{
+		{"events.txt", []string{"get", "events", "-n", ns, "--sort-by=.lastTimestamp"}},
+		{"pods-describe.txt", []string{"describe", "pods", "-n", ns}},
+		{"deployments-describe.txt", []string{"describe", "deployments", "-n", ns}},
+		{"controller-logs.txt", []string{"logs", "-n", ns, "-l", selector, "--all-containers", "--tail=-1"}},
+		// webhook TLS setup: caBundle injection, serving certificate issuance and the webhook service
+		// endpoints (without cert-manager the certificates file only records the kubectl error)
+		{"webhook-configurations.yaml", []string{
+			"get", "validatingwebhookconfigurations,mutatingwebhookconfigurations", "-o", "yaml"}},
+		{"certificates-describe.txt", []string{"describe", "certificates,certificaterequests,issuers", "-n", ns}},
+		{"endpoints.txt", []string{"get", "endpoints", "-n", ns, "-o", "wide"}},
+	}
+}
+
+// Dir returns the dump directory for specName.
+func (c *FailureCollector) Dir(specName string) string {
+	return filepath.Join(c.ArtifactsDir, "failures", SanitizeFilename(specName))
+}
+
+// Collect dumps events, pod/deployment describes, controller logs and the webhook TLS state for specName.
+// It returns the written files; the error only reports problems creating the files themselves.
+func (c *FailureCollector) Collect(ctx context.Context, specName string) ([]string, error) {
+	if strings.TrimSpace(c.ArtifactsDir) == "" || strings.TrimSpace(c.Namespace) == "" {
+		return nil, nil
+	}
+	r := c.Runner
+	if r == nil {
+		r = kubeutil.DefaultRunner{}
+	}
+
+	dir := c.Dir(specName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, d := range c.dumps() {
+		out, err := r.Run(ctx, nil, exec.Command("kubectl", d.args...))
+		if err != nil {
+			out = fmt.Sprintf("# kubectl %s failed: %v\n%s", strings.Join(d.args, " "), err, out)
+		}
+
+		opts := artifacts.Options{Gzip: c.Compress}
+		path := opts.Name(filepath.Join(dir, d.file))
+		if err := artifacts.WriteFile(path, []byte(out), opts); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+
+		if c.Index != nil {
+			_ = c.Index.Add(artifacts.IndexEntry{Path: path, Type: artifacts.TypeFailureDump, Spec: specName})
+		}
+	}
+	return written, nil
+}
+
+// registerFailureCollector adds an AfterEach that runs collector() on failed specs.
+// collector may return nil to skip (e.g. no artifacts dir configured).
+func registerFailureCollector(collector func() *FailureCollector) {
+	AfterEach(func(ctx SpecContext) {
+		report := CurrentSpecReport()
+		if !report.Failed() {
+			return
+		}
+		c := collector()
+		if c == nil {
+			return
+		}
+
+		dumpCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), failureDumpTimeout)
+		defer cancel()
+
+		files, err := c.Collect(dumpCtx, report.FullText())
+		if err != nil {
+			slo.Warnf(e2eutil.GinkgoLog, "failure dump: %v", err)
+		}
+		if len(files) > 0 {
+			e2eutil.GinkgoLog.Logf("failure dump written to %s", filepath.Dir(files[0]))
+		}
+	})
+}
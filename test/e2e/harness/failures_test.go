@@ -0,0 +1,97 @@
+package harness
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+)
+
+// dumpRunner echoes the kubectl arguments and refuses the controller logs.
+type dumpRunner struct{}
+
+func (dumpRunner) Run(_ context.Context, _ slo.Logger, cmd *exec.Cmd) (string, error) {
+	line := strings.Join(cmd.Args[1:], " ")
+	if strings.HasPrefix(line, "logs") {
+		return "partial\n", errors.New("pods is forbidden")
+	}
+	return "output of " + line + "\n", nil
+}
+
+func TestFailureCollector(t *testing.T) {
+	dir := t.TempDir()
+	c := NewFailureCollector(dir, "system")
+	c.Runner = dumpRunner{}
+
+	files, err := c.Collect(context.Background(), "Manager should reconcile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(c.dumps()) {
+		t.Fatalf("expected one file per dump, got %v", files)
+	}
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(c.Dir("Manager should reconcile"), name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	if got := read("events.txt"); got != "output of get events -n system --sort-by=.lastTimestamp\n" {
+		t.Errorf("events.txt = %q", got)
+	}
+	// a failing step still writes its file, with the error and the partial output
+	logs := read("controller-logs.txt")
+	if !strings.Contains(logs, "# kubectl logs -n system -l control-plane=controller-manager") ||
+		!strings.Contains(logs, "pods is forbidden") || !strings.HasSuffix(logs, "partial\n") {
+		t.Errorf("controller-logs.txt = %q", logs)
+	}
+
+	idx, err := c.Index.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Entries) != len(files) {
+		t.Fatalf("expected %d index entries, got %+v", len(files), idx.Entries)
+	}
+	for _, e := range idx.Entries {
+		if e.Type != artifacts.TypeFailureDump || e.Spec != "Manager should reconcile" {
+			t.Errorf("unexpected index entry %+v", e)
+		}
+	}
+}
+
+func TestFailureCollectorCompress(t *testing.T) {
+	c := &FailureCollector{ArtifactsDir: t.TempDir(), Namespace: "system", Runner: dumpRunner{}, Compress: true}
+	files, err := c.Collect(context.Background(), "spec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("%s: %v", files[0], err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil || !strings.HasPrefix(string(b), "output of get events") {
+		t.Fatalf("%s = %q, %v", files[0], b, err)
+	}
+
+	// without a namespace there is nothing to dump
+	if files, err := (&FailureCollector{ArtifactsDir: t.TempDir(), Runner: dumpRunner{}}).Collect(
+		context.Background(), "spec"); err != nil || files != nil {
+		t.Fatalf("expected no dump, got %v, %v", files, err)
+	}
+}
@@ -0,0 +1,91 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/onsi/ginkgo/v2"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/engine"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
+)
+
+// DefaultMinMeasurementSuccess is the measurement success rate below which the suite report is
+// marked degraded.
+const DefaultMinMeasurementSuccess = 0.95
+
+// suiteStats totals the fetches of every session of this process, for the suite report.
+var suiteStats fetch.Stats
+
+// SuiteMeasurement returns the fetch counts of all sessions of this process so far.
+func SuiteMeasurement() summary.MeasurementStats { return suiteStats.Report() }
+
+// MeasurementHooks stamps the fetch counts of stats so far into every summary
+// (summary.Measurement).
+func MeasurementHooks(stats *fetch.Stats) engine.Hooks {
+	return engine.Hooks{OnResult: func(_ context.Context, s *summary.Summary) error {
+		if stats == nil {
+			return nil // the window never started: nothing was fetched
+		}
+		m := stats.Report()
+		s.Measurement = &m
+		return nil
+	}}
+}
+
+// withMeasurement prepends MeasurementHooks(stats) to hooks.
+func withMeasurement(stats *fetch.Stats, hooks []engine.Hooks) []engine.Hooks {
+	return append([]engine.Hooks{MeasurementHooks(stats)}, hooks...)
+}
+
+// MeasurementReport is the suite-level measurement reliability: the fetch counts of all sessions
+// of one ginkgo process.
+type MeasurementReport struct {
+	RunID   string `json:"runId,omitempty"`
+	Process int    `json:"process"` // ginkgo parallel process (1 when serial)
+	summary.MeasurementStats
+	SuccessRate    float64 `json:"successRate"`
+	MinSuccessRate float64 `json:"minSuccessRate"`
+	// Degraded is set when SuccessRate < MinSuccessRate: results were silently skipped often enough
+	// that the suite's SLIs should not be trusted.
+	Degraded bool `json:"degraded"`
+}
+
+// WriteMeasurementReport logs the suite's measurement reliability (SuiteMeasurement) and writes it
+// to dir/measurement-report.<run>.<process>.json (dir "" => log only). minRate <= 0 =>
+// DefaultMinMeasurementSuccess; a lower success rate is logged as a warning, never a failure.
+// Nothing is reported when no session fetched anything.
+func WriteMeasurementReport(dir, runID string, minRate float64) (MeasurementReport, error) {
+	if minRate <= 0 {
+		minRate = DefaultMinMeasurementSuccess
+	}
+	m := SuiteMeasurement()
+	r := MeasurementReport{
+		RunID:            runID,
+		Process:          ginkgo.GinkgoParallelProcess(),
+		MeasurementStats: m,
+		SuccessRate:      m.SuccessRate(),
+		MinSuccessRate:   minRate,
+		Degraded:         m.SuccessRate() < minRate,
+	}
+	if m.Attempts() == 0 {
+		return r, nil
+	}
+	msg := fmt.Sprintf("SLO: measurement success %.1f%% (%d snapshots, %d fetch failures, %d parse errors, "+
+		"%d retries)", 100*r.SuccessRate, m.Snapshots, m.FetchFailures, m.ParseErrors, m.Retries)
+	if r.Degraded {
+		slo.Warnf(e2eutil.GinkgoLog, "%s below %.1f%%: skipped SLIs hide measurement decay", msg, 100*minRate)
+	} else {
+		e2eutil.GinkgoLog.Logf("%s", msg)
+	}
+	if dir == "" {
+		return r, nil
+	}
+	name := fmt.Sprintf("measurement-report.%s.%d.json", SanitizeFilename(runID), r.Process)
+	return r, artifacts.NewJSONWriter(artifacts.DefaultOptions()).WriteJSON(filepath.Join(dir, name), r)
+}
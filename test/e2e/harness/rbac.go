@@ -0,0 +1,159 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
+)
+
+// managedByLabel marks a metrics-reader binding as created by MetricsReaderRBAC (value: the field
+// manager), so a later run knows it may delete it.
+const managedByLabel = "app.kubernetes.io/managed-by"
+
+// MetricsReaderRBAC binds the scraping ServiceAccount to the metrics-reader ClusterRole (a
+// ClusterRoleBinding, server-side applied). Ensure is idempotent and applies once per value;
+// Cleanup deletes the binding only when it is ours: created by Ensure, or applied by this field
+// manager in an earlier run. A binding someone else made is only checked for the subject and left
+// alone.
+//
+//	rbac := &harness.MetricsReaderRBAC{Client: boot.Client(), ClusterRole: "my-operator-metrics-reader",
+//		Namespace: ns, ServiceAccountName: sa, SkipCleanup: cfg.SkipCleanup}
+//	BeforeAll: Expect(rbac.Ensure(ctx)).To(Succeed())
+//	AfterAll:  _ = rbac.Cleanup(ctx)
+//
+// Set FetchDeps.MetricsRBAC to have Attach ensure it before the first measured spec.
+type MetricsReaderRBAC struct {
+	Client client.Client
+	// Name of the ClusterRoleBinding (default "<ClusterRole>-<Namespace>-<ServiceAccountName>").
+	Name               string
+	ClusterRole        string
+	Namespace          string
+	ServiceAccountName string
+	// FieldManager of the apply and value of the ownership label (default "my-operator-e2e-rbac").
+	FieldManager string
+	// SkipCleanup keeps the binding (E2E_SKIP_CLEANUP).
+	SkipCleanup bool
+	Logger      slo.Logger
+
+	mu      sync.Mutex
+	ensured bool
+	owned   bool
+}
+
+func (r *MetricsReaderRBAC) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s-%s-%s", r.ClusterRole, r.Namespace, r.ServiceAccountName)
+}
+
+func (r *MetricsReaderRBAC) fieldManager() string {
+	if r.FieldManager != "" {
+		return r.FieldManager
+	}
+	return "my-operator-e2e-rbac"
+}
+
+// Ensure applies the binding unless this value already did.
+func (r *MetricsReaderRBAC) Ensure(ctx context.Context) error {
+	if r.Client == nil || r.ClusterRole == "" || r.Namespace == "" || r.ServiceAccountName == "" {
+		return errors.New("metrics rbac: Client, ClusterRole, Namespace and ServiceAccountName are required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ensured {
+		return nil
+	}
+	name, manager := r.name(), r.fieldManager()
+
+	var existing rbacv1.ClusterRoleBinding
+	err := r.Client.Get(ctx, client.ObjectKey{Name: name}, &existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		r.owned = true
+	case err != nil:
+		return fmt.Errorf("metrics rbac: get ClusterRoleBinding %s: %w", name, err)
+	case existing.RoleRef.Name != r.ClusterRole:
+		// roleRef is immutable: apply would be refused
+		return fmt.Errorf("metrics rbac: ClusterRoleBinding %s binds ClusterRole %s, not %s",
+			name, existing.RoleRef.Name, r.ClusterRole)
+	default:
+		r.owned = appliedBy(&existing, manager)
+		if !r.owned {
+			// subjects is an atomic list: applying would replace the other manager's subjects
+			if !slices.ContainsFunc(existing.Subjects, r.isSubject) {
+				return fmt.Errorf("metrics rbac: ClusterRoleBinding %s is not ours and does not bind %s/%s",
+					name, r.Namespace, r.ServiceAccountName)
+			}
+			r.ensured = true
+			return nil
+		}
+	}
+
+	crb := rbacv1.ClusterRoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: r.ClusterRole,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind: rbacv1.ServiceAccountKind, Name: r.ServiceAccountName, Namespace: r.Namespace,
+		}},
+	}
+	crb.Labels = map[string]string{managedByLabel: manager}
+	manifest, err := yaml.Marshal(crb)
+	if err != nil {
+		return err
+	}
+	if _, err := e2eutil.ApplyYAML(ctx, r.Client, string(manifest), manager); err != nil {
+		return fmt.Errorf("metrics rbac: %w", err)
+	}
+	slo.NewLogger(r.Logger).Logf("metrics rbac: ClusterRoleBinding %s (%s -> %s/%s, owned=%v)",
+		name, r.ClusterRole, r.Namespace, r.ServiceAccountName, r.owned)
+	r.ensured = true
+	return nil
+}
+
+func (r *MetricsReaderRBAC) isSubject(s rbacv1.Subject) bool {
+	return s.Kind == rbacv1.ServiceAccountKind && s.Name == r.ServiceAccountName && s.Namespace == r.Namespace
+}
+
+// appliedBy reports whether the binding carries manager's ownership label or was applied by it
+// (bindings of runs before the label).
+func appliedBy(crb *rbacv1.ClusterRoleBinding, manager string) bool {
+	if crb.Labels[managedByLabel] == manager {
+		return true
+	}
+	for _, f := range crb.ManagedFields {
+		if f.Manager == manager && f.Operation == metav1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+	return false
+}
+
+// Cleanup deletes the binding when it is ours and SkipCleanup is off; deleting it again is a no-op.
+func (r *MetricsReaderRBAC) Cleanup(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.ensured || !r.owned || r.SkipCleanup {
+		return nil
+	}
+	crb := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: r.name()}}
+	if err := r.Client.Delete(ctx, crb); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("metrics rbac: delete ClusterRoleBinding %s: %w", crb.Name, err)
+	}
+	r.ensured = false
+	return nil
+}
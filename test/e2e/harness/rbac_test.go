@@ -0,0 +1,78 @@
+package harness
+
+import (
+	"context"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// applyAsCreate stands in for server-side apply on the fake client: create, or update when present.
+var applyAsCreate = interceptor.Funcs{
+	Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, _ client.Patch,
+		_ ...client.PatchOption) error {
+		err := c.Create(ctx, obj)
+		if apierrors.IsAlreadyExists(err) {
+			return c.Update(ctx, obj)
+		}
+		return err
+	},
+}
+
+func TestMetricsReaderRBAC(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithInterceptorFuncs(applyAsCreate).Build()
+	r := &MetricsReaderRBAC{Client: c, ClusterRole: "metrics-reader", Namespace: "ns", ServiceAccountName: "sa"}
+	for range 2 {
+		if err := r.Ensure(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var crb rbacv1.ClusterRoleBinding
+	if err := c.Get(ctx, client.ObjectKey{Name: "metrics-reader-ns-sa"}, &crb); err != nil {
+		t.Fatal(err)
+	}
+	if crb.Labels[managedByLabel] != "my-operator-e2e-rbac" || crb.Subjects[0].Name != "sa" {
+		t.Fatalf("unexpected binding %+v", crb)
+	}
+	if err := r.Cleanup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Name: "metrics-reader-ns-sa"}, &crb); !apierrors.IsNotFound(err) {
+		t.Fatalf("owned binding should be deleted, got %v", err)
+	}
+
+	// someone else's binding is reused but kept
+	theirs := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared"},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "metrics-reader"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "sa", Namespace: "ns"}},
+	}
+	c = fake.NewClientBuilder().WithObjects(theirs).WithInterceptorFuncs(applyAsCreate).Build()
+	r = &MetricsReaderRBAC{Client: c, Name: "shared", ClusterRole: "metrics-reader", Namespace: "ns",
+		ServiceAccountName: "sa"}
+	if err := r.Ensure(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Cleanup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Name: "shared"}, &crb); err != nil {
+		t.Fatalf("foreign binding should be kept, got %v", err)
+	}
+
+	r = &MetricsReaderRBAC{Client: c, Name: "shared", ClusterRole: "other", Namespace: "ns", ServiceAccountName: "sa"}
+	if err := r.Ensure(ctx); err == nil {
+		t.Fatal("a binding to another ClusterRole should be an error (roleRef is immutable)")
+	}
+	r = &MetricsReaderRBAC{Client: c, Name: "shared", ClusterRole: "metrics-reader", Namespace: "ns",
+		ServiceAccountName: "other"}
+	if err := r.Ensure(ctx); err == nil {
+		t.Fatal("a foreign binding without the subject should be an error")
+	}
+}
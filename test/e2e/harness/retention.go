@@ -0,0 +1,20 @@
+package harness
+
+import (
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+)
+
+// PruneArtifacts applies the artifacts retention (artifacts.Prune) to dir before a run. It is
+// best-effort like every measurement side effect: failures are logged, never returned.
+func PruneArtifacts(dir string, opts artifacts.PruneOptions, l slo.Logger) {
+	if opts.MaxAge <= 0 && opts.MaxFiles <= 0 {
+		return
+	}
+	logger := slo.NewLogger(l)
+	res, err := artifacts.Prune(dir, opts)
+	if err != nil {
+		logger.Logf("artifacts retention: %v", err)
+	}
+	logger.Logf("artifacts retention: removed %d old file(s) from %s, kept %d", len(res.Removed), dir, res.Kept)
+}
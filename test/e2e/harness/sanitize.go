@@ -1,16 +1,10 @@
 package harness
 
-import "strings"
+import "github.com/yeongki/my-operator/pkg/slo/common/fsname"
 
-// SanitizeFilename makes a string safe-ish for filenames.
+// SanitizeFilename makes a string safe for one filename component on any OS: unsafe runes become
+// '_', the length is capped and a changed name gets a stable short hash so names do not collide
+// (see fsname.Sanitize). Blank input gives "na".
 func SanitizeFilename(s string) string {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return "na"
-	}
-	r := strings.NewReplacer(
-		"/", "_", "\\", "_", " ", "_", ":", "_", ";", "_",
-		"\"", "_", "'", "_", "\n", "_", "\r", "_", "\t", "_",
-	)
-	return r.Replace(s)
+	return fsname.Sanitize(s)
 }
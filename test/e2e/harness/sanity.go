@@ -0,0 +1,48 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/metricdrift"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+)
+
+// ScrapeOnce scrapes the metrics endpoint once through fns, the way measured specs do (the curl
+// pod is removed afterwards, also on interrupt), and returns the exposition body. Specs that check
+// the endpoint itself use it instead of driving the curl pod by hand.
+func ScrapeOnce(ctx context.Context, deps FetchDeps, fns CurlPodFns) (string, error) {
+	body, _, err := scrapeCurlPod(ctx, deps, fns, nil, time.Now())
+	return body, err
+}
+
+// ScrapeHead returns the start of a scrape for logging when a sanity check fails: at most n bytes,
+// cut after the last complete line when there is one.
+func ScrapeHead(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	head := text[:n]
+	if i := strings.LastIndexByte(head, '\n'); i > 0 {
+		return head[:i+1]
+	}
+	return head
+}
+
+// WriteMetricFamilies records the exposed families as metric-families.<run>.json in dir (the
+// baseline format of SLOLAB_METRICS_BASELINE and slocli drift) and adds it to the artifact index.
+func WriteMetricFamilies(dir, runID string, families []metricdrift.Family) (string, error) {
+	path := filepath.Join(dir, fmt.Sprintf("metric-families.%s.json", SanitizeFilename(runID)))
+	if err := artifacts.NewJSONWriter(artifacts.DefaultOptions()).WriteJSON(path, families); err != nil {
+		return "", fmt.Errorf("write metric families: %w", err)
+	}
+	err := artifacts.NewArtifactIndexWriter(dir, artifacts.DefaultOptions()).Add(
+		artifacts.IndexEntry{Path: path, Type: artifacts.TypeMetricFamilies, RunID: runID})
+	if err != nil {
+		return path, fmt.Errorf("index metric families: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,78 @@
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/metricdrift"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+)
+
+func TestScrapeOnce(t *testing.T) {
+	body := "# TYPE up gauge\nup 1\n"
+	var deleted []string
+	fns := CurlPodFns{
+		RunCurlMetricsOnce: func(_ context.Context, ns, token, svc, sa string) (string, error) {
+			if ns != "ns" || token != "tok" || svc != "svc" || sa != "sa" {
+				t.Errorf("unexpected args %s %s %s %s", ns, token, svc, sa)
+			}
+			return "curl-1", nil
+		},
+		WaitCurlMetricsDone: func(context.Context, string, string) error { return nil },
+		CurlMetricsLogs: func(context.Context, string, string) (string, error) {
+			return body + "\n# slo-scrape-trailer size_download=20 content_length=20\n", nil
+		},
+		DeletePodNoWait: func(_ context.Context, _, pod string) error {
+			deleted = append(deleted, pod)
+			return nil
+		},
+	}
+	deps := FetchDeps{Namespace: "ns", Token: "tok", MetricsServiceName: "svc", ServiceAccountName: "sa"}
+
+	got, err := ScrapeOnce(context.Background(), deps, fns)
+	if err != nil || got != body {
+		t.Fatalf("ScrapeOnce = %q, %v", got, err)
+	}
+	if len(deleted) != 1 || deleted[0] != "curl-1" {
+		t.Errorf("pod not deleted: %v", deleted)
+	}
+
+	fns.CurlMetricsLogs = func(context.Context, string, string) (string, error) { return "up 1", nil }
+	if _, err := ScrapeOnce(context.Background(), deps, fns); err == nil || !strings.Contains(err.Error(), "curl-1") {
+		t.Errorf("missing trailer should fail naming the pod, got %v", err)
+	}
+}
+
+func TestScrapeHead(t *testing.T) {
+	text := "a 1\nbb 2\nccc 3\n"
+	for n, want := range map[int]string{100: text, 9: "a 1\nbb 2\n", 6: "a 1\n", 2: "a "} {
+		if got := ScrapeHead(text, n); got != want {
+			t.Errorf("ScrapeHead(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestWriteMetricFamilies(t *testing.T) {
+	dir := t.TempDir()
+	families := []metricdrift.Family{{Name: "up", Type: "GAUGE"}}
+	path, err := WriteMetricFamilies(dir, "run/1", families)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []metricdrift.Family
+	if err := json.Unmarshal(b, &got); err != nil || len(got) != 1 || got[0].Name != "up" {
+		t.Fatalf("families file %s: %v %v", b, got, err)
+	}
+	idx, err := artifacts.NewArtifactIndexWriter(dir, artifacts.Options{}).Read()
+	if err != nil || len(idx.Entries) != 1 || idx.Entries[0].Type != artifacts.TypeMetricFamilies ||
+		idx.Entries[0].RunID != "run/1" {
+		t.Fatalf("index = %+v, %v", idx, err)
+	}
+}
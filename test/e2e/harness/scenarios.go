@@ -0,0 +1,147 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/onsi/ginkgo/v2"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slolab"
+)
+
+// Scenario is a measured scenario declared by a team: what it does (Run) and what is measured
+// while it runs (Presets, Metrics, Objectives), without editing the harness.
+type Scenario struct {
+	// Name identifies the scenario: the Ginkgo container text and the summary's test_case.
+	Name string
+	// Presets are preset names (presets.Names) measured during the scenario. Presets and Metrics
+	// both empty => DefaultV3Specs.
+	Presets []string
+	// Metrics are additional SLI definitions measured during the scenario.
+	Metrics []spec.SLISpec
+	// Objectives replace the judge rules of SLIs by ID (presets, Metrics or the defaults).
+	Objectives []slolab.Objective
+	// Run is the scenario body, measured between Start and End of the session window; an error
+	// fails the spec (the window is still measured). nil => an idle window.
+	Run func(ctx context.Context, sess *SessionV4) error
+}
+
+// Specs returns the SLI specs the scenario measures, with the same rules as a slolab.yaml
+// profile (slolab.Profile.Specs).
+func (s Scenario) Specs() ([]spec.SLISpec, error) {
+	p := slolab.Profile{Presets: s.Presets, Metrics: s.Metrics, Objectives: s.Objectives}
+	return p.Specs(DefaultV3Specs())
+}
+
+// ScenarioRegistry holds scenarios by name, in registration order. It is safe for concurrent use.
+type ScenarioRegistry struct {
+	mu    sync.Mutex
+	items map[string]registeredScenario
+	order []string
+}
+
+type registeredScenario struct {
+	Scenario
+	specs []spec.SLISpec
+}
+
+// NewScenarioRegistry returns an empty registry.
+func NewScenarioRegistry() *ScenarioRegistry {
+	return &ScenarioRegistry{items: map[string]registeredScenario{}}
+}
+
+// DefaultScenarios is the registry of RegisterScenario, run by the e2e suite's DescribeScenarios.
+var DefaultScenarios = NewScenarioRegistry()
+
+// RegisterScenario adds s to DefaultScenarios (typically from an init func of the team's test
+// package).
+func RegisterScenario(s Scenario) error {
+	return DefaultScenarios.Register(s)
+}
+
+// Register adds s. A scenario needs a unique name and specs that resolve (known presets, no
+// duplicate metric, objectives for measured SLIs only).
+func (r *ScenarioRegistry) Register(s Scenario) error {
+	if s.Name == "" {
+		return errors.New("scenario name is required")
+	}
+	specs, err := s.Specs()
+	if err != nil {
+		return fmt.Errorf("scenario %q: %w", s.Name, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.items[s.Name]; exists {
+		return fmt.Errorf("scenario already registered: %s", s.Name)
+	}
+	r.items[s.Name] = registeredScenario{Scenario: s, specs: specs}
+	r.order = append(r.order, s.Name)
+	return nil
+}
+
+// MustRegister is Register, panicking on error.
+func (r *ScenarioRegistry) MustRegister(s Scenario) {
+	if err := r.Register(s); err != nil {
+		panic(err)
+	}
+}
+
+// Get returns the named scenario.
+func (r *ScenarioRegistry) Get(name string) (Scenario, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.items[name]
+	return s.Scenario, ok
+}
+
+// List returns the scenarios in registration order.
+func (r *ScenarioRegistry) List() []Scenario {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Scenario, 0, len(r.order))
+	for _, n := range r.order {
+		out = append(out, r.items[n].Scenario)
+	}
+	return out
+}
+
+// DescribeScenarios adds one Ginkgo container per scenario of r (nil => DefaultScenarios), each
+// measuring its Run in a SessionV4 through the AttachV4 hooks. base returns the session config
+// of the environment (namespace, token, artifacts, ...) and is read when the spec runs; the
+// scenario sets TestCase and Specs. opts decide how End treats the summary (e.g. FailOnPolicy).
+func DescribeScenarios(r *ScenarioRegistry, base func() SessionV4Config, opts EndOptions) {
+	if r == nil {
+		r = DefaultScenarios
+	}
+	r.mu.Lock()
+	scenarios := make([]registeredScenario, 0, len(r.order))
+	for _, n := range r.order {
+		scenarios = append(scenarios, r.items[n])
+	}
+	r.mu.Unlock()
+
+	for _, sc := range scenarios {
+		ginkgo.Describe(sc.Name, func() {
+			var sess *SessionV4
+			attachSession("SLO("+sc.Name+")", func(context.Context) *SessionV4 {
+				cfg := base()
+				cfg.TestCase = sc.Name
+				cfg.Specs = sc.specs
+				sess = NewSessionV4(cfg)
+				return sess
+			}, func() EndOptions { return opts })
+
+			ginkgo.It("runs the measured scenario", func(ctx ginkgo.SpecContext) {
+				if sc.Run == nil {
+					return
+				}
+				if err := sc.Run(ctx, sess); err != nil {
+					ginkgo.Fail(fmt.Sprintf("scenario %s: %v", sc.Name, err))
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package harness
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slolab"
+)
+
+func TestScenarioRegistry(t *testing.T) {
+	r := NewScenarioRegistry()
+	churn := spec.SLISpec{
+		ID:      "cr_created_delta",
+		Inputs:  []spec.MetricRef{spec.PromMetric("my_operator_cr_created_total", nil)},
+		Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+	}
+	r.MustRegister(Scenario{
+		Name:    "churn",
+		Presets: []string{"workqueue"},
+		Metrics: []spec.SLISpec{churn},
+		Objectives: []slolab.Objective{{
+			SLI:   "cr_created_delta",
+			Rules: []spec.Rule{{Metric: "value", Op: spec.OpLE, Target: 100, Level: spec.LevelFail}},
+		}},
+	})
+	r.MustRegister(Scenario{Name: "idle"})
+
+	if got := r.List(); len(got) != 2 || got[0].Name != "churn" || got[1].Name != "idle" {
+		t.Fatalf("List = %+v", got)
+	}
+	sc, ok := r.Get("churn")
+	if !ok {
+		t.Fatal("churn not registered")
+	}
+	specs, err := sc.Specs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := specs[len(specs)-1]
+	if last.ID != "cr_created_delta" || last.Judge == nil || len(last.Judge.Rules) != 1 {
+		t.Errorf("the scenario metric with its objective must be measured, got %+v", last)
+	}
+	if idle, _ := r.Get("idle"); len(mustSpecs(t, idle)) != len(DefaultV3Specs()) {
+		t.Error("a scenario without presets or metrics measures the default specs")
+	}
+
+	for _, bad := range []Scenario{
+		{},
+		{Name: "idle"},
+		{Name: "typo", Presets: []string{"workqueues"}},
+		{Name: "orphan", Objectives: []slolab.Objective{{SLI: "missing"}}},
+	} {
+		if err := r.Register(bad); err == nil {
+			t.Errorf("Register(%+v) must fail", bad)
+		}
+	}
+	if err := r.Register(Scenario{Name: "idle"}); err == nil || !strings.Contains(err.Error(), "already") {
+		t.Errorf("duplicate name: %v", err)
+	}
+}
+
+func mustSpecs(t *testing.T, s Scenario) []spec.SLISpec {
+	t.Helper()
+	specs, err := s.Specs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return specs
+}
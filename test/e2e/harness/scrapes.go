@@ -0,0 +1,97 @@
+package harness
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// scrapeCapture writes the raw /metrics body of every scrape to dir (gzip'd), so a delta that
+// looks wrong can be checked against exactly what was scraped. Files are named
+// metrics-scrape.<run>.<test case>.<seq>-<phase>.prom.gz, phase being "start", the checkpoint
+// name or "end".
+type scrapeCapture struct {
+	dir      string
+	runID    string
+	testCase string
+
+	mu sync.Mutex
+	// started is the window start (a scrape at that time is the "start" one); checkpoint names the
+	// scrape a Checkpoint is taking.
+	started    time.Time
+	checkpoint string
+	seq        int
+}
+
+// newScrapeCapture returns nil (capture off) unless enabled with an artifacts dir.
+func newScrapeCapture(enabled bool, dir, runID, testCase string) *scrapeCapture {
+	if !enabled || dir == "" {
+		return nil
+	}
+	return &scrapeCapture{dir: dir, runID: runID, testCase: testCase}
+}
+
+// start begins a new window.
+func (c *scrapeCapture) start(at time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started, c.checkpoint = at, ""
+}
+
+// setCheckpoint names the following scrapes ("" => start/end by time).
+func (c *scrapeCapture) setCheckpoint(name string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkpoint = name
+}
+
+// save writes body as the scrape taken for at and returns the file path.
+func (c *scrapeCapture) save(at time.Time, body string) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	c.mu.Lock()
+	c.seq++
+	phase := "end"
+	switch {
+	case c.checkpoint != "":
+		phase = c.checkpoint
+	case at.Equal(c.started):
+		phase = "start"
+	}
+	name := fmt.Sprintf("metrics-scrape.%s.%s.%02d-%s.prom.gz",
+		SanitizeFilename(c.runID), SanitizeFilename(c.testCase), c.seq, SanitizeFilename(phase))
+	c.mu.Unlock()
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(c.dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	zw := gzip.NewWriter(f)
+	zw.Name = name[:len(name)-len(".gz")]
+	zw.ModTime = at
+	_, err = zw.Write([]byte(body))
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("capture scrape %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,54 @@
+package harness
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScrapeCapture(t *testing.T) {
+	if c := newScrapeCapture(true, "", "run", "case"); c != nil {
+		t.Fatal("expected no capture without an artifacts dir")
+	}
+
+	dir := t.TempDir()
+	c := newScrapeCapture(true, dir, "run-1", "my case")
+	started := time.Now()
+	c.start(started)
+	c.setCheckpoint("CR applied")
+	if _, err := c.save(started.Add(time.Second), "checkpoint_body 1\n"); err != nil {
+		t.Fatal(err)
+	}
+	c.setCheckpoint("")
+	if _, err := c.save(started, "start_body 1\n"); err != nil {
+		t.Fatal(err)
+	}
+	path, err := c.save(started.Add(2*time.Second), "end_body 1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names, _ := filepath.Glob(filepath.Join(dir, "metrics-scrape.*.prom.gz"))
+	if len(names) != 3 {
+		t.Fatalf("expected 3 captured scrapes, got %v", names)
+	}
+	if base := filepath.Base(path); base != "metrics-scrape.run-1."+SanitizeFilename("my case")+".03-end.prom.gz" {
+		t.Fatalf("unexpected name %q", base)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if string(body) != "end_body 1\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
@@ -1,46 +1,174 @@
 package harness
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/yeongki/my-operator/pkg/kubeutil"
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/clock"
 	"github.com/yeongki/my-operator/pkg/slo/engine"
 	"github.com/yeongki/my-operator/pkg/slo/fetch"
-	"github.com/yeongki/my-operator/pkg/slo/fetch/promtext"
+	"github.com/yeongki/my-operator/pkg/slo/presets"
+	"github.com/yeongki/my-operator/pkg/slo/replay"
+	"github.com/yeongki/my-operator/pkg/slo/runid"
 	"github.com/yeongki/my-operator/pkg/slo/spec"
 	"github.com/yeongki/my-operator/pkg/slo/summary"
 	"github.com/yeongki/my-operator/pkg/slo/tags"
 	"github.com/yeongki/my-operator/test/e2e/curlmetrics"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
 )
 
+// ErrPolicyFailed is returned by End when EndOptions.FailOnPolicy is set
+// and at least one SLI was judged as fail.
+// It is classified as slo.KindPolicy (errors.Is(err, slo.ErrPolicy) holds too).
+var ErrPolicyFailed = &slo.Error{Kind: slo.KindPolicy, Err: errors.New("slo policy failed")}
+
+// EndOptions tunes how End treats the evaluated summary.
+type EndOptions struct {
+	// FailOnPolicy turns recorded rule failures into ErrPolicyFailed.
+	// Measurement failures (skip) never trigger it.
+	FailOnPolicy bool
+}
+
 // SessionV4Config contains v4 session inputs and defaults.
 type SessionV4Config struct {
 	Namespace          string
 	MetricsServiceName string
+	// MetricsEndpoint is how the curl pod reaches the metrics service (zero => https:8443/metrics,
+	// certificate not verified).
+	MetricsEndpoint    curlmetrics.Endpoint
 	TestCase           string
 	Suite              string
-	RunID              string
+	RunID              string // empty => runid.Resolve (CI run or ULID, one per process)
 	ServiceAccountName string
 	Token              string
 	ArtifactsDir       string
+	UploadURL          string
+	UploadPolicy       summary.RedactPolicy
+	OTLPEndpoint       string
 	Tags               map[string]string
-	Now                func() time.Time
+	// Now tells the time when Clock is nil (nil => wall clock).
+	Now func() time.Time
+	// Clock times the window (Start, Checkpoint, End/Abort) and the waits recorded into the session;
+	// a clock.Fake makes durations exact in unit tests.
+	Clock clock.Clock
 
 	Specs   []spec.SLISpec
 	Fetcher fetch.MetricsFetcher
+
+	// Chaos kills a controller pod mid-window (optional). Its Namespace defaults to Namespace.
+	Chaos *PodKillChaos
+
+	// BundleDir records each evaluation's inputs for `slocli replay` (optional).
+	BundleDir string
+
+	// CaptureScrapes writes each raw curl-pod /metrics body to ArtifactsDir (gzip'd, named by
+	// run/test case/phase) next to the summary, to debug a surprising delta.
+	CaptureScrapes bool
+
+	// ResultLine prints each summary as one SLOLAB_RESULT JSON line on stdout (also without
+	// ArtifactsDir), for CI that only keeps logs (artifacts.ResultLineWriter). UploadPolicy applies.
+	ResultLine bool
+	// Compress gzips replay bundle sessions (and, through AttachV4, failure dumps).
+	Compress bool
+
+	// MetricFilter (optional) keeps only the metrics the specs read plus its Include extras, minus
+	// its Exclude, in every snapshot (and replay bundle). An invalid Exclude is a warning and the
+	// session keeps everything.
+	MetricFilter *fetch.MetricFilter
+
+	// CurlImage is the curl pod image (empty => curlmetrics.DefaultImage), pulled with
+	// CurlImagePullPolicy ("" => cluster default) and CurlImagePullSecrets, e.g. from a registry
+	// mirror (curlmetrics.MirrorImage) on an air-gapped cluster.
+	CurlImage            string
+	CurlImagePullPolicy  string
+	CurlImagePullSecrets []string
+	// CurlRunAsUser / CurlRunAsUserFromNamespace set the curl container's UID (see
+	// curlmetrics.Client.RunAsUser; OpenShift: from the namespace's UID range).
+	CurlRunAsUser              int64
+	CurlRunAsUserFromNamespace bool
+	// CurlScheduling sets the curl pod's resources, priority class and placement (nil =>
+	// curlmetrics.DefaultScheduling), so it is not evicted or left pending on a busy cluster.
+	CurlScheduling *curlmetrics.PodScheduling
+
+	// Load churns objects during the window (optional, e.g. *load.Generator).
+	// Its report is attached to the summary and normalizes delta counters per object.
+	Load LoadGenerator
+
+	// PeakInterval polls the metrics this often during the window, so gauges computed with
+	// spec.ComputeMax (e.g. workqueue_depth_max) see peaks between the edges (0 => edges only).
+	// Polls are counted in Summary.Measurement like the edge fetches.
+	PeakInterval time.Duration
+
+	// Events adds the kube-events preset, fed by the Events of Namespace (EventsFetcher), so
+	// scheduler and kubelet delays show up next to the operator metrics.
+	Events bool
+
+	// Hooks enrich the start/end snapshots and the summary before it is written, e.g. tag the
+	// cluster version (engine.Hooks, run in order, after the build provenance of buildinfo.Resolve
+	// is stamped). Aborted summaries get OnResult only.
+	Hooks []engine.Hooks
+}
+
+// LoadGenerator applies synthetic load between Start and End of a session.
+type LoadGenerator interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) summary.LoadReport
+}
+
+// SessionState is the lifecycle state of a SessionV4.
+type SessionState int
+
+const (
+	SessionNotStarted SessionState = iota
+	SessionStarted
+	SessionEnded // End or Abort completed; Start begins a new window
+)
+
+func (st SessionState) String() string {
+	switch st {
+	case SessionNotStarted:
+		return "not-started"
+	case SessionStarted:
+		return "started"
+	case SessionEnded:
+		return "ended"
+	}
+	return fmt.Sprintf("SessionState(%d)", int(st))
 }
 
 // SessionV4 holds v4 runtime state.
+//
+// Concurrency: `ginkgo -p` runs specs in separate processes, so parallel specs never share a
+// session; give each concurrently running window its own session (one per Ordered container or
+// per spec). Within one session, Start/End/Abort are serialized (a concurrent End waits and gets
+// the first result), and AddWarning, AddDisruption, AddConvergence, SetTag and State may be called
+// from any goroutine, e.g. from scenario helpers or watchers running next to the spec. Read Warnings
+// and Tags directly only when no other goroutine uses the session (or use WarningsSnapshot).
 type SessionV4 struct {
 	Config SessionV4Config
 
-	MetricsPort      int
+	// MetricsPort is informational (Config.MetricsEndpoint decides the scrape).
+	MetricsPort int
+	// ServiceURLFormat overrides the scraped URL (service, namespace), e.g. for a port-forward.
 	ServiceURLFormat string
-	CurlImage        string
+	// CurlImage* start from the config's (see SessionV4Config.CurlImage).
+	CurlImage                  string
+	CurlImagePullPolicy        string
+	CurlImagePullSecrets       []string
+	CurlRunAsUser              int64
+	CurlRunAsUserFromNamespace bool
 
 	ScrapeTimeout      time.Duration
 	WaitPodDoneTimeout time.Duration
@@ -53,21 +181,46 @@ type SessionV4 struct {
 
 	specs   []spec.SLISpec
 	fetcher fetch.MetricsFetcher
-	writer  summary.Writer
-	started time.Time
+	// eventsRunner runs kubectl for the Events series (nil => kubeutil.DefaultRunner).
+	eventsRunner kubeutil.CmdRunner
+	writer       summary.Writer
+	clock        clock.Clock
+	started      time.Time
+	// start is the snapshot taken by Start (startErr when it failed).
+	start    fetch.Sample
+	startErr error
+	stats    *fetch.Stats // fetches of the current window (summary.Measurement)
+	chaos    *chaosRun
+	loading  bool
+	// peaks polls the window when Config.PeakInterval is set; stopped by End/Abort.
+	peaks *fetch.PeakTracker
+
+	// opMu serializes Start/Checkpoint/End/Abort/SetSpecs; it guards specs, metricFilter, started,
+	// start, stats, chaos, loading, peaks, checkpoints, endSum and endErr.
+	opMu sync.Mutex
+	// mu guards Warnings, Tags, disruptions, convergences, waits and state (held only briefly, never
+	// across I/O).
+	mu sync.Mutex
+
+	disruptions  []summary.Disruption
+	convergences []summary.Convergence
+	waits        []summary.Wait
+	checkpoints  []checkpoint
+
+	// scrapes is nil unless Config.CaptureScrapes.
+	scrapes *scrapeCapture
+	// metricFilter is Config.MetricFilter completed with the specs' metrics (nil => keep all).
+	metricFilter *fetch.MetricFilter
+
+	state SessionState
+	// endSum/endErr are the result of the last End/Abort, returned again on repeated calls.
+	endSum *summary.Summary
+	endErr error
 }
 
 // NewSessionV4 builds a session with defaults applied.
 func NewSessionV4(cfg SessionV4Config) *SessionV4 {
-	now := cfg.Now
-	if now == nil {
-		now = time.Now
-	}
-
-	runID := cfg.RunID
-	if strings.TrimSpace(runID) == "" {
-		runID = fmt.Sprintf("local-%d", now().Unix())
-	}
+	runID := runid.Resolve(cfg.RunID)
 
 	autoTags := tags.AutoTagsV4(tags.AutoTagsV4Input{
 		Suite:     cfg.Suite,
@@ -77,21 +230,72 @@ func NewSessionV4(cfg SessionV4Config) *SessionV4 {
 	})
 
 	mergedTags := tags.MergeTagsV4(cfg.Tags, autoTags)
+	specs, filter, err := resolveSpecs(cfg, cfg.Specs)
+	var warnings []string
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
 
 	return &SessionV4{
 		Config:             cfg,
-		MetricsPort:        8443,
-		ServiceURLFormat:   "https://%s.%s.svc:8443/metrics",
-		CurlImage:          "curlimages/curl:latest",
+		MetricsPort:        cfg.MetricsEndpoint.WithDefaults().Port,
 		ScrapeTimeout:      2 * time.Minute,
 		WaitPodDoneTimeout: 5 * time.Minute,
 		LogsTimeout:        2 * time.Minute,
 		RunID:              runID,
 		Tags:               mergedTags,
-		specs:              defaultSpecsV4(cfg.Specs),
+		Warnings:           warnings,
+		specs:              specs,
 		fetcher:            cfg.Fetcher,
-		writer:             summary.NewJSONFileWriter(),
+		writer:             newSummaryWriterV4(cfg),
+		scrapes:            newScrapeCapture(cfg.CaptureScrapes, cfg.ArtifactsDir, runID, cfg.TestCase),
+		metricFilter:       filter,
+		clock:              clock.Or(cfg.Clock, cfg.Now),
+
+		CurlImage:                  cmp.Or(cfg.CurlImage, curlmetrics.DefaultImage),
+		CurlImagePullPolicy:        cfg.CurlImagePullPolicy,
+		CurlImagePullSecrets:       cfg.CurlImagePullSecrets,
+		CurlRunAsUser:              cfg.CurlRunAsUser,
+		CurlRunAsUserFromNamespace: cfg.CurlRunAsUserFromNamespace,
+	}
+}
+
+// resolveSpecs returns the specs a session measures (nil => DefaultV3Specs, plus the kube-events
+// preset when cfg.Events) and cfg.MetricFilter completed with their metrics. An invalid filter is
+// returned as error and keeps every metric.
+func resolveSpecs(cfg SessionV4Config, specs []spec.SLISpec) ([]spec.SLISpec, *fetch.MetricFilter, error) {
+	specs = withEventSpecs(defaultSpecsV4(specs), cfg.Events)
+	if cfg.MetricFilter == nil {
+		return specs, nil, nil
+	}
+	f := *cfg.MetricFilter
+	f.Include = append(spec.MetricNames(specs), f.Include...)
+	if err := f.Validate(); err != nil {
+		return specs, nil, fmt.Errorf("metric filter ignored: %w", err)
 	}
+	return specs, &f, nil
+}
+
+// SetSpecs replaces the specs measured from the next window on (nil => DefaultV3Specs), e.g. per
+// spec by AttachV4Config.SpecsFor. On a started session it is ignored (with a warning).
+func (s *SessionV4) SetSpecs(specs []spec.SLISpec) {
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+	if s.State() == SessionStarted {
+		s.misuse("SetSpecs called on a started session; ignored")
+		return
+	}
+	specs, filter, err := resolveSpecs(s.Config, specs)
+	if err != nil {
+		s.AddWarning(err.Error())
+	}
+	s.specs, s.metricFilter = specs, filter
+}
+
+func newSummaryWriterV4(cfg SessionV4Config) summary.Writer {
+	w := artifacts.NewIndexedSummaryWriter(cfg.ArtifactsDir, artifacts.DefaultOptions())
+	up := withOTLP(withUpload(w, cfg.UploadURL, cfg.UploadPolicy), cfg.OTLPEndpoint, cfg.UploadPolicy)
+	return withResultLine(up, cfg.ResultLine, cfg.UploadPolicy)
 }
 
 // ShouldWriteArtifacts reports whether v4 should write summary output.
@@ -124,53 +328,488 @@ func (s *SessionV4) AddWarning(message string) {
 	if message == "" {
 		return
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.Warnings = append(s.Warnings, message)
 }
 
-// Start begins v4 measurement.
-func (s *SessionV4) Start() {
-	s.started = time.Now()
+// WarningsSnapshot returns a copy of the recorded warnings.
+func (s *SessionV4) WarningsSnapshot() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.Warnings)
 }
 
-// End completes v4 measurement.
-func (s *SessionV4) End(ctx context.Context) (*summary.Summary, error) {
+// AddDisruption records a disruption observed during the window (e.g. by UpgradeScenario).
+func (s *SessionV4) AddDisruption(d summary.Disruption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disruptions = append(s.disruptions, d)
+}
+
+// AddConvergence records a convergence observed during the window (e.g. by watchconv.Watcher).
+func (s *SessionV4) AddConvergence(c summary.Convergence) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.convergences = append(s.convergences, c)
+}
+
+// AddWait records a polling wait of the spec (e.g. by EventuallySLO).
+func (s *SessionV4) AddWait(w summary.Wait) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waits = append(s.waits, w)
+}
+
+// SetTag sets a summary tag; it applies to the summaries written from now on.
+func (s *SessionV4) SetTag(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Tags == nil {
+		s.Tags = map[string]string{}
+	}
+	s.Tags[key] = value
+}
+
+// State returns the lifecycle state.
+func (s *SessionV4) State() SessionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+func (s *SessionV4) setState(st SessionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = st
+}
+
+// records are what was added to a session during the window, besides the snapshots.
+type records struct {
+	tags         map[string]string
+	disruptions  []summary.Disruption
+	convergences []summary.Convergence
+	waits        []summary.Wait
+}
+
+// windowRecords snapshots the tags, disruptions, convergences and waits added so far.
+func (s *SessionV4) windowRecords() records {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return records{
+		tags:         maps.Clone(s.Tags),
+		disruptions:  slices.Clone(s.disruptions),
+		convergences: slices.Clone(s.convergences),
+		waits:        slices.Clone(s.waits),
+	}
+}
+
+// misuse records a lifecycle misuse as a warning instead of failing or writing twice.
+func (s *SessionV4) misuse(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	s.AddWarning(msg)
+	slo.Warnf(e2eutil.GinkgoLog, "SLO(v4): %s", msg)
+}
+
+// Start begins v4 measurement: it takes the start snapshot, then starts the load and schedules the
+// chaos, so both land inside the window. A failed snapshot skips every result of the window.
+// Start on a started session is ignored (with a warning); after End/Abort it begins a new window.
+// ctx bounds the snapshot only: the load and chaos run until End/Abort, so ctx may be a
+// BeforeEach context.
+func (s *SessionV4) Start(ctx context.Context) {
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+	if s.State() == SessionStarted {
+		s.misuse("Start called on a started session; ignored")
+		return
+	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	finished := time.Now()
+	s.setState(SessionStarted)
+	s.endSum, s.endErr = nil, nil
+	s.checkpoints = nil
+	s.stats = &fetch.Stats{}
+	s.started = s.clock.Now()
+	s.scrapes.start(s.started)
+	background := context.WithoutCancel(ctx)
+	s.peaks = nil
+	if s.Config.PeakInterval > 0 {
+		tracker := &fetch.PeakTracker{Inner: s.liveFetcher(), Interval: s.Config.PeakInterval, Clock: s.clock}
+		tracker.Start(background)
+		s.peaks = tracker
+	}
+	s.start, s.startErr = s.liveFetcher().Fetch(ctx, s.started)
+	if s.startErr != nil {
+		slo.Warnf(e2eutil.GinkgoLog, "SLO(v4): start snapshot failed: %v", s.startErr)
+	}
 
-	fetcher := s.fetcher
-	if fetcher == nil {
-		fetcher = newCurlPodFetcherV4(s)
+	if c := s.Config.Chaos; c != nil {
+		chaos := *c
+		chaos.Namespace = cmp.Or(chaos.Namespace, s.Config.Namespace)
+		s.chaos = chaos.start(background)
 	}
+	if s.Config.Load != nil {
+		if err := s.Config.Load.Start(background); err != nil {
+			slo.Warnf(e2eutil.GinkgoLog, "SLO(v4): load skipped: %v", err)
+		} else {
+			s.loading = true
+		}
+	}
+}
 
-	eng := engine.New(fetcher, s.writer, nil)
-	outPath := ""
-	if s.ShouldWriteArtifacts() {
-		filename := fmt.Sprintf(
-			"sli-summary.v3.%s.%s.json",
-			SanitizeFilename(s.RunID),
-			SanitizeFilename(s.Config.TestCase),
-		)
-		path, err := s.NextSummaryPath(filename)
+// checkpoint is a snapshot taken when a named phase ended.
+type checkpoint struct {
+	name   string
+	sample fetch.Sample
+}
+
+// Checkpoint marks the end of phase name (e.g. "CR applied", "status Ready", "deletion complete")
+// by taking a snapshot now. End then also evaluates the specs per phase: [Start, first checkpoint],
+// ..., [last checkpoint, End] (named "end"), recorded in Summary.Phases with their durations.
+// A failed snapshot is a warning: that phase merges into the next one.
+func (s *SessionV4) Checkpoint(ctx context.Context, name string) {
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+	if s.State() != SessionStarted {
+		s.misuse("Checkpoint %q called on a session that is not started; ignored", name)
+		return
+	}
+	s.scrapes.setCheckpoint(name)
+	sample, err := s.liveFetcher().Fetch(ctx, s.clock.Now())
+	s.scrapes.setCheckpoint("")
+	if err != nil {
+		s.AddWarning(fmt.Sprintf("checkpoint %q skipped: %v", name, err))
+		return
+	}
+	s.checkpoints = append(s.checkpoints, checkpoint{name: name, sample: sample})
+}
+
+// liveFetcher is the configured fetcher or the curl pod scraper, merged with the Events series
+// when Config.Events is set and narrowed by the metric filter. While a window is polled for
+// peaks, it is the tracker, so the snapshots count towards the peaks too.
+func (s *SessionV4) liveFetcher() fetch.MetricsFetcher {
+	if s.peaks != nil {
+		return s.peaks
+	}
+	f := s.fetcher
+	if f == nil {
+		f = newCurlPodFetcherV4(s)
+	}
+	if s.Config.Events {
+		f = fetch.Merge(f, EventsFetcher{Namespace: s.Config.Namespace, Since: s.started, Runner: s.eventsRunner})
+	}
+	if s.metricFilter != nil {
+		// validated in NewSessionV4
+		f, _ = fetch.FilterMetrics(f, *s.metricFilter)
+	}
+	return fetch.CountFetches(f, s.stats, &suiteStats)
+}
+
+// evaluatePhases takes the window end snapshot and evaluates every phase between consecutive
+// snapshots, from the one taken by Start. The returned fetcher replays the edges for the
+// whole-window evaluation.
+func (s *SessionV4) evaluatePhases(
+	ctx context.Context, fetcher fetch.MetricsFetcher, finished time.Time,
+) ([]summary.Phase, fetch.MetricsFetcher, error) {
+	if s.startErr != nil {
+		return nil, nil, s.startErr
+	}
+	end, err := fetcher.Fetch(ctx, finished)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bounds := append([]checkpoint{{sample: s.start}}, s.checkpoints...)
+	bounds = append(bounds, checkpoint{name: "end", sample: end})
+	phases := make([]summary.Phase, 0, len(bounds)-1)
+	for i := 1; i < len(bounds); i++ {
+		from, to := bounds[i-1].sample, bounds[i].sample
+		eng := engine.New(samplePair{startAt: from.At, start: from, end: to}, noopWriter{}, nil)
+		sum, err := eng.Execute(ctx, engine.ExecuteRequest{
+			Config: engine.RunConfig{StartedAt: from.At, FinishedAt: to.At},
+			Specs:  s.specs,
+		})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		outPath = path
+		phases = append(phases, summary.Phase{
+			Name:            bounds[i].name,
+			StartedAt:       from.At,
+			FinishedAt:      to.At,
+			DurationSeconds: to.At.Sub(from.At).Seconds(),
+			Results:         sum.Results,
+			Warnings:        sum.Warnings,
+		})
+	}
+	var edges fetch.MetricsFetcher = samplePair{startAt: s.started, start: s.start, end: end}
+	if s.peaks != nil {
+		edges = withPeaks{edges, s.peaks}
 	}
+	return phases, edges, nil
+}
 
-	return engine.ExecuteV4(ctx, eng, engine.ExecuteRequestV4{
-		Method: engine.InsideSnapshot,
-		Config: engine.RunConfig{
+// samplePair replays two already taken samples: start when fetched at startAt, end otherwise.
+type samplePair struct {
+	startAt    time.Time
+	start, end fetch.Sample
+}
+
+func (p samplePair) Fetch(_ context.Context, at time.Time) (fetch.Sample, error) {
+	if at.Equal(p.startAt) {
+		return p.start, nil
+	}
+	return p.end, nil
+}
+
+// windowFetcher serves the snapshot taken by Start (or its error) for the window start and
+// fetches live otherwise.
+type windowFetcher struct {
+	startAt  time.Time
+	start    fetch.Sample
+	startErr error
+	live     fetch.MetricsFetcher
+}
+
+func (w windowFetcher) Fetch(ctx context.Context, at time.Time) (fetch.Sample, error) {
+	if at.Equal(w.startAt) {
+		return w.start, w.startErr
+	}
+	return w.live.Fetch(ctx, at)
+}
+
+func (w windowFetcher) Unwrap() fetch.MetricsFetcher { return w.live }
+
+// withPeaks replays the window edges with the peaks polled in between.
+type withPeaks struct {
+	fetch.MetricsFetcher
+	fetch.PeakSource
+}
+
+// stopPeaks ends the polling; the end snapshot still counts towards the peaks.
+func (s *SessionV4) stopPeaks() {
+	if s.peaks != nil {
+		s.peaks.Stop()
+	}
+}
+
+// finishLoad stops the load generator, if one is running, and returns its report.
+func (s *SessionV4) finishLoad(ctx context.Context) *summary.LoadReport {
+	if !s.loading {
+		return nil
+	}
+	s.loading = false
+	report := s.Config.Load.Stop(ctx)
+	return &report
+}
+
+// finishChaos cancels a pending pod kill or waits for the recovery of an injected one.
+// Errors other than ErrChaosNotRecovered (e.g. no pod to kill) are measurement problems:
+// they are logged and do not fail the spec.
+func (s *SessionV4) finishChaos(ctx context.Context) ([]summary.Disruption, error) {
+	if s.chaos == nil {
+		return nil, nil
+	}
+	run := s.chaos
+	s.chaos = nil
+
+	d, err := run.finish(ctx)
+	var out []summary.Disruption
+	if d != nil {
+		out = append(out, *d)
+	}
+	if err != nil && !errors.Is(err, ErrChaosNotRecovered) {
+		slo.Warnf(e2eutil.GinkgoLog, "SLO(v4): chaos skipped: %v", err)
+		err = nil
+	}
+	return out, err
+}
+
+// End completes v4 measurement.
+// The summary is always returned when it was produced, even together with ErrPolicyFailed
+// or ErrChaosNotRecovered. End is idempotent: a repeated call returns the first result without
+// writing again, and End without Start writes skip results (both recorded as warnings).
+func (s *SessionV4) End(ctx context.Context, opts ...EndOptions) (*summary.Summary, error) {
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+	switch s.State() {
+	case SessionEnded:
+		s.misuse("End called on an ended session; returning the first result")
+		return s.endSum, s.endErr
+	case SessionNotStarted:
+		s.misuse("End called before Start")
+		return s.abort(ctx, "End called before Start")
+	}
+	s.endSum, s.endErr = s.end(ctx, opts)
+	s.setState(SessionEnded)
+	return s.endSum, s.endErr
+}
+
+// Abort ends the window without measuring: every spec gets a skip result with reason, which is
+// written like a regular summary (e.g. for an interrupted spec). Load and chaos are stopped.
+func (s *SessionV4) Abort(ctx context.Context, reason string) (*summary.Summary, error) {
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+	return s.abort(ctx, reason)
+}
+
+func (s *SessionV4) abort(ctx context.Context, reason string) (*summary.Summary, error) {
+	state := s.State()
+	if state == SessionEnded {
+		s.misuse("Abort called on an ended session; ignored")
+		return s.endSum, s.endErr
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	s.stopPeaks()
+	loadReport := s.finishLoad(ctx)
+	if s.chaos != nil {
+		// don't wait for the recovery of an injected kill
+		s.chaos.cancel()
+	}
+	chaosDisruptions, _ := s.finishChaos(ctx)
+	finished := s.clock.Now()
+	started := s.started
+	if state == SessionNotStarted {
+		started = finished
+	}
+	rec := s.windowRecords()
+
+	sum := &summary.Summary{
+		SchemaVersion: summary.CurrentSchemaVersion,
+		GeneratedAt:   finished,
+		Config: summary.RunConfig{
 			RunID:      s.RunID,
-			StartedAt:  s.started,
+			StartedAt:  started,
 			FinishedAt: finished,
+			Tags:       rec.tags,
 			Format:     "v4",
-			Tags:       s.Tags,
+		},
+		Results:      make([]summary.SLIResult, 0, len(s.specs)),
+		Warnings:     append(s.WarningsSnapshot(), "session aborted: "+reason),
+		Disruptions:  append(rec.disruptions, chaosDisruptions...),
+		Load:         loadReport,
+		Convergences: rec.convergences,
+		Waits:        rec.waits,
+	}
+	for _, sp := range s.specs {
+		sum.Results = append(sum.Results, summary.SLIResult{
+			ID:          sp.ID,
+			Title:       sp.Title,
+			Unit:        sp.Unit,
+			Kind:        sp.Kind,
+			Description: sp.Description,
+			Owner:       sp.Owner,
+			Category:    sp.Category,
+			Status:      summary.StatusSkip,
+			Reason:      "aborted: " + reason,
+		})
+	}
+
+	engine.Chain(withBuild(withMeasurement(s.stats, s.Config.Hooks))).Result(ctx, sum)
+	var err error
+	if path, perr := s.summaryPath(); perr != nil {
+		err = perr
+	} else {
+		// path "" (artifacts off) still reaches the exporters, as for End
+		err = s.writer.Write(path, *sum)
+	}
+	s.setState(SessionEnded)
+	s.endSum, s.endErr = sum, err
+	return sum, err
+}
+
+// summaryPath returns the next free summary path ("" when artifacts are off).
+func (s *SessionV4) summaryPath() (string, error) {
+	if !s.ShouldWriteArtifacts() {
+		return "", nil
+	}
+	return s.NextSummaryPath(fmt.Sprintf(
+		"sli-summary.v3.%s.%s.json",
+		SanitizeFilename(s.RunID),
+		SanitizeFilename(s.Config.TestCase),
+	))
+}
+
+func (s *SessionV4) end(ctx context.Context, opts []EndOptions) (*summary.Summary, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// the end snapshot must be taken after the load stopped and the system recovered from injected
+	// chaos, so the window (from the snapshot taken by Start) holds both
+	s.stopPeaks()
+	loadReport := s.finishLoad(ctx)
+	chaos := s.chaos
+	chaosDisruptions, chaosErr := s.finishChaos(ctx)
+	rec := s.windowRecords()
+	finished := s.clock.Now()
+
+	live := s.liveFetcher()
+	var fetcher fetch.MetricsFetcher = windowFetcher{startAt: s.started, start: s.start, startErr: s.startErr, live: live}
+	var phases []summary.Phase
+	if len(s.checkpoints) > 0 {
+		p, edges, err := s.evaluatePhases(ctx, live, finished)
+		if err != nil {
+			s.AddWarning(fmt.Sprintf("phases skipped: %v", err))
+		} else {
+			phases, fetcher = p, edges
+		}
+	}
+	var capture *replay.CaptureFetcher
+	if strings.TrimSpace(s.Config.BundleDir) != "" {
+		capture = &replay.CaptureFetcher{Inner: fetcher}
+		fetcher = capture
+	}
+
+	eng := engine.New(fetcher, s.writer, nil).Use(withBuild(withMeasurement(s.stats, s.Config.Hooks))...)
+	outPath, err := s.summaryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	sum, err := engine.ExecuteV4(ctx, eng, engine.ExecuteRequestV4{
+		Method: engine.InsideSnapshot,
+		Config: engine.RunConfig{
+			RunID:        s.RunID,
+			StartedAt:    s.started,
+			FinishedAt:   finished,
+			Format:       "v4",
+			Tags:         rec.tags,
+			Disruptions:  append(rec.disruptions, chaosDisruptions...),
+			Load:         loadReport,
+			Phases:       phases,
+			Convergences: rec.convergences,
+			Waits:        rec.waits,
 		},
 		Specs:   s.specs,
 		OutPath: outPath,
 	})
+	if err != nil {
+		return sum, err
+	}
+	if chaosErr == nil && len(chaosDisruptions) > 0 {
+		chaosErr = chaos.chaos.checkRecoverySLI(sum)
+	}
+	recorder := replay.NewRecorder(s.Config.BundleDir, artifactOptions(s.Config.Compress))
+	return sum, errors.Join(checkPolicy(sum, opts), chaosErr, recordBundle(recorder, capture, s.specs, sum))
+}
+
+func checkPolicy(sum *summary.Summary, opts []EndOptions) error {
+	failOnPolicy := false
+	for _, o := range opts {
+		failOnPolicy = failOnPolicy || o.FailOnPolicy
+	}
+	if !failOnPolicy || sum == nil || !sum.HasPolicyFailures() {
+		return nil
+	}
+	ids := make([]string, 0)
+	for _, r := range sum.Results {
+		if r.Status == summary.StatusFail {
+			ids = append(ids, r.ID)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrPolicyFailed, strings.Join(ids, ", "))
 }
 
 type curlPodFetcherV4 struct {
@@ -178,8 +817,9 @@ type curlPodFetcherV4 struct {
 	pod     *curlmetrics.CurlPodV4
 }
 
+// newCurlPodFetcherV4 scrapes through a curl pod, scraping again when the body looks truncated.
 func newCurlPodFetcherV4(session *SessionV4) fetch.MetricsFetcher {
-	return &curlPodFetcherV4{
+	return fetch.RetryTruncated(&curlPodFetcherV4{
 		session: session,
 		pod: &curlmetrics.CurlPodV4{
 			Namespace:          session.Config.Namespace,
@@ -187,46 +827,46 @@ func newCurlPodFetcherV4(session *SessionV4) fetch.MetricsFetcher {
 			ServiceAccountName: session.Config.ServiceAccountName,
 			Token:              session.Config.Token,
 			Image:              session.CurlImage,
+			ImagePullPolicy:    session.CurlImagePullPolicy,
+			ImagePullSecrets:   session.CurlImagePullSecrets,
+			RunAsUser:          session.CurlRunAsUser,
+			Endpoint:           session.Config.MetricsEndpoint.WithDefaults(),
 			ServiceURLFormat:   session.ServiceURLFormat,
+
+			RunAsUserFromNamespace: session.CurlRunAsUserFromNamespace,
+			Scheduling:             session.Config.CurlScheduling,
 		},
-	}
+	}, fetch.TruncationOptions{Logger: e2eutil.GinkgoLog})
 }
 
 func (f *curlPodFetcherV4) Fetch(ctx context.Context, at time.Time) (fetch.Sample, error) {
 	podCtx, cancel := context.WithTimeout(ctx, f.session.ScrapeTimeout)
 	defer cancel()
 
-	raw, err := f.pod.Run(podCtx, f.session.WaitPodDoneTimeout, f.session.LogsTimeout)
+	res, err := f.pod.RunDetailed(podCtx, f.session.WaitPodDoneTimeout, f.session.LogsTimeout)
 	if err != nil {
 		return fetch.Sample{}, err
 	}
-
-	values, err := parsePrometheusTextV4(raw)
+	if _, err := f.session.scrapes.save(at, res.Logs); err != nil {
+		f.session.AddWarning(err.Error())
+	}
+	body, err := curlmetrics.SplitScrape(res.Logs)
 	if err != nil {
-		return fetch.Sample{}, err
+		return fetch.Sample{}, fmt.Errorf("curl pod %s: %w", res.PodName, err)
 	}
 
-	return fetch.Sample{
-		At:     at,
-		Values: values,
-	}, nil
+	return fetch.SampleFromText(at, body, &fetch.Provenance{
+		Fetcher: "curl-pod",
+		Target:  res.URL,
+		Via:     f.session.Config.Namespace + "/" + res.PodName,
+	})
 }
 
-func parsePrometheusTextV4(raw string) (map[string]float64, error) {
-	base, err := promtext.ParseTextToMap(strings.NewReader(raw))
-	if err != nil {
-		return nil, err
-	}
-
-	out := map[string]float64{}
-	for key, val := range base {
-		out[key] = val
-		if idx := strings.Index(key, "{"); idx > 0 {
-			name := key[:idx]
-			out[name] = out[name] + val
-		}
+func withEventSpecs(specs []spec.SLISpec, events bool) []spec.SLISpec {
+	if !events {
+		return specs
 	}
-	return out, nil
+	return append(slices.Clone(specs), presets.KubeEvents()...)
 }
 
 func defaultSpecsV4(specs []spec.SLISpec) []spec.SLISpec {
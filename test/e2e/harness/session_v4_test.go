@@ -2,11 +2,24 @@ package harness
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/onsi/ginkgo/v2/types"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/clock"
+	"github.com/yeongki/my-operator/pkg/slo/engine"
 	"github.com/yeongki/my-operator/pkg/slo/fetch"
+	"github.com/yeongki/my-operator/pkg/slo/slotest"
 	"github.com/yeongki/my-operator/pkg/slo/spec"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
 )
 
 type fakeFetcherV4 struct {
@@ -51,7 +64,7 @@ func TestSessionV4BuildsSummary(t *testing.T) {
 		},
 	})
 
-	session.Start()
+	session.Start(context.Background())
 	summary, err := session.End(context.Background())
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
@@ -71,4 +84,439 @@ func TestSessionV4BuildsSummary(t *testing.T) {
 	if summary.Config.Tags["run_id"] != "override-run" {
 		t.Fatalf("expected user run_id tag override, got %q", summary.Config.Tags["run_id"])
 	}
+	if m := summary.Measurement; m == nil || m.Snapshots != 2 || m.SuccessRate() != 1 {
+		t.Fatalf("expected the two edge snapshots in the measurement stats, got %+v", m)
+	}
+	if SuiteMeasurement().Snapshots < 2 {
+		t.Fatalf("expected the suite stats to include the session, got %+v", SuiteMeasurement())
+	}
+}
+
+func TestSessionV4FailOnPolicy(t *testing.T) {
+	newSession := func() *SessionV4 {
+		return NewSessionV4(SessionV4Config{
+			Namespace:          "default",
+			MetricsServiceName: "metrics",
+			TestCase:           "case",
+			Fetcher: &fakeFetcherV4{
+				samples: []fetch.Sample{
+					{Values: map[string]float64{"errors": 0}},
+					{Values: map[string]float64{"errors": 2}},
+				},
+			},
+			Specs: []spec.SLISpec{
+				{
+					ID:      "errors_delta",
+					Inputs:  []spec.MetricRef{spec.PromMetric("errors", nil)},
+					Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+					Judge: &spec.JudgeSpec{Rules: []spec.Rule{
+						{Op: spec.OpGT, Target: 0, Level: spec.LevelFail},
+					}},
+				},
+			},
+		})
+	}
+
+	session := newSession()
+	session.Start(context.Background())
+	summary, err := session.End(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error without FailOnPolicy, got %v", err)
+	}
+	if !summary.HasPolicyFailures() {
+		t.Fatalf("expected policy failure to be recorded")
+	}
+
+	session = newSession()
+	session.Start(context.Background())
+	summary, err = session.End(context.Background(), EndOptions{FailOnPolicy: true})
+	if !errors.Is(err, ErrPolicyFailed) || !errors.Is(err, slo.ErrPolicy) {
+		t.Fatalf("expected ErrPolicyFailed, got %v", err)
+	}
+	if summary == nil {
+		t.Fatalf("expected summary alongside ErrPolicyFailed")
+	}
+}
+
+func TestSessionV4CounterResetCorrection(t *testing.T) {
+	session := NewSessionV4(SessionV4Config{
+		Namespace:          "default",
+		MetricsServiceName: "metrics",
+		TestCase:           "case",
+		Fetcher: &fakeFetcherV4{
+			samples: []fetch.Sample{
+				{Values: map[string]float64{"reconciles": 40}},
+				// controller restarted mid-window: counter starts again from zero
+				{Values: map[string]float64{"reconciles": 7}},
+			},
+		},
+		Specs: []spec.SLISpec{
+			{
+				ID:      "reconciles_delta",
+				Inputs:  []spec.MetricRef{spec.PromMetric("reconciles", nil)},
+				Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+			},
+		},
+	})
+
+	session.Start(context.Background())
+	sum, err := session.End(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	r := sum.Results[0]
+	if r.Status != "warn" || r.Value == nil || *r.Value != 7 {
+		t.Fatalf("expected warn with value 7 (increase since reset), got %+v", r)
+	}
+}
+
+func TestSessionV4LifecycleGuard(t *testing.T) {
+	dir := t.TempDir()
+	specs := []spec.SLISpec{{
+		ID:      "metric_delta",
+		Inputs:  []spec.MetricRef{spec.PromMetric("metric", nil)},
+		Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+	}}
+	fetcher := &fakeFetcherV4{samples: []fetch.Sample{
+		{Values: map[string]float64{"metric": 1}},
+		{Values: map[string]float64{"metric": 3}},
+	}}
+	session := NewSessionV4(SessionV4Config{
+		TestCase: "case", RunID: "r", ArtifactsDir: dir, Fetcher: fetcher, Specs: specs,
+	})
+
+	// End before Start: skip results instead of an error
+	sum, err := session.End(context.Background())
+	if err != nil || len(sum.Results) != 1 || sum.Results[0].Status != "skip" {
+		t.Fatalf("expected a skip result, got %+v, %v", sum, err)
+	}
+
+	session.Start(context.Background())
+	session.Start(context.Background())
+	first, err := session.End(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fetcher has no samples left: a second evaluation would panic
+	again, err := session.End(context.Background())
+	if err != nil || again != first {
+		t.Fatalf("expected the first result again, got %+v, %v", again, err)
+	}
+	if session.State() != SessionEnded || len(session.Warnings) != 3 {
+		t.Fatalf("expected state ended with 3 warnings, got %s %v", session.State(), session.Warnings)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "sli-summary.*"))
+	if len(files) != 2 {
+		t.Fatalf("expected one summary for the skipped End and one for the window, got %v", files)
+	}
+}
+
+func TestSessionV4Abort(t *testing.T) {
+	session := NewSessionV4(SessionV4Config{TestCase: "case", Specs: DefaultV3Specs(), Fetcher: slotest.NewFakeFetcher(), Hooks: []engine.Hooks{{
+		OnResult: func(_ context.Context, s *summary.Summary) error {
+			s.Config.Tags["git_sha"] = "abc123"
+			return nil
+		},
+	}}})
+	session.Start(context.Background())
+	sum, err := session.Abort(context.Background(), "spec interrupted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Config.Tags["git_sha"] != "abc123" {
+		t.Errorf("OnResult hook not applied to the aborted summary: %v", sum.Config.Tags)
+	}
+	for _, r := range sum.Results {
+		if r.Status != "skip" || r.Reason != "aborted: spec interrupted" {
+			t.Fatalf("unexpected result %+v", r)
+		}
+	}
+	if _, err := session.Abort(context.Background(), "again"); err != nil || len(session.Warnings) != 1 {
+		t.Fatalf("expected the repeated Abort to be a warning, got %v %v", err, session.Warnings)
+	}
+}
+
+// TestSessionV4ConcurrentUse is meant for `go test -race`: helpers record warnings, tags and
+// disruptions while the spec ends the window from several goroutines.
+func TestSessionV4ConcurrentUse(t *testing.T) {
+	fetcher := &fakeFetcherV4{samples: []fetch.Sample{
+		{Values: map[string]float64{"metric": 1}},
+		{Values: map[string]float64{"metric": 3}},
+	}}
+	session := NewSessionV4(SessionV4Config{TestCase: "case", Fetcher: fetcher, Specs: []spec.SLISpec{{
+		ID:      "metric_delta",
+		Inputs:  []spec.MetricRef{spec.PromMetric("metric", nil)},
+		Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+	}}})
+	session.Start(context.Background())
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			session.AddWarning(fmt.Sprintf("helper %d", i))
+			session.AddDisruption(summary.Disruption{Kind: "test"})
+			session.SetTag(fmt.Sprintf("k%d", i), "v")
+			_ = session.State()
+		}()
+	}
+	results := make([]*summary.Summary, 4)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// only the first End fetches (the fake has exactly two samples)
+			results[i], _ = session.End(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		if r == nil || r != results[0] {
+			t.Fatalf("expected every End to return the same summary, got %v", results)
+		}
+	}
+	if got := len(session.WarningsSnapshot()); got != 8+3 {
+		t.Fatalf("expected 8 helper warnings and 3 repeated End warnings, got %d", got)
+	}
+}
+
+func TestSessionV4Checkpoints(t *testing.T) {
+	t0 := time.Now().Add(-time.Minute)
+	sample := func(offset time.Duration, v float64) fetch.Sample {
+		return fetch.Sample{At: t0.Add(offset), Values: map[string]float64{"metric": v}}
+	}
+	// fetched in order: the window start, the checkpoint, the window end
+	fetcher := &fakeFetcherV4{samples: []fetch.Sample{
+		sample(0, 1), sample(10*time.Second, 4), sample(30*time.Second, 10),
+	}}
+	session := NewSessionV4(SessionV4Config{
+		TestCase: "case",
+		Fetcher:  fetcher,
+		Specs: []spec.SLISpec{{
+			ID:      "metric_delta",
+			Inputs:  []spec.MetricRef{spec.PromMetric("metric", nil)},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		}},
+	})
+
+	session.Checkpoint(context.Background(), "too early")
+	session.Start(context.Background())
+	session.Checkpoint(context.Background(), "applied")
+	sum, err := session.End(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sum.Phases) != 2 || sum.Phases[0].Name != "applied" || sum.Phases[1].Name != "end" {
+		t.Fatalf("unexpected phases %+v", sum.Phases)
+	}
+	want := []float64{3, 6}
+	for i, p := range sum.Phases {
+		if len(p.Results) != 1 || p.Results[0].Value == nil || *p.Results[0].Value != want[i] {
+			t.Fatalf("phase %q: unexpected results %+v", p.Name, p.Results)
+		}
+	}
+	if sum.Phases[1].DurationSeconds != 20 {
+		t.Fatalf("expected 20s end phase, got %v", sum.Phases[1].DurationSeconds)
+	}
+	if v := sum.Results[0].Value; v == nil || *v != 9 {
+		t.Fatalf("expected whole-window delta 9, got %+v", sum.Results[0])
+	}
+	if len(session.Warnings) != 1 {
+		t.Fatalf("expected the early checkpoint to be a warning, got %v", session.Warnings)
+	}
+}
+
+func TestSessionV4MetricFilter(t *testing.T) {
+	specs := []spec.SLISpec{{ID: "metric_delta", Inputs: []spec.MetricRef{spec.PromMetric("metric", nil)}}}
+	fetcher := &fakeFetcherV4{samples: []fetch.Sample{{Values: map[string]float64{
+		"metric": 1, "extra_total": 2, "go_goroutines": 40, `go_threads{x="y"}`: 9,
+	}}}}
+	session := NewSessionV4(SessionV4Config{
+		TestCase: "case", Fetcher: fetcher, Specs: specs,
+		MetricFilter: &fetch.MetricFilter{Include: []string{"extra_total", "go_threads"}, Exclude: "^go_"},
+	})
+	s, err := session.liveFetcher().Fetch(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Values) != 2 || s.Values["metric"] != 1 || s.Values["extra_total"] != 2 {
+		t.Fatalf("expected the spec metric plus the extra, got %v", s.Values)
+	}
+
+	session = NewSessionV4(SessionV4Config{
+		TestCase: "case", Specs: specs, MetricFilter: &fetch.MetricFilter{Exclude: "("},
+	})
+	if session.metricFilter != nil || len(session.Warnings) != 1 {
+		t.Fatalf("expected an invalid filter to be a warning, got %v", session.Warnings)
+	}
+}
+
+func TestSessionV4Clock(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	c := clock.NewFake(t0)
+	session := NewSessionV4(SessionV4Config{
+		TestCase: "case",
+		Clock:    c,
+		Fetcher:  slotest.NewFakeFetcher(map[string]float64{"metric": 1}, map[string]float64{"metric": 3}),
+		Specs: []spec.SLISpec{{
+			ID: "metric_delta", Inputs: []spec.MetricRef{spec.PromMetric("metric", nil)},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		}},
+	})
+
+	session.Start(context.Background())
+	_ = TimeWait(session, "ready", 10*time.Second, func() error {
+		c.Advance(3 * time.Second)
+		return nil
+	})
+	c.Advance(2 * time.Second)
+	sum, err := session.End(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sum.Config.StartedAt.Equal(t0) || !sum.Config.FinishedAt.Equal(t0.Add(5*time.Second)) {
+		t.Errorf("window = %v .. %v, want the fake clock's", sum.Config.StartedAt, sum.Config.FinishedAt)
+	}
+	if len(sum.Waits) != 1 || sum.Waits[0].DurationSeconds != 3 || sum.Waits[0].BudgetUsed() != 0.3 {
+		t.Errorf("waits = %+v, want one wait of exactly 3s", sum.Waits)
+	}
+}
+
+func TestSessionV4SetSpecs(t *testing.T) {
+	fetcher := slotest.NewFakeFetcher(
+		map[string]float64{"reconciles": 1, "deletions": 5},
+		map[string]float64{"reconciles": 4, "deletions": 6},
+	)
+	delta := func(id, metric string) spec.SLISpec {
+		return spec.SLISpec{
+			ID: id, Inputs: []spec.MetricRef{spec.PromMetric(metric, nil)},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		}
+	}
+	session := NewSessionV4(SessionV4Config{TestCase: "case", Fetcher: fetcher,
+		Specs: []spec.SLISpec{delta("reconcile_delta", "reconciles")}})
+
+	bySpec := SpecsByLabel(map[string][]spec.SLISpec{"deletion": {delta("deletion_delta", "deletions")}})
+	session.SetSpecs(bySpec(types.SpecReport{LeafNodeLabels: []string{"slow", "deletion"}}, nil))
+	session.Start(context.Background())
+	session.SetSpecs(nil)
+	sum, err := session.End(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sum.Results) != 1 || sum.Results[0].ID != "deletion_delta" || *sum.Results[0].Value != 1 {
+		t.Fatalf("the labelled spec must measure the deletion specs, got %+v", sum.Results)
+	}
+	warnings := session.WarningsSnapshot()
+	if !slices.ContainsFunc(warnings, func(w string) bool { return strings.Contains(w, "SetSpecs") }) {
+		t.Errorf("SetSpecs on a started session must warn, got %v", warnings)
+	}
+
+	other := bySpec(types.SpecReport{LeafNodeLabels: []string{"creation"}}, []spec.SLISpec{delta("a", "b")})
+	if len(other) != 1 || other[0].ID != "a" {
+		t.Errorf("an unlabelled spec keeps the attach-wide specs, got %+v", other)
+	}
+}
+
+// counterAt serves a counter growing by one per second of the requested time, like a live
+// endpoint scraped when asked.
+type counterAt struct{ t0 time.Time }
+
+func (f counterAt) Fetch(_ context.Context, at time.Time) (fetch.Sample, error) {
+	return fetch.Sample{At: at, Values: map[string]float64{"metric": at.Sub(f.t0).Seconds()}}, nil
+}
+
+func TestSessionV4StartSnapshotAtStart(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	c := clock.NewFake(t0)
+	session := NewSessionV4(SessionV4Config{
+		TestCase: "case",
+		Clock:    c,
+		Fetcher:  counterAt{t0: t0},
+		Specs: []spec.SLISpec{{
+			ID:      "metric_delta",
+			Inputs:  []spec.MetricRef{spec.PromMetric("metric", nil)},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeDelta},
+		}},
+	})
+
+	session.Start(context.Background())
+	c.Advance(10 * time.Second)
+	session.Checkpoint(context.Background(), "applied")
+	c.Advance(20 * time.Second)
+	sum, err := session.End(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{10, 20}
+	for i, p := range sum.Phases {
+		r := p.Results[0]
+		if r.Status != summary.StatusPass || r.Value == nil || *r.Value != want[i] {
+			t.Fatalf("phase %q: expected delta %v, got %+v", p.Name, want[i], r)
+		}
+	}
+	if r := sum.Results[0]; r.Value == nil || *r.Value != 30 {
+		t.Fatalf("expected whole-window delta 30, got %+v", r)
+	}
+}
+
+func TestSessionV4StartSnapshotFailed(t *testing.T) {
+	session := NewSessionV4(SessionV4Config{
+		TestCase: "case",
+		Fetcher:  slotest.NewFakeFetcher(map[string]float64{"metric": 1}).FailAt(0, errors.New("unreachable")),
+		Specs:    []spec.SLISpec{{ID: "metric_delta", Inputs: []spec.MetricRef{spec.PromMetric("metric", nil)}}},
+	})
+	session.Start(context.Background())
+	sum, err := session.End(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := sum.Results[0]; r.Status != summary.StatusSkip || !strings.Contains(r.Reason, "unreachable") {
+		t.Fatalf("expected the failed start snapshot to skip the result, got %+v", r)
+	}
+}
+
+func TestSessionV4PeakInterval(t *testing.T) {
+	c := clock.NewFake(time.Unix(1700000000, 0))
+	// start, one poll, checkpoint, end
+	f := slotest.NewFakeFetcher(
+		map[string]float64{"depth": 2},
+		map[string]float64{"depth": 9},
+		map[string]float64{"depth": 4},
+		map[string]float64{"depth": 1},
+	)
+	session := NewSessionV4(SessionV4Config{
+		TestCase:     "case",
+		Clock:        c,
+		Fetcher:      f,
+		PeakInterval: 5 * time.Second,
+		Specs: []spec.SLISpec{{
+			ID:      "depth_max",
+			Kind:    spec.KindGauge,
+			Inputs:  []spec.MetricRef{spec.PromMetric("depth", nil)},
+			Compute: spec.ComputeSpec{Mode: spec.ComputeMax},
+		}},
+	})
+
+	session.Start(context.Background())
+	c.Advance(5 * time.Second)
+	for deadline := time.Now().Add(10 * time.Second); f.Calls() < 2; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the window was not polled")
+		}
+	}
+	session.Checkpoint(context.Background(), "applied")
+	sum, err := session.End(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := sum.Results[0]; r.Value == nil || *r.Value != 9 {
+		t.Fatalf("expected the polled peak 9, got %+v", r)
+	}
+	if f.Calls() != 4 {
+		t.Fatalf("polling must stop at End, got %d fetches", f.Calls())
+	}
 }
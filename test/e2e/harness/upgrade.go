@@ -0,0 +1,217 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/kubeutil"
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
+)
+
+// ScenarioTag is the summary tag naming the scenario a session measured (e.g. "upgrade").
+const ScenarioTag = "scenario"
+
+var (
+	// ErrUpgradeDisrupted is returned by UpgradeScenario.Upgrade when a watched resource was deleted
+	// or recreated (different UID) by the upgrade.
+	ErrUpgradeDisrupted = errors.New("upgrade: resources were disrupted")
+	// ErrUpgradeNotConverged is returned when the rollout or Converged did not finish within Timeout.
+	ErrUpgradeNotConverged = errors.New("upgrade: system did not converge")
+)
+
+// UpgradeScenario deploys a previously released image, lets the spec create CRs and then upgrades
+// to the image under test with `make deploy` (or Deploy), which is where operator regressions tend to appear:
+//
+//	u := &harness.UpgradeScenario{FromImage: prev, ToImage: projectImage, ...}
+//	Expect(u.DeployFrom(ctx)).To(Succeed())
+//	... create CRs, list them in u.Resources ...
+//	sess.Start(ctx)
+//	_, err := u.Upgrade(ctx, sess)
+//	sum, endErr := sess.End(ctx)
+//
+// The upgrade is recorded in the session summary as a Disruption of kind "upgrade" (StartedAt =
+// upgrade applied, RecoveredAt = converged) and the session is tagged scenario=upgrade.
+type UpgradeScenario struct {
+	// ProjectDir is where `make deploy` runs (repository root).
+	ProjectDir string
+	// Deploy installs the controller with image (optional, e.g. bootstrap.Bootstrapper.DeployImage);
+	// nil => `make deploy IMG=<image>`.
+	Deploy func(ctx context.Context, image string) error
+	// Namespace of the operator deployment.
+	Namespace string
+	// Selector of the controller deployment/pods (default "control-plane=controller-manager").
+	Selector string
+
+	// FromImage is the released image deployed first; it must be pullable by (or loaded into) the cluster.
+	FromImage string
+	// ToImage is the image under test.
+	ToImage string
+
+	// Resources are "<kind>/<name>" in ResourceNamespace (e.g. "joboperators/sample") that must survive
+	// the upgrade untouched: same UID before and after.
+	ResourceNamespace string
+	Resources         []string
+
+	// Converged reports whether the resources converged under the new controller (optional; the
+	// rollout of the controller deployment is always awaited), e.g. a Ready status condition.
+	Converged func(ctx context.Context) (bool, error)
+	// Timeout bounds rollout plus convergence (default 5m); Interval is the poll interval (default 2s).
+	Timeout  time.Duration
+	Interval time.Duration
+
+	// Runner may be nil (kubeutil.DefaultRunner).
+	Runner kubeutil.CmdRunner
+}
+
+func (u *UpgradeScenario) withDefaults() UpgradeScenario {
+	out := *u
+	if out.Selector == "" {
+		out.Selector = "control-plane=controller-manager"
+	}
+	if out.Timeout <= 0 {
+		out.Timeout = 5 * time.Minute
+	}
+	if out.Interval <= 0 {
+		out.Interval = 2 * time.Second
+	}
+	if out.Runner == nil {
+		out.Runner = kubeutil.DefaultRunner{}
+	}
+	return out
+}
+
+func (u UpgradeScenario) kubectl(ctx context.Context, args ...string) (string, error) {
+	out, err := u.Runner.Run(ctx, e2eutil.GinkgoLog, exec.Command("kubectl", args...))
+	return strings.TrimSpace(out), err
+}
+
+// deploy runs Deploy (default `make deploy IMG=<image>`) and waits for the controller rollout.
+func (u UpgradeScenario) deploy(ctx context.Context, image string) error {
+	deploy := u.Deploy
+	if deploy == nil {
+		deploy = func(ctx context.Context, image string) error {
+			cmd := exec.Command("make", "deploy", "IMG="+image)
+			cmd.Dir = u.ProjectDir
+			_, err := u.Runner.Run(ctx, e2eutil.GinkgoLog, cmd)
+			return err
+		}
+	}
+	if err := deploy(ctx, image); err != nil {
+		return fmt.Errorf("upgrade: deploy %s: %w", image, err)
+	}
+
+	deployments, err := u.kubectl(ctx, "get", "deployments", "-n", u.Namespace, "-l", u.Selector, "-o", "name")
+	if err != nil {
+		return fmt.Errorf("upgrade: list deployments: %w", err)
+	}
+	for _, d := range strings.Fields(deployments) {
+		if _, err := u.kubectl(ctx, "rollout", "status", d, "-n", u.Namespace,
+			"--timeout="+u.Timeout.String()); err != nil {
+			return fmt.Errorf("%w: rollout of %s: %v", ErrUpgradeNotConverged, d, err)
+		}
+	}
+	return nil
+}
+
+// DeployFrom deploys FromImage and waits for its rollout.
+func (u *UpgradeScenario) DeployFrom(ctx context.Context) error {
+	cfg := u.withDefaults()
+	if cfg.FromImage == "" {
+		return errors.New("upgrade: FromImage is required")
+	}
+	return cfg.deploy(ctx, cfg.FromImage)
+}
+
+// uids returns the UID of each watched resource ("" when it does not exist).
+func (u UpgradeScenario) uids(ctx context.Context) map[string]string {
+	out := make(map[string]string, len(u.Resources))
+	for _, r := range u.Resources {
+		uid, err := u.kubectl(ctx, "get", r, "-n", u.ResourceNamespace,
+			"--ignore-not-found", "-o", "jsonpath={.metadata.uid}")
+		if err != nil {
+			uid = ""
+		}
+		out[r] = uid
+	}
+	return out
+}
+
+// Upgrade deploys ToImage over the running FromImage, waits for the rollout and Converged, and
+// checks that no watched resource was disrupted. The Disruption is returned (and recorded in sess,
+// which may be nil) even together with an error.
+func (u *UpgradeScenario) Upgrade(ctx context.Context, sess *SessionV4) (*summary.Disruption, error) {
+	cfg := u.withDefaults()
+	if cfg.ToImage == "" {
+		return nil, errors.New("upgrade: ToImage is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	before := cfg.uids(ctx)
+	d := &summary.Disruption{
+		Kind:      "upgrade",
+		Target:    fmt.Sprintf("%s: %s -> %s", cfg.Namespace, cfg.FromImage, cfg.ToImage),
+		StartedAt: time.Now(),
+	}
+	if sess != nil {
+		sess.SetTag(ScenarioTag, "upgrade")
+		defer func() { sess.AddDisruption(*d) }()
+	}
+
+	if err := cfg.deploy(ctx, cfg.ToImage); err != nil {
+		d.Detail = err.Error()
+		return d, err
+	}
+	if err := cfg.waitConverged(ctx); err != nil {
+		d.Detail = err.Error()
+		return d, err
+	}
+	converged := time.Now()
+	d.RecoveredAt = &converged
+	d.Detail = fmt.Sprintf("converged in %s", converged.Sub(d.StartedAt).Round(time.Millisecond))
+
+	var disrupted []string
+	for r, uid := range cfg.uids(ctx) {
+		switch {
+		case before[r] == "":
+			// not there before the upgrade: nothing to compare
+		case uid == "":
+			disrupted = append(disrupted, r+" deleted")
+		case uid != before[r]:
+			disrupted = append(disrupted, r+" recreated")
+		}
+	}
+	if len(disrupted) > 0 {
+		d.Detail += "; " + strings.Join(disrupted, ", ")
+		return d, fmt.Errorf("%w: %s", ErrUpgradeDisrupted, strings.Join(disrupted, ", "))
+	}
+	return d, nil
+}
+
+// waitConverged polls Converged until it reports true or ctx is done.
+func (u UpgradeScenario) waitConverged(ctx context.Context) error {
+	if u.Converged == nil {
+		return nil
+	}
+	for {
+		ok, err := u.Converged(ctx)
+		if err == nil && ok {
+			return nil
+		}
+		if err != nil {
+			slo.Debugf(e2eutil.GinkgoLog, "upgrade: not converged yet: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ErrUpgradeNotConverged, ctx.Err())
+		case <-time.After(u.Interval):
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// scriptRunner answers each command (joined args) with reply.
+type scriptRunner struct {
+	reply func(args string) string
+}
+
+func (s scriptRunner) Run(_ context.Context, _ slo.Logger, cmd *exec.Cmd) (string, error) {
+	return s.reply(strings.Join(cmd.Args, " ")), nil
+}
+
+func TestUpgradeDetectsRecreatedResources(t *testing.T) {
+	deployed := ""
+	r := scriptRunner{reply: func(args string) string {
+		switch {
+		case strings.HasPrefix(args, "make deploy IMG="):
+			deployed = strings.TrimPrefix(args, "make deploy IMG=")
+		case strings.Contains(args, "-o name"):
+			return "deployment.apps/controller-manager"
+		case strings.Contains(args, "joboperators/sample"):
+			return "uid-1"
+		case strings.Contains(args, "statefulsets/sample-sts"):
+			return "uid-" + deployed // recreated by the new controller
+		}
+		return ""
+	}}
+
+	sess := NewSessionV4(SessionV4Config{Namespace: "ns"})
+	u := &UpgradeScenario{
+		Namespace: "ns", FromImage: "op:v1", ToImage: "op:v2", ResourceNamespace: "ns",
+		Resources: []string{"joboperators/sample", "statefulsets/sample-sts"},
+		Converged: func(context.Context) (bool, error) { return true, nil },
+		Interval:  time.Millisecond,
+		Runner:    r,
+	}
+	if err := u.DeployFrom(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	d, err := u.Upgrade(context.Background(), sess)
+	if !errors.Is(err, ErrUpgradeDisrupted) || !strings.Contains(err.Error(), "statefulsets/sample-sts recreated") {
+		t.Fatalf("expected the recreated StatefulSet to be reported, got %v", err)
+	}
+	if d == nil || d.Kind != "upgrade" || !d.Recovered() {
+		t.Fatalf("unexpected disruption %+v", d)
+	}
+	if sess.Tags[ScenarioTag] != "upgrade" || len(sess.disruptions) != 1 {
+		t.Fatalf("session not labeled: tags=%v disruptions=%v", sess.Tags, sess.disruptions)
+	}
+}
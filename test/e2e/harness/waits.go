@@ -0,0 +1,118 @@
+package harness
+
+import (
+	"context"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
+)
+
+// TimedWait is a Gomega Eventually that records how long it took in a session's summary
+// (Summary.Waits), so the run shows which wait dominates the spec time and how close each wait
+// comes to its timeout:
+//
+//	harness.EventuallySLO(sess, "statefulset created", func() (string, error) { ... }).
+//		WithContext(ctx).WithTimeout(3 * time.Minute).Should(Equal("1"))
+//
+// The wait is recorded even when it fails the spec.
+type TimedWait struct {
+	sess    *SessionV4
+	name    string
+	actual  any
+	ctx     context.Context
+	timeout time.Duration
+	polling time.Duration
+}
+
+// EventuallySLO starts a timed Eventually on actual (any value Gomega's Eventually accepts).
+// sess may be nil: the wait then behaves like a plain Eventually.
+func EventuallySLO(sess *SessionV4, name string, actual any) *TimedWait {
+	return &TimedWait{sess: sess, name: name, actual: actual}
+}
+
+// WithContext stops polling when ctx is done.
+func (w *TimedWait) WithContext(ctx context.Context) *TimedWait {
+	w.ctx = ctx
+	return w
+}
+
+// WithTimeout sets the timeout, which is also the budget the wait is compared to.
+func (w *TimedWait) WithTimeout(d time.Duration) *TimedWait {
+	w.timeout = d
+	return w
+}
+
+// WithPolling sets the polling interval.
+func (w *TimedWait) WithPolling(d time.Duration) *TimedWait {
+	w.polling = d
+	return w
+}
+
+// Should waits until actual matches.
+func (w *TimedWait) Should(matcher types.GomegaMatcher, optionalDescription ...any) bool {
+	return w.run(true, matcher, optionalDescription)
+}
+
+// ShouldNot waits until actual stops matching.
+func (w *TimedWait) ShouldNot(matcher types.GomegaMatcher, optionalDescription ...any) bool {
+	return w.run(false, matcher, optionalDescription)
+}
+
+func (w *TimedWait) run(should bool, matcher types.GomegaMatcher, desc []any) (ok bool) {
+	// offset 2: report failures at the caller of Should/ShouldNot
+	a := gomega.EventuallyWithOffset(2, w.actual)
+	if w.ctx != nil {
+		a = a.WithContext(w.ctx)
+	}
+	if w.timeout > 0 {
+		a = a.WithTimeout(w.timeout)
+	}
+	if w.polling > 0 {
+		a = a.WithPolling(w.polling)
+	}
+
+	start := sessionNow(w.sess)
+	// deferred, so a failing wait (Gomega's fail handler panics under Ginkgo) is recorded too
+	defer func() { recordWait(w.sess, w.name, start, w.timeout, ok) }()
+	if should {
+		return a.Should(matcher, desc...)
+	}
+	return a.ShouldNot(matcher, desc...)
+}
+
+// TimeWait runs wait (e.g. an e2eutil.WaitFor bounded by timeout) and records it like EventuallySLO.
+// sess may be nil.
+func TimeWait(sess *SessionV4, name string, timeout time.Duration, wait func() error) error {
+	start := sessionNow(sess)
+	err := wait()
+	recordWait(sess, name, start, timeout, err == nil)
+	return err
+}
+
+// sessionNow reads the clock of sess (nil => wall clock).
+func sessionNow(sess *SessionV4) time.Time {
+	if sess == nil || sess.clock == nil {
+		return time.Now()
+	}
+	return sess.clock.Now()
+}
+
+func recordWait(sess *SessionV4, name string, start time.Time, timeout time.Duration, ok bool) {
+	if sess == nil {
+		return
+	}
+	w := summary.Wait{
+		Name:            name,
+		StartedAt:       start,
+		DurationSeconds: sessionNow(sess).Sub(start).Seconds(),
+		TimeoutSeconds:  timeout.Seconds(),
+		Succeeded:       ok,
+	}
+	sess.AddWait(w)
+	e2eutil.GinkgoLog.Logf("SLO(v4): wait %q took %.1fs (%.0f%% of its timeout)",
+		w.Name, w.DurationSeconds, 100*w.BudgetUsed())
+}
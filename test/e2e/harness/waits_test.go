@@ -0,0 +1,41 @@
+package harness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestEventuallySLORecordsWaits(t *testing.T) {
+	session := NewSessionV4(SessionV4Config{TestCase: "case", Specs: DefaultV3Specs()})
+	session.Start(context.Background())
+
+	gomega.RegisterTestingT(t)
+	n := 0
+	EventuallySLO(session, "counter", func() int { n++; return n }).
+		WithTimeout(time.Second).WithPolling(time.Millisecond).Should(gomega.BeNumerically(">=", 3))
+
+	// a failing wait is recorded before the fail handler unwinds the spec
+	gomega.RegisterFailHandler(func(message string, _ ...int) { panic(message) })
+	func() {
+		defer func() { _ = recover() }()
+		EventuallySLO(session, "never", func() bool { return false }).
+			WithTimeout(20 * time.Millisecond).WithPolling(time.Millisecond).Should(gomega.BeTrue())
+	}()
+
+	sum, err := session.Abort(context.Background(), "done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sum.Waits) != 2 {
+		t.Fatalf("expected 2 waits, got %+v", sum.Waits)
+	}
+	if w := sum.Waits[0]; w.Name != "counter" || !w.Succeeded || w.TimeoutSeconds != 1 {
+		t.Fatalf("unexpected wait %+v", w)
+	}
+	if w := sum.Waits[1]; w.Name != "never" || w.Succeeded || w.BudgetUsed() < 1 {
+		t.Fatalf("expected the failed wait to use its whole budget, got %+v", w)
+	}
+}
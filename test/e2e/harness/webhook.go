@@ -0,0 +1,84 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/kubeutil"
+	"github.com/yeongki/my-operator/pkg/slo/fetch"
+)
+
+// ErrAdmitted is returned by ApplyExpectDenied when the API server accepted the manifest.
+var ErrAdmitted = errors.New("manifest was admitted")
+
+// AdmissionDenial is a webhook denial parsed from kubectl output.
+type AdmissionDenial struct {
+	Webhook string // e.g. "vjoboperator.kb.io"
+	Message string // the webhook's message, as returned to the user
+}
+
+var denialRe = regexp.MustCompile(`admission webhook "([^"]+)" denied the request: (.*)`)
+
+// ParseAdmissionDenial extracts the first webhook denial from kubectl stderr/stdout.
+func ParseAdmissionDenial(out string) (AdmissionDenial, bool) {
+	m := denialRe.FindStringSubmatch(out)
+	if m == nil {
+		return AdmissionDenial{}, false
+	}
+	return AdmissionDenial{Webhook: m[1], Message: strings.TrimSpace(m[2])}, true
+}
+
+// ApplyExpectDenied applies manifest (kubectl apply -f -) expecting an admission webhook to deny it,
+// so specs can assert the exact message:
+//
+//	d, err := harness.ApplyExpectDenied(ctx, runner, invalidCR)
+//	Expect(err).NotTo(HaveOccurred())
+//	Expect(d.Message).To(Equal(`spec.replicas: Invalid value: -1: must be >= 0`))
+//
+// A failure that is not a webhook denial (e.g. webhook unreachable or TLS errors) is returned as is;
+// an accepted manifest returns ErrAdmitted. r may be nil (kubeutil.DefaultRunner).
+func ApplyExpectDenied(ctx context.Context, r kubeutil.CmdRunner, manifest string) (AdmissionDenial, error) {
+	if r == nil {
+		r = kubeutil.DefaultRunner{}
+	}
+	cmd := exec.Command("kubectl", "apply", "-f", "-")
+	cmd.Stdin = strings.NewReader(manifest)
+	_, err := r.Run(ctx, nil, cmd)
+	if err == nil {
+		return AdmissionDenial{}, ErrAdmitted
+	}
+	if d, ok := ParseAdmissionDenial(err.Error()); ok {
+		return d, nil
+	}
+	return AdmissionDenial{}, err
+}
+
+// APIServerFetcher samples the kube-apiserver /metrics (kubectl get --raw), e.g. for the
+// admission-webhook preset:
+//
+//	w, err := slogather.Start(ctx, harness.APIServerFetcher{})
+//	... apply CRs ...
+//	sum, err := w.End(ctx, presets.AdmissionWebhook("vjoboperator.kb.io"))
+//
+// It needs RBAC for the nonResourceURL /metrics (cluster-admin on kind).
+type APIServerFetcher struct {
+	// Runner may be nil (kubeutil.DefaultRunner).
+	Runner kubeutil.CmdRunner
+}
+
+func (f APIServerFetcher) Fetch(ctx context.Context, at time.Time) (fetch.Sample, error) {
+	r := f.Runner
+	if r == nil {
+		r = kubeutil.DefaultRunner{}
+	}
+	out, err := r.Run(ctx, nil, exec.Command("kubectl", "get", "--raw", "/metrics"))
+	if err != nil {
+		return fetch.Sample{}, fmt.Errorf("apiserver metrics: %w", err)
+	}
+	return fetch.SampleFromText(at, out, &fetch.Provenance{Fetcher: "kubectl-raw", Target: "kube-apiserver/metrics"})
+}
@@ -0,0 +1,54 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// fakeRunner returns out/err for every command.
+type fakeRunner struct {
+	out string
+	err error
+}
+
+func (f fakeRunner) Run(_ context.Context, _ slo.Logger, _ *exec.Cmd) (string, error) {
+	return f.out, f.err
+}
+
+func TestApplyExpectDenied(t *testing.T) {
+	stderr := `Error from server (Forbidden): error when creating "STDIN": ` +
+		`admission webhook "vjoboperator.kb.io" denied the request: spec.replicas: must be >= 0` + "\n"
+	d, err := ApplyExpectDenied(context.Background(), fakeRunner{err: fmt.Errorf("kubectl failed: %s", stderr)}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Webhook != "vjoboperator.kb.io" || d.Message != "spec.replicas: must be >= 0" {
+		t.Fatalf("unexpected denial %+v", d)
+	}
+
+	if _, err := ApplyExpectDenied(context.Background(), fakeRunner{}, ""); !errors.Is(err, ErrAdmitted) {
+		t.Fatalf("expected ErrAdmitted, got %v", err)
+	}
+
+	tlsErr := errors.New(`failed calling webhook "vjoboperator.kb.io": tls: failed to verify certificate`)
+	if _, err := ApplyExpectDenied(context.Background(), fakeRunner{err: tlsErr}, ""); err != tlsErr {
+		t.Fatalf("expected the TLS error to be returned as is, got %v", err)
+	}
+}
+
+func TestAPIServerFetcher(t *testing.T) {
+	out := `apiserver_admission_webhook_rejection_count{error_type="no_error",name="v.kb.io"} 2` + "\n"
+	s, err := APIServerFetcher{Runner: fakeRunner{out: out}}.Fetch(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Values["apiserver_admission_webhook_rejection_count"] != 2 {
+		t.Fatalf("unexpected values %v", s.Values)
+	}
+}
@@ -1,65 +1,385 @@
 package env
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/runid"
+	"github.com/yeongki/my-operator/pkg/slolab"
 )
 
-// LoadOptions Options holds E2E test configuration loaded from environment variables.
+// ConfigFileEnv names a config file of KEY=VALUE lines (same keys as the env vars, '#' comments)
+// read under the environment: a variable that is set wins over the file.
+const ConfigFileEnv = "SLOLAB_CONFIG"
+
+// ProfileEnv names a slolab.yaml profile (see slolab.Profile). Its fetcher, writers and policies
+// provide the values of the matching options under the env vars and the ConfigFileEnv file; its
+// specs are read by the e2e suite.
+const ProfileEnv = "SLOLAB_PROFILE"
+
+// LoadOptions Options holds E2E test configuration loaded from environment variables (and
+// ConfigFileEnv). Malformed values fall back to their defaults; use Load to see them.
 func LoadOptions() Options {
+	o, _, _ := Load()
+	return o
+}
+
+// Load reads the options from the environment, the ConfigFileEnv file, the ProfileEnv profile and
+// defaults, in that order.
+// The error joins every malformed value (the default is used instead) and invalid combination
+// (Options.Check); Provenance tells where each value came from.
+func Load() (Options, Provenance, error) {
+	l := &loader{}
+	if path := strings.TrimSpace(os.Getenv(ConfigFileEnv)); path != "" {
+		file, err := readConfigFile(path)
+		if err != nil {
+			l.errs = append(l.errs, err)
+		}
+		l.file, l.prov.File = file, path
+	}
+	if path, _ := l.scalar(ProfileEnv); path != "" {
+		p, err := slolab.Load(path)
+		if err == nil {
+			l.profile, err = profileValues(p)
+		}
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("%s: %w", ProfileEnv, err))
+		}
+	}
+	o := l.options()
+	errs := append(l.errs, o.Check()...)
+	return o, l.prov, errors.Join(errs...)
+}
+
+// options reads every option (see Load).
+func (l *loader) options() Options {
 	return Options{
-		Enabled: boolEnv("SLOLAB_ENABLED", false),
+		Enabled: l.bool("SLOLAB_ENABLED", false),
+		Profile: l.string(ProfileEnv, ""),
+
+		ArtifactsDir:     l.string("ARTIFACTS_DIR", "/tmp"),
+		RunID:            runid.Resolve(l.string("CI_RUN_ID", "")),
+		FailOnPolicy:     l.bool("SLOLAB_FAIL_ON_POLICY", false),
+		ReapMinAge:       l.duration("SLOLAB_REAP_MIN_AGE", 10*time.Minute),
+		UploadURL:        l.string("SLOLAB_UPLOAD_URL", ""),
+		Diag:             l.string("SLOLAB_DIAG", DiagOnFailure),
+		UpgradeFromImage: l.string("SLOLAB_UPGRADE_FROM_IMAGE", ""),
+		ManagerProfile:   l.string("SLOLAB_MANAGER_PROFILE", ""),
+		Namespaced:       l.bool("SLOLAB_NAMESPACED", false),
+		AuditLog:         l.string("SLOLAB_AUDIT_LOG", ""),
+		MetricsBaseline:  l.string("SLOLAB_METRICS_BASELINE", ""),
+		RequiredMetrics:  l.listOr("SLOLAB_REQUIRED_METRICS", DefaultRequiredMetrics),
+		BundleDir:        l.string("SLOLAB_BUNDLE_DIR", ""),
+		CaptureScrapes:   l.bool("SLOLAB_CAPTURE_SCRAPES", false),
+		MetricFilter:     l.bool("SLOLAB_METRIC_FILTER", false),
+		MetricInclude:    l.list("SLOLAB_METRIC_INCLUDE"),
+		MetricExclude:    l.string("SLOLAB_METRIC_EXCLUDE", ""),
+		OTLPEndpoint:     l.string("SLOLAB_OTLP_ENDPOINT", l.string("OTEL_EXPORTER_OTLP_ENDPOINT", "")),
+		ResultLine:       l.bool("SLOLAB_RESULT_LINE", false),
+		LogLevel:         l.level("SLOLAB_LOG_LEVEL", slo.LevelInfo),
+
+		MetricsScheme:      l.string("SLOLAB_METRICS_SCHEME", "https"),
+		MetricsPort:        l.int("SLOLAB_METRICS_PORT", 0),
+		MetricsPath:        l.string("SLOLAB_METRICS_PATH", "/metrics"),
+		MetricsInsecure:    l.bool("SLOLAB_METRICS_INSECURE", true),
+		MetricsCAFile:      l.string("SLOLAB_METRICS_CA_FILE", ""),
+		MetricsCASecret:    l.string("SLOLAB_METRICS_CA_SECRET", ""),
+		MetricsCAConfigMap: l.string("SLOLAB_METRICS_CA_CONFIGMAP", ""),
+		MetricsCAKey:       l.string("SLOLAB_METRICS_CA_KEY", "ca.crt"),
+
+		MetricsClientCertSecret: l.string("SLOLAB_METRICS_CLIENT_CERT_SECRET", ""),
+		MetricsProjectedToken:   l.bool("SLOLAB_METRICS_PROJECTED_TOKEN", false),
+
+		CurlImage:            l.string("SLOLAB_CURL_IMAGE", ""),
+		CurlImagePullPolicy:  l.string("SLOLAB_CURL_IMAGE_PULL_POLICY", ""),
+		CurlImagePullSecrets: l.list("SLOLAB_CURL_IMAGE_PULL_SECRETS"),
+		RegistryMirror:       l.string("SLOLAB_REGISTRY_MIRROR", ""),
+		CurlRunAsUser:        l.string("SLOLAB_CURL_RUN_AS_USER", ""),
+
+		CurlRequests:      l.list("SLOLAB_CURL_REQUESTS"),
+		CurlLimits:        l.list("SLOLAB_CURL_LIMITS"),
+		CurlNodeSelector:  l.list("SLOLAB_CURL_NODE_SELECTOR"),
+		CurlTolerations:   l.list("SLOLAB_CURL_TOLERATIONS"),
+		CurlPriorityClass: l.string("SLOLAB_CURL_PRIORITY_CLASS", ""),
+
+		LoadRate:     l.float("SLOLAB_LOAD_RATE", 0),
+		LoadObjects:  l.int("SLOLAB_LOAD_OBJECTS", 0),
+		LoadDuration: l.duration("SLOLAB_LOAD_DURATION", time.Minute),
+
+		ProcessMetrics: l.bool("SLOLAB_PROCESS_METRICS", false),
+		ClusterInfo:    l.bool("SLOLAB_CLUSTER_INFO", true),
+
+		MinMeasurementSuccess: l.float("SLOLAB_MIN_MEASUREMENT_SUCCESS", 0.95),
+
+		ArtifactsMaxAge:   l.duration("SLOLAB_ARTIFACTS_MAX_AGE", 0),
+		ArtifactsMaxFiles: l.int("SLOLAB_ARTIFACTS_MAX_FILES", 0),
+		CompressArtifacts: l.bool("SLOLAB_COMPRESS_ARTIFACTS", false),
 
-		ArtifactsDir: stringEnv("ARTIFACTS_DIR", "/tmp"),
-		RunID:        stringEnv("CI_RUN_ID", ""),
+		PrometheusURL:      l.string("SLOLAB_PROMETHEUS_URL", ""),
+		PrometheusSelector: l.string("SLOLAB_PROMETHEUS_SELECTOR", ""),
 
-		SkipCleanup:            boolEnv("E2E_SKIP_CLEANUP", false),
-		SkipCertManagerInstall: boolEnv("CERT_MANAGER_INSTALL_SKIP", false),
+		UploadKeepTags:     l.list("SLOLAB_UPLOAD_KEEP_TAGS"),
+		UploadMaskTags:     l.list("SLOLAB_UPLOAD_MASK_TAGS"),
+		UploadKeepFields:   l.list("SLOLAB_UPLOAD_KEEP_FIELDS"),
+		UploadDropWarnings: l.bool("SLOLAB_UPLOAD_DROP_WARNINGS", false),
 
-		TokenRequestTimeout: durationEnv("TOKEN_REQUEST_TIMEOUT", 2*time.Minute),
+		SkipCleanup:            l.bool("E2E_SKIP_CLEANUP", false),
+		SkipCertManagerInstall: l.bool("CERT_MANAGER_INSTALL_SKIP", false),
+		CertManagerVersion:     l.string("CERT_MANAGER_VERSION", ""),
+
+		TokenRequestTimeout: l.duration("TOKEN_REQUEST_TIMEOUT", 2*time.Minute),
 	}
 }
 
+// loader looks options up (env, then file, then profile, then default) and records their provenance.
+type loader struct {
+	file    map[string]string
+	profile map[string]string
+	prov    Provenance
+	errs    []error
+}
+
+// lookup returns the raw value of key and its source (SourceDefault => unset).
+func (l *loader) lookup(key string) (string, Source) {
+	if v, ok := os.LookupEnv(key); ok {
+		return v, SourceEnv
+	}
+	if v, ok := l.file[key]; ok {
+		return v, SourceFile
+	}
+	if v, ok := l.profile[key]; ok {
+		return v, SourceProfile
+	}
+	return "", SourceDefault
+}
+
+// scalar returns the trimmed value of key ("" => unset, the default applies).
+func (l *loader) scalar(key string) (string, Source) {
+	v, src := l.lookup(key)
+	if v = strings.TrimSpace(v); v == "" {
+		return "", SourceDefault
+	}
+	return v, src
+}
+
+func (l *loader) record(key, value string, src Source) {
+	l.prov.entries = append(l.prov.entries, entry{Key: key, Value: value, Source: src})
+}
+
+func (l *loader) invalid(key, value, want string, def any) {
+	l.errs = append(l.errs, fmt.Errorf("%s=%q: want %s (using default %v)", key, value, want, def))
+}
+
 // --- helpers (규칙 통일: "1"/"true"/"yes"/"on" 모두 허용) ---
 
-// stringEnv returns environment variable as string.
-func stringEnv(key, def string) string {
-	v := strings.TrimSpace(os.Getenv(key))
-	if v == "" {
-		return def
+// string returns the option as string.
+func (l *loader) string(key, def string) string {
+	v, src := l.scalar(key)
+	if src == SourceDefault {
+		v = def
 	}
+	l.record(key, v, src)
 	return v
 }
 
-// boolEnv parses environment variable as bool.
-func boolEnv(key string, def bool) bool {
-	v := strings.TrimSpace(os.Getenv(key))
-	if v == "" {
+// list parses a comma-separated list. Unset => nil (no allow-list), set but empty items => empty list.
+func (l *loader) list(key string) []string {
+	v, src := l.lookup(key)
+	if src == SourceDefault {
+		l.record(key, "", src)
+		return nil
+	}
+	out := []string{}
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	l.record(key, strings.Join(out, ","), src)
+	return out
+}
+
+// listOr is list with def when key is unset (set but empty => empty list).
+func (l *loader) listOr(key string, def []string) []string {
+	if v := l.list(key); v != nil {
+		return v
+	}
+	l.prov.entries[len(l.prov.entries)-1].Value = strings.Join(def, ",")
+	return append([]string(nil), def...)
+}
+
+// int parses the option as int.
+func (l *loader) int(key string, def int) int {
+	v, src := l.scalar(key)
+	n, err := strconv.Atoi(v)
+	if src == SourceDefault || err != nil {
+		if src != SourceDefault {
+			l.invalid(key, v, "an integer", def)
+		}
+		l.record(key, strconv.Itoa(def), SourceDefault)
+		return def
+	}
+	l.record(key, v, src)
+	return n
+}
+
+// float parses the option as float64.
+func (l *loader) float(key string, def float64) float64 {
+	v, src := l.scalar(key)
+	f, err := strconv.ParseFloat(v, 64)
+	if src == SourceDefault || err != nil {
+		if src != SourceDefault {
+			l.invalid(key, v, "a number", def)
+		}
+		l.record(key, strconv.FormatFloat(def, 'g', -1, 64), SourceDefault)
+		return def
+	}
+	l.record(key, v, src)
+	return f
+}
+
+// level parses the option as slo.Level (debug, info, warn).
+func (l *loader) level(key string, def slo.Level) slo.Level {
+	v, src := l.scalar(key)
+	lv, err := slo.ParseLevel(v)
+	if src == SourceDefault || err != nil {
+		if src != SourceDefault {
+			l.invalid(key, v, "a log level (debug, info, warn)", def)
+		}
+		l.record(key, def.String(), SourceDefault)
+		return def
+	}
+	l.record(key, lv.String(), src)
+	return lv
+}
+
+// bool parses the option as bool.
+func (l *loader) bool(key string, def bool) bool {
+	v, src := l.scalar(key)
+	b, ok := parseBool(v)
+	if src == SourceDefault || !ok {
+		if src != SourceDefault {
+			l.invalid(key, v, "a boolean (true/false, 1/0, yes/no, on/off)", def)
+		}
+		l.record(key, strconv.FormatBool(def), SourceDefault)
 		return def
 	}
+	l.record(key, strconv.FormatBool(b), src)
+	return b
+}
+
+func parseBool(v string) (value, ok bool) {
 	switch strings.ToLower(v) {
 	case "1", "true", "t", "yes", "y", "on":
-		return true
+		return true, true
 	case "0", "false", "f", "no", "n", "off":
-		return false
-	default:
-		return def
+		return false, true
 	}
+	return false, false
 }
 
-// durationEnv parses environment variable as time.Duration. 다만, 숫자만 들어오면 초단위로 간주.
-func durationEnv(key string, def time.Duration) time.Duration {
-	v := strings.TrimSpace(os.Getenv(key))
-	if v == "" {
+// duration parses the option as time.Duration. 다만, 숫자만 들어오면 초단위로 간주.
+func (l *loader) duration(key string, def time.Duration) time.Duration {
+	v, src := l.scalar(key)
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		n, nerr := strconv.Atoi(v)
+		d, err = time.Duration(n)*time.Second, nerr
+	}
+	if src == SourceDefault || err != nil {
+		if src != SourceDefault {
+			l.invalid(key, v, "a duration (e.g. 90s, 2m) or seconds", def)
+		}
+		l.record(key, def.String(), SourceDefault)
 		return def
 	}
-	if d, err := time.ParseDuration(v); err == nil {
-		return d
+	l.record(key, d.String(), src)
+	return d
+}
+
+// profileValues maps a profile's fetcher, writers and policies to option keys.
+func profileValues(p *slolab.Profile) (map[string]string, error) {
+	out := map[string]string{}
+	if f := p.Fetcher; f != nil {
+		switch f.Type {
+		case slolab.FetcherPrometheus:
+			out["SLOLAB_PROMETHEUS_URL"] = f.URL
+			out["SLOLAB_PROMETHEUS_SELECTOR"] = f.Selector
+		case slolab.FetcherHTTP:
+			return nil, fmt.Errorf("fetcher %s is not supported by the e2e suite (use %s or %s)",
+				f.Type, slolab.FetcherCurl, slolab.FetcherPrometheus)
+		}
+	}
+	for key, writer := range map[string]string{
+		"ARTIFACTS_DIR":        slolab.WriterLocal,
+		"SLOLAB_UPLOAD_URL":    slolab.WriterUpload,
+		"SLOLAB_OTLP_ENDPOINT": slolab.WriterOTLP,
+		"SLOLAB_BUNDLE_DIR":    slolab.WriterBundle,
+	} {
+		if target := p.Writer(writer); target != "" {
+			out[key] = target
+		}
+	}
+	if p.HasWriter(slolab.WriterStdout) {
+		out["SLOLAB_RESULT_LINE"] = "true"
+	}
+	if p.Policies.FailOnPolicy {
+		out["SLOLAB_FAIL_ON_POLICY"] = "true"
+	}
+	if u := p.Policies.Upload; u != nil {
+		for key, list := range map[string][]string{
+			"SLOLAB_UPLOAD_KEEP_TAGS":   u.KeepTags,
+			"SLOLAB_UPLOAD_MASK_TAGS":   u.MaskTags,
+			"SLOLAB_UPLOAD_KEEP_FIELDS": u.KeepResultFields,
+		} {
+			if list != nil {
+				out[key] = strings.Join(list, ",")
+			}
+		}
+		if u.DropWarnings {
+			out["SLOLAB_UPLOAD_DROP_WARNINGS"] = "true"
+		}
+	}
+	return out, nil
+}
+
+// readConfigFile parses KEY=VALUE lines ("export " prefixes and quoted values allowed).
+func readConfigFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ConfigFileEnv, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	out := map[string]string{}
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return out, fmt.Errorf("%s:%d: want KEY=VALUE, got %q", path, n, line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		out[key] = value
+	}
+	if err := sc.Err(); err != nil {
+		return out, fmt.Errorf("%s: %w", path, err)
 	}
-	if n, err := strconv.Atoi(v); err == nil {
-		return time.Duration(n) * time.Second
+	if _, ok := out[ConfigFileEnv]; ok {
+		return out, fmt.Errorf("%s: %s cannot be set in the config file", path, ConfigFileEnv)
 	}
-	return def
+	return out, nil
 }
@@ -0,0 +1,117 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadSourcesAndErrors(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "slolab.env")
+	content := "# CI defaults\nexport SLOLAB_ENABLED=true\nSLOLAB_REAP_MIN_AGE=\"5m\"\nSLOLAB_METRICS_PORT=9443\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ConfigFileEnv, file)
+	t.Setenv("ARTIFACTS_DIR", filepath.Join(dir, "artifacts"))
+	t.Setenv("SLOLAB_METRICS_PORT", "8443") // env wins over the file
+	t.Setenv("SLOLAB_FAIL_ON_POLICY", "maybe")
+	t.Setenv("SLOLAB_UPLOAD_URL", "https://user:pw@upload.example/x?sig=s3cr3t")
+
+	o, prov, err := Load()
+	if !o.Enabled || o.ReapMinAge != 5*time.Minute || o.MetricsPort != 8443 || o.FailOnPolicy {
+		t.Fatalf("unexpected options %+v", o)
+	}
+	if err == nil || !strings.Contains(err.Error(), `SLOLAB_FAIL_ON_POLICY="maybe"`) {
+		t.Fatalf("malformed bool should be reported, got %v", err)
+	}
+	for key, want := range map[string]Source{
+		"SLOLAB_ENABLED":        SourceFile,
+		"SLOLAB_METRICS_PORT":   SourceEnv,
+		"SLOLAB_FAIL_ON_POLICY": SourceDefault,
+		"SLOLAB_DIAG":           SourceDefault,
+	} {
+		if got := prov.Source(key); got != want {
+			t.Errorf("source of %s = %s, want %s", key, got, want)
+		}
+	}
+	line := prov.String()
+	if strings.Contains(line, "pw@") || strings.Contains(line, "s3cr3t") ||
+		!strings.Contains(line, `SLOLAB_ENABLED="true"(file)`) {
+		t.Fatalf("effective config not redacted or incomplete: %s", line)
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	profile := filepath.Join(dir, "slolab.yaml")
+	content := `writers:
+  - {type: local, target: ` + dir + `}
+  - {type: upload, target: "s3://bucket/slo"}
+fetcher: {type: prometheus, url: "http://prometheus:9090", selector: 'namespace="x"'}
+policies: {failOnPolicy: true, upload: {keepTags: []}}
+`
+	if err := os.WriteFile(profile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ProfileEnv, profile)
+	t.Setenv("SLOLAB_UPLOAD_URL", "gs://other/slo") // env wins over the profile
+
+	o, prov, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if o.Profile != profile || o.ArtifactsDir != dir || o.UploadURL != "gs://other/slo" || !o.FailOnPolicy ||
+		o.PrometheusURL != "http://prometheus:9090" || o.PrometheusSelector != `namespace="x"` ||
+		o.UploadKeepTags == nil || len(o.UploadKeepTags) != 0 {
+		t.Fatalf("unexpected options %+v", o)
+	}
+	if got := prov.Source("ARTIFACTS_DIR"); got != SourceProfile {
+		t.Errorf("source of ARTIFACTS_DIR = %s, want %s", got, SourceProfile)
+	}
+
+	if err := os.WriteFile(profile, []byte("fetcher: {type: http, url: http://x}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Load(); err == nil || !strings.Contains(err.Error(), "not supported by the e2e suite") {
+		t.Errorf("http fetcher should be rejected, got %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	o := Options{
+		Enabled: true, ArtifactsDir: t.TempDir(), Diag: DiagOnFailure, MetricsScheme: "https",
+		TokenRequestTimeout: time.Minute,
+	}
+	if errs := o.Check(); len(errs) != 0 {
+		t.Fatalf("valid options rejected: %v", errs)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o.ArtifactsDir = filepath.Join(file, "sub") // below a regular file: not creatable
+	o.MetricExclude = "("
+	o.UploadDropWarnings = true
+	o.LoadRate = -1
+	if errs := o.Check(); len(errs) != 5 {
+		t.Fatalf("want artifacts dir, load, filter switch, regexp and upload filter errors, got %v", errs)
+	}
+}
+
+func TestLoadRejectsUnknownDiag(t *testing.T) {
+	t.Setenv(ConfigFileEnv, "")
+	t.Setenv("SLOLAB_DIAG", "sometimes")
+	// BeforeSuite fails on this error, so a typo cannot silently turn the diagnostics off
+	if _, _, err := Load(); err == nil || !strings.Contains(err.Error(), `SLOLAB_DIAG="sometimes"`) {
+		t.Fatalf("expected the unknown SLOLAB_DIAG to be reported, got %v", err)
+	}
+	t.Setenv("SLOLAB_DIAG", DiagAlways)
+	if o, _, err := Load(); err != nil || o.Diag != DiagAlways {
+		t.Fatalf("diag = %q, err = %v", o.Diag, err)
+	}
+}
@@ -1,19 +1,156 @@
 package env
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
 	"path/filepath"
+	"regexp"
 	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+)
+
+// Diag modes.
+const (
+	DiagOnFailure = "on-failure"
+	DiagAlways    = "always"
+	DiagOff       = "off"
 )
 
+// DefaultRequiredMetrics are the metric families every controller-runtime manager exposes.
+var DefaultRequiredMetrics = []string{
+	"controller_runtime_reconcile_total",
+	"controller_runtime_reconcile_errors_total",
+	"workqueue_depth",
+	"rest_client_requests_total",
+}
+
 // Options is e2e-only configuration.
 // Keep this independent from pkg/slo (v1 legacy).
 type Options struct {
-	Enabled      bool
+	Enabled bool
+	// Profile is a slolab.yaml profile (ProfileEnv): its presets, metrics and objectives replace the
+	// default specs (ProcessMetrics included) when it names any.
+	Profile      string
 	ArtifactsDir string
+	// RunID is CI_RUN_ID, else the CI system's run or a ULID (runid.Resolve): never empty.
 	RunID        string
+	FailOnPolicy bool
+	// ReapMinAge is the age after which leftover curl-metrics pods are reaped at suite start.
+	ReapMinAge time.Duration
+	// ArtifactsMaxAge and ArtifactsMaxFiles prune old summaries and raw scrapes from ArtifactsDir
+	// at suite start (artifacts.Prune; 0 => no limit, both 0 => off).
+	ArtifactsMaxAge   time.Duration
+	ArtifactsMaxFiles int
+	// UploadURL (s3://bucket/prefix, gs://bucket/prefix or https://...) also uploads summaries.
+	UploadURL string
+	// Upload* filter what is uploaded (nil keep list => keep all); the local JSON keeps everything.
+	UploadKeepTags     []string
+	UploadMaskTags     []string
+	UploadKeepFields   []string
+	UploadDropWarnings bool
+	// OTLPEndpoint exports summaries to an OpenTelemetry collector over OTLP/HTTP (empty => off).
+	OTLPEndpoint string
+	// ResultLine prints each summary as one `SLOLAB_RESULT {json}` line on stdout, for CI that
+	// only keeps logs (the Upload* filters apply).
+	ResultLine bool
+	// LogLevel is the minimum level of the suite's GinkgoWriter log lines (SLOLAB_LOG_LEVEL:
+	// debug shows per-poll wait lines, warn keeps only problems).
+	LogLevel slo.Level
+	// PrometheusURL reads snapshots from Prometheus instead of curl-pod scrapes (empty => curl pod).
+	PrometheusURL string
+	// PrometheusSelector narrows the queried series (empty => the operator namespace).
+	PrometheusSelector string
+	// Metrics* describe the operator's metrics endpoint as scraped by the curl pod
+	// (defaults: https, port 8443 (8080 for http), /metrics, certificate not verified).
+	// A CA source (MetricsCAFile PEM, MetricsCASecret e.g. "metrics-server-cert", or
+	// MetricsCAConfigMap e.g. "kube-root-ca.crt", key MetricsCAKey) turns verification on;
+	// MetricsInsecure only applies without one.
+	MetricsScheme      string
+	MetricsPort        int
+	MetricsPath        string
+	MetricsInsecure    bool
+	MetricsCAFile      string
+	MetricsCASecret    string
+	MetricsCAConfigMap string
+	MetricsCAKey       string
+	// MetricsClientCertSecret is a kubernetes.io/tls Secret presented as client certificate (mTLS).
+	MetricsClientCertSecret string
+	// MetricsProjectedToken has the curl pod use its own ServiceAccount token (projected volume)
+	// instead of a requested token passed in a Secret.
+	MetricsProjectedToken bool
+	// CurlImage is the curl pod image (empty => curlmetrics.DefaultImage; pin a digest with
+	// image@sha256:...). RegistryMirror rewrites its registry (air-gapped clusters), and
+	// CurlImagePullPolicy/CurlImagePullSecrets control the pull.
+	CurlImage            string
+	CurlImagePullPolicy  string
+	CurlImagePullSecrets []string
+	RegistryMirror       string
+	// CurlRunAsUser is the curl container's UID: "" => 1000, "auto" => the namespace's OpenShift UID
+	// range (restricted SCC), "none" => unset, or a UID (see curlmetrics.ParseRunAsUser).
+	CurlRunAsUser string
+	// Curl{Requests,Limits,NodeSelector} are key=value lists (unset => curlmetrics.DefaultScheduling
+	// resources, any node); CurlTolerations use taint syntax (key=value:Effect); CurlPriorityClass
+	// is the pod's priorityClassName.
+	CurlRequests      []string
+	CurlLimits        []string
+	CurlNodeSelector  []string
+	CurlTolerations   []string
+	CurlPriorityClass string
+	// ProcessMetrics adds the Go runtime/process resource-usage measurements (memory, CPU, GC).
+	ProcessMetrics bool
+	// ClusterInfo tags summaries with the k8s version, node count, provider and operator image
+	// (harness.ClusterInfoHooks; on by default).
+	ClusterInfo bool
+	// MinMeasurementSuccess is the fetch success rate (0..1) below which the suite's measurement
+	// report warns that skipped SLIs hide measurement decay (harness.WriteMeasurementReport).
+	MinMeasurementSuccess float64
+	// BundleDir records evaluation inputs for `slocli replay` (empty => off).
+	BundleDir string
+	// CaptureScrapes writes every raw curl-pod /metrics body to ArtifactsDir (gzip'd) for debugging.
+	CaptureScrapes bool
+	// CompressArtifacts gzips failure dumps and replay bundles (<name>.gz), to stay within CI
+	// artifact quotas; loaders and slocli read both forms.
+	CompressArtifacts bool
+	// MetricFilter keeps only the metrics the specs read in session snapshots, plus MetricInclude
+	// and minus the MetricExclude regular expression.
+	MetricFilter  bool
+	MetricInclude []string
+	MetricExclude string
+	// RequiredMetrics are the metric families the metrics sanity spec requires
+	// (SLOLAB_REQUIRED_METRICS, comma-separated; unset => DefaultRequiredMetrics, "" => none).
+	RequiredMetrics []string
+	// MetricsBaseline is a metric-families artifact (or raw scrape) of a known-good run; the metrics
+	// sanity spec fails on removed, renamed or changed families (empty => no drift check).
+	MetricsBaseline string
+	// AuditLog is an API server audit log (JSON lines) read by the RBAC verification spec to diff the
+	// permissions the operator used against config/rbac (empty => only 403 responses are checked).
+	AuditLog string
+	// UpgradeFromImage enables the upgrade scenario: this released image is deployed first and then
+	// upgraded to the image under test (empty => spec skipped).
+	UpgradeFromImage string
+	// ManagerProfile deploys the manager with a templated --config file (manifests.ManagerProfile:
+	// default, concurrent, slo-agent; empty => flags only).
+	ManagerProfile string
+	// Namespaced deploys config/namespaced: the manager watches only its namespace with a Role
+	// instead of a ClusterRole.
+	Namespaced bool
+	// LoadRate enables the CR churn spec: JobOperators are created, updated and deleted at this many
+	// operations per second during the window (load.Generator; 0 => spec skipped).
+	LoadRate float64
+	// LoadObjects is the number of churned JobOperators (0 => load default).
+	LoadObjects int
+	// LoadDuration is how long the churn spec measures.
+	LoadDuration time.Duration
+	// Diag controls the namespace diagnostics tarball written in AfterAll (DiagOnFailure/DiagAlways/DiagOff).
+	Diag string
 
 	SkipCleanup            bool
 	SkipCertManagerInstall bool
+	// CertManagerVersion pins the installed cert-manager release (empty => kubeutil.CertManagerVersion).
+	CertManagerVersion string
 
 	TokenRequestTimeout time.Duration
 }
@@ -23,6 +160,12 @@ func (o Options) Validate() Options {
 	if out.ArtifactsDir == "" {
 		out.ArtifactsDir = "/tmp"
 	}
+	if out.Diag == "" {
+		out.Diag = DiagOnFailure
+	}
+	if out.ReapMinAge == 0 {
+		out.ReapMinAge = 10 * time.Minute
+	}
 	if out.TokenRequestTimeout == 0 {
 		out.TokenRequestTimeout = 2 * time.Minute
 	}
@@ -36,3 +179,102 @@ func (o Options) SummaryPath(filename string) string {
 	}
 	return filepath.Join(v.ArtifactsDir, filename)
 }
+
+// Check reports invalid values and combinations that single options cannot show, e.g. a summary
+// directory that cannot be written or a filter option without its switch.
+func (o Options) Check() []error {
+	var errs []error
+	if o.Enabled {
+		if err := writableDir(o.ArtifactsDir); err != nil {
+			errs = append(errs, fmt.Errorf("SLOLAB_ENABLED=true needs a writable ARTIFACTS_DIR: %w", err))
+		}
+	}
+	if o.BundleDir != "" {
+		if err := writableDir(o.BundleDir); err != nil {
+			errs = append(errs, fmt.Errorf("SLOLAB_BUNDLE_DIR: %w", err))
+		}
+	}
+	switch o.Diag {
+	case DiagOnFailure, DiagAlways, DiagOff:
+	default:
+		errs = append(errs, fmt.Errorf("SLOLAB_DIAG=%q: want %s, %s or %s", o.Diag, DiagOnFailure, DiagAlways, DiagOff))
+	}
+	if o.LoadRate < 0 || o.LoadObjects < 0 {
+		errs = append(errs, fmt.Errorf("SLOLAB_LOAD_RATE=%g/SLOLAB_LOAD_OBJECTS=%d: must not be negative",
+			o.LoadRate, o.LoadObjects))
+	}
+	if o.LoadRate > 0 && o.LoadDuration <= 0 {
+		errs = append(errs, fmt.Errorf("SLOLAB_LOAD_DURATION=%s: must be positive", o.LoadDuration))
+	}
+	if o.MetricsScheme != "http" && o.MetricsScheme != "https" {
+		errs = append(errs, fmt.Errorf("SLOLAB_METRICS_SCHEME=%q: want http or https", o.MetricsScheme))
+	}
+	if o.MetricsPort < 0 || o.MetricsPort > 65535 {
+		errs = append(errs, fmt.Errorf("SLOLAB_METRICS_PORT=%d: out of range", o.MetricsPort))
+	}
+	if o.MetricsCAFile != "" {
+		if _, err := os.Stat(o.MetricsCAFile); err != nil {
+			errs = append(errs, fmt.Errorf("SLOLAB_METRICS_CA_FILE: %w", err))
+		}
+	}
+	if !o.MetricFilter && (len(o.MetricInclude) > 0 || o.MetricExclude != "") {
+		errs = append(errs, errors.New("SLOLAB_METRIC_INCLUDE/SLOLAB_METRIC_EXCLUDE need SLOLAB_METRIC_FILTER=true"))
+	}
+	if _, err := regexp.Compile(o.MetricExclude); err != nil {
+		errs = append(errs, fmt.Errorf("SLOLAB_METRIC_EXCLUDE: %w", err))
+	}
+	if err := checkURL(o.UploadURL, "s3", "gs", "http", "https"); err != nil {
+		errs = append(errs, fmt.Errorf("SLOLAB_UPLOAD_URL: %w", err))
+	}
+	if err := checkURL(o.PrometheusURL, "http", "https"); err != nil {
+		errs = append(errs, fmt.Errorf("SLOLAB_PROMETHEUS_URL: %w", err))
+	}
+	uploadFilters := o.UploadKeepTags != nil || o.UploadMaskTags != nil || o.UploadKeepFields != nil ||
+		o.UploadDropWarnings
+	if uploadFilters && o.UploadURL == "" && o.OTLPEndpoint == "" {
+		errs = append(errs, errors.New("SLOLAB_UPLOAD_* filters need SLOLAB_UPLOAD_URL or an OTLP endpoint"))
+	}
+	switch o.CurlImagePullPolicy {
+	case "", "Always", "IfNotPresent", "Never":
+	default:
+		errs = append(errs, fmt.Errorf("SLOLAB_CURL_IMAGE_PULL_POLICY=%q: want Always, IfNotPresent or Never",
+			o.CurlImagePullPolicy))
+	}
+	if o.TokenRequestTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("TOKEN_REQUEST_TIMEOUT=%s: must be positive", o.TokenRequestTimeout))
+	}
+	return errs
+}
+
+// writableDir creates dir if needed and checks a file can be created in it.
+func writableDir(dir string) error {
+	if dir == "" {
+		return errors.New("not set")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".slolab-write-check-*")
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkURL validates an optional URL and its scheme.
+func checkURL(raw string, schemes ...string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return errors.New("not a valid URL") // url errors quote the URL, credentials included
+	}
+	for _, s := range schemes {
+		if u.Scheme == s && u.Host != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q: want a %v URL with a host", redactValue(raw), schemes)
+}
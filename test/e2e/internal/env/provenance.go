@@ -0,0 +1,79 @@
+package env
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/yeongki/my-operator/pkg/kubeutil"
+)
+
+// Source is where an option's value came from.
+type Source string
+
+const (
+	SourceEnv     Source = "env"
+	SourceFile    Source = "file"
+	SourceProfile Source = "profile"
+	SourceDefault Source = "default"
+)
+
+// Provenance records the effective value and source of every option, in load order.
+type Provenance struct {
+	// File is the ConfigFileEnv file read ("" => none).
+	File    string
+	entries []entry
+}
+
+type entry struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+// Source returns where key's value came from (SourceDefault for unknown keys).
+func (p Provenance) Source(key string) Source {
+	for _, e := range p.entries {
+		if e.Key == key {
+			return e.Source
+		}
+	}
+	return SourceDefault
+}
+
+// String is the effective configuration on one line, KEY=value(source) with the defaults last,
+// redacted (see redactValue) so it can be logged at suite start.
+func (p Provenance) String() string {
+	var set, defaults []string
+	for _, e := range p.entries {
+		kv := fmt.Sprintf("%s=%q", e.Key, redactValue(e.Value))
+		if e.Source == SourceDefault {
+			defaults = append(defaults, kv)
+			continue
+		}
+		set = append(set, fmt.Sprintf("%s(%s)", kv, e.Source))
+	}
+	out := strings.Join(set, " ")
+	if p.File != "" {
+		out = fmt.Sprintf("file=%q %s", p.File, out)
+	}
+	return strings.TrimSpace(out + " defaults: " + strings.Join(defaults, " "))
+}
+
+// redactValue masks credentials: URL user info and query values (upload and OTLP endpoints
+// may carry them) and anything kubeutil.Redact recognizes.
+func redactValue(v string) string {
+	if u, err := url.Parse(v); err == nil && u.Scheme != "" && u.Host != "" {
+		if u.User != nil {
+			u.User = url.User("REDACTED")
+		}
+		if q := u.Query(); len(q) > 0 {
+			for k := range q {
+				q.Set(k, "REDACTED")
+			}
+			u.RawQuery = q.Encode()
+		}
+		v = u.String()
+	}
+	return kubeutil.Redact(v)
+}
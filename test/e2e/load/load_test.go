@@ -0,0 +1,69 @@
+package load
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/clock"
+)
+
+// fakeRunner sends "<verb> <object>" for every kubectl call.
+type fakeRunner struct {
+	ops chan string
+}
+
+func (f *fakeRunner) Run(_ context.Context, _ slo.Logger, cmd *exec.Cmd) (string, error) {
+	manifest, _ := io.ReadAll(cmd.Stdin)
+	name := ""
+	for _, line := range strings.Split(string(manifest), "\n") {
+		if v, ok := strings.CutPrefix(line, "  name: "); ok {
+			name = v
+		}
+	}
+	f.ops <- cmd.Args[1] + " " + name
+	return "", nil
+}
+
+func TestGeneratorCycle(t *testing.T) {
+	r := &fakeRunner{ops: make(chan string, 16)}
+	c := clock.NewFake(time.Unix(1700000000, 0))
+	g := New(Options{Namespace: "ns", Objects: 2, Rate: 10, UpdatesPerObject: 1, Runner: r, Clock: c})
+	if err := g.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// one operation per tick, round robin over the objects
+	want := []string{"apply slo-load-0", "apply slo-load-1", "apply slo-load-0", "apply slo-load-1", "delete slo-load-0"}
+	for i, w := range want {
+		c.Advance(100 * time.Millisecond)
+		select {
+		case got := <-r.ops:
+			if got != w {
+				t.Fatalf("tick %d: got %q, want %q", i, got, w)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("tick %d: no operation", i)
+		}
+	}
+	rep := g.Stop(context.Background())
+	// Stop deletes the object that still exists
+	if got := <-r.ops; got != "delete slo-load-1" {
+		t.Fatalf("cleanup: got %q", got)
+	}
+	if rep.Objects != 2 || rep.Created != 2 || rep.Updated != 2 || rep.Deleted != 2 || rep.Failed != 0 {
+		t.Fatalf("report = %+v", rep)
+	}
+
+	// a stopped generator can serve the next window with fresh counters
+	if err := g.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if next := g.Stop(context.Background()); next.Created != 0 || next.Deleted != 0 {
+		t.Fatalf("counters not reset: %+v", next)
+	}
+}
@@ -3,3 +3,32 @@ package manifests
 type NamespaceData struct {
 	Namespace string
 }
+
+// ManagerConfigName is the ConfigMap holding the manager --config file (key config.yaml).
+const ManagerConfigName = "controller-manager-config"
+
+// ManagerConfigData renders manager-config.tmpl.yaml.gotmpl.
+type ManagerConfigData struct {
+	Namespace string
+	Name      string
+	Profile   string
+
+	ReadyzChecks            string
+	MaxConcurrentReconciles int
+	// SLOAgentWindow turns on the in-process SLO agent (e.g. "2m"; "" => off).
+	SLOAgentWindow string
+}
+
+// managerProfiles are the test profiles selectable with SLOLAB_MANAGER_PROFILE.
+var managerProfiles = map[string]ManagerConfigData{
+	"default":    {ReadyzChecks: "ping,cache-sync"},
+	"concurrent": {ReadyzChecks: "ping,cache-sync", MaxConcurrentReconciles: 4},
+	"slo-agent":  {ReadyzChecks: "ping,cache-sync", SLOAgentWindow: "2m"},
+}
+
+// ManagerProfile returns the config of a named test profile for the manager in namespace.
+func ManagerProfile(name, namespace string) (ManagerConfigData, bool) {
+	d, ok := managerProfiles[name]
+	d.Namespace, d.Name, d.Profile = namespace, ManagerConfigName, name
+	return d, ok
+}
@@ -0,0 +1,36 @@
+package integration
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	batchv1 "github.com/yeongki/my-operator/api/v1"
+)
+
+var _ = Describe("JobOperator", func() {
+	attachSLO()
+
+	It("creates the StatefulSet of a new JobOperator", func(sctx SpecContext) {
+		obj := &batchv1.JobOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: "integration", Namespace: "default"},
+			Spec:       batchv1.JobOperatorSpec{Replicas: ptr.To[int32](1), Image: "nginx:latest", Port: 80},
+		}
+		Expect(k8sClient.Create(sctx, obj)).To(Succeed())
+		DeferCleanup(func(sctx SpecContext) {
+			Expect(k8sClient.Delete(sctx, obj)).To(Succeed())
+		})
+
+		sts := &appsv1.StatefulSet{}
+		Eventually(func() error {
+			return k8sClient.Get(sctx, types.NamespacedName{Name: "integration-sts", Namespace: "default"}, sts)
+		}).WithTimeout(30 * time.Second).Should(Succeed())
+		Expect(sts.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:latest"))
+	})
+})
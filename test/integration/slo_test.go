@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+
+	"github.com/yeongki/my-operator/pkg/slo"
+	"github.com/yeongki/my-operator/pkg/slo/artifacts"
+	"github.com/yeongki/my-operator/pkg/slo/presets"
+	"github.com/yeongki/my-operator/pkg/slo/summary"
+	"github.com/yeongki/my-operator/pkg/slogather"
+	"github.com/yeongki/my-operator/test/e2e/e2eutil"
+	"github.com/yeongki/my-operator/test/e2e/harness"
+)
+
+// attachSLO measures each spec of the calling container with the controller-runtime preset.
+// Summaries go to $ARTIFACTS_DIR (when set); like in e2e, measurement problems are only logged.
+func attachSLO() {
+	var window *slogather.Window
+	log := e2eutil.GinkgoLog
+
+	BeforeEach(func(sctx SpecContext) {
+		w, err := slogather.Start(sctx, slogather.New(nil))
+		if err != nil {
+			slo.Warnf(log, "SLO(integration): start skipped: %v", err)
+			return
+		}
+		w.RunID = os.Getenv("CI_RUN_ID")
+		w.Tags = map[string]string{"suite": "integration", "test_case": CurrentSpecReport().LeafNodeText}
+		window = w
+	})
+
+	AfterEach(func(sctx SpecContext) {
+		if window == nil {
+			return
+		}
+		w := window
+		window = nil
+
+		sum, err := w.End(sctx, presets.ControllerRuntime())
+		if err != nil {
+			slo.Warnf(log, "SLO(integration): end skipped: %v", err)
+			return
+		}
+		for _, r := range sum.Results {
+			if r.Value != nil {
+				log.Logf("SLO(integration): %s = %g", r.ID, *r.Value)
+			}
+		}
+		for _, r := range sum.Breaches() {
+			slo.Warnf(log, "%s", summary.BreachMessage(r))
+		}
+
+		dir := strings.TrimSpace(os.Getenv("ARTIFACTS_DIR"))
+		if dir == "" {
+			return
+		}
+		name := fmt.Sprintf("sli-summary.integration.%s.json", harness.SanitizeFilename(w.Tags["test_case"]))
+		out := artifacts.NewIndexedSummaryWriter(dir, artifacts.DefaultOptions())
+		if err := out.Write(filepath.Join(dir, name), *sum); err != nil {
+			slo.Warnf(log, "SLO(integration): write skipped: %v", err)
+		}
+	})
+}